@@ -23,16 +23,19 @@ const (
 type LockMechanism int32
 
 const (
-	LockMechanism_HTLC LockMechanism = 0
+	LockMechanism_HTLC      LockMechanism = 0
+	LockMechanism_SIGNATURE LockMechanism = 1
 )
 
 // Enum value maps for LockMechanism.
 var (
 	LockMechanism_name = map[int32]string{
 		0: "HTLC",
+		1: "SIGNATURE",
 	}
 	LockMechanism_value = map[string]int32{
-		"HTLC": 0,
+		"HTLC":      0,
+		"SIGNATURE": 1,
 	}
 )
 
@@ -66,8 +69,12 @@ func (LockMechanism) EnumDescriptor() ([]byte, []int) {
 type HashMechanism int32
 
 const (
-	HashMechanism_SHA256 HashMechanism = 0
-	HashMechanism_SHA512 HashMechanism = 1
+	HashMechanism_SHA256   HashMechanism = 0
+	HashMechanism_SHA512   HashMechanism = 1
+	HashMechanism_SHA3_256 HashMechanism = 2
+	// Legacy Keccak-256 (as used by Ethereum), which differs from SHA3-256 in its padding
+	HashMechanism_KECCAK256  HashMechanism = 3
+	HashMechanism_BLAKE2B256 HashMechanism = 4
 )
 
 // Enum value maps for HashMechanism.
@@ -75,10 +82,16 @@ var (
 	HashMechanism_name = map[int32]string{
 		0: "SHA256",
 		1: "SHA512",
+		2: "SHA3_256",
+		3: "KECCAK256",
+		4: "BLAKE2B256",
 	}
 	HashMechanism_value = map[string]int32{
-		"SHA256": 0,
-		"SHA512": 1,
+		"SHA256":     0,
+		"SHA512":     1,
+		"SHA3_256":   2,
+		"KECCAK256":  3,
+		"BLAKE2B256": 4,
 	}
 )
 
@@ -391,34 +404,592 @@ func (x *AssetClaimHTLC) GetHashPreimageBase64() []byte {
 	return nil
 }
 
+// Lock whose claim is authorised by a signature from a designated arbiter (e.g., a neutral notary in an escrow flow)
+type AssetLockSignature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// PEM-encoded public key or X.509 certificate of the arbiter (ECDSA or Ed25519)
+	ArbiterPublicKey []byte   `protobuf:"bytes,1,opt,name=arbiterPublicKey,proto3" json:"arbiterPublicKey,omitempty"`
+	ExpiryTimeSecs   uint64   `protobuf:"varint,2,opt,name=expiryTimeSecs,proto3" json:"expiryTimeSecs,omitempty"`
+	TimeSpec         TimeSpec `protobuf:"varint,3,opt,name=timeSpec,proto3,enum=common.asset_locks.TimeSpec" json:"timeSpec,omitempty"`
+}
+
+func (x *AssetLockSignature) Reset() {
+	*x = AssetLockSignature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssetLockSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssetLockSignature) ProtoMessage() {}
+
+func (x *AssetLockSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssetLockSignature.ProtoReflect.Descriptor instead.
+func (*AssetLockSignature) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AssetLockSignature) GetArbiterPublicKey() []byte {
+	if x != nil {
+		return x.ArbiterPublicKey
+	}
+	return nil
+}
+
+func (x *AssetLockSignature) GetExpiryTimeSecs() uint64 {
+	if x != nil {
+		return x.ExpiryTimeSecs
+	}
+	return 0
+}
+
+func (x *AssetLockSignature) GetTimeSpec() TimeSpec {
+	if x != nil {
+		return x.TimeSpec
+	}
+	return TimeSpec_EPOCH
+}
+
+type AssetClaimSignature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Arbiter's signature (ASN.1 DER for ECDSA) over the claim message "<contractId>:<recipient>",
+	// where the ECDSA signature is computed on the SHA-256 digest of the message
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *AssetClaimSignature) Reset() {
+	*x = AssetClaimSignature{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssetClaimSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssetClaimSignature) ProtoMessage() {}
+
+func (x *AssetClaimSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssetClaimSignature.ProtoReflect.Descriptor instead.
+func (*AssetClaimSignature) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AssetClaimSignature) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
 type AssetExchangeAgreement struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	AssetType string `protobuf:"bytes,1,opt,name=assetType,proto3" json:"assetType,omitempty"`
-	Id        string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	Locker    string `protobuf:"bytes,3,opt,name=locker,proto3" json:"locker,omitempty"`
-	Recipient string `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	AssetType string `protobuf:"bytes,1,opt,name=assetType,proto3" json:"assetType,omitempty"`
+	Id        string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Locker    string `protobuf:"bytes,3,opt,name=locker,proto3" json:"locker,omitempty"`
+	Recipient string `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`
+}
+
+func (x *AssetExchangeAgreement) Reset() {
+	*x = AssetExchangeAgreement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssetExchangeAgreement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssetExchangeAgreement) ProtoMessage() {}
+
+func (x *AssetExchangeAgreement) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssetExchangeAgreement.ProtoReflect.Descriptor instead.
+func (*AssetExchangeAgreement) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AssetExchangeAgreement) GetAssetType() string {
+	if x != nil {
+		return x.AssetType
+	}
+	return ""
+}
+
+func (x *AssetExchangeAgreement) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AssetExchangeAgreement) GetLocker() string {
+	if x != nil {
+		return x.Locker
+	}
+	return ""
+}
+
+func (x *AssetExchangeAgreement) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+type HybridAssetExchangeAgreement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AssetType string `protobuf:"bytes,1,opt,name=assetType,proto3" json:"assetType,omitempty"`
+	Id        string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	AssetData []byte `protobuf:"bytes,3,opt,name=assetData,proto3" json:"assetData,omitempty"`
+	NumUnits  uint64 `protobuf:"varint,4,opt,name=numUnits,proto3" json:"numUnits,omitempty"`
+	Locker    string `protobuf:"bytes,5,opt,name=locker,proto3" json:"locker,omitempty"`
+	Recipient string `protobuf:"bytes,6,opt,name=recipient,proto3" json:"recipient,omitempty"`
+}
+
+func (x *HybridAssetExchangeAgreement) Reset() {
+	*x = HybridAssetExchangeAgreement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HybridAssetExchangeAgreement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HybridAssetExchangeAgreement) ProtoMessage() {}
+
+func (x *HybridAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HybridAssetExchangeAgreement.ProtoReflect.Descriptor instead.
+func (*HybridAssetExchangeAgreement) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HybridAssetExchangeAgreement) GetAssetType() string {
+	if x != nil {
+		return x.AssetType
+	}
+	return ""
+}
+
+func (x *HybridAssetExchangeAgreement) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *HybridAssetExchangeAgreement) GetAssetData() []byte {
+	if x != nil {
+		return x.AssetData
+	}
+	return nil
+}
+
+func (x *HybridAssetExchangeAgreement) GetNumUnits() uint64 {
+	if x != nil {
+		return x.NumUnits
+	}
+	return 0
+}
+
+func (x *HybridAssetExchangeAgreement) GetLocker() string {
+	if x != nil {
+		return x.Locker
+	}
+	return ""
+}
+
+func (x *HybridAssetExchangeAgreement) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+type FungibleAssetExchangeAgreement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AssetType string `protobuf:"bytes,1,opt,name=assetType,proto3" json:"assetType,omitempty"`
+	NumUnits  uint64 `protobuf:"varint,2,opt,name=numUnits,proto3" json:"numUnits,omitempty"`
+	Locker    string `protobuf:"bytes,3,opt,name=locker,proto3" json:"locker,omitempty"`
+	Recipient string `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`
+}
+
+func (x *FungibleAssetExchangeAgreement) Reset() {
+	*x = FungibleAssetExchangeAgreement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FungibleAssetExchangeAgreement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FungibleAssetExchangeAgreement) ProtoMessage() {}
+
+func (x *FungibleAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FungibleAssetExchangeAgreement.ProtoReflect.Descriptor instead.
+func (*FungibleAssetExchangeAgreement) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FungibleAssetExchangeAgreement) GetAssetType() string {
+	if x != nil {
+		return x.AssetType
+	}
+	return ""
+}
+
+func (x *FungibleAssetExchangeAgreement) GetNumUnits() uint64 {
+	if x != nil {
+		return x.NumUnits
+	}
+	return 0
+}
+
+func (x *FungibleAssetExchangeAgreement) GetLocker() string {
+	if x != nil {
+		return x.Locker
+	}
+	return ""
+}
+
+func (x *FungibleAssetExchangeAgreement) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+// Agreement to lock several assets, non-fungible and fungible, under a single lock and contract ID (a basket),
+// to be claimed or unlocked together. The locker and recipient apply to all the assets in the basket.
+type BasketAssetExchangeAgreement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Assets         []*AssetExchangeAgreement         `protobuf:"bytes,1,rep,name=assets,proto3" json:"assets,omitempty"`
+	FungibleAssets []*FungibleAssetExchangeAgreement `protobuf:"bytes,2,rep,name=fungibleAssets,proto3" json:"fungibleAssets,omitempty"`
+	Locker         string                            `protobuf:"bytes,3,opt,name=locker,proto3" json:"locker,omitempty"`
+	Recipient      string                            `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`
+}
+
+func (x *BasketAssetExchangeAgreement) Reset() {
+	*x = BasketAssetExchangeAgreement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BasketAssetExchangeAgreement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BasketAssetExchangeAgreement) ProtoMessage() {}
+
+func (x *BasketAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BasketAssetExchangeAgreement.ProtoReflect.Descriptor instead.
+func (*BasketAssetExchangeAgreement) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BasketAssetExchangeAgreement) GetAssets() []*AssetExchangeAgreement {
+	if x != nil {
+		return x.Assets
+	}
+	return nil
+}
+
+func (x *BasketAssetExchangeAgreement) GetFungibleAssets() []*FungibleAssetExchangeAgreement {
+	if x != nil {
+		return x.FungibleAssets
+	}
+	return nil
+}
+
+func (x *BasketAssetExchangeAgreement) GetLocker() string {
+	if x != nil {
+		return x.Locker
+	}
+	return ""
+}
+
+func (x *BasketAssetExchangeAgreement) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+type AssetContractHTLC struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContractId string                  `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *AssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockHTLC          `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimHTLC         `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
+}
+
+func (x *AssetContractHTLC) Reset() {
+	*x = AssetContractHTLC{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AssetContractHTLC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssetContractHTLC) ProtoMessage() {}
+
+func (x *AssetContractHTLC) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssetContractHTLC.ProtoReflect.Descriptor instead.
+func (*AssetContractHTLC) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AssetContractHTLC) GetContractId() string {
+	if x != nil {
+		return x.ContractId
+	}
+	return ""
+}
+
+func (x *AssetContractHTLC) GetAgreement() *AssetExchangeAgreement {
+	if x != nil {
+		return x.Agreement
+	}
+	return nil
+}
+
+func (x *AssetContractHTLC) GetLock() *AssetLockHTLC {
+	if x != nil {
+		return x.Lock
+	}
+	return nil
+}
+
+func (x *AssetContractHTLC) GetClaim() *AssetClaimHTLC {
+	if x != nil {
+		return x.Claim
+	}
+	return nil
+}
+
+type FungibleAssetContractHTLC struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContractId string                          `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *FungibleAssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockHTLC                  `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimHTLC                 `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
+}
+
+func (x *FungibleAssetContractHTLC) Reset() {
+	*x = FungibleAssetContractHTLC{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_asset_locks_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FungibleAssetContractHTLC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FungibleAssetContractHTLC) ProtoMessage() {}
+
+func (x *FungibleAssetContractHTLC) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FungibleAssetContractHTLC.ProtoReflect.Descriptor instead.
+func (*FungibleAssetContractHTLC) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FungibleAssetContractHTLC) GetContractId() string {
+	if x != nil {
+		return x.ContractId
+	}
+	return ""
+}
+
+func (x *FungibleAssetContractHTLC) GetAgreement() *FungibleAssetExchangeAgreement {
+	if x != nil {
+		return x.Agreement
+	}
+	return nil
+}
+
+func (x *FungibleAssetContractHTLC) GetLock() *AssetLockHTLC {
+	if x != nil {
+		return x.Lock
+	}
+	return nil
+}
+
+func (x *FungibleAssetContractHTLC) GetClaim() *AssetClaimHTLC {
+	if x != nil {
+		return x.Claim
+	}
+	return nil
+}
+
+type AssetContractSignature struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ContractId string                  `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *AssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockSignature     `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimSignature    `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
 }
 
-func (x *AssetExchangeAgreement) Reset() {
-	*x = AssetExchangeAgreement{}
+func (x *AssetContractSignature) Reset() {
+	*x = AssetContractSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_common_asset_locks_proto_msgTypes[4]
+		mi := &file_common_asset_locks_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AssetExchangeAgreement) String() string {
+func (x *AssetContractSignature) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AssetExchangeAgreement) ProtoMessage() {}
+func (*AssetContractSignature) ProtoMessage() {}
 
-func (x *AssetExchangeAgreement) ProtoReflect() protoreflect.Message {
-	mi := &file_common_asset_locks_proto_msgTypes[4]
+func (x *AssetContractSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,69 +1000,67 @@ func (x *AssetExchangeAgreement) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AssetExchangeAgreement.ProtoReflect.Descriptor instead.
-func (*AssetExchangeAgreement) Descriptor() ([]byte, []int) {
-	return file_common_asset_locks_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use AssetContractSignature.ProtoReflect.Descriptor instead.
+func (*AssetContractSignature) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *AssetExchangeAgreement) GetAssetType() string {
+func (x *AssetContractSignature) GetContractId() string {
 	if x != nil {
-		return x.AssetType
+		return x.ContractId
 	}
 	return ""
 }
 
-func (x *AssetExchangeAgreement) GetId() string {
+func (x *AssetContractSignature) GetAgreement() *AssetExchangeAgreement {
 	if x != nil {
-		return x.Id
+		return x.Agreement
 	}
-	return ""
+	return nil
 }
 
-func (x *AssetExchangeAgreement) GetLocker() string {
+func (x *AssetContractSignature) GetLock() *AssetLockSignature {
 	if x != nil {
-		return x.Locker
+		return x.Lock
 	}
-	return ""
+	return nil
 }
 
-func (x *AssetExchangeAgreement) GetRecipient() string {
+func (x *AssetContractSignature) GetClaim() *AssetClaimSignature {
 	if x != nil {
-		return x.Recipient
+		return x.Claim
 	}
-	return ""
+	return nil
 }
 
-type HybridAssetExchangeAgreement struct {
+type FungibleAssetContractSignature struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	AssetType string `protobuf:"bytes,1,opt,name=assetType,proto3" json:"assetType,omitempty"`
-	Id        string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	AssetData []byte `protobuf:"bytes,3,opt,name=assetData,proto3" json:"assetData,omitempty"`
-	NumUnits  uint64 `protobuf:"varint,4,opt,name=numUnits,proto3" json:"numUnits,omitempty"`
-	Locker    string `protobuf:"bytes,5,opt,name=locker,proto3" json:"locker,omitempty"`
-	Recipient string `protobuf:"bytes,6,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	ContractId string                          `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *FungibleAssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockSignature             `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimSignature            `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
 }
 
-func (x *HybridAssetExchangeAgreement) Reset() {
-	*x = HybridAssetExchangeAgreement{}
+func (x *FungibleAssetContractSignature) Reset() {
+	*x = FungibleAssetContractSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_common_asset_locks_proto_msgTypes[5]
+		mi := &file_common_asset_locks_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *HybridAssetExchangeAgreement) String() string {
+func (x *FungibleAssetContractSignature) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HybridAssetExchangeAgreement) ProtoMessage() {}
+func (*FungibleAssetContractSignature) ProtoMessage() {}
 
-func (x *HybridAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
-	mi := &file_common_asset_locks_proto_msgTypes[5]
+func (x *FungibleAssetContractSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -502,81 +1071,67 @@ func (x *HybridAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HybridAssetExchangeAgreement.ProtoReflect.Descriptor instead.
-func (*HybridAssetExchangeAgreement) Descriptor() ([]byte, []int) {
-	return file_common_asset_locks_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *HybridAssetExchangeAgreement) GetAssetType() string {
-	if x != nil {
-		return x.AssetType
-	}
-	return ""
+// Deprecated: Use FungibleAssetContractSignature.ProtoReflect.Descriptor instead.
+func (*FungibleAssetContractSignature) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *HybridAssetExchangeAgreement) GetId() string {
+func (x *FungibleAssetContractSignature) GetContractId() string {
 	if x != nil {
-		return x.Id
+		return x.ContractId
 	}
 	return ""
 }
 
-func (x *HybridAssetExchangeAgreement) GetAssetData() []byte {
+func (x *FungibleAssetContractSignature) GetAgreement() *FungibleAssetExchangeAgreement {
 	if x != nil {
-		return x.AssetData
+		return x.Agreement
 	}
 	return nil
 }
 
-func (x *HybridAssetExchangeAgreement) GetNumUnits() uint64 {
-	if x != nil {
-		return x.NumUnits
-	}
-	return 0
-}
-
-func (x *HybridAssetExchangeAgreement) GetLocker() string {
+func (x *FungibleAssetContractSignature) GetLock() *AssetLockSignature {
 	if x != nil {
-		return x.Locker
+		return x.Lock
 	}
-	return ""
+	return nil
 }
 
-func (x *HybridAssetExchangeAgreement) GetRecipient() string {
+func (x *FungibleAssetContractSignature) GetClaim() *AssetClaimSignature {
 	if x != nil {
-		return x.Recipient
+		return x.Claim
 	}
-	return ""
+	return nil
 }
 
-type FungibleAssetExchangeAgreement struct {
+type HybridAssetContractHTLC struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	AssetType string `protobuf:"bytes,1,opt,name=assetType,proto3" json:"assetType,omitempty"`
-	NumUnits  uint64 `protobuf:"varint,2,opt,name=numUnits,proto3" json:"numUnits,omitempty"`
-	Locker    string `protobuf:"bytes,3,opt,name=locker,proto3" json:"locker,omitempty"`
-	Recipient string `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	ContractId string                        `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *HybridAssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockHTLC                `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimHTLC               `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
 }
 
-func (x *FungibleAssetExchangeAgreement) Reset() {
-	*x = FungibleAssetExchangeAgreement{}
+func (x *HybridAssetContractHTLC) Reset() {
+	*x = HybridAssetContractHTLC{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_common_asset_locks_proto_msgTypes[6]
+		mi := &file_common_asset_locks_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *FungibleAssetExchangeAgreement) String() string {
+func (x *HybridAssetContractHTLC) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FungibleAssetExchangeAgreement) ProtoMessage() {}
+func (*HybridAssetContractHTLC) ProtoMessage() {}
 
-func (x *FungibleAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
-	mi := &file_common_asset_locks_proto_msgTypes[6]
+func (x *HybridAssetContractHTLC) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -587,67 +1142,67 @@ func (x *FungibleAssetExchangeAgreement) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FungibleAssetExchangeAgreement.ProtoReflect.Descriptor instead.
-func (*FungibleAssetExchangeAgreement) Descriptor() ([]byte, []int) {
-	return file_common_asset_locks_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use HybridAssetContractHTLC.ProtoReflect.Descriptor instead.
+func (*HybridAssetContractHTLC) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *FungibleAssetExchangeAgreement) GetAssetType() string {
+func (x *HybridAssetContractHTLC) GetContractId() string {
 	if x != nil {
-		return x.AssetType
+		return x.ContractId
 	}
 	return ""
 }
 
-func (x *FungibleAssetExchangeAgreement) GetNumUnits() uint64 {
+func (x *HybridAssetContractHTLC) GetAgreement() *HybridAssetExchangeAgreement {
 	if x != nil {
-		return x.NumUnits
+		return x.Agreement
 	}
-	return 0
+	return nil
 }
 
-func (x *FungibleAssetExchangeAgreement) GetLocker() string {
+func (x *HybridAssetContractHTLC) GetLock() *AssetLockHTLC {
 	if x != nil {
-		return x.Locker
+		return x.Lock
 	}
-	return ""
+	return nil
 }
 
-func (x *FungibleAssetExchangeAgreement) GetRecipient() string {
+func (x *HybridAssetContractHTLC) GetClaim() *AssetClaimHTLC {
 	if x != nil {
-		return x.Recipient
+		return x.Claim
 	}
-	return ""
+	return nil
 }
 
-type AssetContractHTLC struct {
+type HybridAssetContractSignature struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ContractId string                  `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
-	Agreement  *AssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
-	Lock       *AssetLockHTLC          `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
-	Claim      *AssetClaimHTLC         `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
+	ContractId string                        `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *HybridAssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockSignature           `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimSignature          `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
 }
 
-func (x *AssetContractHTLC) Reset() {
-	*x = AssetContractHTLC{}
+func (x *HybridAssetContractSignature) Reset() {
+	*x = HybridAssetContractSignature{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_common_asset_locks_proto_msgTypes[7]
+		mi := &file_common_asset_locks_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AssetContractHTLC) String() string {
+func (x *HybridAssetContractSignature) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AssetContractHTLC) ProtoMessage() {}
+func (*HybridAssetContractSignature) ProtoMessage() {}
 
-func (x *AssetContractHTLC) ProtoReflect() protoreflect.Message {
-	mi := &file_common_asset_locks_proto_msgTypes[7]
+func (x *HybridAssetContractSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -658,67 +1213,67 @@ func (x *AssetContractHTLC) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AssetContractHTLC.ProtoReflect.Descriptor instead.
-func (*AssetContractHTLC) Descriptor() ([]byte, []int) {
-	return file_common_asset_locks_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use HybridAssetContractSignature.ProtoReflect.Descriptor instead.
+func (*HybridAssetContractSignature) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *AssetContractHTLC) GetContractId() string {
+func (x *HybridAssetContractSignature) GetContractId() string {
 	if x != nil {
 		return x.ContractId
 	}
 	return ""
 }
 
-func (x *AssetContractHTLC) GetAgreement() *AssetExchangeAgreement {
+func (x *HybridAssetContractSignature) GetAgreement() *HybridAssetExchangeAgreement {
 	if x != nil {
 		return x.Agreement
 	}
 	return nil
 }
 
-func (x *AssetContractHTLC) GetLock() *AssetLockHTLC {
+func (x *HybridAssetContractSignature) GetLock() *AssetLockSignature {
 	if x != nil {
 		return x.Lock
 	}
 	return nil
 }
 
-func (x *AssetContractHTLC) GetClaim() *AssetClaimHTLC {
+func (x *HybridAssetContractSignature) GetClaim() *AssetClaimSignature {
 	if x != nil {
 		return x.Claim
 	}
 	return nil
 }
 
-type FungibleAssetContractHTLC struct {
+type BasketAssetContractHTLC struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	ContractId string                          `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
-	Agreement  *FungibleAssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
-	Lock       *AssetLockHTLC                  `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
-	Claim      *AssetClaimHTLC                 `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
+	ContractId string                        `protobuf:"bytes,1,opt,name=contractId,proto3" json:"contractId,omitempty"`
+	Agreement  *BasketAssetExchangeAgreement `protobuf:"bytes,2,opt,name=agreement,proto3" json:"agreement,omitempty"`
+	Lock       *AssetLockHTLC                `protobuf:"bytes,3,opt,name=lock,proto3" json:"lock,omitempty"`
+	Claim      *AssetClaimHTLC               `protobuf:"bytes,4,opt,name=claim,proto3" json:"claim,omitempty"`
 }
 
-func (x *FungibleAssetContractHTLC) Reset() {
-	*x = FungibleAssetContractHTLC{}
+func (x *BasketAssetContractHTLC) Reset() {
+	*x = BasketAssetContractHTLC{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_common_asset_locks_proto_msgTypes[8]
+		mi := &file_common_asset_locks_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *FungibleAssetContractHTLC) String() string {
+func (x *BasketAssetContractHTLC) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*FungibleAssetContractHTLC) ProtoMessage() {}
+func (*BasketAssetContractHTLC) ProtoMessage() {}
 
-func (x *FungibleAssetContractHTLC) ProtoReflect() protoreflect.Message {
-	mi := &file_common_asset_locks_proto_msgTypes[8]
+func (x *BasketAssetContractHTLC) ProtoReflect() protoreflect.Message {
+	mi := &file_common_asset_locks_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -729,33 +1284,33 @@ func (x *FungibleAssetContractHTLC) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use FungibleAssetContractHTLC.ProtoReflect.Descriptor instead.
-func (*FungibleAssetContractHTLC) Descriptor() ([]byte, []int) {
-	return file_common_asset_locks_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use BasketAssetContractHTLC.ProtoReflect.Descriptor instead.
+func (*BasketAssetContractHTLC) Descriptor() ([]byte, []int) {
+	return file_common_asset_locks_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *FungibleAssetContractHTLC) GetContractId() string {
+func (x *BasketAssetContractHTLC) GetContractId() string {
 	if x != nil {
 		return x.ContractId
 	}
 	return ""
 }
 
-func (x *FungibleAssetContractHTLC) GetAgreement() *FungibleAssetExchangeAgreement {
+func (x *BasketAssetContractHTLC) GetAgreement() *BasketAssetExchangeAgreement {
 	if x != nil {
 		return x.Agreement
 	}
 	return nil
 }
 
-func (x *FungibleAssetContractHTLC) GetLock() *AssetLockHTLC {
+func (x *BasketAssetContractHTLC) GetLock() *AssetLockHTLC {
 	if x != nil {
 		return x.Lock
 	}
 	return nil
 }
 
-func (x *FungibleAssetContractHTLC) GetClaim() *AssetClaimHTLC {
+func (x *BasketAssetContractHTLC) GetClaim() *AssetClaimHTLC {
 	if x != nil {
 		return x.Claim
 	}
@@ -804,58 +1359,71 @@ var file_common_asset_locks_proto_rawDesc = []byte{
 	0x0d, 0x68, 0x61, 0x73, 0x68, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x12, 0x2e,
 	0x0a, 0x12, 0x68, 0x61, 0x73, 0x68, 0x50, 0x72, 0x65, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x61,
 	0x73, 0x65, 0x36, 0x34, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x12, 0x68, 0x61, 0x73, 0x68,
-	0x50, 0x72, 0x65, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x22, 0x7c,
-	0x0a, 0x16, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41,
+	0x50, 0x72, 0x65, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x61, 0x73, 0x65, 0x36, 0x34, 0x22, 0xa2,
+	0x01, 0x0a, 0x12, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x2a, 0x0a, 0x10, 0x61, 0x72, 0x62, 0x69, 0x74, 0x65, 0x72,
+	0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x10, 0x61, 0x72, 0x62, 0x69, 0x74, 0x65, 0x72, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65,
+	0x79, 0x12, 0x26, 0x0a, 0x0e, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x53,
+	0x65, 0x63, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x79, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x65, 0x63, 0x73, 0x12, 0x38, 0x0a, 0x08, 0x74, 0x69, 0x6d,
+	0x65, 0x53, 0x70, 0x65, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x70, 0x65, 0x63, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x53,
+	0x70, 0x65, 0x63, 0x22, 0x33, 0x0a, 0x13, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69,
+	0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x7c, 0x0a, 0x16, 0x41, 0x73, 0x73, 0x65,
+	0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69,
+	0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63,
+	0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0xbc, 0x01, 0x0a, 0x1c, 0x48, 0x79, 0x62, 0x72, 0x69,
+	0x64, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67,
+	0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65,
+	0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x44, 0x61,
+	0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x44,
+	0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70,
+	0x69, 0x65, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69,
+	0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x90, 0x01, 0x0a, 0x1e, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62,
+	0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41,
 	0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x73, 0x73, 0x65,
 	0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x73, 0x73,
-	0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c,
-	0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0xbc, 0x01, 0x0a,
-	0x1c, 0x48, 0x79, 0x62, 0x72, 0x69, 0x64, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68,
-	0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a,
-	0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69,
-	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61,
-	0x73, 0x73, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
-	0x61, 0x73, 0x73, 0x65, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d,
-	0x55, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6e, 0x75, 0x6d,
-	0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a,
-	0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0x90, 0x01, 0x0a, 0x1e,
-	0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63,
-	0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1c,
-	0x0a, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08,
-	0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
-	0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b,
-	0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72,
-	0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0xee,
-	0x01, 0x0a, 0x11, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74,
-	0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74,
-	0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61,
-	0x63, 0x74, 0x49, 0x64, 0x12, 0x48, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
-	0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73,
-	0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d,
-	0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35,
-	0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63,
-	0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b,
-	0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52,
-	0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
-	0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43,
-	0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x22,
-	0xfe, 0x01, 0x0a, 0x19, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65,
-	0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a,
-	0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x50, 0x0a,
-	0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x32, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f,
-	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73,
+	0x65, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6e, 0x75, 0x6d, 0x55, 0x6e, 0x69,
+	0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65,
+	0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72,
+	0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22, 0xf4, 0x01, 0x0a, 0x1c, 0x42, 0x61, 0x73,
+	0x6b, 0x65, 0x74, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x42, 0x0a, 0x06, 0x61, 0x73, 0x73,
+	0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41,
+	0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x61, 0x73, 0x73, 0x65, 0x74, 0x73, 0x12, 0x5a, 0x0a,
+	0x0e, 0x66, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61,
+	0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x46, 0x75, 0x6e, 0x67, 0x69,
+	0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0e, 0x66, 0x75, 0x6e, 0x67, 0x69,
+	0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63,
+	0x6b, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65,
+	0x72, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70, 0x69, 0x65, 0x6e, 0x74, 0x22,
+	0xee, 0x01, 0x0a, 0x11, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63,
+	0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x48, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73,
 	0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65,
 	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
 	0x35, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e,
@@ -865,21 +1433,122 @@ var file_common_asset_locks_proto_rawDesc = []byte{
 	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61,
 	0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74,
 	0x43, 0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d,
-	0x2a, 0x19, 0x0a, 0x0d, 0x4c, 0x6f, 0x63, 0x6b, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73,
-	0x6d, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x4c, 0x43, 0x10, 0x00, 0x2a, 0x27, 0x0a, 0x0d, 0x48,
-	0x61, 0x73, 0x68, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x12, 0x0a, 0x0a, 0x06,
-	0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48, 0x41, 0x35,
-	0x31, 0x32, 0x10, 0x01, 0x2a, 0x23, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x53, 0x70, 0x65, 0x63,
-	0x12, 0x09, 0x0a, 0x05, 0x45, 0x50, 0x4f, 0x43, 0x48, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x44,
-	0x55, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x01, 0x42, 0x78, 0x0a, 0x36, 0x6f, 0x72, 0x67,
-	0x2e, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2e, 0x63, 0x61, 0x63,
-	0x74, 0x69, 0x2e, 0x77, 0x65, 0x61, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73,
+	0x22, 0xfe, 0x01, 0x0a, 0x19, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73,
+	0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e,
+	0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x50,
+	0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x32, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74,
+	0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41,
+	0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x12, 0x35, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21,
 	0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f,
-	0x63, 0x6b, 0x73, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
-	0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2f, 0x63, 0x61, 0x63, 0x74,
-	0x69, 0x2f, 0x77, 0x65, 0x61, 0x76, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2d, 0x67, 0x6f, 0x2f, 0x76, 0x32, 0x2f, 0x63, 0x6f, 0x6d,
-	0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c,
+	0x43, 0x52, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65,
+	0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x22, 0xfd, 0x01, 0x0a, 0x16, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x48, 0x0a, 0x09,
+	0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x2a, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c,
+	0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72,
+	0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x3a, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c,
+	0x6f, 0x63, 0x6b, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x6c, 0x6f,
+	0x63, 0x6b, 0x12, 0x3d, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x27, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74,
+	0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69,
+	0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x22, 0x8d, 0x02, 0x0a, 0x1e, 0x46, 0x75, 0x6e, 0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73,
+	0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74,
+	0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x49, 0x64, 0x12, 0x50, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x46, 0x75, 0x6e,
+	0x67, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72,
+	0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x3a, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c,
+	0x6f, 0x63, 0x6b, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x6c, 0x6f,
+	0x63, 0x6b, 0x12, 0x3d, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x27, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74,
+	0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69,
+	0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x22, 0xfa, 0x01, 0x0a, 0x17, 0x48, 0x79, 0x62, 0x72, 0x69, 0x64, 0x41, 0x73, 0x73, 0x65,
+	0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x4e, 0x0a,
+	0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x30, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x48, 0x79, 0x62, 0x72, 0x69, 0x64, 0x41, 0x73, 0x73, 0x65,
+	0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a,
+	0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x04,
+	0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73,
+	0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c,
+	0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x22, 0x89,
+	0x02, 0x0a, 0x1c, 0x48, 0x79, 0x62, 0x72, 0x69, 0x64, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f,
+	0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12,
+	0x4e, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x30, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65,
+	0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x48, 0x79, 0x62, 0x72, 0x69, 0x64, 0x41, 0x73,
+	0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x3a, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c, 0x6f, 0x63, 0x6b, 0x53, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x52, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x3d, 0x0a, 0x05, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e,
+	0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x22, 0xfa, 0x01, 0x0a, 0x17, 0x42,
+	0x61, 0x73, 0x6b, 0x65, 0x74, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x48, 0x54, 0x4c, 0x43, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x74,
+	0x72, 0x61, 0x63, 0x74, 0x49, 0x64, 0x12, 0x4e, 0x0a, 0x09, 0x61, 0x67, 0x72, 0x65, 0x65, 0x6d,
+	0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x42,
+	0x61, 0x73, 0x6b, 0x65, 0x74, 0x41, 0x73, 0x73, 0x65, 0x74, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x09, 0x61, 0x67, 0x72,
+	0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x35, 0x0a, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4c,
+	0x6f, 0x63, 0x6b, 0x48, 0x54, 0x4c, 0x43, 0x52, 0x04, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x38, 0x0a,
+	0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63,
+	0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6c, 0x6f, 0x63, 0x6b,
+	0x73, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x48, 0x54, 0x4c, 0x43,
+	0x52, 0x05, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x2a, 0x28, 0x0a, 0x0d, 0x4c, 0x6f, 0x63, 0x6b, 0x4d,
+	0x65, 0x63, 0x68, 0x61, 0x6e, 0x69, 0x73, 0x6d, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x4c, 0x43,
+	0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x49, 0x47, 0x4e, 0x41, 0x54, 0x55, 0x52, 0x45, 0x10,
+	0x01, 0x2a, 0x54, 0x0a, 0x0d, 0x48, 0x61, 0x73, 0x68, 0x4d, 0x65, 0x63, 0x68, 0x61, 0x6e, 0x69,
+	0x73, 0x6d, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x48, 0x41, 0x32, 0x35, 0x36, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x53, 0x48, 0x41, 0x35, 0x31, 0x32, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x48,
+	0x41, 0x33, 0x5f, 0x32, 0x35, 0x36, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x4b, 0x45, 0x43, 0x43,
+	0x41, 0x4b, 0x32, 0x35, 0x36, 0x10, 0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x42, 0x4c, 0x41, 0x4b, 0x45,
+	0x32, 0x42, 0x32, 0x35, 0x36, 0x10, 0x04, 0x2a, 0x23, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x53,
+	0x70, 0x65, 0x63, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x50, 0x4f, 0x43, 0x48, 0x10, 0x00, 0x12, 0x0c,
+	0x0a, 0x08, 0x44, 0x55, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x01, 0x42, 0x78, 0x0a, 0x36,
+	0x6f, 0x72, 0x67, 0x2e, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2e,
+	0x63, 0x61, 0x63, 0x74, 0x69, 0x2e, 0x77, 0x65, 0x61, 0x76, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x73, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x61, 0x73, 0x73, 0x65, 0x74,
+	0x5f, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2f, 0x63,
+	0x61, 0x63, 0x74, 0x69, 0x2f, 0x77, 0x65, 0x61, 0x76, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2d, 0x67, 0x6f, 0x2f, 0x76, 0x32, 0x2f,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -895,7 +1564,7 @@ func file_common_asset_locks_proto_rawDescGZIP() []byte {
 }
 
 var file_common_asset_locks_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_common_asset_locks_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_common_asset_locks_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_common_asset_locks_proto_goTypes = []interface{}{
 	(LockMechanism)(0),                     // 0: common.asset_locks.LockMechanism
 	(HashMechanism)(0),                     // 1: common.asset_locks.HashMechanism
@@ -904,11 +1573,19 @@ var file_common_asset_locks_proto_goTypes = []interface{}{
 	(*AssetClaim)(nil),                     // 4: common.asset_locks.AssetClaim
 	(*AssetLockHTLC)(nil),                  // 5: common.asset_locks.AssetLockHTLC
 	(*AssetClaimHTLC)(nil),                 // 6: common.asset_locks.AssetClaimHTLC
-	(*AssetExchangeAgreement)(nil),         // 7: common.asset_locks.AssetExchangeAgreement
-	(*HybridAssetExchangeAgreement)(nil),   // 8: common.asset_locks.HybridAssetExchangeAgreement
-	(*FungibleAssetExchangeAgreement)(nil), // 9: common.asset_locks.FungibleAssetExchangeAgreement
-	(*AssetContractHTLC)(nil),              // 10: common.asset_locks.AssetContractHTLC
-	(*FungibleAssetContractHTLC)(nil),      // 11: common.asset_locks.FungibleAssetContractHTLC
+	(*AssetLockSignature)(nil),             // 7: common.asset_locks.AssetLockSignature
+	(*AssetClaimSignature)(nil),            // 8: common.asset_locks.AssetClaimSignature
+	(*AssetExchangeAgreement)(nil),         // 9: common.asset_locks.AssetExchangeAgreement
+	(*HybridAssetExchangeAgreement)(nil),   // 10: common.asset_locks.HybridAssetExchangeAgreement
+	(*FungibleAssetExchangeAgreement)(nil), // 11: common.asset_locks.FungibleAssetExchangeAgreement
+	(*BasketAssetExchangeAgreement)(nil),   // 12: common.asset_locks.BasketAssetExchangeAgreement
+	(*AssetContractHTLC)(nil),              // 13: common.asset_locks.AssetContractHTLC
+	(*FungibleAssetContractHTLC)(nil),      // 14: common.asset_locks.FungibleAssetContractHTLC
+	(*AssetContractSignature)(nil),         // 15: common.asset_locks.AssetContractSignature
+	(*FungibleAssetContractSignature)(nil), // 16: common.asset_locks.FungibleAssetContractSignature
+	(*HybridAssetContractHTLC)(nil),        // 17: common.asset_locks.HybridAssetContractHTLC
+	(*HybridAssetContractSignature)(nil),   // 18: common.asset_locks.HybridAssetContractSignature
+	(*BasketAssetContractHTLC)(nil),        // 19: common.asset_locks.BasketAssetContractHTLC
 }
 var file_common_asset_locks_proto_depIdxs = []int32{
 	0,  // 0: common.asset_locks.AssetLock.lockMechanism:type_name -> common.asset_locks.LockMechanism
@@ -916,17 +1593,35 @@ var file_common_asset_locks_proto_depIdxs = []int32{
 	1,  // 2: common.asset_locks.AssetLockHTLC.hashMechanism:type_name -> common.asset_locks.HashMechanism
 	2,  // 3: common.asset_locks.AssetLockHTLC.timeSpec:type_name -> common.asset_locks.TimeSpec
 	1,  // 4: common.asset_locks.AssetClaimHTLC.hashMechanism:type_name -> common.asset_locks.HashMechanism
-	7,  // 5: common.asset_locks.AssetContractHTLC.agreement:type_name -> common.asset_locks.AssetExchangeAgreement
-	5,  // 6: common.asset_locks.AssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
-	6,  // 7: common.asset_locks.AssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
-	9,  // 8: common.asset_locks.FungibleAssetContractHTLC.agreement:type_name -> common.asset_locks.FungibleAssetExchangeAgreement
-	5,  // 9: common.asset_locks.FungibleAssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
-	6,  // 10: common.asset_locks.FungibleAssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	2,  // 5: common.asset_locks.AssetLockSignature.timeSpec:type_name -> common.asset_locks.TimeSpec
+	9,  // 6: common.asset_locks.BasketAssetExchangeAgreement.assets:type_name -> common.asset_locks.AssetExchangeAgreement
+	11, // 7: common.asset_locks.BasketAssetExchangeAgreement.fungibleAssets:type_name -> common.asset_locks.FungibleAssetExchangeAgreement
+	9,  // 8: common.asset_locks.AssetContractHTLC.agreement:type_name -> common.asset_locks.AssetExchangeAgreement
+	5,  // 9: common.asset_locks.AssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
+	6,  // 10: common.asset_locks.AssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
+	11, // 11: common.asset_locks.FungibleAssetContractHTLC.agreement:type_name -> common.asset_locks.FungibleAssetExchangeAgreement
+	5,  // 12: common.asset_locks.FungibleAssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
+	6,  // 13: common.asset_locks.FungibleAssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
+	9,  // 14: common.asset_locks.AssetContractSignature.agreement:type_name -> common.asset_locks.AssetExchangeAgreement
+	7,  // 15: common.asset_locks.AssetContractSignature.lock:type_name -> common.asset_locks.AssetLockSignature
+	8,  // 16: common.asset_locks.AssetContractSignature.claim:type_name -> common.asset_locks.AssetClaimSignature
+	11, // 17: common.asset_locks.FungibleAssetContractSignature.agreement:type_name -> common.asset_locks.FungibleAssetExchangeAgreement
+	7,  // 18: common.asset_locks.FungibleAssetContractSignature.lock:type_name -> common.asset_locks.AssetLockSignature
+	8,  // 19: common.asset_locks.FungibleAssetContractSignature.claim:type_name -> common.asset_locks.AssetClaimSignature
+	10, // 20: common.asset_locks.HybridAssetContractHTLC.agreement:type_name -> common.asset_locks.HybridAssetExchangeAgreement
+	5,  // 21: common.asset_locks.HybridAssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
+	6,  // 22: common.asset_locks.HybridAssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
+	10, // 23: common.asset_locks.HybridAssetContractSignature.agreement:type_name -> common.asset_locks.HybridAssetExchangeAgreement
+	7,  // 24: common.asset_locks.HybridAssetContractSignature.lock:type_name -> common.asset_locks.AssetLockSignature
+	8,  // 25: common.asset_locks.HybridAssetContractSignature.claim:type_name -> common.asset_locks.AssetClaimSignature
+	12, // 26: common.asset_locks.BasketAssetContractHTLC.agreement:type_name -> common.asset_locks.BasketAssetExchangeAgreement
+	5,  // 27: common.asset_locks.BasketAssetContractHTLC.lock:type_name -> common.asset_locks.AssetLockHTLC
+	6,  // 28: common.asset_locks.BasketAssetContractHTLC.claim:type_name -> common.asset_locks.AssetClaimHTLC
+	29, // [29:29] is the sub-list for method output_type
+	29, // [29:29] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
 }
 
 func init() { file_common_asset_locks_proto_init() }
@@ -984,7 +1679,7 @@ func file_common_asset_locks_proto_init() {
 			}
 		}
 		file_common_asset_locks_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AssetExchangeAgreement); i {
+			switch v := v.(*AssetLockSignature); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -996,7 +1691,7 @@ func file_common_asset_locks_proto_init() {
 			}
 		}
 		file_common_asset_locks_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HybridAssetExchangeAgreement); i {
+			switch v := v.(*AssetClaimSignature); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1008,7 +1703,7 @@ func file_common_asset_locks_proto_init() {
 			}
 		}
 		file_common_asset_locks_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*FungibleAssetExchangeAgreement); i {
+			switch v := v.(*AssetExchangeAgreement); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1020,7 +1715,7 @@ func file_common_asset_locks_proto_init() {
 			}
 		}
 		file_common_asset_locks_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AssetContractHTLC); i {
+			switch v := v.(*HybridAssetExchangeAgreement); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1032,6 +1727,42 @@ func file_common_asset_locks_proto_init() {
 			}
 		}
 		file_common_asset_locks_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FungibleAssetExchangeAgreement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BasketAssetExchangeAgreement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AssetContractHTLC); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*FungibleAssetContractHTLC); i {
 			case 0:
 				return &v.state
@@ -1043,6 +1774,66 @@ func file_common_asset_locks_proto_init() {
 				return nil
 			}
 		}
+		file_common_asset_locks_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AssetContractSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FungibleAssetContractSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HybridAssetContractHTLC); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HybridAssetContractSignature); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_asset_locks_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BasketAssetContractHTLC); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1050,7 +1841,7 @@ func file_common_asset_locks_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_common_asset_locks_proto_rawDesc,
 			NumEnums:      3,
-			NumMessages:   9,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -703,6 +703,119 @@ func (x *EventState) GetMessage() string {
 	return ""
 }
 
+// An event emitted by a chaincode, carried in a ChaincodeEventEnvelope
+type ChaincodeEventEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EventName string `protobuf:"bytes,1,opt,name=event_name,json=eventName,proto3" json:"event_name,omitempty"`
+	Payload   []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *ChaincodeEventEntry) Reset() {
+	*x = ChaincodeEventEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_events_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChaincodeEventEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChaincodeEventEntry) ProtoMessage() {}
+
+func (x *ChaincodeEventEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_common_events_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChaincodeEventEntry.ProtoReflect.Descriptor instead.
+func (*ChaincodeEventEntry) Descriptor() ([]byte, []int) {
+	return file_common_events_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChaincodeEventEntry) GetEventName() string {
+	if x != nil {
+		return x.EventName
+	}
+	return ""
+}
+
+func (x *ChaincodeEventEntry) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// Events emitted by a chaincode within a single transaction, set together as
+// one chaincode event since Fabric retains only the last event set in a transaction
+type ChaincodeEventEnvelope struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version uint32                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Events  []*ChaincodeEventEntry `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+}
+
+func (x *ChaincodeEventEnvelope) Reset() {
+	*x = ChaincodeEventEnvelope{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_common_events_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChaincodeEventEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChaincodeEventEnvelope) ProtoMessage() {}
+
+func (x *ChaincodeEventEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_common_events_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChaincodeEventEnvelope.ProtoReflect.Descriptor instead.
+func (*ChaincodeEventEnvelope) Descriptor() ([]byte, []int) {
+	return file_common_events_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ChaincodeEventEnvelope) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ChaincodeEventEnvelope) GetEvents() []*ChaincodeEventEntry {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
 var File_common_events_proto protoreflect.FileDescriptor
 
 var file_common_events_proto_rawDesc = []byte{
@@ -807,7 +920,19 @@ var file_common_events_proto_rawDesc = []byte{
 	0x19, 0x0a, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x07, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x2a, 0x3e, 0x0a, 0x09, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0x4e, 0x0a, 0x13, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61,
+	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79,
+	0x6c, 0x6f, 0x61, 0x64, 0x22, 0x6e, 0x0a, 0x16, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f, 0x64,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x3a, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x63, 0x6f,
+	0x64, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x2a, 0x3e, 0x0a, 0x09, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
 	0x65, 0x12, 0x10, 0x0a, 0x0c, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54,
 	0x45, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x53, 0x53, 0x45, 0x54, 0x5f, 0x4c, 0x4f, 0x43,
 	0x4b, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x41, 0x53, 0x53, 0x45, 0x54, 0x5f, 0x43, 0x4c, 0x41,
@@ -839,7 +964,7 @@ func file_common_events_proto_rawDescGZIP() []byte {
 }
 
 var file_common_events_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_common_events_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_common_events_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_common_events_proto_goTypes = []interface{}{
 	(EventType)(0),                     // 0: common.events.EventType
 	(EventSubOperation)(0),             // 1: common.events.EventSubOperation
@@ -851,25 +976,28 @@ var file_common_events_proto_goTypes = []interface{}{
 	(*EventPublication)(nil),           // 7: common.events.EventPublication
 	(*EventStates)(nil),                // 8: common.events.EventStates
 	(*EventState)(nil),                 // 9: common.events.EventState
-	(*Query)(nil),                      // 10: common.query.Query
-	(*RequestState)(nil),               // 11: common.state.RequestState
+	(*ChaincodeEventEntry)(nil),        // 10: common.events.ChaincodeEventEntry
+	(*ChaincodeEventEnvelope)(nil),     // 11: common.events.ChaincodeEventEnvelope
+	(*Query)(nil),                      // 12: common.query.Query
+	(*RequestState)(nil),               // 13: common.state.RequestState
 }
 var file_common_events_proto_depIdxs = []int32{
 	0,  // 0: common.events.EventMatcher.event_type:type_name -> common.events.EventType
 	3,  // 1: common.events.EventSubscription.event_matcher:type_name -> common.events.EventMatcher
-	10, // 2: common.events.EventSubscription.query:type_name -> common.query.Query
+	12, // 2: common.events.EventSubscription.query:type_name -> common.query.Query
 	1,  // 3: common.events.EventSubscription.operation:type_name -> common.events.EventSubOperation
 	2,  // 4: common.events.EventSubscriptionState.status:type_name -> common.events.EventSubscriptionState.STATUS
 	3,  // 5: common.events.EventSubscriptionState.event_matcher:type_name -> common.events.EventMatcher
 	7,  // 6: common.events.EventSubscriptionState.event_publication_specs:type_name -> common.events.EventPublication
 	6,  // 7: common.events.EventPublication.ctx:type_name -> common.events.ContractTransaction
 	9,  // 8: common.events.EventStates.states:type_name -> common.events.EventState
-	11, // 9: common.events.EventState.state:type_name -> common.state.RequestState
-	10, // [10:10] is the sub-list for method output_type
-	10, // [10:10] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	13, // 9: common.events.EventState.state:type_name -> common.state.RequestState
+	10, // 10: common.events.ChaincodeEventEnvelope.events:type_name -> common.events.ChaincodeEventEntry
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_common_events_proto_init() }
@@ -964,6 +1092,30 @@ func file_common_events_proto_init() {
 				return nil
 			}
 		}
+		file_common_events_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChaincodeEventEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_common_events_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChaincodeEventEnvelope); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	file_common_events_proto_msgTypes[4].OneofWrappers = []interface{}{
 		(*EventPublication_Ctx)(nil),
@@ -975,7 +1127,7 @@ func file_common_events_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_common_events_proto_rawDesc,
 			NumEnums:      3,
-			NumMessages:   7,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -63,6 +63,56 @@ func (ConfidentialPayload_HashType) EnumDescriptor() ([]byte, []int) {
 	return file_common_interop_payload_proto_rawDescGZIP(), []int{1, 0}
 }
 
+type ConfidentialPayload_EncryptionScheme int32
+
+const (
+	// ECIES using the requestor's ECDSA public key
+	ConfidentialPayload_ECIES ConfidentialPayload_EncryptionScheme = 0
+	// X25519 key agreement with the requestor's Ed25519 public key (converted to X25519) and
+	// ChaCha20-Poly1305 authenticated encryption. The encrypted payload is the concatenation of
+	// the ephemeral X25519 public key (32 bytes), the nonce (12 bytes) and the ciphertext.
+	ConfidentialPayload_X25519_CHACHA20_POLY1305 ConfidentialPayload_EncryptionScheme = 1
+)
+
+// Enum value maps for ConfidentialPayload_EncryptionScheme.
+var (
+	ConfidentialPayload_EncryptionScheme_name = map[int32]string{
+		0: "ECIES",
+		1: "X25519_CHACHA20_POLY1305",
+	}
+	ConfidentialPayload_EncryptionScheme_value = map[string]int32{
+		"ECIES":                    0,
+		"X25519_CHACHA20_POLY1305": 1,
+	}
+)
+
+func (x ConfidentialPayload_EncryptionScheme) Enum() *ConfidentialPayload_EncryptionScheme {
+	p := new(ConfidentialPayload_EncryptionScheme)
+	*p = x
+	return p
+}
+
+func (x ConfidentialPayload_EncryptionScheme) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ConfidentialPayload_EncryptionScheme) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_interop_payload_proto_enumTypes[1].Descriptor()
+}
+
+func (ConfidentialPayload_EncryptionScheme) Type() protoreflect.EnumType {
+	return &file_common_interop_payload_proto_enumTypes[1]
+}
+
+func (x ConfidentialPayload_EncryptionScheme) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ConfidentialPayload_EncryptionScheme.Descriptor instead.
+func (ConfidentialPayload_EncryptionScheme) EnumDescriptor() ([]byte, []int) {
+	return file_common_interop_payload_proto_rawDescGZIP(), []int{1, 1}
+}
+
 type InteropPayload struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -150,6 +200,10 @@ type ConfidentialPayload struct {
 	EncryptedPayload []byte                       `protobuf:"bytes,1,opt,name=encrypted_payload,json=encryptedPayload,proto3" json:"encrypted_payload,omitempty"`
 	HashType         ConfidentialPayload_HashType `protobuf:"varint,2,opt,name=hash_type,json=hashType,proto3,enum=common.interop_payload.ConfidentialPayload_HashType" json:"hash_type,omitempty"`
 	Hash             []byte                       `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Version of the envelope format. Version 0 payloads carry no encryption scheme and are always
+	// encrypted using ECIES; version 1 payloads specify the scheme used.
+	Version          uint32                               `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	EncryptionScheme ConfidentialPayload_EncryptionScheme `protobuf:"varint,5,opt,name=encryption_scheme,json=encryptionScheme,proto3,enum=common.interop_payload.ConfidentialPayload_EncryptionScheme" json:"encryption_scheme,omitempty"`
 }
 
 func (x *ConfidentialPayload) Reset() {
@@ -205,6 +259,20 @@ func (x *ConfidentialPayload) GetHash() []byte {
 	return nil
 }
 
+func (x *ConfidentialPayload) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ConfidentialPayload) GetEncryptionScheme() ConfidentialPayload_EncryptionScheme {
+	if x != nil {
+		return x.EncryptionScheme
+	}
+	return ConfidentialPayload_ECIES
+}
+
 type ConfidentialPayloadContents struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -277,7 +345,7 @@ var file_common_interop_payload_proto_rawDesc = []byte{
 	0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x14, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x43, 0x65, 0x72, 0x74, 0x69,
 	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x22, 0xbf, 0x01, 0x0a,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x6f, 0x6e, 0x63, 0x65, 0x22, 0x81, 0x03, 0x0a,
 	0x13, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x50, 0x61, 0x79,
 	0x6c, 0x6f, 0x61, 0x64, 0x12, 0x2b, 0x0a, 0x11, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65,
 	0x64, 0x5f, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
@@ -288,22 +356,34 @@ var file_common_interop_payload_proto_rawDesc = []byte{
 	0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61,
 	0x64, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x68, 0x61, 0x73, 0x68,
 	0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x14, 0x0a, 0x08, 0x48, 0x61, 0x73, 0x68,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x4d, 0x41, 0x43, 0x10, 0x00, 0x22, 0x4f,
-	0x0a, 0x1b, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x50, 0x61,
-	0x79, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x18, 0x0a,
-	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
-	0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x6e, 0x64, 0x6f,
-	0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x61, 0x6e, 0x64, 0x6f, 0x6d, 0x42,
-	0x7c, 0x0a, 0x3a, 0x6f, 0x72, 0x67, 0x2e, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65, 0x64, 0x67,
-	0x65, 0x72, 0x2e, 0x63, 0x61, 0x63, 0x74, 0x69, 0x2e, 0x77, 0x65, 0x61, 0x76, 0x65, 0x72, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x69, 0x6e,
-	0x74, 0x65, 0x72, 0x6f, 0x70, 0x5f, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x5a, 0x3e, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c,
-	0x65, 0x64, 0x67, 0x65, 0x72, 0x2f, 0x63, 0x61, 0x63, 0x74, 0x69, 0x2f, 0x77, 0x65, 0x61, 0x76,
-	0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73,
-	0x2d, 0x67, 0x6f, 0x2f, 0x76, 0x32, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x28, 0x0c, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x69, 0x0a, 0x11, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x3c, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6f, 0x70, 0x5f, 0x70,
+	0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x61, 0x6c, 0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x52, 0x10, 0x65, 0x6e, 0x63,
+	0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x22, 0x14, 0x0a,
+	0x08, 0x48, 0x61, 0x73, 0x68, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x4d, 0x41,
+	0x43, 0x10, 0x00, 0x22, 0x3b, 0x0a, 0x10, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x43, 0x49, 0x45, 0x53,
+	0x10, 0x00, 0x12, 0x1c, 0x0a, 0x18, 0x58, 0x32, 0x35, 0x35, 0x31, 0x39, 0x5f, 0x43, 0x48, 0x41,
+	0x43, 0x48, 0x41, 0x32, 0x30, 0x5f, 0x50, 0x4f, 0x4c, 0x59, 0x31, 0x33, 0x30, 0x35, 0x10, 0x01,
+	0x22, 0x4f, 0x0a, 0x1b, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c,
+	0x50, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x6e,
+	0x64, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x61, 0x6e, 0x64, 0x6f,
+	0x6d, 0x42, 0x7c, 0x0a, 0x3a, 0x6f, 0x72, 0x67, 0x2e, 0x68, 0x79, 0x70, 0x65, 0x72, 0x6c, 0x65,
+	0x64, 0x67, 0x65, 0x72, 0x2e, 0x63, 0x61, 0x63, 0x74, 0x69, 0x2e, 0x77, 0x65, 0x61, 0x76, 0x65,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x6f, 0x70, 0x5f, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x5a,
+	0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x79, 0x70, 0x65,
+	0x72, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x2f, 0x63, 0x61, 0x63, 0x74, 0x69, 0x2f, 0x77, 0x65,
+	0x61, 0x76, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x73, 0x2d, 0x67, 0x6f, 0x2f, 0x76, 0x32, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -318,21 +398,23 @@ func file_common_interop_payload_proto_rawDescGZIP() []byte {
 	return file_common_interop_payload_proto_rawDescData
 }
 
-var file_common_interop_payload_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_common_interop_payload_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
 var file_common_interop_payload_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_common_interop_payload_proto_goTypes = []interface{}{
-	(ConfidentialPayload_HashType)(0),   // 0: common.interop_payload.ConfidentialPayload.HashType
-	(*InteropPayload)(nil),              // 1: common.interop_payload.InteropPayload
-	(*ConfidentialPayload)(nil),         // 2: common.interop_payload.ConfidentialPayload
-	(*ConfidentialPayloadContents)(nil), // 3: common.interop_payload.ConfidentialPayloadContents
+	(ConfidentialPayload_HashType)(0),         // 0: common.interop_payload.ConfidentialPayload.HashType
+	(ConfidentialPayload_EncryptionScheme)(0), // 1: common.interop_payload.ConfidentialPayload.EncryptionScheme
+	(*InteropPayload)(nil),                    // 2: common.interop_payload.InteropPayload
+	(*ConfidentialPayload)(nil),               // 3: common.interop_payload.ConfidentialPayload
+	(*ConfidentialPayloadContents)(nil),       // 4: common.interop_payload.ConfidentialPayloadContents
 }
 var file_common_interop_payload_proto_depIdxs = []int32{
 	0, // 0: common.interop_payload.ConfidentialPayload.hash_type:type_name -> common.interop_payload.ConfidentialPayload.HashType
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1, // 1: common.interop_payload.ConfidentialPayload.encryption_scheme:type_name -> common.interop_payload.ConfidentialPayload.EncryptionScheme
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_common_interop_payload_proto_init() }
@@ -383,7 +465,7 @@ func file_common_interop_payload_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_common_interop_payload_proto_rawDesc,
-			NumEnums:      1,
+			NumEnums:      2,
 			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   0,
@@ -510,7 +510,8 @@ async function writeExternalStateHelper(
     const interopArgIndices = [],
       viewsSerializedBase64 = [],
       addresses = [],
-      viewContentsBase64 = [];
+      viewContentsBase64 = [],
+      nonces = [];
     const view: state_pb.View = viewPayload.getView();
 
     const result = InteroperableHelper.getResponseDataFromView(
@@ -525,6 +526,8 @@ async function writeExternalStateHelper(
 
     interopArgIndices.push(ctx.getReplaceArgIndex());
     addresses.push(result.viewAddress);
+    // The source network gives the view of every event its own nonce, so events of one subscription can all be written
+    nonces.push(result.nonce);
     viewsSerializedBase64.push(
       Buffer.from(viewPayload.getView().serializeBinary()).toString("base64"),
     );
@@ -560,6 +563,7 @@ async function writeExternalStateHelper(
         addresses,
         viewsSerializedBase64,
         viewContentsBase64,
+        nonces,
         endorsingOrgs,
       ),
     );
@@ -17,9 +17,18 @@ import { Certificate } from "@fidm/x509";
 import { getConfig } from "./walletSetup";
 import logger from "./logger";
 
+// Sections of a view segment starting with this marker are percent-encoded
+const ADDRESS_VERSION_ESCAPED_MARKER = "@v2:";
+
 const parseAddress = (address: string) => {
   const addressList = address.split("/");
-  const fabricArgs = addressList[2].split(":");
+  let fabricArgs = addressList[2].split(":");
+  if (addressList[2].startsWith(ADDRESS_VERSION_ESCAPED_MARKER)) {
+    fabricArgs = addressList[2]
+      .substring(ADDRESS_VERSION_ESCAPED_MARKER.length)
+      .split(":")
+      .map(decodeURIComponent);
+  }
   return {
     channel: fabricArgs[0],
     contract: fabricArgs[1],
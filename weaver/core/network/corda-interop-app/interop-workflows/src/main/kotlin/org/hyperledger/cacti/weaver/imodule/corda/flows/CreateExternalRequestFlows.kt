@@ -14,6 +14,7 @@ import net.corda.core.contracts.UniqueIdentifier
 import net.corda.core.flows.FlowLogic
 import net.corda.core.flows.StartableByRPC
 import java.security.MessageDigest
+import java.time.Instant
 import java.util.*
 
 /**
@@ -45,8 +46,9 @@ class CreateExternalRequest(val address: String) : FlowLogic<Either<Error, Reque
     override fun call(): Either<Error, RequestForExternalState> = try {
         println("Request to create an external request received with address $address")
         // This nonce is used to sign the request to prevent replay attacks in the case that
-        // a request is intercepted by a middleman.
-        val nonce = UniqueIdentifier().toString()
+        // a request is intercepted by a middleman. It is prefixed with its issue time (in unix
+        // seconds) so that the source network can reject stale requests.
+        val nonce = "${Instant.now().epochSecond}:${UniqueIdentifier()}"
         val data = (address + nonce).toByteArray()
 //        val digest = MessageDigest.getInstance("SHA-256").digest((address + nonce).toByteArray())
         subFlow(CreateNodeSignatureFlow(data)).flatMap{ signature ->
@@ -84,6 +84,8 @@ class HandleExternalRequest(val query: QueryOuterClass.Query) : FlowLogic<Either
             val interopPayload = InteropPayloadOuterClass.InteropPayload.newBuilder()
                     .setAddress(query.address)
                     .setPayload(ByteString.copyFrom(flowResult))
+                    .setNonce(query.nonce)
+                    .setRequestorCertificate(query.certificate)
                     .build()
             // 7. Assemble the view from the result returned from the flow
             subFlow(CreateNodeSignatureFlow(interopPayload.toByteArray())).flatMap { signature ->
@@ -17,9 +17,19 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/patternmatcher"
 )
 
 const accessControlObjectType = "accessControl"
+const functionAccessModeObjectType = "functionAccessMode"
+
+// Access modes of chaincode functions exposed to external networks. Functions whose mode has not been
+// declared are treated as only reading ledger state, so that existing rules granting read access keep applying
+// to them; functions that mutate ledger state must be declared with SetFunctionAccessMode to require write access.
+const (
+	accessModeRead  = "read"
+	accessModeWrite = "write"
+)
 
 // CreateAccessControlPolicy cc is used to store a AccessControlPolicy in the ledger
 func (s *SmartContract) CreateAccessControlPolicy(ctx contractapi.TransactionContextInterface, accessControlPolicyJSON string) error {
@@ -36,6 +46,11 @@ func (s *SmartContract) CreateAccessControlPolicy(ctx contractapi.TransactionCon
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
+	if err := validateAccessControlPolicy(accessControlPolicy); err != nil {
+		errorMessage := fmt.Sprintf("Invalid access control policy: %s", err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
 	accessControlKey, err := ctx.GetStub().CreateCompositeKey(accessControlObjectType, []string{accessControlPolicy.SecurityDomain})
 	acp, err := ctx.GetStub().GetState(accessControlKey)
 	if err != nil {
@@ -72,6 +87,11 @@ func (s *SmartContract) UpdateAccessControlPolicy(ctx contractapi.TransactionCon
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
+	if err := validateAccessControlPolicy(accessControlPolicy); err != nil {
+		errorMessage := fmt.Sprintf("Invalid access control policy: %s", err)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
 	accessControlKey, err := ctx.GetStub().CreateCompositeKey(accessControlObjectType, []string{accessControlPolicy.SecurityDomain})
 	_, err = s.GetAccessControlPolicyBySecurityDomain(ctx, accessControlPolicy.SecurityDomain)
 	if err != nil {
@@ -138,8 +158,97 @@ func (s *SmartContract) DeleteAccessControlPolicy(ctx contractapi.TransactionCon
 	return nil
 }
 
+// SetFunctionAccessMode cc is used to declare whether a chaincode function exposed to external networks
+// only reads ledger state ("read") or mutates it ("write"). Access control rules must permit the declared
+// mode for a request to be allowed.
+func (s *SmartContract) SetFunctionAccessMode(ctx contractapi.TransactionContextInterface, channel string, contract string, ccFunc string, mode string) error {
+	// Check if the caller has network admin privileges
+	if isAdmin, err := wutils.IsClientNetworkAdmin(ctx); err != nil {
+		return fmt.Errorf("Admin client check error: %s", err)
+	} else if !isAdmin {
+		return fmt.Errorf("Caller not a network admin; access denied")
+	}
+
+	if mode != accessModeRead && mode != accessModeWrite {
+		errorMessage := fmt.Sprintf("Invalid access mode '%s'; must be '%s' or '%s'", mode, accessModeRead, accessModeWrite)
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
+	functionKey, err := ctx.GetStub().CreateCompositeKey(functionAccessModeObjectType, []string{channel, contract, ccFunc})
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+	return ctx.GetStub().PutState(functionKey, []byte(mode))
+}
+
+// GetFunctionAccessMode cc gets the access mode declared for a chaincode function, defaulting to "read"
+func (s *SmartContract) GetFunctionAccessMode(ctx contractapi.TransactionContextInterface, channel string, contract string, ccFunc string) (string, error) {
+	functionKey, err := ctx.GetStub().CreateCompositeKey(functionAccessModeObjectType, []string{channel, contract, ccFunc})
+	if err != nil {
+		log.Error(err.Error())
+		return "", err
+	}
+	bytes, err := ctx.GetStub().GetState(functionKey)
+	if err != nil {
+		log.Error(err.Error())
+		return "", err
+	}
+	if bytes == nil {
+		return accessModeRead, nil
+	}
+	return string(bytes), nil
+}
+
+// validateAccessControlPolicy checks that the resource and argument patterns of every rule are valid
+func validateAccessControlPolicy(acp *common.AccessControlPolicy) error {
+	for _, rule := range acp.Rules {
+		if rule == nil {
+			return fmt.Errorf("rule is missing")
+		}
+		if err := patternmatcher.Validate(rule.Resource); err != nil {
+			return fmt.Errorf("resource pattern '%s' is not valid: %s", rule.Resource, err.Error())
+		}
+		for _, argConstraint := range rule.Args {
+			if argConstraint == nil {
+				return fmt.Errorf("resource '%s': argument constraint is missing", rule.Resource)
+			}
+			if err := patternmatcher.Validate(argConstraint.Pattern); err != nil {
+				return fmt.Errorf("resource '%s': argument pattern '%s' is not valid: %s", rule.Resource, argConstraint.Pattern, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// isRulePrincipalMatch checks whether the requester is the principal of the rule.
+// Principals of type "ca" are matched against the requesting organization, whose membership has already been
+// authenticated, and principals of type "*" match any authenticated requester of the security domain.
+func isRulePrincipalMatch(rule *common.Rule, query *common.Query) bool {
+	switch rule.PrincipalType {
+	case "certificate":
+		return query.Certificate == rule.Principal
+	case "ca":
+		return query.RequestingOrg == rule.Principal
+	case "*":
+		return true
+	}
+	return false
+}
+
+// areRuleArgsMatch checks whether the arguments of the view address satisfy the argument constraints of the rule
+func areRuleArgsMatch(rule *common.Rule, args []string) bool {
+	for _, argConstraint := range rule.Args {
+		if int(argConstraint.Index) >= len(args) || !patternmatcher.Match(argConstraint.Pattern, args[argConstraint.Index]) {
+			return false
+		}
+	}
+	return true
+}
+
 // verifyAccessToCC looks up the Access Control State for the external network
 // and verifies that the requester has the required permission to call the specified CC function.
+// A request is permitted if a matching rule grants the access mode of the function and no matching rule denies it.
 func verifyAccessToCC(s *SmartContract, ctx contractapi.TransactionContextInterface, viewAddress *FabricViewAddress, viewAddressString string, query *common.Query) error {
 	acpString, err := s.GetAccessControlPolicyBySecurityDomain(ctx, query.RequestingNetwork)
 	if err != nil {
@@ -153,25 +262,45 @@ func verifyAccessToCC(s *SmartContract, ctx contractapi.TransactionContextInterf
 		log.Error(errorMessage)
 		return errors.New(errorMessage)
 	}
+	accessMode, err := s.GetFunctionAccessMode(ctx, viewAddress.Channel, viewAddress.Contract, viewAddress.CCFunc)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed to get access mode of function %s: %s", viewAddress.CCFunc, err.Error())
+		log.Error(errorMessage)
+		return errors.New(errorMessage)
+	}
 
+	permitted := false
+	denied := false
 	for _, rule := range acp.Rules {
-		if rule.Resource == viewAddressString || (validPatternString(rule.Resource) && isPatternAndAddressMatch(rule.Resource, viewAddressString)) {
-			// TODO: Check if these will be the same format (Or convert to matching formats at some point)
-			// TODO: Need to use principalType and perform different validation for type "certificate" and "ca".
-			// Code below assumes that requestor's membership has already been authenticated earlier if the type is "ca"
-			if (rule.PrincipalType == "certificate" && query.Certificate == rule.Principal) {
-				// Break loop as cert is valid.
-				log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate)
-				return nil
-			}
-			if (rule.PrincipalType == "ca" && query.RequestingOrg == rule.Principal) {
-				// Break loop as cert is valid.
-				log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.RequestingOrg)
-				return nil
-			}
+		if rule.Resource != viewAddressString && !patternmatcher.Match(rule.Resource, viewAddressString) {
+			continue
 		}
-
+		if !isRulePrincipalMatch(rule, query) || !areRuleArgsMatch(rule, viewAddress.Args) {
+			continue
+		}
+		if rule.Deny {
+			// Deny rules override any rule permitting access
+			log.Infof("Access Control Policy rule for '%s' DENIES the request '%s'", rule.Resource, viewAddressString)
+			denied = true
+			break
+		}
+		if (accessMode == accessModeWrite && rule.Write) || (accessMode == accessModeRead && rule.Read) {
+			permitted = true
+		}
+	}
+	if permitted && !denied {
+		if query.Certificate != "" {
+			log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate)
+		} else {
+			log.Infof("Access Control Policy PERMITS the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.RequestingOrg)
+		}
+		return nil
+	}
+	resources := make([]string, len(acp.Rules))
+	for i, rule := range acp.Rules {
+		resources[i] = rule.Resource
 	}
+	log.Debugf("Access control rule resources: %s", patternmatcher.Explain(resources, viewAddressString))
 	var errorMessage string
 	if (query.Certificate != "") {
         errorMessage = fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate)
@@ -9,6 +9,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils/mocks"
@@ -27,6 +28,23 @@ var accessControlAsset = common.AccessControlPolicy{
 	}},
 }
 
+// setAccessControlState mocks the ledger state read when verifying access: the access control policy
+// and the access modes declared for functions, keyed by "<channel>:<contract>:<function>"
+func setAccessControlState(chaincodeStub *mocks.ChaincodeStub, accessControlBytes []byte, functionAccessModes map[string]string) {
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+	chaincodeStub.GetStateCalls(func(key string) ([]byte, error) {
+		if strings.HasPrefix(key, accessControlObjectType+":") {
+			return accessControlBytes, nil
+		}
+		if mode, ok := functionAccessModes[strings.TrimPrefix(key, functionAccessModeObjectType+":")]; ok {
+			return []byte(mode), nil
+		}
+		return nil, nil
+	})
+}
+
 func TestGetAccessControlPolicyBySecurityDomain(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
@@ -68,6 +86,27 @@ func TestCreateAccessControlPolicy(t *testing.T) {
 	chaincodeStub.GetStateReturns([]byte{}, nil)
 	err = interopcc.CreateAccessControlPolicy(ctx, string(accessControlBytes))
 	require.EqualError(t, err, fmt.Sprintf("AccessControlPolicy already exists for securityDomain: %s", accessControlAsset.SecurityDomain))
+	// Invalid resource pattern
+	invalidAccessControlAsset := common.AccessControlPolicy{
+		SecurityDomain: "2345",
+		Rules: []*common.Rule{{
+			Principal:     "Org1MSP",
+			PrincipalType: "ca",
+			Resource:      "mychannel:[*:Read",
+			Read:          true,
+		}},
+	}
+	invalidAccessControlBytes, err := json.Marshal(&invalidAccessControlAsset)
+	require.NoError(t, err)
+	err = interopcc.CreateAccessControlPolicy(ctx, string(invalidAccessControlBytes))
+	require.EqualError(t, err, "Invalid access control policy: resource pattern 'mychannel:[*:Read' is not valid: unterminated character class at position 10")
+	// Invalid argument pattern
+	invalidAccessControlAsset.Rules[0].Resource = "mychannel:interop:Read:*"
+	invalidAccessControlAsset.Rules[0].Args = []*common.ArgConstraint{{Index: 0, Pattern: "a**"}}
+	invalidAccessControlBytes, err = json.Marshal(&invalidAccessControlAsset)
+	require.NoError(t, err)
+	err = interopcc.CreateAccessControlPolicy(ctx, string(invalidAccessControlBytes))
+	require.EqualError(t, err, "Invalid access control policy: resource 'mychannel:interop:Read:*': argument pattern 'a**' is not valid: '**' must be a whole segment")
 }
 
 func TestUpdateAccessControlPolicy(t *testing.T) {
@@ -160,9 +199,10 @@ func TestVerifyAccessToCC(t *testing.T) {
 	}
 	accessControlBytes, err := json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
+	functionAccessModes := map[string]string{"mychannel:interop:Read": accessModeRead}
 
 	// Test: Happy case
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.NoError(t, err)
 	newRule := common.Rule{
@@ -174,7 +214,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlAsset.Rules = []*common.Rule{&newRule}
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.NoError(t, err)
 
@@ -187,7 +227,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlAsset.Rules = []*common.Rule{&newRule}
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.NoError(t, err)
 
@@ -201,7 +241,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlAsset.Rules = []*common.Rule{&invalidPrincipalRule}
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
@@ -215,7 +255,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlAsset.Rules = []*common.Rule{&invalidPrincipalRule}
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
@@ -229,7 +269,7 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlAsset.Rules = []*common.Rule{&differentResourceRule}
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
@@ -242,12 +282,165 @@ func TestVerifyAccessToCC(t *testing.T) {
 	accessControlAsset.Rules = []*common.Rule{&differentResourceRule}
 	accessControlBytes, err = json.Marshal(&accessControlAsset)
 	require.NoError(t, err)
-	chaincodeStub.GetStateReturns(accessControlBytes, nil)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", viewAddressString, query.RequestingNetwork, query.Certificate))
 
 	// Test: No Rule for ID
-	chaincodeStub.GetStateReturns(nil, nil)
+	setAccessControlState(chaincodeStub, nil, functionAccessModes)
 	err = verifyAccessToCC(&interopcc, ctx, &validAddressStruct, viewAddressString, &query)
 	require.EqualError(t, err, fmt.Sprintf("Access control policy does not exist for network: %s", query.RequestingNetwork))
 }
+
+func TestVerifyAccessToCCAccessModes(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+
+	// data for tests
+	readAddressStruct := FabricViewAddress{
+		Channel:  "mychannel",
+		Contract: "interop",
+		CCFunc:   "ReadAsset",
+		Args:     []string{"asset1", "Org1MSP"},
+	}
+	readAddressString := "mychannel:interop:ReadAsset:asset1:Org1MSP"
+	writeAddressStruct := FabricViewAddress{
+		Channel:  "mychannel",
+		Contract: "interop",
+		CCFunc:   "DeleteAsset",
+		Args:     []string{"asset1"},
+	}
+	writeAddressString := "mychannel:interop:DeleteAsset:asset1"
+	query := common.Query{
+		RequestingNetwork: "network1",
+		RequestingOrg:     "Org1MSP",
+	}
+	functionAccessModes := map[string]string{
+		"mychannel:interop:ReadAsset":   accessModeRead,
+		"mychannel:interop:DeleteAsset": accessModeWrite,
+	}
+	accessControlAsset := common.AccessControlPolicy{
+		SecurityDomain: "network1",
+		Rules: []*common.Rule{{
+			Principal:     "Org1MSP",
+			PrincipalType: "ca",
+			Resource:      "mychannel:interop:*",
+			Read:          true,
+		}},
+	}
+	accessControlBytes, err := json.Marshal(&accessControlAsset)
+	require.NoError(t, err)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
+
+	// Read permission allows read-only functions but not functions that mutate state
+	err = verifyAccessToCC(&interopcc, ctx, &readAddressStruct, readAddressString, &query)
+	require.NoError(t, err)
+	err = verifyAccessToCC(&interopcc, ctx, &writeAddressStruct, writeAddressString, &query)
+	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", writeAddressString, query.RequestingNetwork, query.RequestingOrg))
+	// Functions whose access mode has not been declared are treated as read-only, so legacy rules only granting
+	// read access keep permitting them
+	undeclaredAddressStruct := FabricViewAddress{
+		Channel:  "mychannel",
+		Contract: "interop",
+		CCFunc:   "TransferAsset",
+		Args:     []string{"asset1"},
+	}
+	undeclaredAddressString := "mychannel:interop:TransferAsset:asset1"
+	err = verifyAccessToCC(&interopcc, ctx, &undeclaredAddressStruct, undeclaredAddressString, &query)
+	require.NoError(t, err)
+
+	// Write permission allows functions that mutate state but not read-only functions
+	accessControlAsset.Rules[0].Read = false
+	accessControlAsset.Rules[0].Write = true
+	accessControlBytes, err = json.Marshal(&accessControlAsset)
+	require.NoError(t, err)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
+	err = verifyAccessToCC(&interopcc, ctx, &writeAddressStruct, writeAddressString, &query)
+	require.NoError(t, err)
+	err = verifyAccessToCC(&interopcc, ctx, &readAddressStruct, readAddressString, &query)
+	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", readAddressString, query.RequestingNetwork, query.RequestingOrg))
+
+	// Deny rules override rules permitting access
+	accessControlAsset.Rules = []*common.Rule{{
+		Principal:     "Org1MSP",
+		PrincipalType: "ca",
+		Resource:      "mychannel:interop:*",
+		Read:          true,
+		Write:         true,
+	}, {
+		Principal:     "*",
+		PrincipalType: "*",
+		Resource:      "mychannel:interop:DeleteAsset:*",
+		Deny:          true,
+	}}
+	accessControlBytes, err = json.Marshal(&accessControlAsset)
+	require.NoError(t, err)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
+	err = verifyAccessToCC(&interopcc, ctx, &readAddressStruct, readAddressString, &query)
+	require.NoError(t, err)
+	err = verifyAccessToCC(&interopcc, ctx, &writeAddressStruct, writeAddressString, &query)
+	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", writeAddressString, query.RequestingNetwork, query.RequestingOrg))
+
+	// Argument constraints restrict the rule to matching arguments
+	accessControlAsset.Rules = []*common.Rule{{
+		Principal:     "Org1MSP",
+		PrincipalType: "ca",
+		Resource:      "mychannel:interop:ReadAsset:*",
+		Read:          true,
+		Args:          []*common.ArgConstraint{{Index: 0, Pattern: "asset*"}, {Index: 1, Pattern: "Org1MSP"}},
+	}}
+	accessControlBytes, err = json.Marshal(&accessControlAsset)
+	require.NoError(t, err)
+	setAccessControlState(chaincodeStub, accessControlBytes, functionAccessModes)
+	err = verifyAccessToCC(&interopcc, ctx, &readAddressStruct, readAddressString, &query)
+	require.NoError(t, err)
+	otherOrgAddressStruct := FabricViewAddress{
+		Channel:  "mychannel",
+		Contract: "interop",
+		CCFunc:   "ReadAsset",
+		Args:     []string{"asset1", "Org2MSP"},
+	}
+	otherOrgAddressString := "mychannel:interop:ReadAsset:asset1:Org2MSP"
+	err = verifyAccessToCC(&interopcc, ctx, &otherOrgAddressStruct, otherOrgAddressString, &query)
+	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", otherOrgAddressString, query.RequestingNetwork, query.RequestingOrg))
+	missingArgAddressStruct := FabricViewAddress{
+		Channel:  "mychannel",
+		Contract: "interop",
+		CCFunc:   "ReadAsset",
+		Args:     []string{"asset1"},
+	}
+	missingArgAddressString := "mychannel:interop:ReadAsset:asset1"
+	err = verifyAccessToCC(&interopcc, ctx, &missingArgAddressStruct, missingArgAddressString, &query)
+	require.EqualError(t, err, fmt.Sprintf("Access Control Policy DOES NOT PERMIT the request '%s' from '%s:%s'", missingArgAddressString, query.RequestingNetwork, query.RequestingOrg))
+}
+
+func TestFunctionAccessMode(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+
+	// Case when caller is not an admin
+	err := interopcc.SetFunctionAccessMode(ctx, "mychannel", "interop", "DeleteAsset", accessModeWrite)
+	require.EqualError(t, err, "Caller not a network admin; access denied")
+	// Set caller to be admin now
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetAttributeValueCalls(setClientAdmin)
+	ctx.GetClientIdentityReturns(clientIdentity)
+	err = interopcc.SetFunctionAccessMode(ctx, "mychannel", "interop", "DeleteAsset", "delete")
+	require.EqualError(t, err, "Invalid access mode 'delete'; must be 'read' or 'write'")
+	err = interopcc.SetFunctionAccessMode(ctx, "mychannel", "interop", "DeleteAsset", accessModeWrite)
+	require.NoError(t, err)
+	objectType, attributes := chaincodeStub.CreateCompositeKeyArgsForCall(0)
+	require.Equal(t, functionAccessModeObjectType, objectType)
+	require.Equal(t, []string{"mychannel", "interop", "DeleteAsset"}, attributes)
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, accessModeWrite, string(value))
+
+	// Functions are treated as read-only unless declared otherwise
+	mode, err := interopcc.GetFunctionAccessMode(ctx, "mychannel", "interop", "ReadAsset")
+	require.NoError(t, err)
+	require.Equal(t, accessModeRead, mode)
+	chaincodeStub.GetStateReturns([]byte(accessModeWrite), nil)
+	mode, err = interopcc.GetFunctionAccessMode(ctx, "mychannel", "interop", "DeleteAsset")
+	require.NoError(t, err)
+	require.Equal(t, accessModeWrite, mode)
+}
@@ -0,0 +1,482 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// besu_view contains the functions used to verify views that come from a Besu network
+// and to extract the interop payloads they carry
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/besu"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// Members of a Besu network's membership are its IBFT 2.0/QBFT validators, each identified by its account address
+const besuValidatorMemberType = "address"
+
+// The validator signatures in a Besu view are 65-byte secp256k1 commit seals [R || S || V]
+const besuCommitSealLength = 65
+
+// The BFT consensus protocols of Besu networks whose commit seals can be verified
+const (
+	besuConsensusIBFT2 = "IBFT 2.0"
+	besuConsensusQBFT  = "QBFT"
+)
+
+// IBFT 2.0 encodes the round in extraData as a 4-byte integer
+const besuIBFT2RoundLength = 4
+
+// getBesuProvenLog verifies the Merkle-Patricia proof of the receipt in the Besu view against the receipts root
+// in the block header and returns the log at the view's log index, along with the interop payload it carries.
+// The data of the log is expected to be the ABI encoding of a single 'bytes' value: the serialized interop payload.
+func getBesuProvenLog(besuView *besu.BesuView) (*types.Log, []byte, error) {
+	if besuView.BlockHeader == nil {
+		return nil, nil, fmt.Errorf("Besu view does not contain a block header")
+	}
+	receiptsRoot, err := decodeBesuHash("receiptsRoot", besuView.BlockHeader.ReceiptsRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	var proof [][]byte
+	err = rlp.DecodeBytes(besuView.MerkleProof, &proof)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to decode receipt Merkle proof: %s", err.Error())
+	}
+	receiptKey, err := rlp.EncodeToBytes(uint64(besuView.ReceiptIndex))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to encode receipt index: %s", err.Error())
+	}
+	receiptBytes, err := verifyMerklePatriciaProof(receiptsRoot, receiptKey, proof)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Receipt Merkle proof is invalid: %s", err.Error())
+	}
+	var receipt types.Receipt
+	err = receipt.UnmarshalBinary(receiptBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to decode receipt: %s", err.Error())
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, nil, fmt.Errorf("Receipt %d records a failed transaction", besuView.ReceiptIndex)
+	}
+	if int(besuView.LogIndex) >= len(receipt.Logs) {
+		return nil, nil, fmt.Errorf("Log index %d out of bounds of receipt logs (length %d)", besuView.LogIndex, len(receipt.Logs))
+	}
+	provenLog := receipt.Logs[besuView.LogIndex]
+	payload, err := decodeABIBytes(provenLog.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to decode interop payload from log: %s", err.Error())
+	}
+	if len(besuView.InteropPayload) > 0 && !bytes.Equal(besuView.InteropPayload, payload) {
+		return nil, nil, fmt.Errorf("Interop payload in Besu view does not match the payload in the proven log")
+	}
+	return provenLog, payload, nil
+}
+
+// getBesuInteropPayload extracts the interop payload from the proven log in a Besu view
+func getBesuInteropPayload(data []byte) (*common.InteropPayload, error) {
+	var besuView besu.BesuView
+	err := protoV2.Unmarshal(data, &besuView)
+	if err != nil {
+		return nil, fmt.Errorf("BesuView Unmarshal error: %s", err)
+	}
+	_, payload, err := getBesuProvenLog(&besuView)
+	if err != nil {
+		return nil, err
+	}
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal(payload, &interopPayload)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+	}
+	return &interopPayload, nil
+}
+
+// The verifyBesuNotarization function is used to verify views that come from a Besu network
+// running the IBFT 2.0 or QBFT consensus protocol.
+//
+// Verification requires the following checks to be performed:
+// 1. Ensure the response is in a valid format - view data should be parsed to [BesuView].
+// 2. Verify the receipt Merkle-Patricia proof against the receipts root in the block header and extract the log.
+// 3. Verify the log was emitted by the contract in the view address, and the address in its payload is the same as original address
+// 4. Recover the validator addresses from the commit seals over the block header.
+// 5. Check each of the validators is a member of the network's Membership and that a quorum of validators sealed the block.
+// 6. Check that the seals fulfill the verification policy of the request.
+func verifyBesuNotarization(s *SmartContract, ctx contractapi.TransactionContextInterface, data []byte, verificationPolicy *common.Policy, securityDomain, address string) error {
+	// 1. Ensure the response is in a valid format
+	var besuView besu.BesuView
+	err := protoV2.Unmarshal(data, &besuView)
+	if err != nil {
+		return fmt.Errorf("Unable to decode besu view data: %s", err.Error())
+	}
+
+	// 2. Verify the receipt Merkle-Patricia proof and extract the log
+	provenLog, payload, err := getBesuProvenLog(&besuView)
+	if err != nil {
+		return err
+	}
+
+	// 3. Verify the log was emitted by the contract in the view address, and the address in its payload
+	addressStruct, err := parseAddress(address)
+	if err != nil {
+		return fmt.Errorf("Unable to parse address: %s", err.Error())
+	}
+	besuViewAddress, err := parseBesuViewAddress(addressStruct.ViewSegment)
+	if err != nil {
+		return fmt.Errorf("Unable to parse view address: %s", err.Error())
+	}
+	if !ethcommon.IsHexAddress(besuViewAddress.Contract) || ethcommon.HexToAddress(besuViewAddress.Contract) != provenLog.Address {
+		return fmt.Errorf("Log emitter does not match contract in view address: Original: %s Log: %s", besuViewAddress.Contract, provenLog.Address.Hex())
+	}
+	var interopPayload common.InteropPayload
+	err = protoV2.Unmarshal(payload, &interopPayload)
+	if err != nil {
+		return fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+	}
+	if address != interopPayload.Address {
+		return fmt.Errorf("Address in response does not match original address: Original: %s Response: %s", address, interopPayload.Address)
+	}
+
+	// 4. Recover the validator addresses from the commit seals over the block header
+	sealHash, err := besuCommitSealHash(besuView.BlockHeader)
+	if err != nil {
+		return err
+	}
+	membershipString, err := s.GetMembershipBySecurityDomain(ctx, securityDomain)
+	if err != nil {
+		return err
+	}
+	membership, err := decodeMembership([]byte(membershipString))
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
+	}
+	validators := map[ethcommon.Address]string{}
+	for name, member := range membership.Members {
+		if member.Type == besuValidatorMemberType && ethcommon.IsHexAddress(member.Value) {
+			validators[ethcommon.HexToAddress(member.Value)] = name
+		}
+	}
+	if len(validators) == 0 {
+		return fmt.Errorf("Membership of security domain %s does not contain any validators", securityDomain)
+	}
+
+	signerList := []string{}
+	for i, seal := range besuView.ValidatorSignatures {
+		if len(seal) != besuCommitSealLength {
+			return fmt.Errorf("Invalid length of validator signature %d: %d", i, len(seal))
+		}
+		publicKey, err := crypto.SigToPub(sealHash.Bytes(), seal)
+		if err != nil {
+			return fmt.Errorf("Unable to recover signer of validator signature %d: %s", i, err.Error())
+		}
+		// 5. Check each of the validators is a member of the network's Membership
+		validatorAddress := crypto.PubkeyToAddress(*publicKey)
+		name, ok := validators[validatorAddress]
+		if !ok {
+			return fmt.Errorf("Verify membership failed. Signer of validator signature %d is not a validator: %s", i, validatorAddress.Hex())
+		}
+		if Contains(signerList, name) {
+			return fmt.Errorf("Duplicate signature from validator %s", name)
+		}
+		signerList = append(signerList, name)
+	}
+	// A Byzantine fault tolerant quorum of validators must have sealed the block
+	quorum := (2*len(validators) + 2) / 3
+	if len(signerList) < quorum {
+		return fmt.Errorf("Block sealed by %d validators, which is less than the quorum of %d", len(signerList), quorum)
+	}
+
+	// 6. Check the seals fulfill the verification policy of the request.
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return err
+	}
+	log.Infof("Proof associated with response '%s' from Besu network for query '%s' is VALID", string(payload), address)
+	return nil
+}
+
+// besuCommitSealHash computes the hash signed by the validators in their commit seals, which is the hash of the block
+// header with the commit seals removed from the extra data. IBFT 2.0 drops the commit seals field from the extra data,
+// while QBFT keeps it as an empty list.
+func besuCommitSealHash(blockHeader *besu.BlockHeader) (ethcommon.Hash, error) {
+	header, err := decodeBesuBlockHeader(blockHeader)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+	extraDataFields, err := splitRLPList(header.Extra)
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("Unable to decode extraData: %s", err.Error())
+	}
+	// extraData = RLP([vanity, validators, vote, round, commit seals])
+	if len(extraDataFields) != 5 {
+		return ethcommon.Hash{}, fmt.Errorf("extraData is not in the IBFT 2.0/QBFT format: expected 5 fields, found %d", len(extraDataFields))
+	}
+	consensus, err := getBesuConsensus(extraDataFields[3])
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+	switch consensus {
+	case besuConsensusIBFT2:
+		header.Extra, err = rlp.EncodeToBytes(extraDataFields[:4])
+	case besuConsensusQBFT:
+		header.Extra, err = rlp.EncodeToBytes(append(extraDataFields[:4:4], rlp.RawValue(rlp.EmptyList)))
+	}
+	if err != nil {
+		return ethcommon.Hash{}, fmt.Errorf("Unable to encode extraData: %s", err.Error())
+	}
+	return header.Hash(), nil
+}
+
+// getBesuConsensus identifies the consensus protocol that produced a block from the round field of its extra data.
+// IBFT 2.0 encodes the round as a 4-byte integer, whereas QBFT encodes it as an integer without leading zero bytes,
+// which takes fewer bytes for any round below 2^24.
+func getBesuConsensus(roundField rlp.RawValue) (string, error) {
+	round, _, err := rlp.SplitString(roundField)
+	if err != nil {
+		return "", fmt.Errorf("Unable to decode round in extraData: %s", err.Error())
+	}
+	if len(round) == besuIBFT2RoundLength {
+		return besuConsensusIBFT2, nil
+	}
+	return besuConsensusQBFT, nil
+}
+
+// decodeBesuBlockHeader converts a block header in a Besu view, whose fields are hex-encoded as in Besu's JSON-RPC responses,
+// into an Ethereum block header
+func decodeBesuBlockHeader(blockHeader *besu.BlockHeader) (*types.Header, error) {
+	if blockHeader == nil {
+		return nil, fmt.Errorf("Besu view does not contain a block header")
+	}
+	var header types.Header
+	var err error
+	hashFields := []struct {
+		name  string
+		value string
+		field *ethcommon.Hash
+	}{
+		{"parentHash", blockHeader.ParentHash, &header.ParentHash},
+		{"sha3Uncles", blockHeader.Sha3Uncles, &header.UncleHash},
+		{"stateRoot", blockHeader.StateRoot, &header.Root},
+		{"transactionsRoot", blockHeader.TransactionsRoot, &header.TxHash},
+		{"receiptsRoot", blockHeader.ReceiptsRoot, &header.ReceiptHash},
+		{"mixHash", blockHeader.MixHash, &header.MixDigest},
+	}
+	for _, hashField := range hashFields {
+		*hashField.field, err = decodeBesuHash(hashField.name, hashField.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !ethcommon.IsHexAddress(blockHeader.Miner) {
+		return nil, fmt.Errorf("Invalid miner in block header: %s", blockHeader.Miner)
+	}
+	header.Coinbase = ethcommon.HexToAddress(blockHeader.Miner)
+	logsBloom, err := hexutil.Decode(blockHeader.LogsBloom)
+	if err != nil || len(logsBloom) != types.BloomByteLength {
+		return nil, fmt.Errorf("Invalid logsBloom in block header: %s", blockHeader.LogsBloom)
+	}
+	header.Bloom = types.BytesToBloom(logsBloom)
+	if header.Difficulty, err = hexutil.DecodeBig(blockHeader.Difficulty); err != nil {
+		return nil, fmt.Errorf("Invalid difficulty in block header: %s", err.Error())
+	}
+	if header.Number, err = hexutil.DecodeBig(blockHeader.Number); err != nil {
+		return nil, fmt.Errorf("Invalid number in block header: %s", err.Error())
+	}
+	if header.GasLimit, err = hexutil.DecodeUint64(blockHeader.GasLimit); err != nil {
+		return nil, fmt.Errorf("Invalid gasLimit in block header: %s", err.Error())
+	}
+	if header.GasUsed, err = hexutil.DecodeUint64(blockHeader.GasUsed); err != nil {
+		return nil, fmt.Errorf("Invalid gasUsed in block header: %s", err.Error())
+	}
+	if header.Time, err = hexutil.DecodeUint64(blockHeader.Timestamp); err != nil {
+		return nil, fmt.Errorf("Invalid timestamp in block header: %s", err.Error())
+	}
+	if header.Extra, err = hexutil.Decode(blockHeader.ExtraData); err != nil {
+		return nil, fmt.Errorf("Invalid extraData in block header: %s", err.Error())
+	}
+	nonce, err := hexutil.Decode(blockHeader.Nonce)
+	if err != nil || len(nonce) != len(header.Nonce) {
+		return nil, fmt.Errorf("Invalid nonce in block header: %s", blockHeader.Nonce)
+	}
+	copy(header.Nonce[:], nonce)
+	if blockHeader.BaseFeePerGas != "" {
+		if header.BaseFee, err = hexutil.DecodeBig(blockHeader.BaseFeePerGas); err != nil {
+			return nil, fmt.Errorf("Invalid baseFeePerGas in block header: %s", err.Error())
+		}
+	}
+	return &header, nil
+}
+
+// decodeBesuHash decodes a hex-encoded 32-byte hash from a Besu block header
+func decodeBesuHash(name, value string) (ethcommon.Hash, error) {
+	hash, err := hexutil.Decode(value)
+	if err != nil || len(hash) != ethcommon.HashLength {
+		return ethcommon.Hash{}, fmt.Errorf("Invalid %s in block header: %s", name, value)
+	}
+	return ethcommon.BytesToHash(hash), nil
+}
+
+// verifyMerklePatriciaProof walks the proof nodes from the trie root along the path of the key and returns the value
+// stored under the key. Nodes are referenced by the Keccak-256 hash of their RLP encoding, unless that encoding is
+// shorter than 32 bytes, in which case they are embedded in their parent.
+func verifyMerklePatriciaProof(rootHash ethcommon.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	proofNodes := map[ethcommon.Hash][]byte{}
+	for _, node := range proof {
+		proofNodes[crypto.Keccak256Hash(node)] = node
+	}
+	node, ok := proofNodes[rootHash]
+	if !ok {
+		return nil, fmt.Errorf("proof does not contain the root node %s", rootHash.Hex())
+	}
+	keyNibbles := keyToNibbles(key)
+	for {
+		nodeFields, err := splitRLPList(node)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode trie node: %s", err.Error())
+		}
+		var childRef []byte
+		switch len(nodeFields) {
+		case 17:
+			// branch node
+			if len(keyNibbles) == 0 {
+				return decodeTrieValue(nodeFields[16])
+			}
+			childRef = nodeFields[keyNibbles[0]]
+			keyNibbles = keyNibbles[1:]
+		case 2:
+			// extension or leaf node
+			compactPath, _, err := rlp.SplitString(nodeFields[0])
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode trie node path: %s", err.Error())
+			}
+			pathNibbles, isLeaf, err := compactToNibbles(compactPath)
+			if err != nil {
+				return nil, err
+			}
+			if isLeaf {
+				if !bytes.Equal(pathNibbles, keyNibbles) {
+					return nil, fmt.Errorf("key not found in trie")
+				}
+				return decodeTrieValue(nodeFields[1])
+			}
+			if !bytes.HasPrefix(keyNibbles, pathNibbles) {
+				return nil, fmt.Errorf("key not found in trie")
+			}
+			keyNibbles = keyNibbles[len(pathNibbles):]
+			childRef = nodeFields[1]
+		default:
+			return nil, fmt.Errorf("invalid trie node with %d fields", len(nodeFields))
+		}
+
+		kind, content, _, err := rlp.Split(childRef)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode trie node reference: %s", err.Error())
+		}
+		if kind == rlp.List {
+			node = childRef
+		} else if len(content) == ethcommon.HashLength {
+			node, ok = proofNodes[ethcommon.BytesToHash(content)]
+			if !ok {
+				return nil, fmt.Errorf("proof does not contain the trie node %s", hexutil.Encode(content))
+			}
+		} else if len(content) == 0 {
+			return nil, fmt.Errorf("key not found in trie")
+		} else {
+			return nil, fmt.Errorf("invalid trie node reference %s", hexutil.Encode(content))
+		}
+	}
+}
+
+// decodeTrieValue decodes the value stored in a trie node, failing if no value is stored in it
+func decodeTrieValue(field []byte) ([]byte, error) {
+	value, _, err := rlp.SplitString(field)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode trie value: %s", err.Error())
+	}
+	if len(value) == 0 {
+		return nil, fmt.Errorf("key not found in trie")
+	}
+	return value, nil
+}
+
+// keyToNibbles splits each byte of a trie key into two 4-bit nibbles
+func keyToNibbles(key []byte) []byte {
+	nibbles := make([]byte, 2*len(key))
+	for i, b := range key {
+		nibbles[2*i] = b / 16
+		nibbles[2*i+1] = b % 16
+	}
+	return nibbles
+}
+
+// compactToNibbles decodes the hex-prefix encoded path of an extension or leaf node, and reports whether it is a leaf
+func compactToNibbles(compactPath []byte) ([]byte, bool, error) {
+	if len(compactPath) == 0 {
+		return nil, false, fmt.Errorf("empty trie node path")
+	}
+	nibbles := keyToNibbles(compactPath)
+	flag := nibbles[0]
+	if flag > 3 {
+		return nil, false, fmt.Errorf("invalid trie node path flag %d", flag)
+	}
+	isLeaf := flag >= 2
+	// an odd-length path is packed with the flag, an even-length one is padded with a zero nibble
+	if flag%2 == 1 {
+		return nibbles[1:], isLeaf, nil
+	}
+	return nibbles[2:], isLeaf, nil
+}
+
+// splitRLPList splits an RLP-encoded list into the RLP encodings of its elements
+func splitRLPList(encoded []byte) ([]rlp.RawValue, error) {
+	content, rest, err := rlp.SplitList(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%d trailing bytes after list", len(rest))
+	}
+	var elements []rlp.RawValue
+	for len(content) > 0 {
+		_, _, rest, err := rlp.Split(content)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, content[:len(content)-len(rest)])
+		content = rest
+	}
+	return elements, nil
+}
+
+// decodeABIBytes decodes the ABI encoding of a single 'bytes' value
+func decodeABIBytes(data []byte) ([]byte, error) {
+	bytesType, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := abi.Arguments{{Type: bytesType}}.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected a single value, found %d", len(values))
+	}
+	value, ok := values[0].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("value is not of type bytes")
+	}
+	return value, nil
+}
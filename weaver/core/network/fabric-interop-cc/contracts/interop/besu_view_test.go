@@ -0,0 +1,329 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/besu"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+const besuNetwork = "besu-network"
+const besuContract = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+const besuPattern = besuContract + ":get(string):a"
+const besuViewAddress = "relay-besu-network:9080/" + besuNetwork + "/" + besuPattern
+const besuNonce = "0f7c2b57-6d0e-4fd4-9a6b-2d5c1a3e8b41"
+
+// besuReceipt creates the consensus encoding of a receipt with a log emitted by the contract, carrying the payload
+func besuReceipt(t *testing.T, contract string, payload []byte) []byte {
+	bytesType, err := abi.NewType("bytes", "", nil)
+	require.NoError(t, err)
+	data, err := abi.Arguments{{Type: bytesType}}.Pack(payload)
+	require.NoError(t, err)
+	receipt := types.Receipt{
+		Type:              types.DynamicFeeTxType,
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 42000,
+		Logs: []*types.Log{{
+			Address: ethcommon.HexToAddress(contract),
+			Topics:  []ethcommon.Hash{crypto.Keccak256Hash([]byte("InteropPayload(bytes)"))},
+			Data:    data,
+		}},
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{&receipt})
+	receiptBytes, err := receipt.MarshalBinary()
+	require.NoError(t, err)
+	return receiptBytes
+}
+
+// besuReceiptTrie creates the receipt trie of a block with two receipts, whose root is a branch node with a leaf for
+// each receipt (keys 0x80 and 0x01), and returns its root and the proof of the first receipt
+func besuReceiptTrie(t *testing.T, receipt0, receipt1 []byte) (ethcommon.Hash, []byte) {
+	leaf0, err := rlp.EncodeToBytes([][]byte{{0x30}, receipt0})
+	require.NoError(t, err)
+	leaf1, err := rlp.EncodeToBytes([][]byte{{0x31}, receipt1})
+	require.NoError(t, err)
+	branchChildren := make([][]byte, 17)
+	branchChildren[0] = crypto.Keccak256(leaf1)
+	branchChildren[8] = crypto.Keccak256(leaf0)
+	branch, err := rlp.EncodeToBytes(branchChildren)
+	require.NoError(t, err)
+	proof, err := rlp.EncodeToBytes([][]byte{branch, leaf0})
+	require.NoError(t, err)
+	return crypto.Keccak256Hash(branch), proof
+}
+
+// besuBlockHeader creates the header of a block with the receipts root, sealed by the signers with the commit seals of
+// the consensus protocol
+func besuBlockHeader(t *testing.T, consensus string, receiptsRoot ethcommon.Hash, validators []ethcommon.Address, signers []*ecdsa.PrivateKey) (*besu.BlockHeader, [][]byte) {
+	// IBFT 2.0 encodes the round as a 4-byte integer and drops the seals from the extraData signed by the validators,
+	// while QBFT encodes the round without leading zeros and keeps the seals as an empty list
+	round := []byte{0, 0, 0, 1}
+	extraDataWithoutSeals, err := rlp.EncodeToBytes([]interface{}{make([]byte, 32), validators, []interface{}{}, round})
+	require.NoError(t, err)
+	if consensus == besuConsensusQBFT {
+		round = []byte{1}
+		extraDataWithoutSeals, err = rlp.EncodeToBytes([]interface{}{make([]byte, 32), validators, []interface{}{}, round, []interface{}{}})
+		require.NoError(t, err)
+	}
+	header := types.Header{
+		ParentHash:  ethcommon.HexToHash("0x1"),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    validators[0],
+		Root:        ethcommon.HexToHash("0x2"),
+		TxHash:      ethcommon.HexToHash("0x3"),
+		ReceiptHash: receiptsRoot,
+		Difficulty:  big.NewInt(1),
+		Number:      big.NewInt(1234),
+		GasLimit:    30000000,
+		GasUsed:     63000,
+		Time:        1700000000,
+		Extra:       extraDataWithoutSeals,
+		MixDigest:   ethcommon.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365"),
+		BaseFee:     big.NewInt(7),
+	}
+	sealHash := header.Hash()
+	seals := [][]byte{}
+	for _, signer := range signers {
+		seal, err := crypto.Sign(sealHash.Bytes(), signer)
+		require.NoError(t, err)
+		seals = append(seals, seal)
+	}
+	extraData, err := rlp.EncodeToBytes([]interface{}{make([]byte, 32), validators, []interface{}{}, round, seals})
+	require.NoError(t, err)
+	return &besu.BlockHeader{
+		ParentHash:       header.ParentHash.Hex(),
+		Sha3Uncles:       header.UncleHash.Hex(),
+		Miner:            header.Coinbase.Hex(),
+		StateRoot:        header.Root.Hex(),
+		TransactionsRoot: header.TxHash.Hex(),
+		ReceiptsRoot:     header.ReceiptHash.Hex(),
+		LogsBloom:        hexutil.Encode(header.Bloom.Bytes()),
+		Difficulty:       hexutil.EncodeBig(header.Difficulty),
+		Number:           hexutil.EncodeBig(header.Number),
+		GasLimit:         hexutil.EncodeUint64(header.GasLimit),
+		GasUsed:          hexutil.EncodeUint64(header.GasUsed),
+		Timestamp:        hexutil.EncodeUint64(header.Time),
+		ExtraData:        hexutil.Encode(extraData),
+		MixHash:          header.MixDigest.Hex(),
+		Nonce:            hexutil.Encode(header.Nonce[:]),
+		BaseFeePerGas:    hexutil.EncodeBig(header.BaseFee),
+	}, seals
+}
+
+// besuView creates a base64-encoded view from a Besu network for the address, sealed by the signers
+func besuView(t *testing.T, consensus, address, contract string, requestorCert []byte, validators []ethcommon.Address, signers []*ecdsa.PrivateKey) string {
+	interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{
+		Payload:              []byte("I am a result"),
+		Address:              address,
+		Nonce:                besuNonce,
+		RequestorCertificate: string(requestorCert),
+	})
+	require.NoError(t, err)
+	receiptsRoot, proof := besuReceiptTrie(t, besuReceipt(t, contract, interopPayloadBytes), besuReceipt(t, contract, []byte("another payload")))
+	blockHeader, seals := besuBlockHeader(t, consensus, receiptsRoot, validators, signers)
+	besuViewBytes, err := protoV2.Marshal(&besu.BesuView{
+		InteropPayload:      interopPayloadBytes,
+		BlockHeader:         blockHeader,
+		MerkleProof:         proof,
+		ReceiptIndex:        0,
+		LogIndex:            0,
+		ValidatorSignatures: seals,
+	})
+	require.NoError(t, err)
+	viewBytes, err := protoV2.Marshal(&common.View{
+		Meta: &common.Meta{Protocol: common.Meta_ETHEREUM, ProofType: "Notarization", SerializationFormat: "Protobuf"},
+		Data: besuViewBytes,
+	})
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(viewBytes)
+}
+
+func TestBesuViewVerification(t *testing.T) {
+	var fabricRequestorCert, _ = ioutil.ReadFile("./test_data/fabric_requestor_cert.pem")
+
+	validatorKeys := make([]*ecdsa.PrivateKey, 4)
+	validators := make([]ethcommon.Address, 4)
+	besuMembership := common.Membership{SecurityDomain: besuNetwork, Members: map[string]*common.Member{}}
+	for i := range validatorKeys {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		validatorKeys[i] = key
+		validators[i] = crypto.PubkeyToAddress(key.PublicKey)
+		besuMembership.Members[fmt.Sprintf("validator%d", i+1)] = &common.Member{Value: validators[i].Hex(), Type: "address"}
+	}
+	besuMembershipBytes, err := json.Marshal(&besuMembership)
+	require.NoError(t, err)
+	besuVerificationPolicy := common.VerificationPolicy{
+		SecurityDomain: besuNetwork,
+		Identifiers: []*common.Identifier{{
+			Pattern: besuPattern,
+			Policy: &common.Policy{
+				Criteria: []string{"validator1", "validator2"},
+				Type:     "signature",
+			},
+		}},
+	}
+	besuVerificationPolicyBytes, err := json.Marshal(&besuVerificationPolicy)
+	require.NoError(t, err)
+
+	// Happy case: view sealed by 3 of the 4 validators
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
+	interopcc := SmartContract{}
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, besuMembershipBytes, nil)
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{
+		Status:  200,
+		Message: "",
+		Payload: []byte("I am a result"),
+	})
+	b64View := besuView(t, besuConsensusIBFT2, besuViewAddress, besuContract, fabricRequestorCert, validators, validatorKeys[:3])
+	viewData, err := interopcc.ParseAndValidateView(ctx, besuViewAddress, b64View, []string{""}, besuNonce)
+	require.NoError(t, err)
+	require.Equal(t, "I am a result", viewData)
+
+	chaincodeStub.GetStateReturnsOnCall(2, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(3, besuMembershipBytes, nil)
+	err = interopcc.WriteExternalState(ctx, "simplestate", "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{besuViewAddress}, []string{b64View}, [][]string{{""}}, []string{besuNonce})
+	require.NoError(t, err)
+
+	// Happy case: view of a block produced with QBFT
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, besuMembershipBytes, nil)
+	b64View = besuView(t, besuConsensusQBFT, besuViewAddress, besuContract, fabricRequestorCert, validators, validatorKeys[:3])
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.NoError(t, err)
+
+	// Test case: block sealed by fewer validators than the quorum
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	chaincodeStub.GetStateReturnsOnCall(0, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, besuMembershipBytes, nil)
+	b64View = besuView(t, besuConsensusIBFT2, besuViewAddress, besuContract, fabricRequestorCert, validators, validatorKeys[:2])
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.EqualError(t, err, "Block sealed by 2 validators, which is less than the quorum of 3")
+
+	// Test case: seals do not satisfy the verification policy
+	chaincodeStub.GetStateReturnsOnCall(2, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(3, besuMembershipBytes, nil)
+	b64View = besuView(t, besuConsensusIBFT2, besuViewAddress, besuContract, fabricRequestorCert, validators, validatorKeys[1:])
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.EqualError(t, err, "Notarizations missing signer: validator1")
+
+	// Test case: block sealed by a key that is not a validator in the membership
+	outsiderKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(4, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(5, besuMembershipBytes, nil)
+	b64View = besuView(t, besuConsensusIBFT2, besuViewAddress, besuContract, fabricRequestorCert, validators, append(validatorKeys[:3:3], outsiderKey))
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.EqualError(t, err, "Verify membership failed. Signer of validator signature 3 is not a validator: "+crypto.PubkeyToAddress(outsiderKey.PublicKey).Hex())
+
+	// Test case: the same validator seals the block several times
+	chaincodeStub.GetStateReturnsOnCall(6, besuVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(7, besuMembershipBytes, nil)
+	b64View = besuView(t, besuConsensusIBFT2, besuViewAddress, besuContract, fabricRequestorCert, validators, []*ecdsa.PrivateKey{validatorKeys[0], validatorKeys[1], validatorKeys[0]})
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.EqualError(t, err, "Duplicate signature from validator validator1")
+
+	// Test case: log emitted by a different contract than the one in the view address
+	chaincodeStub.GetStateReturnsOnCall(8, besuVerificationPolicyBytes, nil)
+	b64View = besuView(t, besuConsensusIBFT2, besuViewAddress, "0x8464135c8F25Da09e49BC8782676a84730C318bC", fabricRequestorCert, validators, validatorKeys[:3])
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.EqualError(t, err, "Log emitter does not match contract in view address: Original: "+besuContract+" Log: 0x8464135c8F25Da09e49BC8782676a84730C318bC")
+
+	// Test case: interop payload generated for a different address
+	otherViewAddress := "relay-besu-network:9081/" + besuNetwork + "/" + besuPattern
+	chaincodeStub.GetStateReturnsOnCall(9, besuVerificationPolicyBytes, nil)
+	b64View = besuView(t, besuConsensusIBFT2, otherViewAddress, besuContract, fabricRequestorCert, validators, validatorKeys[:3])
+	err = interopcc.VerifyView(ctx, b64View, besuViewAddress)
+	require.EqualError(t, err, "Address in response does not match original address: Original: "+besuViewAddress+" Response: "+otherViewAddress)
+}
+
+func TestBesuViewProofs(t *testing.T) {
+	var fabricRequestorCert, _ = ioutil.ReadFile("./test_data/fabric_requestor_cert.pem")
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	b64View := besuView(t, besuConsensusIBFT2, besuViewAddress, besuContract, fabricRequestorCert, []ethcommon.Address{crypto.PubkeyToAddress(key.PublicKey)}, []*ecdsa.PrivateKey{key})
+	viewBytes, err := base64.StdEncoding.DecodeString(b64View)
+	require.NoError(t, err)
+	var view common.View
+	require.NoError(t, protoV2.Unmarshal(viewBytes, &view))
+	var besuViewData besu.BesuView
+	require.NoError(t, protoV2.Unmarshal(view.Data, &besuViewData))
+
+	// Happy case: the interop payload is extracted from the proven log
+	viewData, err := ExtractAndValidateDataFromView(&view, []string{""}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "I am a result", string(viewData))
+
+	// Happy case: the commit seals are over the header hash without the seals
+	header, err := decodeBesuBlockHeader(besuViewData.BlockHeader)
+	require.NoError(t, err)
+	sealHash, err := besuCommitSealHash(besuViewData.BlockHeader)
+	require.NoError(t, err)
+	require.NotEqual(t, header.Hash(), sealHash)
+	publicKey, err := crypto.SigToPub(sealHash.Bytes(), besuViewData.ValidatorSignatures[0])
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*publicKey))
+
+	// Happy case: QBFT commit seals are over the header hash with the seals kept as an empty list
+	qbftReceiptsRoot, _ := besuReceiptTrie(t, besuReceipt(t, besuContract, []byte("a payload")), besuReceipt(t, besuContract, []byte("another payload")))
+	qbftBlockHeader, qbftSeals := besuBlockHeader(t, besuConsensusQBFT, qbftReceiptsRoot, []ethcommon.Address{crypto.PubkeyToAddress(key.PublicKey)}, []*ecdsa.PrivateKey{key})
+	qbftSealHash, err := besuCommitSealHash(qbftBlockHeader)
+	require.NoError(t, err)
+	publicKey, err = crypto.SigToPub(qbftSealHash.Bytes(), qbftSeals[0])
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*publicKey))
+
+	// Test case: proof of a receipt index that is not in the trie
+	besuViewData.ReceiptIndex = 16
+	_, _, err = getBesuProvenLog(&besuViewData)
+	require.EqualError(t, err, "Receipt Merkle proof is invalid: key not found in trie")
+	besuViewData.ReceiptIndex = 0
+
+	// Test case: log index out of bounds
+	besuViewData.LogIndex = 1
+	_, _, err = getBesuProvenLog(&besuViewData)
+	require.EqualError(t, err, "Log index 1 out of bounds of receipt logs (length 1)")
+	besuViewData.LogIndex = 0
+
+	// Test case: receipts root does not match the proof
+	receiptsRoot := besuViewData.BlockHeader.ReceiptsRoot
+	besuViewData.BlockHeader.ReceiptsRoot = ethcommon.HexToHash("0x4").Hex()
+	_, _, err = getBesuProvenLog(&besuViewData)
+	require.EqualError(t, err, "Receipt Merkle proof is invalid: proof does not contain the root node "+ethcommon.HexToHash("0x4").Hex())
+	besuViewData.BlockHeader.ReceiptsRoot = receiptsRoot
+
+	// Test case: interop payload in the view differs from the one in the proven log
+	besuViewData.InteropPayload = []byte("tampered")
+	_, _, err = getBesuProvenLog(&besuViewData)
+	require.EqualError(t, err, "Interop payload in Besu view does not match the payload in the proven log")
+
+	// Test case: extraData not in the IBFT 2.0/QBFT format
+	besuViewData.BlockHeader.ExtraData = "0xc0"
+	_, err = besuCommitSealHash(besuViewData.BlockHeader)
+	require.EqualError(t, err, "extraData is not in the IBFT 2.0/QBFT format: expected 5 fields, found 0")
+}
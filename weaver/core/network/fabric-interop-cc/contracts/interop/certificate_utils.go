@@ -8,6 +8,7 @@
 package main
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/hmac"
 	"crypto/rand"
@@ -27,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/x25519"
 )
 
 const (
@@ -34,6 +36,9 @@ const (
 	intCertsKey  = "intermediate_certs"
 )
 
+// Version of the ConfidentialPayload envelope generated by this chaincode
+const confidentialPayloadVersion = 1
+
 // ECDSASignature represents an ECDSA signature
 type ECDSASignature struct {
 	R, S *big.Int
@@ -77,23 +82,32 @@ func getCertChainOptions(rootCerts []interface{}, intermediateCerts []interface{
 	return *certOptions, nil
 }
 
-func verifyCaCertificate(cert *x509.Certificate, memberCertificate string) error {
+func verifyCaCertificate(cert *x509.Certificate, memberCertificate string, crlPEMs []string, currentTime time.Time) error {
 	memberX509Cert, err := parseCert(memberCertificate)
 	if err != nil {
 		return err
 	}
-	err = validateCertificateUsingCA(cert, memberX509Cert, true)
+	err = validateCertificateUsingCA(cert, memberX509Cert, true, currentTime)
 	if err != nil {
 		return fmt.Errorf("CA Certificate is not valid: %s", err.Error())
 	}
-	return nil
+	crls, err := parseCRLs(crlPEMs)
+	if err != nil {
+		return err
+	}
+	return verifyCertificateNotRevoked(cert, crls)
 }
 
 /* This function will receive arguments for exactly one node with the following cert chain assumed: <root cert> -> <int cert 0> -> <int cert 1> -> ......
    In a Fabric network, we assume that there are multiple MSPs, each having one or more Root CAs and zero or more Intermediate CAs.
    In a Corda network, we assume that there is a single Root CA and Doorman CA, and one or more Node CAs corresponding to nodes.
+   The node's certificate and the intermediate certificates must not be revoked by any of the CRLs issued by their respective issuers.
 */
-func verifyCertificateChain(cert *x509.Certificate, certPEMs []string) error {
+func verifyCertificateChain(cert *x509.Certificate, certPEMs []string, crlPEMs []string, currentTime time.Time) error {
+	crls, err := parseCRLs(crlPEMs)
+	if err != nil {
+		return err
+	}
 	var parentCert *x509.Certificate
 	for i, certPEM := range certPEMs {
 		decodedCert, _ := pem.Decode([]byte(certPEM))
@@ -106,13 +120,17 @@ func verifyCertificateChain(cert *x509.Certificate, certPEMs []string) error {
 		}
 
 		if i > 0 {
-			err := validateCertificateUsingCA(caCert, parentCert, i == 1)
+			err := validateCertificateUsingCA(caCert, parentCert, i == 1, currentTime)
 			if err != nil {
 				errMsg := fmt.Sprintf("Certificate link for Subject %s with Parent Subject %s invalid", caCert.Subject.String(), parentCert.Subject.String())
 				return errors.New(errMsg)
 			}
+			err = verifyCertificateNotRevoked(caCert, crls)
+			if err != nil {
+				return err
+			}
 			if i == len(certPEMs)-1 && cert != nil {
-				err := validateCertificateUsingCA(cert, caCert, i == 1)
+				err := validateCertificateUsingCA(cert, caCert, i == 1, currentTime)
 				if err != nil {
 					return errors.New("Certificate link invalid for endorser")
 				}
@@ -120,11 +138,75 @@ func verifyCertificateChain(cert *x509.Certificate, certPEMs []string) error {
 		}
 		parentCert = caCert
 	}
+	if cert != nil {
+		return verifyCertificateNotRevoked(cert, crls)
+	}
 
 	return nil
 }
 
-func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certificate, isSignerRootCA bool) error {
+// parseCRLs decodes a list of PEM-encoded certificate revocation lists
+func parseCRLs(crlPEMs []string) ([]*x509.RevocationList, error) {
+	crls := []*x509.RevocationList{}
+	for _, crlPEM := range crlPEMs {
+		decodedCRL, _ := pem.Decode([]byte(crlPEM))
+		if decodedCRL == nil {
+			return nil, errors.New("Unable to decode CRL PEM")
+		}
+		crl, err := x509.ParseRevocationList(decodedCRL.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to parse CRL: %s", err.Error())
+		}
+		crls = append(crls, crl)
+	}
+	return crls, nil
+}
+
+// verifyCertificateNotRevoked checks that a certificate is not listed in any revocation list issued by its issuer
+func verifyCertificateNotRevoked(cert *x509.Certificate, crls []*x509.RevocationList) error {
+	for _, crl := range crls {
+		if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) {
+			continue
+		}
+		for _, revokedCert := range crl.RevokedCertificates {
+			if revokedCert.SerialNumber != nil && revokedCert.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("Certificate %s with serial number %s has been revoked by %s", cert.Subject.String(), cert.SerialNumber.String(), crl.Issuer.String())
+			}
+		}
+	}
+	return nil
+}
+
+// validateCRLsFromCAs checks that every revocation list is signed by one of the given CA certificates
+func validateCRLsFromCAs(crlPEMs []string, caCertPEMs []string) error {
+	crls, err := parseCRLs(crlPEMs)
+	if err != nil {
+		return err
+	}
+	caCerts := []*x509.Certificate{}
+	for _, caCertPEM := range caCertPEMs {
+		caCert, err := parseCert(caCertPEM)
+		if err != nil {
+			return err
+		}
+		caCerts = append(caCerts, caCert)
+	}
+	for _, crl := range crls {
+		signed := false
+		for _, caCert := range caCerts {
+			if bytes.Equal(crl.RawIssuer, caCert.RawSubject) && crl.CheckSignatureFrom(caCert) == nil {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			return fmt.Errorf("CRL issued by %s is not signed by any CA of the member", crl.Issuer.String())
+		}
+	}
+	return nil
+}
+
+func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certificate, isSignerRootCA bool, currentTime time.Time) error {
 	var err error
 	if isSignerRootCA {
 		if err = signerCACert.CheckSignature(signerCACert.SignatureAlgorithm, signerCACert.RawTBSCertificate, signerCACert.Signature); err != nil {
@@ -134,7 +216,7 @@ func validateCertificateUsingCA(cert *x509.Certificate, signerCACert *x509.Certi
 	if err = signerCACert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
 		return err
 	}
-	err = isCertificateWithinExpiry(cert)
+	err = isCertificateWithinExpiry(cert, currentTime)
 	if err != nil {
 		errMsg := fmt.Sprintf("Certificate is outside of expiry date. No longer valid. Cert: %s", cert.Subject.String())
 		return errors.New(errMsg)
@@ -255,28 +337,32 @@ func parseCert(certString string) (*x509.Certificate, error) {
 	return cert, err
 }
 
-func isCertificateWithinExpiry(cert *x509.Certificate) error {
+// isCertificateWithinExpiry checks the validity period of a certificate against the given time, which
+// should be the transaction timestamp so that all endorsing peers reach the same result
+func isCertificateWithinExpiry(cert *x509.Certificate, currentTime time.Time) error {
 	if cert == nil {
 		return errors.New("Cert is nil")
 	}
 	certLocation := cert.NotBefore.Location()
-	currentDate := time.Now().In(certLocation)
+	currentDate := currentTime.In(certLocation)
 	if currentDate.After(cert.NotBefore) && currentDate.Before(cert.NotAfter) {
 		return nil
 	}
 	return errors.New("Cert is invalid")
 }
 
-func encryptWithCert(message []byte, cert *x509.Certificate) ([]byte, error) {
+func encryptWithCert(message []byte, cert *x509.Certificate) ([]byte, common.ConfidentialPayload_EncryptionScheme, error) {
 	// Check if the public key in the cert is an ECDSA public key
 	pubKey := getECDSAPublicKeyFromCertificate(cert)
 	if pubKey != nil {
-		return encryptWithECDSAPublicKey(message, pubKey)
+		encBytes, err := encryptWithECDSAPublicKey(message, pubKey)
+		return encBytes, common.ConfidentialPayload_ECIES, err
 	} else if (cert.RawSubjectPublicKeyInfo != nil && len(cert.RawSubjectPublicKeyInfo) == 44) {	// ed25519 public key
 		// We expect the key to be 44 bytes, but only the last 32 bytes (multiple of 8) comprise the public key
-		return encryptWithEd25519PublicKey(message, cert.RawSubjectPublicKeyInfo[12:])
+		encBytes, err := x25519.EncryptWithEd25519PublicKey(message, cert.RawSubjectPublicKeyInfo[12:])
+		return encBytes, common.ConfidentialPayload_X25519_CHACHA20_POLY1305, err
 	} else {
-		return []byte(""), errors.New("Missing or unsupported public key type for encryption")
+		return []byte(""), common.ConfidentialPayload_ECIES, errors.New("Missing or unsupported public key type for encryption")
 	}
 }
 
@@ -292,10 +378,6 @@ func encryptWithECDSAPublicKey(message []byte, pubKey *ecdsa.PublicKey) ([]byte,
 	return []byte(""), errors.New("Missing or invalid ECDSA public key")
 }
 
-func encryptWithEd25519PublicKey(message []byte, pubKey []byte) ([]byte, error) {
-	return []byte(""), nil
-}
-
 func generateSecureRandomKey(length int) ([]byte, error) {
 	key := make([]byte, length)
 	_, err := rand.Read(key)
@@ -335,7 +417,7 @@ func generateConfidentialInteropPayloadAndHash(message []byte, cert string) ([]b
 		return []byte(""), err
 	}
 
-	encryptedPayload, err := encryptWithCert(confidentialPayloadContentsBytes, x509Cert)
+	encryptedPayload, encryptionScheme, err := encryptWithCert(confidentialPayloadContentsBytes, x509Cert)
 	if err != nil {
 		return []byte(""), err
 	}
@@ -349,6 +431,8 @@ func generateConfidentialInteropPayloadAndHash(message []byte, cert string) ([]b
 		EncryptedPayload: encryptedPayload,
 		HashType: common.ConfidentialPayload_HMAC,
 		Hash: payloadHMAC,
+		Version: confidentialPayloadVersion,
+		EncryptionScheme: encryptionScheme,
 	}
 	confidentialPayloadBytes, err := proto.Marshal(&confidentialPayload)
 	if err != nil {
@@ -19,6 +19,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/x25519"
 )
 
 func TestVerifyCertificateChain(t *testing.T) {
@@ -84,9 +86,61 @@ GqO4M1KlfimphQwCICiq00hDanT5W8bTLqE7GIGuplf/O8AABlpWrUg6uiUB
 	cordaCert, err := parseCert("-----BEGIN CERTIFICATE-----\nMIIBwjCCAV+gAwIBAgIIUJkQvmKm35YwFAYIKoZIzj0EAwIGCCqGSM49AwEHMC8x\nCzAJBgNVBAYTAkdCMQ8wDQYDVQQHDAZMb25kb24xDzANBgNVBAoMBlBhcnR5QTAe\nFw0yMDA3MjQwMDAwMDBaFw0yNzA1MjAwMDAwMDBaMC8xCzAJBgNVBAYTAkdCMQ8w\nDQYDVQQHDAZMb25kb24xDzANBgNVBAoMBlBhcnR5QTAqMAUGAytlcAMhAMMKaREK\nhcTgSBMMzK81oPUSPoVmG/fJMLXq/ujSmse9o4GJMIGGMB0GA1UdDgQWBBRMXtDs\nKFZzULdQ3c2DCUEx3T1CUDAPBgNVHRMBAf8EBTADAQH/MAsGA1UdDwQEAwIChDAT\nBgNVHSUEDDAKBggrBgEFBQcDAjAfBgNVHSMEGDAWgBR4hwLuLgfIZMEWzG4n3Axw\nfgPbezARBgorBgEEAYOKYgEBBAMCAQYwFAYIKoZIzj0EAwIGCCqGSM49AwEHA0cA\nMEQCIC7J46SxDDz3LjDNrEPjjwP2prgMEMh7r/gJpouQHBk+AiA+KzXD0d5miI86\nD2mYK4C3tRli3X3VgnCe8COqfYyuQg==\n-----END CERTIFICATE-----")
 	require.NoError(t, err)
 
-	err = verifyCertificateChain(cordaCert, certs)
+	err = verifyCertificateChain(cordaCert, certs, nil, time.Now())
 	require.NoError(t, err)
 }
+
+func TestVerifyCertificateRevocation(t *testing.T) {
+	rootCert, rootKey, err := createCACertificate("root-ca", 1, nil, nil)
+	require.NoError(t, err)
+	intCert, intKey, err := createCACertificate("intermediate-ca", 2, rootCert, rootKey)
+	require.NoError(t, err)
+	orgCert, orgKey, err := createCACertificate("org-ca", 4, intCert, intKey)
+	require.NoError(t, err)
+	peerCert, _, err := createCACertificate("peer0", 3, orgCert, orgKey)
+	require.NoError(t, err)
+	chain := []string{x509ToPem(rootCert), x509ToPem(intCert), x509ToPem(orgCert)}
+
+	// Happy case without and with CRLs
+	err = verifyCertificateChain(peerCert, chain, nil, time.Now())
+	require.NoError(t, err)
+	unrelatedCRL, err := createCRLPEM(orgCert, orgKey, []int64{5})
+	require.NoError(t, err)
+	err = verifyCertificateChain(peerCert, chain, []string{unrelatedCRL}, time.Now())
+	require.NoError(t, err)
+
+	// Revoked endorser certificate
+	peerCRL, err := createCRLPEM(orgCert, orgKey, []int64{3})
+	require.NoError(t, err)
+	err = verifyCertificateChain(peerCert, chain, []string{unrelatedCRL, peerCRL}, time.Now())
+	require.EqualError(t, err, "Certificate CN=peer0 with serial number 3 has been revoked by CN=org-ca")
+
+	// Revoked intermediate certificate
+	intCRL, err := createCRLPEM(rootCert, rootKey, []int64{2})
+	require.NoError(t, err)
+	err = verifyCertificateChain(peerCert, chain, []string{intCRL}, time.Now())
+	require.EqualError(t, err, "Certificate CN=intermediate-ca with serial number 2 has been revoked by CN=root-ca")
+
+	// Revoked certificate issued by a CA member
+	err = verifyCaCertificate(intCert, chain[0], nil, time.Now())
+	require.NoError(t, err)
+	err = verifyCaCertificate(intCert, chain[0], []string{intCRL}, time.Now())
+	require.EqualError(t, err, "Certificate CN=intermediate-ca with serial number 2 has been revoked by CN=root-ca")
+
+	// Invalid CRL
+	err = verifyCertificateChain(peerCert, chain, []string{"invalid"}, time.Now())
+	require.EqualError(t, err, "Unable to decode CRL PEM")
+
+	// CRLs must be signed by the member's CAs
+	err = validateCRLsFromCAs([]string{peerCRL, intCRL}, chain)
+	require.NoError(t, err)
+	otherCACert, otherCAKey, err := createCACertificate("org-ca", 4, nil, nil)
+	require.NoError(t, err)
+	forgedCRL, err := createCRLPEM(otherCACert, otherCAKey, []int64{3})
+	require.NoError(t, err)
+	err = validateCRLsFromCAs([]string{forgedCRL}, chain)
+	require.EqualError(t, err, "CRL issued by CN=org-ca is not signed by any CA of the member")
+}
 func TestParseCert(t *testing.T) {
 	// Test: Valid cert (happy case)
 	validCert := "-----BEGIN CERTIFICATE-----\nMIICKjCCAdGgAwIBAgIUBFTi56rmjunJiRESpyJW0q4sRL4wCgYIKoZIzj0EAwIw\ncjELMAkGA1UEBhMCVVMxFzAVBgNVBAgTDk5vcnRoIENhcm9saW5hMQ8wDQYDVQQH\nEwZEdXJoYW0xGjAYBgNVBAoTEW9yZzEubmV0d29yazEuY29tMR0wGwYDVQQDExRj\nYS5vcmcxLm5ldHdvcmsxLmNvbTAeFw0yMDA3MjkwNDM1MDBaFw0zNTA3MjYwNDM1\nMDBaMHIxCzAJBgNVBAYTAlVTMRcwFQYDVQQIEw5Ob3J0aCBDYXJvbGluYTEPMA0G\nA1UEBxMGRHVyaGFtMRowGAYDVQQKExFvcmcxLm5ldHdvcmsxLmNvbTEdMBsGA1UE\nAxMUY2Eub3JnMS5uZXR3b3JrMS5jb20wWTATBgcqhkjOPQIBBggqhkjOPQMBBwNC\nAAQONsIOz5o+HhKgSdIOpqGrTcvJ3tADkFsyMg0vV3MSo6gyAq5V23c1grO4X5xU\nY71ZVTPQuokv6/WIQYIaumjDo0UwQzAOBgNVHQ8BAf8EBAMCAQYwEgYDVR0TAQH/\nBAgwBgEB/wIBATAdBgNVHQ4EFgQU1g+tPngh2w8g99z1mwsVbkKjAKkwCgYIKoZI\nzj0EAwIDRwAwRAIgGdSMyEzimoSwjTyF+NmOwOLn4xpeMOhev5idRWpy+ZsCIFKA\n0I8cCd5tw7zTukyjWMJi737K+4zPK6QDKIeql+R1\n-----END CERTIFICATE-----\n"
@@ -114,7 +168,7 @@ func TestIsCertificateWithinExpiry(t *testing.T) {
 		fmt.Printf("Parse ERROR %s \n", err.Error())
 		t.Fatal(fmt.Sprintf("Parse ERROR %s \n", err.Error()))
 	}
-	err = isCertificateWithinExpiry(x509Cert)
+	err = isCertificateWithinExpiry(x509Cert, now)
 	require.NoError(t, err)
 
 	// Test: Expired cert case
@@ -123,7 +177,7 @@ func TestIsCertificateWithinExpiry(t *testing.T) {
 		fmt.Printf("Parse ERROR %s \n", err.Error())
 		t.Fatal(fmt.Sprintf("Parse ERROR %s \n", err.Error()))
 	}
-	err = isCertificateWithinExpiry(x509Cert)
+	err = isCertificateWithinExpiry(x509Cert, now)
 	require.EqualError(t, err, fmt.Sprintf("Cert is invalid"))
 
 	// Test: Not valid yet case
@@ -132,7 +186,7 @@ func TestIsCertificateWithinExpiry(t *testing.T) {
 		fmt.Printf("Parse ERROR %s \n", err.Error())
 		t.Fatal(fmt.Sprintf("Parse ERROR %s \n", err.Error()))
 	}
-	err = isCertificateWithinExpiry(x509Cert)
+	err = isCertificateWithinExpiry(x509Cert, now)
 	require.EqualError(t, err, fmt.Sprintf("Cert is invalid"))
 }
 
@@ -194,10 +248,11 @@ func TestECDSAEncryption(t *testing.T) {
 
 	// Encrypt some random bytes
 	message := []byte("random-message")
-	encBytes, err := encryptWithCert(message, cert)
+	encBytes, encryptionScheme, err := encryptWithCert(message, cert)
 	if err != nil {
 		t.Fatal(err)
 	}
+	require.Equal(t, common.ConfidentialPayload_ECIES, encryptionScheme)
 	fmt.Printf("Original message: %s\n", string(message))
 	fmt.Printf("Encrypted message: %s\n", string(encBytes))
 
@@ -209,6 +264,31 @@ func TestECDSAEncryption(t *testing.T) {
 	require.Equal(t, message, decBytes)
 }
 
+func TestEd25519Encryption(t *testing.T) {
+	template := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: "corda-client",
+		},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		SerialNumber: big.NewInt(1337),
+	}
+	certBytes, privKey, err := createED25519CertAndKeyFromTemplate(template)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certBytes)
+	require.NoError(t, err)
+
+	// Encrypt and decrypt some random bytes
+	message := []byte("random-message")
+	encBytes, encryptionScheme, err := encryptWithCert(message, cert)
+	require.NoError(t, err)
+	require.Equal(t, common.ConfidentialPayload_X25519_CHACHA20_POLY1305, encryptionScheme)
+	require.Equal(t, 32+12+len(message)+16, len(encBytes))
+	decBytes, err := x25519.DecryptWithEd25519PrivateKey(encBytes, *privKey)
+	require.NoError(t, err)
+	require.Equal(t, message, decBytes)
+}
+
 func TestConfidentialInteropPayload(t *testing.T) {
 	// Load certificate with embedded public key
 	certBytes, _ := ioutil.ReadFile("./test_data/signCertFabric.pem")
@@ -243,6 +323,62 @@ func TestConfidentialInteropPayload(t *testing.T) {
 	mac.Write(confPayloadContents.Payload)
 	fmac := mac.Sum(nil)
 	require.Equal(t, confPayload.Hash, fmac)
+
+	// Decrypt and authenticate in one step
+	require.Equal(t, uint32(confidentialPayloadVersion), confPayload.Version)
+	require.Equal(t, common.ConfidentialPayload_ECIES, confPayload.EncryptionScheme)
+	privKey := loadECDSAPrivKeyFile(t, "./test_data/privKey.pem")
+	decContents, err := decryptConfidentialPayload(confBytes, privKey)
+	require.NoError(t, err)
+	require.Equal(t, viewContents, decContents.Payload)
+
+	// Payloads without a version are ECIES encrypted
+	confPayload.Version = 0
+	legacyConfBytes, _ := proto.Marshal(&confPayload)
+	decContents, err = decryptConfidentialPayload(legacyConfBytes, privKey)
+	require.NoError(t, err)
+	require.Equal(t, viewContents, decContents.Payload)
+
+	// Tampered hash
+	confPayload.Hash = []byte("invalid")
+	tamperedConfBytes, _ := proto.Marshal(&confPayload)
+	_, err = decryptConfidentialPayload(tamperedConfBytes, privKey)
+	require.EqualError(t, err, "Decrypted payload does not match the payload hash")
+
+	// Ed25519 requestor
+	template := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: "corda-client",
+		},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		SerialNumber: big.NewInt(1337),
+	}
+	edCertBytes, edPrivKey, err := createED25519CertAndKeyFromTemplate(template)
+	require.NoError(t, err)
+	edCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: edCertBytes})
+	confBytes, err = generateConfidentialInteropPayloadAndHash(viewContents, string(edCertPEM))
+	require.NoError(t, err)
+	err = proto.Unmarshal(confBytes, &confPayload)
+	require.NoError(t, err)
+	require.Equal(t, common.ConfidentialPayload_X25519_CHACHA20_POLY1305, confPayload.EncryptionScheme)
+	require.NotEmpty(t, confPayload.EncryptedPayload)
+	decContents, err = decryptConfidentialPayload(confBytes, *edPrivKey)
+	require.NoError(t, err)
+	require.Equal(t, viewContents, decContents.Payload)
+
+	// Mismatching key type
+	_, err = decryptConfidentialPayload(confBytes, privKey)
+	require.EqualError(t, err, "X25519_CHACHA20_POLY1305 encrypted payloads require an Ed25519 private key")
+}
+
+func loadECDSAPrivKeyFile(t *testing.T, privKeyFile string) *ecdsa.PrivateKey {
+	signkeyPEM, err := ioutil.ReadFile(privKeyFile)
+	require.NoError(t, err)
+	signkeyBytes, _ := pem.Decode([]byte(signkeyPEM))
+	signkeyPriv, err := x509.ParsePKCS8PrivateKey(signkeyBytes.Bytes)
+	require.NoError(t, err)
+	return signkeyPriv.(*ecdsa.PrivateKey)
 }
 
 func generateCertFromTemplate(template x509.Certificate, keyType string) ([]byte, error) {
@@ -350,3 +486,104 @@ func decryptDataWithPrivKey(privKeyECDSA *ecdsa.PrivateKey, data []byte) ([]byte
 	// Decrypt response and match
 	return privKey.Decrypt(data, nil, nil)
 }
+
+// createCACertificate creates a certificate that can sign certificates and CRLs, self-signed if no issuer is given
+func createCACertificate(commonName string, serialNumber int64, issuerCert *x509.Certificate, issuerKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := x509.Certificate{
+		Subject: pkix.Name{
+			CommonName: commonName,
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		SerialNumber:          big.NewInt(serialNumber),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if issuerCert == nil {
+		issuerCert, issuerKey = &template, key
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, issuerCert, &key.PublicKey, issuerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(certBytes)
+	return cert, key, err
+}
+
+// createCRLPEM creates a PEM-encoded CRL issued by the given CA that revokes the given serial numbers
+func createCRLPEM(issuerCert *x509.Certificate, issuerKey *ecdsa.PrivateKey, revokedSerialNumbers []int64) (string, error) {
+	template := x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, serialNumber := range revokedSerialNumbers {
+		template.RevokedCertificates = append(template.RevokedCertificates, pkix.RevokedCertificate{
+			SerialNumber:   big.NewInt(serialNumber),
+			RevocationTime: time.Now(),
+		})
+	}
+	crlBytes, err := x509.CreateRevocationList(rand.Reader, &template, issuerCert, issuerKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crlBytes})), nil
+}
+
+func x509ToPem(cert *x509.Certificate) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+}
+
+// decryptConfidentialPayload decrypts a serialized ConfidentialPayload using the requestor's private key and
+// authenticates the decrypted contents against the payload hash
+func decryptConfidentialPayload(confidentialPayloadBytes []byte, privKey crypto.PrivateKey) (*common.ConfidentialPayloadContents, error) {
+	confidentialPayload := &common.ConfidentialPayload{}
+	err := proto.Unmarshal(confidentialPayloadBytes, confidentialPayload)
+	if err != nil {
+		return nil, fmt.Errorf("ConfidentialPayload Unmarshal error: %s", err)
+	}
+	if confidentialPayload.Version > confidentialPayloadVersion {
+		return nil, fmt.Errorf("Unsupported confidential payload version: %d", confidentialPayload.Version)
+	}
+	var decryptedPayload []byte
+	switch confidentialPayload.EncryptionScheme {
+	case common.ConfidentialPayload_ECIES:
+		ecdsaPrivKey, ok := privKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("ECIES encrypted payloads require an ECDSA private key")
+		}
+		decryptedPayload, err = ecies.ImportECDSA(ecdsaPrivKey).Decrypt(confidentialPayload.EncryptedPayload, nil, nil)
+	case common.ConfidentialPayload_X25519_CHACHA20_POLY1305:
+		ed25519PrivKey, ok := privKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("X25519_CHACHA20_POLY1305 encrypted payloads require an Ed25519 private key")
+		}
+		decryptedPayload, err = x25519.DecryptWithEd25519PrivateKey(confidentialPayload.EncryptedPayload, ed25519PrivKey)
+	default:
+		return nil, fmt.Errorf("Unsupported confidential payload encryption scheme: %s", confidentialPayload.EncryptionScheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	confidentialPayloadContents := &common.ConfidentialPayloadContents{}
+	err = proto.Unmarshal(decryptedPayload, confidentialPayloadContents)
+	if err != nil {
+		return nil, fmt.Errorf("ConfidentialPayloadContents Unmarshal error: %s", err)
+	}
+	if confidentialPayload.HashType != common.ConfidentialPayload_HMAC {
+		return nil, fmt.Errorf("Unsupported confidential payload hash type: %s", confidentialPayload.HashType)
+	}
+	payloadHMAC, err := generateHMAC(confidentialPayloadContents.Payload, confidentialPayloadContents.Random)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(payloadHMAC, confidentialPayload.Hash) {
+		return nil, errors.New("Decrypted payload does not match the payload hash")
+	}
+	return confidentialPayloadContents, nil
+}
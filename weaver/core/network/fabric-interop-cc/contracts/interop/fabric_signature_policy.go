@@ -0,0 +1,321 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// fabric_signature_policy contains the parser and evaluator for verification policies of type FabricSignaturePolicy,
+// whose single criterion is a Fabric signature policy, either as a base64-encoded serialized SignaturePolicyEnvelope
+// (e.g., the endorsement policy in a chaincode definition) or in the Fabric policy DSL, e.g.
+// `OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')` or `AND('Org1MSP.member', OR('Org2MSP.peer', 'Org3MSP.peer'))`.
+package main
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	fabriccommon "github.com/hyperledger/fabric-protos-go/common"
+	"github.com/hyperledger/fabric-protos-go/msp"
+)
+
+// policySigner is a verified signer of a view: its identifier (MSP ID or notary name) and, for endorsers of Fabric views,
+// its certificate, from which the roles it holds in Fabric signature policies are determined
+type policySigner struct {
+	id   string
+	cert *x509.Certificate
+}
+
+// policySignerIDs lists the identifiers of the signers
+func policySignerIDs(signers []policySigner) []string {
+	signerIDs := make([]string, len(signers))
+	for i, signer := range signers {
+		signerIDs[i] = signer.id
+	}
+	return signerIDs
+}
+
+func isFabricSignaturePolicy(policy *common.Policy) bool {
+	return strings.EqualFold(policy.Type, policyTypeFabricSignature)
+}
+
+// verifySignersSatisfyPolicy checks that the verified signers of a view satisfy the verification policy. Fabric signature
+// policies are evaluated against the roles of the signers; other policies only against their identifiers.
+func verifySignersSatisfyPolicy(policy *common.Policy, signers []policySigner) error {
+	if err := validatePolicy(policy); err != nil {
+		return fmt.Errorf("Invalid verification policy: %s", err.Error())
+	}
+	if isFabricSignaturePolicy(policy) {
+		return verifyFabricSignaturePolicySatisfied(policy.Criteria[0], signers)
+	}
+	return verifyPolicySatisfied(policy, policySignerIDs(signers))
+}
+
+// verifyFabricSignaturePolicySatisfied evaluates a Fabric signature policy the way Fabric evaluates endorsement policies:
+// each distinct signer can satisfy at most one principal in the policy
+func verifyFabricSignaturePolicySatisfied(criterion string, signers []policySigner) error {
+	envelope, err := parseFabricSignaturePolicy(criterion)
+	if err != nil {
+		return fmt.Errorf("Invalid verification policy: %s", err.Error())
+	}
+	var distinctSigners []policySigner
+	for _, signer := range signers {
+		duplicate := false
+		for _, distinctSigner := range distinctSigners {
+			if signer.id == distinctSigner.id && (signer.cert == nil || distinctSigner.cert == nil || signer.cert.Equal(distinctSigner.cert)) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			distinctSigners = append(distinctSigners, signer)
+		}
+	}
+	principals := make([]*msp.MSPRole, len(envelope.Identities))
+	for i, identity := range envelope.Identities {
+		// identities have been validated while parsing the policy
+		principals[i] = &msp.MSPRole{}
+		proto.Unmarshal(identity.Principal, principals[i])
+	}
+	used := make([]bool, len(distinctSigners))
+	if !evaluateFabricSignaturePolicy(envelope.Rule, principals, distinctSigners, used) {
+		return fmt.Errorf("Notarizations do not satisfy Fabric signature policy: %s", criterion)
+	}
+	return nil
+}
+
+// evaluateFabricSignaturePolicy reports whether unused signers satisfy the rule, marking the signers it relies on as used
+func evaluateFabricSignaturePolicy(rule *fabriccommon.SignaturePolicy, principals []*msp.MSPRole, signers []policySigner, used []bool) bool {
+	switch rule.Type.(type) {
+	case *fabriccommon.SignaturePolicy_SignedBy:
+		principal := principals[rule.GetSignedBy()]
+		for i, signer := range signers {
+			if !used[i] && signerHasRole(signer, principal) {
+				used[i] = true
+				return true
+			}
+		}
+		return false
+	case *fabriccommon.SignaturePolicy_NOutOf_:
+		verified := int32(0)
+		ruleUsed := make([]bool, len(used))
+		for _, subRule := range rule.GetNOutOf().Rules {
+			copy(ruleUsed, used)
+			if evaluateFabricSignaturePolicy(subRule, principals, signers, ruleUsed) {
+				verified++
+				copy(used, ruleUsed)
+			}
+		}
+		return verified >= rule.GetNOutOf().N
+	}
+	return false
+}
+
+// signerHasRole checks that the signer belongs to the MSP of the principal and holds its role. Any signer of the MSP is a
+// member; the admin, client, peer and orderer roles are held by signers whose certificates carry the corresponding
+// organizational unit, as issued by MSPs with node OUs enabled.
+func signerHasRole(signer policySigner, principal *msp.MSPRole) bool {
+	if signer.id != principal.MspIdentifier {
+		return false
+	}
+	if principal.Role == msp.MSPRole_MEMBER {
+		return true
+	}
+	if signer.cert == nil {
+		return false
+	}
+	for _, ou := range signer.cert.Subject.OrganizationalUnit {
+		if strings.EqualFold(ou, principal.Role.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFabricSignaturePolicy parses a Fabric signature policy, given either in the Fabric policy DSL or as a base64-encoded
+// serialized SignaturePolicyEnvelope, and validates it
+func parseFabricSignaturePolicy(criterion string) (*fabriccommon.SignaturePolicyEnvelope, error) {
+	var envelope *fabriccommon.SignaturePolicyEnvelope
+	trimmedCriterion := strings.TrimSpace(criterion)
+	if strings.HasSuffix(trimmedCriterion, ")") {
+		parser := &fabricPolicyDSLParser{input: trimmedCriterion}
+		rule, err := parser.parseRule()
+		if err != nil {
+			return nil, fmt.Errorf("invalid Fabric signature policy '%s': %s", criterion, err.Error())
+		}
+		parser.skipSpaces()
+		if parser.pos < len(parser.input) {
+			return nil, fmt.Errorf("invalid Fabric signature policy '%s': unexpected '%s' at position %d", criterion, parser.input[parser.pos:], parser.pos)
+		}
+		envelope = &fabriccommon.SignaturePolicyEnvelope{Version: 0, Rule: rule, Identities: parser.identities}
+	} else {
+		envelopeBytes, err := base64.StdEncoding.DecodeString(trimmedCriterion)
+		if err != nil {
+			return nil, fmt.Errorf("Fabric signature policy is neither a policy DSL expression nor base64-encoded: %s", err.Error())
+		}
+		envelope = &fabriccommon.SignaturePolicyEnvelope{}
+		err = proto.Unmarshal(envelopeBytes, envelope)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal Fabric signature policy: %s", err.Error())
+		}
+	}
+	if envelope.Rule == nil {
+		return nil, fmt.Errorf("Fabric signature policy has no rule")
+	}
+	for i, identity := range envelope.Identities {
+		if identity.PrincipalClassification != msp.MSPPrincipal_ROLE {
+			return nil, fmt.Errorf("principal %d of Fabric signature policy is not an MSP role: %s", i, identity.PrincipalClassification)
+		}
+		var mspRole msp.MSPRole
+		err := proto.Unmarshal(identity.Principal, &mspRole)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal principal %d of Fabric signature policy: %s", i, err.Error())
+		}
+	}
+	if err := validateFabricSignaturePolicyRule(envelope.Rule, len(envelope.Identities)); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// validateFabricSignaturePolicyRule checks that a rule only refers to principals of the policy
+func validateFabricSignaturePolicyRule(rule *fabriccommon.SignaturePolicy, numPrincipals int) error {
+	switch rule.Type.(type) {
+	case *fabriccommon.SignaturePolicy_SignedBy:
+		if rule.GetSignedBy() < 0 || int(rule.GetSignedBy()) >= numPrincipals {
+			return fmt.Errorf("Fabric signature policy refers to unknown principal %d", rule.GetSignedBy())
+		}
+	case *fabriccommon.SignaturePolicy_NOutOf_:
+		for _, subRule := range rule.GetNOutOf().Rules {
+			if err := validateFabricSignaturePolicyRule(subRule, numPrincipals); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("Fabric signature policy has a rule of unknown type")
+	}
+	return nil
+}
+
+// fabricPolicyDSLParser parses the Fabric policy DSL, collecting the distinct principals of the policy as it goes
+type fabricPolicyDSLParser struct {
+	input      string
+	pos        int
+	identities []*msp.MSPPrincipal
+	principals []string
+}
+
+func (p *fabricPolicyDSLParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t' || p.input[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *fabricPolicyDSLParser) expect(c byte) error {
+	p.skipSpaces()
+	if p.pos >= len(p.input) || p.input[p.pos] != c {
+		return fmt.Errorf("expected '%c' at position %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// parseRule parses a principal, or an AND, OR or OutOf gate over rules
+func (p *fabricPolicyDSLParser) parseRule() (*fabriccommon.SignaturePolicy, error) {
+	p.skipSpaces()
+	if p.pos < len(p.input) && (p.input[p.pos] == '\'' || p.input[p.pos] == '"') {
+		return p.parsePrincipal()
+	}
+	start := p.pos
+	for p.pos < len(p.input) && isPolicyIDStart(p.input[p.pos]) {
+		p.pos++
+	}
+	gate := p.input[start:p.pos]
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	threshold := -1
+	if strings.EqualFold(gate, "OutOf") {
+		p.skipSpaces()
+		start := p.pos
+		for p.pos < len(p.input) && isDigit(p.input[p.pos]) {
+			p.pos++
+		}
+		n, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil {
+			return nil, fmt.Errorf("expected threshold of OutOf at position %d", start)
+		}
+		threshold = n
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+	} else if !strings.EqualFold(gate, "AND") && !strings.EqualFold(gate, "OR") {
+		return nil, fmt.Errorf("unknown gate '%s' at position %d", gate, start)
+	}
+	var rules []*fabriccommon.SignaturePolicy
+	for {
+		rule, err := p.parseRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+		p.skipSpaces()
+		if p.pos < len(p.input) && p.input[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		break
+	}
+	if strings.EqualFold(gate, "AND") {
+		threshold = len(rules)
+	} else if strings.EqualFold(gate, "OR") {
+		threshold = 1
+	}
+	return &fabriccommon.SignaturePolicy{
+		Type: &fabriccommon.SignaturePolicy_NOutOf_{NOutOf: &fabriccommon.SignaturePolicy_NOutOf{N: int32(threshold), Rules: rules}},
+	}, nil
+}
+
+// parsePrincipal parses a quoted principal of the form 'MSPID.role'
+func (p *fabricPolicyDSLParser) parsePrincipal() (*fabriccommon.SignaturePolicy, error) {
+	quote := p.input[p.pos]
+	start := p.pos
+	end := strings.IndexByte(p.input[p.pos+1:], quote)
+	if end < 0 {
+		return nil, fmt.Errorf("unterminated principal at position %d", start)
+	}
+	principal := p.input[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	separator := strings.LastIndex(principal, ".")
+	if separator <= 0 {
+		return nil, fmt.Errorf("principal '%s' at position %d is not of the form 'MSPID.role'", principal, start)
+	}
+	role, ok := msp.MSPRole_MSPRoleType_value[strings.ToUpper(principal[separator+1:])]
+	if !ok {
+		return nil, fmt.Errorf("unknown role '%s' of principal at position %d", principal[separator+1:], start)
+	}
+	index := -1
+	for i, knownPrincipal := range p.principals {
+		if knownPrincipal == principal {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		mspRoleBytes, err := proto.Marshal(&msp.MSPRole{MspIdentifier: principal[:separator], Role: msp.MSPRole_MSPRoleType(role)})
+		if err != nil {
+			return nil, err
+		}
+		p.identities = append(p.identities, &msp.MSPPrincipal{PrincipalClassification: msp.MSPPrincipal_ROLE, Principal: mspRoleBytes})
+		p.principals = append(p.principals, principal)
+		index = len(p.principals) - 1
+	}
+	return &fabriccommon.SignaturePolicy{Type: &fabriccommon.SignaturePolicy_SignedBy{SignedBy: int32(index)}}, nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+// signerWithOUs returns a signer of the given MSP whose certificate carries the given organizational units
+func signerWithOUs(mspID string, ous ...string) policySigner {
+	return policySigner{id: mspID, cert: &x509.Certificate{Raw: []byte(mspID + ous[0]), Subject: pkix.Name{OrganizationalUnit: ous}}}
+}
+
+func TestParseFabricSignaturePolicy(t *testing.T) {
+	envelope, err := parseFabricSignaturePolicy("OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org1MSP.peer')")
+	require.NoError(t, err)
+	require.Len(t, envelope.Identities, 2)
+	require.Equal(t, int32(2), envelope.Rule.GetNOutOf().N)
+	require.Len(t, envelope.Rule.GetNOutOf().Rules, 3)
+	require.Equal(t, int32(0), envelope.Rule.GetNOutOf().Rules[2].GetSignedBy())
+
+	envelope, err = parseFabricSignaturePolicy(`AND("Org1MSP.member", OR('Org2MSP.admin', 'Org3MSP.client'))`)
+	require.NoError(t, err)
+	require.Len(t, envelope.Identities, 3)
+	require.Equal(t, int32(2), envelope.Rule.GetNOutOf().N)
+	require.Equal(t, int32(1), envelope.Rule.GetNOutOf().Rules[1].GetNOutOf().N)
+
+	// The serialized envelope of a policy is accepted in base64
+	envelopeBytes, err := proto.Marshal(envelope)
+	require.NoError(t, err)
+	parsedEnvelope, err := parseFabricSignaturePolicy(base64.StdEncoding.EncodeToString(envelopeBytes))
+	require.NoError(t, err)
+	require.True(t, proto.Equal(envelope, parsedEnvelope))
+
+	_, err = parseFabricSignaturePolicy("AND('Org1MSP.peer' 'Org2MSP.peer')")
+	require.EqualError(t, err, "invalid Fabric signature policy 'AND('Org1MSP.peer' 'Org2MSP.peer')': expected ')' at position 19")
+	_, err = parseFabricSignaturePolicy("XOR('Org1MSP.peer')")
+	require.EqualError(t, err, "invalid Fabric signature policy 'XOR('Org1MSP.peer')': unknown gate 'XOR' at position 0")
+	_, err = parseFabricSignaturePolicy("OR('Org1MSP.superuser')")
+	require.EqualError(t, err, "invalid Fabric signature policy 'OR('Org1MSP.superuser')': unknown role 'superuser' of principal at position 3")
+	_, err = parseFabricSignaturePolicy("OR('Org1MSP')")
+	require.EqualError(t, err, "invalid Fabric signature policy 'OR('Org1MSP')': principal 'Org1MSP' at position 3 is not of the form 'MSPID.role'")
+	_, err = parseFabricSignaturePolicy("OutOf(x, 'Org1MSP.peer')")
+	require.EqualError(t, err, "invalid Fabric signature policy 'OutOf(x, 'Org1MSP.peer')': expected threshold of OutOf at position 6")
+	_, err = parseFabricSignaturePolicy("Org1MSP")
+	require.ErrorContains(t, err, "Fabric signature policy is neither a policy DSL expression nor base64-encoded")
+
+	// Rules must only refer to principals of the policy
+	envelope.Identities = envelope.Identities[:1]
+	envelopeBytes, err = proto.Marshal(envelope)
+	require.NoError(t, err)
+	_, err = parseFabricSignaturePolicy(base64.StdEncoding.EncodeToString(envelopeBytes))
+	require.EqualError(t, err, "Fabric signature policy refers to unknown principal 1")
+}
+
+func TestVerifyFabricSignaturePolicySatisfied(t *testing.T) {
+	policy := "OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')"
+	err := verifyFabricSignaturePolicySatisfied(policy, []policySigner{signerWithOUs("Org1MSP", "peer"), signerWithOUs("Org3MSP", "peer")})
+	require.NoError(t, err)
+	err = verifyFabricSignaturePolicySatisfied(policy, []policySigner{signerWithOUs("Org1MSP", "peer"), signerWithOUs("Org3MSP", "client")})
+	require.EqualError(t, err, "Notarizations do not satisfy Fabric signature policy: "+policy)
+	// A signer without a certificate only holds the member role
+	err = verifyFabricSignaturePolicySatisfied(policy, []policySigner{{id: "Org1MSP"}, {id: "Org2MSP"}})
+	require.EqualError(t, err, "Notarizations do not satisfy Fabric signature policy: "+policy)
+	err = verifyFabricSignaturePolicySatisfied("AND('Org1MSP.member', 'Org2MSP.member')", []policySigner{{id: "Org1MSP"}, {id: "Org2MSP"}})
+	require.NoError(t, err)
+
+	// Each signer satisfies at most one principal, and duplicate signatures are counted once
+	policy = "AND('Org1MSP.member', 'Org1MSP.peer')"
+	err = verifyFabricSignaturePolicySatisfied(policy, []policySigner{signerWithOUs("Org1MSP", "peer"), signerWithOUs("Org1MSP", "peer")})
+	require.EqualError(t, err, "Notarizations do not satisfy Fabric signature policy: "+policy)
+	// As in Fabric, principals are matched greedily with signers in the order of the signers
+	err = verifyFabricSignaturePolicySatisfied(policy, []policySigner{signerWithOUs("Org1MSP", "admin"), signerWithOUs("Org1MSP", "peer")})
+	require.NoError(t, err)
+	err = verifyFabricSignaturePolicySatisfied("OR(AND('Org1MSP.peer', 'Org2MSP.peer'), AND('Org1MSP.admin', 'Org3MSP.peer'))",
+		[]policySigner{signerWithOUs("Org1MSP", "admin"), signerWithOUs("Org2MSP", "peer"), signerWithOUs("Org3MSP", "peer")})
+	require.NoError(t, err)
+}
+
+func TestVerifySignersSatisfyPolicy(t *testing.T) {
+	signers := []policySigner{signerWithOUs("Org1MSP", "peer"), signerWithOUs("Org2MSP", "peer")}
+	err := verifySignersSatisfyPolicy(&common.Policy{Type: "FabricSignaturePolicy", Criteria: []string{"AND('Org1MSP.peer', 'Org2MSP.peer')"}}, signers)
+	require.NoError(t, err)
+	err = verifySignersSatisfyPolicy(&common.Policy{Type: "signature", Criteria: []string{"Org1MSP", "Org2MSP"}}, signers)
+	require.NoError(t, err)
+	err = verifySignersSatisfyPolicy(&common.Policy{Type: "FabricSignaturePolicy", Criteria: []string{"OR('Org1MSP.peer')", "OR('Org2MSP.peer')"}}, signers)
+	require.EqualError(t, err, "Invalid verification policy: Fabric signature policy must have a single criterion, found 2")
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/viewaddress"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	log "github.com/sirupsen/logrus"
@@ -27,8 +28,9 @@ import (
 // The flow coordinates the following:
 // 1. Checks the validity of query signature
 // 2. Checks that the certificate of the requester is valid according to the network's Membership
-// 3. Checks the access control policy for the requester and view address is met
-// 4. Calls application chaincode
+// 3. Checks that the query nonce is fresh (replays are rejected by the destination network in WriteExternalState)
+// 4. Checks the access control policy for the requester and view address is met
+// 5. Calls application chaincode
 func (s *SmartContract) HandleExternalRequest(ctx contractapi.TransactionContextInterface, b64QueryBytes string) (string, error) {
 	queryBytes, err := base64.StdEncoding.DecodeString(b64QueryBytes)
 	if err != nil {
@@ -39,10 +41,14 @@ func (s *SmartContract) HandleExternalRequest(ctx contractapi.TransactionContext
 	if err != nil {
 		return "", logThenErrorf("Unable to unmarshal query: %s", err.Error())
 	}
-	resp, err := handleRequest(s, ctx, query, query.Address)
+	resp, err := handleRequest(s, ctx, query, query.Address, false)
 	return resp, err
 }
 
+// HandleEventRequest chaincode processes event publication requests that come from external networks.
+// The query recorded at the time of subscription is reused for every event, so its nonce is not
+// checked for freshness. Instead, the view of each event carries its own nonce (see getEventViewNonce),
+// which the destination network records when consuming the view.
 func (s *SmartContract) HandleEventRequest(ctx contractapi.TransactionContextInterface, b64QueryBytes string, dynamicQueryArg string) (string, error) {
 	queryBytes, err := base64.StdEncoding.DecodeString(b64QueryBytes)
 	if err != nil {
@@ -60,13 +66,20 @@ func (s *SmartContract) HandleEventRequest(ctx contractapi.TransactionContextInt
 	if dynamicArgCount > 1 {
 		return "", logThenErrorf("Expected 1 dynamic argument in the event query address, but found %d", dynamicArgCount)
 	} else if dynamicArgCount == 1 {
+		address, err := parseAddress(query.Address)
+		if err != nil {
+			return "", logThenErrorf("%s", err.Error())
+		}
+		if address.Version == viewaddress.VersionEscaped {
+			dynamicQueryArg = viewaddress.EscapeSection(dynamicQueryArg)
+		}
 		queryAddress = strings.Replace(query.Address, ":?", ":"+dynamicQueryArg, 1)
 		fmt.Println("There is 1 dynamic argument in the event query address, queryArg: ", dynamicQueryArg)
 	} else {
 		fmt.Println("There are no dynamic arguments in the event query address, queryArg: ", dynamicQueryArg)
 	}
 
-	resp, err := handleRequest(s, ctx, query, queryAddress)
+	resp, err := handleRequest(s, ctx, query, queryAddress, true)
 	return resp, err
 }
 
@@ -75,9 +88,10 @@ func (s *SmartContract) HandleEventRequest(ctx contractapi.TransactionContextInt
 // The flow coordinates the following:
 // 1. Checks the validity of query signature
 // 2. Checks that the certificate of the requester is valid according to the network's Membership
-// 3. Checks the access control policy for the requester and view address is met
-// 4. Calls application chaincode
-func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface, query common.Query, queryAddress string) (string, error) {
+// 3. Checks that the query nonce is fresh (unless eventRequest is set)
+// 4. Checks the access control policy for the requester and view address is met
+// 5. Calls application chaincode
+func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface, query common.Query, queryAddress string, eventRequest bool) (string, error) {
 	// Ensure that this function cannot be called by a client without relay permissions
 	relayAccessCheck, err := wutils.IsClientRelay(ctx.GetStub())
 	if err != nil {
@@ -110,7 +124,22 @@ func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface
 	if err != nil {
 		return "", logThenErrorf("Membership Verification failed: %s", err)
 	}
-	// 3. Checks the access control policy for the requester and view address is met
+	// 3. Checks that the query nonce is fresh. The nonce is not recorded here as the relay driver evaluates this
+	// function as a query; the destination network records it when the view is consumed by WriteExternalState.
+	// The view of an event is given a nonce of its own, as the nonce of the subscription query is shared by all events.
+	viewNonce := query.Nonce
+	if eventRequest {
+		viewNonce, err = getEventViewNonce(ctx)
+		if err != nil {
+			return "", logThenErrorf("%s", err.Error())
+		}
+	} else {
+		_, err = checkQueryNonceFreshness(s, ctx, query.Nonce)
+		if err != nil {
+			return "", logThenErrorf("Replay check failed: %s", err)
+		}
+	}
+	// 4. Checks the access control policy for the requester and view address is met
 	address, err := parseAddress(queryAddress)
 	if err != nil {
 		return "", logThenErrorf("Invalid address: %s", err)
@@ -119,11 +148,17 @@ func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface
 	if err != nil {
 		return "", logThenErrorf("Invalid view address: %s", err)
 	}
-	err = verifyAccessToCC(s, ctx, viewAddress, address.ViewSegment, &query)
+	// Access control policies are matched against the canonical view segment, so that encoding the view differently
+	// does not change the rules that apply to it
+	canonicalViewSegment, err := viewaddress.Canonical(address.ViewSegment)
+	if err != nil {
+		return "", logThenErrorf("Invalid view address: %s", err)
+	}
+	err = verifyAccessToCC(s, ctx, viewAddress, canonicalViewSegment, &query)
 	if err != nil {
 		return "", logThenErrorf("CC Access Denied: %s", err)
 	}
-	// 4. Calls application chaincode
+	// 5. Calls application chaincode
 	arr := append([]string{viewAddress.CCFunc}, viewAddress.Args...)
 	byteArgs := strArrToBytesArr(arr)
 
@@ -168,7 +203,7 @@ func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface
 		if pbResp.Status != shim.OK {
 			return "", logThenErrorf("Application chaincode invoke error: %s", string(pbResp.GetMessage()))
 		}
-		// 5. Encrypt payload if necessary
+		// 6. Encrypt payload if necessary
 		confFlag, err := ctx.GetStub().GetState(e2eConfidentialityKey)
 		if err != nil {
 			log.Error(err)
@@ -180,7 +215,7 @@ func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface
 			// Use already authenticated certificate as the source of the public key for encryption
 			payload, err = generateConfidentialInteropPayloadAndHash(pbResp.Payload, query.Certificate)
 			if err != nil {
-				return "", logThenErrorf(err.Error())
+				return "", logThenErrorf("%s", err.Error())
 			}
 		} else {
 			payload = pbResp.Payload
@@ -192,7 +227,7 @@ func handleRequest(s *SmartContract, ctx contractapi.TransactionContextInterface
 		Payload:              payload,
 		Confidential:         confidential,
 		RequestorCertificate: query.Certificate,
-		Nonce:                query.Nonce,
+		Nonce:                viewNonce,
 	}
 	interopPayloadBytes, err := protoV2.Marshal(&interopPayloadStruct)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+	"strings"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -31,6 +32,7 @@ import (
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/require"
 	protoV2 "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // function that supplies value that is to be returned by ctx.GetStub().GetCreator()
@@ -130,6 +132,8 @@ func TestHandleExternalRequest(t *testing.T) {
 	testHandleExternalRequestNoMembership(t, &query, validCertificate, signature, pbResp)
 	// Happy case. ECDSA Cert and Valid Signature
 	testHandleExternalRequestECDSAHappyCase(t, &query, validCertificate, key, signature, pbResp, &accessControlAsset, &membershipAsset)
+	// Deny rules apply to views whose address is encoded differently
+	testHandleExternalRequestEncodedAddressDenied(t, &query, validCertificate, key, pbResp, &membershipAsset)
 	// ed25519 Cert and Signature
 	testHandleExternalRequestED25519Signature(t, &query, pbResp, &accessControlAsset, &membershipAsset, template)
 	// Test event requests
@@ -225,6 +229,62 @@ func testHandleEventRequestInvalidDynamicQueryArgs(t *testing.T, query *common.Q
 	query.Address = queryAddress
 }
 
+func testHandleExternalRequestEncodedAddressDenied(t *testing.T, query *common.Query, validCertificate string, validPrivateKey *ecdsa.PrivateKey, pbResp pb.Response, membership *common.Membership) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	chaincodeStub.GetCreatorReturns([]byte(getRelayCreator()), nil)
+	interopCCId := "interopcc"
+	wtest.SetMockStubCCId(chaincodeStub, interopCCId)
+
+	accessControl := common.AccessControlPolicy{
+		SecurityDomain: "2345",
+		Rules: []*common.Rule{{
+			Principal:     validCertificate,
+			PrincipalType: "certificate",
+			Read:          true,
+			Resource:      "mychannel:interop:Read:*",
+		}, {
+			Principal:     "*",
+			PrincipalType: "*",
+			Resource:      "mychannel:interop:Read:a",
+			Deny:          true,
+		}},
+	}
+	membershipBytes, err := json.Marshal(membership)
+	require.NoError(t, err)
+	accessControlBytes, err := json.Marshal(&accessControl)
+	require.NoError(t, err)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.Now(), nil)
+	chaincodeStub.InvokeChaincodeReturns(pbResp)
+
+	// The argument 'a' is denied however the view segment is encoded
+	for i, address := range []string{
+		"localhost:9080/network1/mychannel:interop:Read:a",
+		"localhost:9080/network1/@v2:mychannel:interop:Read:a",
+		"localhost:9080/network1/@v2:mychannel:interop:Read:%61",
+		"localhost:9080/network1/@v2:%6Dychannel:interop:%52ead:%61",
+	} {
+		encodedQuery := protoV2.Clone(query).(*common.Query)
+		encodedQuery.Certificate = validCertificate
+		encodedQuery.Confidential = false
+		encodedQuery.Address = address
+		hashed, err := computeSHA2Hash([]byte(encodedQuery.Address+encodedQuery.Nonce), validPrivateKey.PublicKey.Params().BitSize)
+		require.NoError(t, err)
+		signature, err := ecdsa.SignASN1(rand.Reader, validPrivateKey, hashed)
+		require.NoError(t, err)
+		encodedQuery.RequestorSignature = base64.StdEncoding.EncodeToString(signature)
+		queryBytes, err := protoV2.Marshal(encodedQuery)
+		require.NoError(t, err)
+
+		chaincodeStub.GetStateReturnsOnCall(4*i, membershipBytes, nil)
+		chaincodeStub.GetStateReturnsOnCall(4*i+2, accessControlBytes, nil)
+		chaincodeStub.GetStateReturnsOnCall(4*i+3, []byte(accessModeRead), nil)
+		_, err = interopcc.HandleExternalRequest(ctx, base64.StdEncoding.EncodeToString(queryBytes))
+		require.ErrorContains(t, err, "CC Access Denied: Access Control Policy DOES NOT PERMIT the request 'mychannel:interop:Read:a' from 'network1:", address)
+	}
+	require.Equal(t, 0, chaincodeStub.InvokeChaincodeCallCount())
+}
+
 func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query, validCertificate string, validPrivateKey *ecdsa.PrivateKey, signature []byte, pbResp pb.Response, accessControl *common.AccessControlPolicy, membership *common.Membership) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	interopcc := SmartContract{}
@@ -255,7 +315,9 @@ func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query,
 	accessControlBytes, err := json.Marshal(accessControl)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(1, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(3, []byte(accessModeRead), nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.Now(), nil)
 	chaincodeStub.InvokeChaincodeReturns(pbResp)
 
 	interopResponse, err := interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
@@ -271,8 +333,9 @@ func testHandleExternalRequestECDSAHappyCase(t *testing.T, query *common.Query,
 	queryBytes, err = protoV2.Marshal(query)
 	require.NoError(t, err)
 	b64QueryBytes = base64.StdEncoding.EncodeToString(queryBytes)
-	chaincodeStub.GetStateReturnsOnCall(3, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(4, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(5, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(7, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(8, []byte(accessModeRead), nil)
 	chaincodeStub.InvokeChaincodeReturns(pbResp)
 	interopResponse, err = interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
 	err = protoV2.Unmarshal([]byte(interopResponse), &interopPayloadResp)
@@ -313,12 +376,19 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	queryBytes, err := protoV2.Marshal(query)
 	require.NoError(t, err)
 	b64QueryBytes := base64.StdEncoding.EncodeToString(queryBytes)
+	// Every event published for the subscription gets a nonce of its own, derived from the publishing transaction
+	txTimestamp := timestamppb.Now()
+	chaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	chaincodeStub.GetTxIDReturnsOnCall(0, "event1")
+	chaincodeStub.GetTxIDReturnsOnCall(1, "event2")
+	chaincodeStub.GetTxIDReturnsOnCall(2, "event3")
+	eventNonces := []string{}
 	interopPayload := common.InteropPayload{
 		Payload:              []byte("17.12"),
 		Address:              "localhost:9080/network1/mychannel:interop:Read:a",
 		Confidential:         false,
 		RequestorCertificate: query.Certificate,
-		Nonce:                query.Nonce,
+		Nonce:                fmt.Sprintf("%d:event1", txTimestamp.Seconds),
 	}
 	interopPayloadBytes, err := protoV2.Marshal(&interopPayload)
 	require.NoError(t, err)
@@ -330,6 +400,7 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
 	chaincodeStub.GetStateReturnsOnCall(1, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, []byte(accessModeRead), nil)
 	chaincodeStub.InvokeChaincodeReturns(pbResp)
 
 	interopResponse, err := interopcc.HandleEventRequest(ctx, string(b64QueryBytes), "a")
@@ -340,6 +411,7 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	require.False(t, interopPayloadResp.Confidential)
 	require.Equal(t, interopPayloadBytes, []byte(interopResponse))
 	require.NoError(t, err)
+	eventNonces = append(eventNonces, interopPayloadResp.Nonce)
 
 	// This tests the case of one dynamic argument in the event query address (and signature is on query with one dynamic arg)
 	query.Address = "localhost:9080/network1/mychannel:interop:Read:?"
@@ -356,8 +428,9 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	b64QueryBytes = base64.StdEncoding.EncodeToString(queryBytes)
 
 	// mock all the calls to the chaincode stub
-	chaincodeStub.GetStateReturnsOnCall(3, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(4, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(4, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(5, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(6, []byte(accessModeRead), nil)
 	chaincodeStub.InvokeChaincodeReturns(pbResp)
 
 	interopResponse, err = interopcc.HandleEventRequest(ctx, string(b64QueryBytes), "a")
@@ -365,16 +438,21 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	err = protoV2.Unmarshal([]byte(interopResponse), &interopPayloadResp)
 	require.NoError(t, err)
 	require.False(t, interopPayloadResp.Confidential)
+	interopPayload.Nonce = fmt.Sprintf("%d:event2", txTimestamp.Seconds)
+	interopPayloadBytes, err = protoV2.Marshal(&interopPayload)
+	require.NoError(t, err)
 	require.Equal(t, interopPayloadBytes, []byte(interopResponse))
 	require.NoError(t, err)
+	eventNonces = append(eventNonces, interopPayloadResp.Nonce)
 
 	// test the same request-response with encryption on
 	query.Confidential = true
 	queryBytes, err = protoV2.Marshal(query)
 	require.NoError(t, err)
 	b64QueryBytes = base64.StdEncoding.EncodeToString(queryBytes)
-	chaincodeStub.GetStateReturnsOnCall(6, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(7, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(8, membershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(9, accessControlBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(10, []byte(accessModeRead), nil)
 	chaincodeStub.InvokeChaincodeReturns(pbResp)
 	interopResponse, err = interopcc.HandleEventRequest(ctx, string(b64QueryBytes), "a")
 	require.NoError(t, err)
@@ -383,7 +461,8 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	require.NotEqual(t, interopPayload.Payload, interopPayloadResp.Payload)
 	require.True(t, interopPayloadResp.Confidential)
 	require.Equal(t, interopPayloadResp.RequestorCertificate, validCertificate)
-	require.Equal(t, interopPayloadResp.Nonce, query.Nonce)
+	require.Equal(t, interopPayloadResp.Nonce, fmt.Sprintf("%d:event3", txTimestamp.Seconds))
+	eventNonces = append(eventNonces, interopPayloadResp.Nonce)
 	var confPayload common.ConfidentialPayload
 	err = protoV2.Unmarshal(interopPayloadResp.Payload, &confPayload)
 	require.NoError(t, err)
@@ -398,6 +477,29 @@ func testHandleEventRequestECDSAHappyCase(t *testing.T, query *common.Query, val
 	mac.Write(confPayloadContents.Payload)
 	fmac := mac.Sum(nil)
 	require.Equal(t, confPayload.Hash, fmac)
+
+	// The views of all the events of the subscription can be consumed by the destination network, even with a
+	// freshness window set, while each of them can only be consumed once
+	destCtx, destChaincodeStub := wtest.PrepMockStub()
+	destChaincodeStub.GetTxTimestampReturns(txTimestamp, nil)
+	destChaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + strings.Join(attributes, ""), nil
+	})
+	recorded := map[string][]byte{nonceFreshnessWindowKey: []byte("60")}
+	destChaincodeStub.GetStateCalls(func(key string) ([]byte, error) {
+		return recorded[key], nil
+	})
+	destChaincodeStub.PutStateCalls(func(key string, value []byte) error {
+		recorded[key] = value
+		return nil
+	})
+	driverCert := &x509.Certificate{Raw: []byte("driver cert")}
+	for _, eventNonce := range eventNonces {
+		err = checkAndRecordViewNonces(&interopcc, destCtx, []string{"network1"}, driverCert, []string{eventNonce})
+		require.NoError(t, err)
+	}
+	err = checkAndRecordViewNonces(&interopcc, destCtx, []string{"network1"}, driverCert, []string{eventNonces[1]})
+	require.EqualError(t, err, "Query nonce has already been used: "+eventNonces[1])
 }
 
 func testHandleExternalRequestED25519Signature(t *testing.T, query *common.Query, pbResp pb.Response, accessControl *common.AccessControlPolicy, fabricMembership *common.Membership, template x509.Certificate) {
@@ -479,7 +581,8 @@ func testHandleExternalRequestNoAccessControlPolicy(t *testing.T, query *common.
 	membershipBytes, err := json.Marshal(membership)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, membershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(1, nil, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, nil, nil)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.Now(), nil)
 
 	_, err = interopcc.HandleExternalRequest(ctx, string(b64QueryBytes))
 	require.EqualError(t, err, fmt.Sprintf("CC Access Denied: Access control policy does not exist for network: %s", query.RequestingNetwork))
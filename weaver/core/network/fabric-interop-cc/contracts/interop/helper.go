@@ -10,6 +10,11 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/viewaddress"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 // Address contains the information that was sent in the address field of a query from an external network
@@ -17,6 +22,9 @@ type Address struct {
 	ViewSegment     string
 	LocationSegment []string
 	LedgerSegment   string
+	// Version of the encoding of the view segment: viewaddress.VersionPlain, or viewaddress.VersionEscaped for view
+	// segments starting with the viewaddress.EscapedMarker, whose sections are escaped
+	Version int
 }
 
 // FabricViewAddress contains the data relevant to the view sent in the address string by the remote client.
@@ -27,6 +35,13 @@ type FabricViewAddress struct {
 	Args     []string
 }
 
+// BesuViewAddress contains the data relevant to a view from a Besu network sent in the address string by the remote client.
+type BesuViewAddress struct {
+	Contract string
+	Function string
+	Args     []string
+}
+
 // strArrToBytesArr converts an array of strings into an array of []byte
 func strArrToBytesArr(strArray []string) [][]byte {
 	output := make([][]byte, len(strArray))
@@ -43,22 +58,28 @@ func parseAddress(address string) (*Address, error) {
 	if len(addressList) != 3 {
 		return nil, fmt.Errorf("Invalid Address. Address should have three segments. %s", address)
 	}
+	version, err := viewaddress.GetVersion(addressList[2])
+	if err != nil {
+		return nil, err
+	}
 
 	return &Address{
 		LocationSegment: strings.Split(addressList[0], ";"),
 		LedgerSegment:   addressList[1],
 		ViewSegment:     addressList[2],
+		Version:         version,
 	}, nil
 
 }
 
 // parseFabricViewAddress receives the view segment of an address and constructs a FabricViewAddress from it
-// It splits on ':' to get sections in the viewAddress. Channel, Contract, CCFunc, the rest are arguments for the chaincode
+// It splits on ':' to get sections in the viewAddress. Channel, Contract, CCFunc, the rest are arguments for the chaincode.
+// The sections of an escaped view segment are unescaped.
 func parseFabricViewAddress(viewAddress string) (*FabricViewAddress, error) {
-	if strings.Contains(viewAddress, "/") {
-		return nil, fmt.Errorf("View segment contains a '/' %s", viewAddress)
+	fabricArgs, err := viewaddress.Split(viewAddress)
+	if err != nil {
+		return nil, err
 	}
-	fabricArgs := strings.Split(viewAddress, ":")
 	if len(fabricArgs) < 3 {
 		return nil, fmt.Errorf("View segment not formatted correctly %s", viewAddress)
 	}
@@ -66,6 +87,20 @@ func parseFabricViewAddress(viewAddress string) (*FabricViewAddress, error) {
 	return &FabricViewAddress{Channel: fabricArgs[0], Contract: fabricArgs[1], CCFunc: fabricArgs[2], Args: fabricArgs[3:]}, nil
 }
 
+// parseBesuViewAddress receives the view segment of an address and constructs a BesuViewAddress from it
+// It splits on ':' to get sections in the viewAddress. Contract address, function signature, the rest are arguments for the function
+func parseBesuViewAddress(viewAddress string) (*BesuViewAddress, error) {
+	besuArgs, err := viewaddress.Split(viewAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(besuArgs) < 2 {
+		return nil, fmt.Errorf("View segment not formatted correctly %s", viewAddress)
+	}
+
+	return &BesuViewAddress{Contract: besuArgs[0], Function: besuArgs[1], Args: besuArgs[2:]}, nil
+}
+
 // Contains tells whether a contains x.
 func Contains(a []string, x string) bool {
 	for _, n := range a {
@@ -76,37 +111,12 @@ func Contains(a []string, x string) bool {
 	return false
 }
 
-func validPatternString(pattern string) bool {
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// check if 0 or 1 stars
-	if numStars <= 1 {
-		// if 0 stars, return true, if 1 star, make sure its at the end
-		return strings.HasSuffix(pattern, "*") || numStars == 0
+// getTxTime returns the timestamp of the current transaction. This is used instead of the local time of the
+// peer for all time-dependent checks so that every endorsing peer arrives at the same result.
+func getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTime, err := wutils.GetTxTime(ctx.GetStub())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unable to get transaction timestamp: %s", err)
 	}
-
-	return false
-}
-
-func isPatternAndAddressMatch(pattern string, address string) bool {
-	// make sure the pattern is valid
-	if !validPatternString(pattern) {
-		return false
-	}
-
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// if 0 stars, and exact match, return true
-	if numStars == 0 && pattern == address {
-		return true
-	}
-
-	// if 1 star and pattern is a substring of address, return true
-	if numStars == 1 && strings.Contains(address, strings.TrimRight(pattern, "*")) {
-		return true
-	}
-
-	return false
+	return txTime, nil
 }
@@ -9,8 +9,13 @@ package main
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
+	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/viewaddress"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestParseFabricViewAddress(t *testing.T) {
@@ -33,6 +38,49 @@ func TestParseFabricViewAddress(t *testing.T) {
 	withSlash := "mychannel/mychannel:interop:Read:a"
 	result, err = parseFabricViewAddress(withSlash)
 	require.EqualError(t, err, fmt.Sprintf("View segment contains a '/' %s", withSlash))
+
+	// Success case with escaped arguments containing ':', '/' and '%'
+	escapedAddressString := "@v2:mychannel:interop:Read:%7B%22id%22%3A%22a%2Fb%22%7D:100%25:"
+	result, err = parseFabricViewAddress(escapedAddressString)
+	require.NoError(t, err)
+	require.Equal(t, &FabricViewAddress{Channel: "mychannel", Contract: "interop", CCFunc: "Read", Args: []string{`{"id":"a/b"}`, "100%", ""}}, result)
+	// Error cases with escaped arguments
+	badEscapeString := "@v2:mychannel:interop:Read:100%"
+	_, err = parseFabricViewAddress(badEscapeString)
+	require.EqualError(t, err, fmt.Sprintf("View segment section 3 is not escaped correctly %s: invalid URL escape \"%%\"", badEscapeString))
+	unknownVersionString := "@v3:mychannel:interop:Read:a"
+	_, err = parseFabricViewAddress(unknownVersionString)
+	require.EqualError(t, err, "Unsupported address encoding version: @v3")
+	// Escaped arguments are unescaped to their original value
+	arg := "https://example.com/a?b=100%\n"
+	result, err = parseFabricViewAddress("@v2:mychannel:interop:Read:" + viewaddress.EscapeSection(arg))
+	require.NoError(t, err)
+	require.Equal(t, []string{arg}, result.Args)
+}
+
+func TestParseBesuViewAddress(t *testing.T) {
+	// Success case
+	validAddressString := "0x5FbDB2315678afecb367f032d93F642f64180aa3:get(string):a"
+	result, err := parseBesuViewAddress(validAddressString)
+	validViewAddressStruct := BesuViewAddress{
+		Contract: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		Function: "get(string)",
+		Args:     []string{"a"},
+	}
+	require.NoError(t, err)
+	require.Equal(t, &validViewAddressStruct, result)
+	// Error cases
+	invalidAddressString := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	result, err = parseBesuViewAddress(invalidAddressString)
+	require.EqualError(t, err, fmt.Sprintf("View segment not formatted correctly %s", invalidAddressString))
+	// Error case View segment has a '/' character
+	withSlash := "0x5FbDB2315678afecb367f032d93F642f64180aa3/get(string):a"
+	result, err = parseBesuViewAddress(withSlash)
+	require.EqualError(t, err, fmt.Sprintf("View segment contains a '/' %s", withSlash))
+	// Success case with escaped arguments
+	result, err = parseBesuViewAddress("@v2:0x5FbDB2315678afecb367f032d93F642f64180aa3:get(string):a%3Ab")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a:b"}, result.Args)
 }
 
 func TestParseAdress(t *testing.T) {
@@ -42,10 +90,16 @@ func TestParseAdress(t *testing.T) {
 		LocationSegment: []string{"localhost:3000"},
 		LedgerSegment:   "network1",
 		ViewSegment:     "mychannel:interop:Read:a",
+		Version:         viewaddress.VersionPlain,
 	}
 	result, err := parseAddress(validAddressString)
 	require.NoError(t, err)
 	require.Equal(t, &validAddressStruct, result)
+	// Success case with an escaped view segment, whose arguments may contain '/'
+	result, err = parseAddress("localhost:3000/network1/@v2:mychannel:interop:Read:a%2Fb")
+	require.NoError(t, err)
+	require.Equal(t, "@v2:mychannel:interop:Read:a%2Fb", result.ViewSegment)
+	require.Equal(t, viewaddress.VersionEscaped, result.Version)
 	// Error cases
 	emptyAddressString := ""
 	_, err = parseAddress(emptyAddressString)
@@ -53,50 +107,36 @@ func TestParseAdress(t *testing.T) {
 	closeAddressString := "localhost:9080/network1/mychannel/interop/asdf"
 	_, err = parseAddress(closeAddressString)
 	require.EqualError(t, err, fmt.Sprintf("Invalid Address. Address should have three segments. %s", closeAddressString))
+	_, err = parseAddress("localhost:9080/network1/@v9:mychannel:interop:Read:a")
+	require.EqualError(t, err, "Unsupported address encoding version: @v9")
 }
 
-func TestValidPatternString(t *testing.T) {
-	//Happy no star
-	validPattern := "valid:no:star"
-	result := validPatternString(validPattern)
-	require.True(t, result)
-	//Happy star
-	validStarPattern := "valid:star:*"
-	result = validPatternString(validStarPattern)
-	require.True(t, result)
-	//Unhappy too many stars
-	tooManyStars := "One*:*too:many"
-	result = validPatternString(tooManyStars)
-	require.False(t, result)
-	//Unhappy invalid star location
-	invalidStarLocation := "test:*:star"
-	result = validPatternString(invalidStarLocation)
-	require.False(t, result)
-	//Just star
-	result = validPatternString("*")
-	require.True(t, result)
-}
-
-func TestIsPatternAndAddressMatch(t *testing.T) {
-	// Happy case valid star pattern  match
-	validPattern := "test:*"
-	matchingString := "test:star"
-	result := isPatternAndAddressMatch(validPattern, matchingString)
-	require.True(t, result)
+func TestGetTxTime(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	txTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(txTime), nil)
 
-	// Happy case valid pattern exact match
-	validNoStarPattern := "test:exact"
-	exactMatchString := "test:exact"
-	result = isPatternAndAddressMatch(validNoStarPattern, exactMatchString)
-	require.True(t, result)
+	// The transaction timestamp is used regardless of the local time when no skew tolerance is set
+	result, err := getTxTime(ctx)
+	require.NoError(t, err)
+	require.True(t, txTime.Equal(result))
 
-	// Unhappy case valid pattern doesnt match value
-	validPatternNoMatch := "notMatch:*"
-	result = isPatternAndAddressMatch(validPatternNoMatch, exactMatchString)
-	require.False(t, result)
+	// Transaction timestamp outside the skew tolerance
+	wutils.SetTxTimeSkewTolerance(time.Minute)
+	defer wutils.SetTxTimeSkewTolerance(0)
+	_, err = getTxTime(ctx)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "by more than the tolerated skew of 1m0s")
 
-	// Just star any match
-	result = isPatternAndAddressMatch("*", exactMatchString)
-	require.True(t, result)
+	// Transaction timestamp within the skew tolerance
+	txTime = time.Now().Add(-30 * time.Second)
+	chaincodeStub.GetTxTimestampReturns(timestamppb.New(txTime), nil)
+	result, err = getTxTime(ctx)
+	require.NoError(t, err)
+	require.True(t, txTime.Equal(result))
 
+	// Missing transaction timestamp
+	chaincodeStub.GetTxTimestampReturns(nil, nil)
+	_, err = getTxTime(ctx)
+	require.EqualError(t, err, "Unable to get transaction timestamp: timestamp is missing")
 }
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -31,6 +33,16 @@ func init() {
 		log.SetLevel(log.DebugLevel)
 	}
 	log.SetOutput(os.Stdout)
+
+	// Optionally reject transactions whose timestamp is too far from the local time of the peer
+	if toleranceSecs, ok := os.LookupEnv("FABRIC_INTEROP_CC_TX_TIME_SKEW_TOLERANCE_SECS"); ok {
+		secs, err := strconv.ParseUint(toleranceSecs, 10, 32)
+		if err != nil {
+			log.Errorf("Invalid transaction time skew tolerance %s: %s", toleranceSecs, err)
+		} else {
+			wutils.SetTxTimeSkewTolerance(time.Duration(secs) * time.Second)
+		}
+	}
 }
 
 // InitLedger initilises ledger with data. Need the application chaincode id so the handleExtnernalRequest flow can
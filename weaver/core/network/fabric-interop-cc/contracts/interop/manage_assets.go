@@ -11,6 +11,9 @@ package main
 import (
 	"fmt"
 	"errors"
+	"encoding/base64"
+
+	"github.com/golang/protobuf/proto"
 
 	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/assetexchange/v2"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -274,6 +277,39 @@ func (s *SmartContract) ClaimFungibleAsset(ctx contractapi.TransactionContextInt
 	return nil
 }
 
+// ClaimFungibleAssetUnits cc is used to record claim of part of a locked fungible asset on the ledger, and returns the units remaining locked
+func (s *SmartContract) ClaimFungibleAssetUnits(ctx contractapi.TransactionContextInterface, contractId string, numUnits uint64, claimInfoBytesBase64 string) (uint64, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return 0, logThenErrorf("Illegal access: ClaimFungibleAssetUnits being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the asset claiming process
+	remainingUnits, err := assetexchange.ClaimFungibleAssetUnits(ctx, contractId, numUnits, claimInfoBytesBase64)
+	if err != nil {
+		return 0, err
+	}
+
+	// The lock stays associated with the calling chaincode until all its units are claimed or unlocked
+	if remainingUnits == 0 {
+		err = ctx.GetStub().DelState(generateContractIdMapCCKey(contractId))
+		if err != nil {
+			return 0, logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
+		}
+	}
+
+	return remainingUnits, nil
+}
+
 // UnlockFungibleAsset cc is used to record unlocking of a fungible asset on the ledger
 func (s *SmartContract) UnlockFungibleAsset(ctx contractapi.TransactionContextInterface, contractId string) error {
 	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
@@ -304,6 +340,247 @@ func (s *SmartContract) UnlockFungibleAsset(ctx contractapi.TransactionContextIn
 	return nil
 }
 
+// LockBasket cc is used to record locking of a basket of assets, under a single lock and contractId, on the ledger
+func (s *SmartContract) LockBasket(ctx contractapi.TransactionContextInterface, basketAgreementBytesBase64 string, lockInfoBytesBase64 string) (string, error) {
+	// First, verify that this call comes from another chaincode rather than directly from the client
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	interopChaincodeID, err := ctx.GetStub().GetState(wutils.GetInteropChaincodeIDKey())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if callerChaincodeID == string(interopChaincodeID) {
+		return "", logThenErrorf("Illegal access: LockBasket being called directly by client")
+	}
+
+	// Start the locking process now
+	contractId, err := assetexchange.LockBasket(ctx, callerChaincodeID, basketAgreementBytesBase64, lockInfoBytesBase64)
+	if err != nil {
+		return "", err
+	}
+
+	// Associate lock with chaincode ID of caller.
+	err = ctx.GetStub().PutState(generateContractIdMapCCKey(contractId), []byte(callerChaincodeID))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	return contractId, nil
+}
+
+// IsBasketLocked cc is used to query the ledger and find out if a basket of assets is locked or not
+func (s *SmartContract) IsBasketLocked(ctx contractapi.TransactionContextInterface, contractId string) (bool, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return false, logThenErrorf("Illegal access: IsBasketLocked being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the basket status checking process
+	return assetexchange.IsBasketLocked(ctx, contractId)
+}
+
+// ClaimBasket cc is used to record claim of all the assets in a basket on the ledger, and returns the basket agreement (serialized in base64 form)
+func (s *SmartContract) ClaimBasket(ctx contractapi.TransactionContextInterface, contractId string, claimInfoBytesBase64 string) (string, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return "", logThenErrorf("Illegal access: ClaimBasket being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the basket claiming process
+	basketAgreement, err := assetexchange.ClaimBasket(ctx, contractId, claimInfoBytesBase64)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().DelState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
+	}
+
+	return marshalAgreement(basketAgreement)
+}
+
+// UnlockBasket cc is used to record unlocking of all the assets in a basket on the ledger, and returns the basket agreement (serialized in base64 form)
+func (s *SmartContract) UnlockBasket(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return "", logThenErrorf("Illegal access: UnlockBasket being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the basket unlocking process
+	basketAgreement, err := assetexchange.UnlockBasket(ctx, contractId)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().DelState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
+	}
+
+	return marshalAgreement(basketAgreement)
+}
+
+// Serialize an asset agreement returned to the calling chaincode in base64 form
+func marshalAgreement(agreement proto.Message) (string, error) {
+	agreementBytes, err := proto.Marshal(agreement)
+	if err != nil {
+		return "", logThenErrorf("marshal error: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(agreementBytes), nil
+}
+
+func (s *SmartContract) GetBasketHTLCHash(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+	return assetexchange.GetBasketHTLCHash(ctx, contractId)
+}
+
+// LockHybridAsset cc is used to record locking of a number of units of a hybrid asset on the ledger
+func (s *SmartContract) LockHybridAsset(ctx contractapi.TransactionContextInterface, hybridAssetAgreementBytesBase64 string, lockInfoBytesBase64 string) (string, error) {
+	// First, verify that this call comes from another chaincode rather than directly from the client
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	interopChaincodeID, err := ctx.GetStub().GetState(wutils.GetInteropChaincodeIDKey())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if callerChaincodeID == string(interopChaincodeID) {
+		return "", logThenErrorf("Illegal access: LockHybridAsset being called directly by client")
+	}
+
+	// Start the locking process now
+	contractId, err := assetexchange.LockHybridAsset(ctx, callerChaincodeID, hybridAssetAgreementBytesBase64, lockInfoBytesBase64)
+	if err != nil {
+		return "", err
+	}
+
+	// Associate lock with chaincode ID of caller.
+	err = ctx.GetStub().PutState(generateContractIdMapCCKey(contractId), []byte(callerChaincodeID))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	return contractId, nil
+}
+
+// IsHybridAssetLocked cc is used to query the ledger and find out if a hybrid asset is locked or not
+func (s *SmartContract) IsHybridAssetLocked(ctx contractapi.TransactionContextInterface, contractId string) (bool, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return false, logThenErrorf("Illegal access: IsHybridAssetLocked being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the lock status checking process
+	return assetexchange.IsHybridAssetLocked(ctx, contractId)
+}
+
+// ClaimHybridAsset cc is used to record claim of a hybrid asset on the ledger, and returns the hybrid asset agreement (serialized in base64 form)
+func (s *SmartContract) ClaimHybridAsset(ctx contractapi.TransactionContextInterface, contractId string, claimInfoBytesBase64 string) (string, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return "", logThenErrorf("Illegal access: ClaimHybridAsset being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the claiming process
+	assetAgreement, err := assetexchange.ClaimHybridAsset(ctx, contractId, claimInfoBytesBase64)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().DelState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
+	}
+
+	return marshalAgreement(assetAgreement)
+}
+
+// UnlockHybridAsset cc is used to record unlocking of a hybrid asset on the ledger, and returns the hybrid asset agreement (serialized in base64 form)
+func (s *SmartContract) UnlockHybridAsset(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return "", logThenErrorf("Illegal access: UnlockHybridAsset being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	// Start the unlocking process
+	assetAgreement, err := assetexchange.UnlockHybridAsset(ctx, contractId)
+	if err != nil {
+		return "", err
+	}
+
+	err = ctx.GetStub().DelState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return "", logThenErrorf("failed to delete the calling chaincode Id associated with the contract Id %s: %+v", contractId, err.Error())
+	}
+
+	return marshalAgreement(assetAgreement)
+}
+
+func (s *SmartContract) GetHybridAssetHTLCHash(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+	return assetexchange.GetHybridAssetHTLCHash(ctx, contractId)
+}
+
+func (s *SmartContract) GetHybridAssetTimeToRelease(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+	return assetexchange.GetHybridAssetTimeToRelease(ctx, contractId)
+}
+
 func (s *SmartContract) GetHTLCHash(ctx contractapi.TransactionContextInterface, assetAgreementBytesBase64 string) (string, error) {
 	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
 	if err != nil {
@@ -329,3 +606,94 @@ func (s *SmartContract) GetHTLCHashPreImageByContractId(ctx contractapi.Transact
 }
 
 
+
+// GetTotalFungibleLockedAssets cc is used to query the total units of an asset type currently locked by the calling chaincode
+func (s *SmartContract) GetTotalFungibleLockedAssets(ctx contractapi.TransactionContextInterface, assetType string) (uint64, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	return assetexchange.GetTotalFungibleLockedAssets(ctx, callerChaincodeID, assetType)
+}
+
+// GetFungibleAssetRemainingUnits cc is used to query the units of the fungible asset locked with contractId that remain
+// locked after partial claims
+func (s *SmartContract) GetFungibleAssetRemainingUnits(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return 0, logThenErrorf("Illegal access: GetFungibleAssetRemainingUnits being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	return assetexchange.GetFungibleAssetRemainingUnits(ctx, contractId)
+}
+
+// MigrateFungibleLockedUnits cc is used by a network admin, after upgrading the chaincode, to record the units locked
+// under the fungible asset locks made by an earlier version, so that GetTotalFungibleLockedAssets accounts for them.
+// It returns the number of locks migrated.
+func (s *SmartContract) MigrateFungibleLockedUnits(ctx contractapi.TransactionContextInterface) (int, error) {
+	// Check if the caller has network admin privileges
+	if isAdmin, err := wutils.IsClientNetworkAdmin(ctx); err != nil {
+		return 0, fmt.Errorf("Admin client check error: %s", err)
+	} else if !isAdmin {
+		return 0, fmt.Errorf("Caller not a network admin; access denied")
+	}
+
+	return assetexchange.MigrateFungibleLockedUnits(ctx, func(contractId string) (string, error) {
+		lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+		if err != nil {
+			return "", err
+		}
+		if lockerChaincodeID == nil {
+			return "", fmt.Errorf("no chaincode is recorded for contractId %s", contractId)
+		}
+		return string(lockerChaincodeID), nil
+	})
+}
+
+// GetAssetTimeToRelease cc is used to query the time (in seconds since the epoch) at which the lock on an asset expires
+func (s *SmartContract) GetAssetTimeToRelease(ctx contractapi.TransactionContextInterface, assetType, assetId, recipient, locker string) (uint64, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	return assetexchange.GetAssetTimeToRelease(ctx, callerChaincodeID, assetType, assetId, recipient, locker)
+}
+
+// GetFungibleAssetTimeToRelease cc is used to query the time (in seconds since the epoch) at which the lock on numUnits
+// units of an asset type, locked by the calling chaincode by locker for recipient, expires
+func (s *SmartContract) GetFungibleAssetTimeToRelease(ctx contractapi.TransactionContextInterface, assetType string, numUnits uint64, recipient, locker string) (uint64, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	return assetexchange.GetFungibleAssetTimeToRelease(ctx, callerChaincodeID, assetType, numUnits, recipient, locker)
+}
+
+// GetFungibleAssetTimeToReleaseByContractId cc is used to query the time (in seconds since the epoch) at which the lock
+// on the fungible assets locked with contractId expires
+func (s *SmartContract) GetFungibleAssetTimeToReleaseByContractId(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+	callerChaincodeID, err := wutils.GetLocalChaincodeID(ctx.GetStub())
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+
+	// Verify that this call comes from the same chaincode the lock instruction came from
+	lockerChaincodeID, err := ctx.GetStub().GetState(generateContractIdMapCCKey(contractId))
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	if callerChaincodeID != string(lockerChaincodeID) {
+		return 0, logThenErrorf("Illegal access: GetFungibleAssetTimeToReleaseByContractId being called from chaincode Id %s; expected %s", callerChaincodeID, string(lockerChaincodeID))
+	}
+
+	return assetexchange.GetFungibleAssetTimeToReleaseByContractId(ctx, contractId)
+}
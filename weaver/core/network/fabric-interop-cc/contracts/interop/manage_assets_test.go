@@ -7,9 +7,18 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"time"
@@ -17,10 +26,13 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/assetexchange/v2"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
 	mspProtobuf "github.com/hyperledger/fabric-protos-go/msp"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -99,13 +111,11 @@ func TestLockAsset(t *testing.T) {
 	require.Error(t, err)
 	log.Info(fmt.Println("Test failed as expected with error:", err))
 
-	// no need to set chaincodeStub.GetStateReturns below since the error is hit before GetState() ledger access in LockAsset()
 	lockInfoHTLC = &common.AssetLockHTLC{
 		HashMechanism: common.HashMechanism_SHA256,
 		HashBase64: []byte(hashBase64),
-		// lock for next 5 mintues
-		ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs,
-		// TimeSpec of AssetLockHTLC_DURATION is not currently supported
+		// lock for 5 minutes from the time of locking
+		ExpiryTimeSecs: defaultTimeLockSecs,
 		TimeSpec: common.TimeSpec_DURATION,
 	}
 	lockInfoHTLCBytes, _ = proto.Marshal(lockInfoHTLC)
@@ -114,10 +124,52 @@ func TestLockAsset(t *testing.T) {
 		LockInfo:      lockInfoHTLCBytes,
 	}
 	lockInfoBytes, _ = proto.Marshal(lockInfo)
-	// Test failure with lock information not specified properly
+	chaincodeStub.GetStateReturnsOnCall(4, []byte("interopcc"), nil)
+	chaincodeStub.GetStateReturnsOnCall(5, nil, nil)
+	// Test success with the lock expiry specified as a duration, which is recorded as an absolute expiry time
 	_, err = interopcc.LockAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.NoError(t, err)
+	txTimestamp, _ := chaincodeStub.GetTxTimestamp()
+	_, assetLockValBytes = chaincodeStub.PutStateArgsForCall(3)
+	assetLockVal = assetexchange.AssetLockValue{}
+	require.NoError(t, json.Unmarshal(assetLockValBytes, &assetLockVal))
+	require.Equal(t, uint64(txTimestamp.GetSeconds()) + defaultTimeLockSecs, assetLockVal.ExpiryTimeSecs)
+	fmt.Println("Test success as expected since the lock duration is specified properly")
+}
+
+func TestGetAssetTimeToRelease(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+
+	assetType := "bond"
+	assetId := "A001"
+	recipient := "Bob"
+	locker := getTxCreatorECertBase64()
+	expiryTimeSecs := uint64(time.Now().Unix()) + defaultTimeLockSecs
+
+	// Test failure with no asset locked
+	chaincodeStub.GetStateReturnsOnCall(0, nil, nil)
+	_, err := interopcc.GetAssetTimeToRelease(ctx, assetType, assetId, recipient, locker)
+	require.EqualError(t, err, "no asset of type bond and ID A001 is locked")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	assetLockVal := assetexchange.AssetLockValue{Locker: locker, Recipient: recipient, ExpiryTimeSecs: expiryTimeSecs}
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+
+	// Test failure with the asset locked for another recipient
+	chaincodeStub.GetStateReturnsOnCall(1, assetLockValBytes, nil)
+	_, err = interopcc.GetAssetTimeToRelease(ctx, assetType, assetId, "Charlie", locker)
 	require.Error(t, err)
 	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with the asset locked by the locker for the recipient
+	chaincodeStub.GetStateReturnsOnCall(2, assetLockValBytes, nil)
+	timeToRelease, err := interopcc.GetAssetTimeToRelease(ctx, assetType, assetId, recipient, locker)
+	require.NoError(t, err)
+	require.Equal(t, expiryTimeSecs, timeToRelease)
+	fmt.Println("Test success as expected since the asset is locked")
 }
 
 func TestUnlockAsset(t *testing.T) {
@@ -506,6 +558,239 @@ func TestHashSHA512(t *testing.T) {
 	log.Info(fmt.Println("Test success as expected since the hash mechanism is specified properly."))
 }
 
+func TestHashMechanisms(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+
+	assetType := "bond"
+	assetId := "A001"
+	recipient := getTxCreatorECertBase64()
+	locker := "Alice"
+	preimage := "abcd"
+	preimageBase64 := base64.StdEncoding.EncodeToString([]byte(preimage))
+	currentTimeSecs := uint64(time.Now().Unix())
+	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
+
+	assetAgreement := &common.AssetExchangeAgreement{
+		AssetType: assetType,
+		Id:        assetId,
+		Recipient: recipient,
+		Locker:    locker,
+	}
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+	_, contractId, _ := assetexchange.GenerateAssetLockKeyAndContractId(ctx, localCCId, assetAgreement)
+
+	// Hashes of the empty string, to check that the correct variant of each hash function is used
+	emptyHashesHex := map[common.HashMechanism]string{
+		common.HashMechanism_SHA3_256:   "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a",
+		common.HashMechanism_KECCAK256:  "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		common.HashMechanism_BLAKE2B256: "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+	}
+	getStateCall := 0
+	for hashMechanism, emptyHashHex := range emptyHashesHex {
+		emptyHash, _ := hex.DecodeString(emptyHashHex)
+		emptyHashBase64, err := assetexchange.GenerateHashInBase64Form("", hashMechanism)
+		require.NoError(t, err)
+		require.Equal(t, base64.StdEncoding.EncodeToString(emptyHash), emptyHashBase64)
+
+		hashBase64, err := assetexchange.GenerateHashInBase64Form(preimage, hashMechanism)
+		require.NoError(t, err)
+		var hashLock interface{}
+		hashLock = assetexchange.HashLock{HashMechanism: hashMechanism, HashBase64: hashBase64}
+		assetLockVal := assetexchange.AssetLockValue{ContractId: contractId, Locker: locker, Recipient: recipient, LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+		assetLockValBytes, _ := json.Marshal(assetLockVal)
+		chaincodeStub.GetStateReturnsOnCall(getStateCall, assetLockValBytes, nil)
+		getStateCall++
+
+		// Test failure with the right preimage claimed without the hash mechanism of the lock (SHA256 by default)
+		claimInfoHTLC := &common.AssetClaimHTLC{
+			HashPreimageBase64: []byte(preimageBase64),
+		}
+		claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+		claimInfo := &common.AssetClaim{
+			LockMechanism: common.LockMechanism_HTLC,
+			ClaimInfo:     claimInfoHTLCBytes,
+		}
+		claimInfoBytes, _ := proto.Marshal(claimInfo)
+		err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+		require.EqualError(t, err, "claim asset associated with contractId " + contractId + " failed with error: hash mechanism used while locking is different from the one supplied: 0")
+		log.Info(fmt.Println("Test failed as expected with error:", err))
+		chaincodeStub.GetStateReturnsOnCall(getStateCall, assetLockValBytes, nil)
+		getStateCall++
+
+		// Test success with the preimage of a hash generated by the same mechanism
+		claimInfoHTLC = &common.AssetClaimHTLC{
+			HashMechanism: hashMechanism,
+			HashPreimageBase64: []byte(preimageBase64),
+		}
+		claimInfoHTLCBytes, _ = proto.Marshal(claimInfoHTLC)
+		claimInfo = &common.AssetClaim{
+			LockMechanism: common.LockMechanism_HTLC,
+			ClaimInfo:     claimInfoHTLCBytes,
+		}
+		claimInfoBytes, _ = proto.Marshal(claimInfo)
+		err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+		require.NoError(t, err)
+		log.Info(fmt.Println("Test success as expected since the preimage is claimed with hash mechanism", hashMechanism))
+	}
+
+	// Test failure with a hash mechanism that is not supported
+	unsupportedHashMechanism := common.HashMechanism(100)
+	_, err := assetexchange.GenerateHashInBase64Form(preimage, unsupportedHashMechanism)
+	require.EqualError(t, err, "hash mechanism 100 is not supported")
+	var hashLock interface{}
+	hashLock = assetexchange.HashLock{HashMechanism: unsupportedHashMechanism, HashBase64: preimageBase64}
+	assetLockVal := assetexchange.AssetLockValue{ContractId: contractId, Locker: locker, Recipient: recipient, LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(getStateCall, assetLockValBytes, nil)
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashMechanism: unsupportedHashMechanism,
+		HashPreimageBase64: []byte(preimageBase64),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_HTLC,
+		ClaimInfo:     claimInfoHTLCBytes,
+	}
+	claimInfoBytes, _ := proto.Marshal(claimInfo)
+	err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.EqualError(t, err, "claim asset associated with contractId " + contractId + " failed with error: hash mechanism 100 is not supported")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+	getStateCall++
+
+	// Test failure with a SHA256 lock claimed using a hash mechanism that is not supported
+	hashLock = assetexchange.HashLock{HashMechanism: common.HashMechanism_SHA256, HashBase64: assetexchange.GenerateSHA256HashInBase64Form(preimage)}
+	assetLockVal = assetexchange.AssetLockValue{ContractId: contractId, Locker: locker, Recipient: recipient, LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(getStateCall, assetLockValBytes, nil)
+	err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.EqualError(t, err, "claim asset associated with contractId " + contractId + " failed with error: hash mechanism 100 is not supported")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+}
+
+// function that returns the PEM-encoded public key of an arbiter
+func getArbiterPublicKeyPEM(publicKey interface{}) []byte {
+	publicKeyBytes, _ := x509.MarshalPKIXPublicKey(publicKey)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+}
+
+// function that returns the claim information carrying the given signature
+func getSignatureClaimInfoBase64(signature []byte) string {
+	claimInfoSignatureBytes, _ := proto.Marshal(&common.AssetClaimSignature{Signature: signature})
+	claimInfoBytes, _ := proto.Marshal(&common.AssetClaim{
+		LockMechanism: common.LockMechanism_SIGNATURE,
+		ClaimInfo:     claimInfoSignatureBytes,
+	})
+	return base64.StdEncoding.EncodeToString(claimInfoBytes)
+}
+
+func TestSignatureLock(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+
+	assetType := "cbdc"
+	numUnits := uint64(10)
+	recipient := getTxCreatorECertBase64()
+	currentTimeSecs := uint64(time.Now().Unix())
+	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
+	arbiterKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	lockInfoSignature := &common.AssetLockSignature{
+		ArbiterPublicKey: getArbiterPublicKeyPEM(arbiterKey.Public()),
+		ExpiryTimeSecs:   currentTimeSecs + defaultTimeLockSecs,
+		TimeSpec:         common.TimeSpec_EPOCH,
+	}
+	lockInfoSignatureBytes, _ := proto.Marshal(lockInfoSignature)
+	lockInfo := &common.AssetLock{
+		LockMechanism: common.LockMechanism_SIGNATURE,
+		LockInfo:      lockInfoSignatureBytes,
+	}
+	lockInfoBytes, _ := proto.Marshal(lockInfo)
+
+	assetAgreement := &common.FungibleAssetExchangeAgreement{
+		AssetType: assetType,
+		NumUnits:  numUnits,
+		Locker:    getTxCreatorECertBase64(),
+		Recipient: recipient,
+	}
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+
+	// Test success with the arbiter public key specified properly
+	chaincodeStub.GetStateReturnsOnCall(0, []byte("interopcc"), nil)
+	chaincodeStub.GetStateReturnsOnCall(1, nil, nil)
+	contractId, err := interopcc.LockFungibleAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.NoError(t, err)
+	_, putStateBytes := chaincodeStub.PutStateArgsForCall(0)
+	assetLockVal := assetexchange.FungibleAssetLockValue{}
+	require.NoError(t, json.Unmarshal(putStateBytes, &assetLockVal))
+	require.Equal(t, currentTimeSecs + defaultTimeLockSecs, assetLockVal.ExpiryTimeSecs)
+	fmt.Println("Test success as expected since the signature lock information is specified properly")
+
+	// Test failure with an arbiter public key that cannot be parsed
+	lockInfoSignature.ArbiterPublicKey = []byte("arbiter")
+	lockInfoSignatureBytes, _ = proto.Marshal(lockInfoSignature)
+	lockInfo.LockInfo = lockInfoSignatureBytes
+	lockInfoBytes, _ = proto.Marshal(lockInfo)
+	chaincodeStub.GetStateReturnsOnCall(2, []byte("interopcc"), nil)
+	_, err = interopcc.LockFungibleAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "arbiter public key is not PEM-encoded")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test failure with the claim signed by a key other than the arbiter's
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	digest := sha256.Sum256(assetexchange.GenerateClaimAuthorizationMessage(contractId, recipient))
+	wrongSignature, _ := ecdsa.SignASN1(rand.Reader, otherKey, digest[:])
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(3, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(4, assetLockValBytes, nil)
+	err = interopcc.ClaimFungibleAsset(ctx, contractId, getSignatureClaimInfoBase64(wrongSignature))
+	require.EqualError(t, err, "cannot claim asset associated with contractId "+contractId+" as the claim is not authorised by the arbiter")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test failure with the signature authorising the claim of another contract
+	otherDigest := sha256.Sum256(assetexchange.GenerateClaimAuthorizationMessage("other-contract", recipient))
+	otherContractSignature, _ := ecdsa.SignASN1(rand.Reader, arbiterKey, otherDigest[:])
+	chaincodeStub.GetStateReturnsOnCall(5, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(6, assetLockValBytes, nil)
+	err = interopcc.ClaimFungibleAsset(ctx, contractId, getSignatureClaimInfoBase64(otherContractSignature))
+	require.EqualError(t, err, "cannot claim asset associated with contractId "+contractId+" as the claim is not authorised by the arbiter")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with the claim signed by the arbiter
+	signature, _ := ecdsa.SignASN1(rand.Reader, arbiterKey, digest[:])
+	chaincodeStub.GetStateReturnsOnCall(7, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(8, assetLockValBytes, nil)
+	err = interopcc.ClaimFungibleAsset(ctx, contractId, getSignatureClaimInfoBase64(signature))
+	require.NoError(t, err)
+	fmt.Println("Test success as expected since the claim is signed by the arbiter")
+
+	// Test failure with a signature claim on an asset locked in an HTLC
+	hashLock := assetexchange.HashLock{HashMechanism: common.HashMechanism_SHA256, HashBase64: assetexchange.GenerateSHA256HashInBase64Form("abcd")}
+	htlcLockVal := assetexchange.FungibleAssetLockValue{Type: assetType, NumUnits: numUnits, Recipient: recipient,
+		LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+	htlcLockValBytes, _ := json.Marshal(htlcLockVal)
+	chaincodeStub.GetStateReturnsOnCall(9, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(10, htlcLockValBytes, nil)
+	err = interopcc.ClaimFungibleAsset(ctx, contractId, getSignatureClaimInfoBase64(signature))
+	require.EqualError(t, err, "claim asset associated with contractId "+contractId+" failed with error: asset is not locked with the signature lock mechanism")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with an Ed25519 arbiter key
+	edPublicKey, edPrivateKey, _ := ed25519.GenerateKey(rand.Reader)
+	assetLockVal.LockInfo = assetexchange.SignatureLock{ArbiterPublicKey: string(getArbiterPublicKeyPEM(edPublicKey))}
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(11, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(12, assetLockValBytes, nil)
+	edSignature := ed25519.Sign(edPrivateKey, assetexchange.GenerateClaimAuthorizationMessage(contractId, recipient))
+	err = interopcc.ClaimFungibleAsset(ctx, contractId, getSignatureClaimInfoBase64(edSignature))
+	require.NoError(t, err)
+	fmt.Println("Test success as expected since the claim is signed by the Ed25519 arbiter key")
+}
+
 func TestGetHTLCHash(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	localCCId := "mycc"
@@ -715,6 +1000,19 @@ func TestUnlockAssetUsingContractId(t *testing.T) {
 	err = interopcc.UnlockAssetUsingContractId(ctx, contractId)
 	require.NoError(t, err)
 	fmt.Printf("Test success as expected since a valid contractId is specified.\n")
+
+	// Test success with the expiry time elapsed according to the transaction timestamp but not the local time
+	assetLockVal = assetexchange.AssetLockValue{ContractId: contractId, Locker: locker, Recipient: recipient, LockInfo: lockInfoVal, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs}
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(26, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(27, assetLockKeyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(28, assetLockValBytes, nil)
+	chaincodeStub.DelStateReturnsOnCall(5, nil)
+	chaincodeStub.DelStateReturnsOnCall(6, nil)
+	chaincodeStub.GetTxTimestampReturns(&timestamppb.Timestamp{Seconds: int64(currentTimeSecs + 2*defaultTimeLockSecs)}, nil)
+	err = interopcc.UnlockAssetUsingContractId(ctx, contractId)
+	require.NoError(t, err)
+	fmt.Printf("Test success as expected since the transaction timestamp is past the expiry time.\n")
 }
 
 func TestClaimAssetUsingContractId(t *testing.T) {
@@ -995,13 +1293,11 @@ func TestLockFungibleAsset(t *testing.T) {
 	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
 	chaincodeStub.GetStateReturnsOnCall(0, []byte("interopcc"), nil)
 
-	// Test failure with TimeSpec that is part of lock information not being currently supported
+	// Test failure with a lock duration of zero
 	// no need to set chaincodeStub.GetStateReturns below since the error is hit before GetState() ledger access
 	lockInfoHTLC := &common.AssetLockHTLC{
 		HashBase64: []byte(hashBase64),
-		// lock for next 5 mintues
-		ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs,
-		// TimeSpec of AssetLockHTLC_DURATION is not currently supported
+		ExpiryTimeSecs: 0,
 		TimeSpec: common.TimeSpec_DURATION,
 	}
 	lockInfoHTLCBytes, _ := proto.Marshal(lockInfoHTLC)
@@ -1012,7 +1308,7 @@ func TestLockFungibleAsset(t *testing.T) {
 	lockInfoBytes, _ := proto.Marshal(lockInfo)
 	_, err := interopcc.LockFungibleAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
 	require.Error(t, err)
-	require.EqualError(t, err, "only EPOCH time is supported at present")
+	require.EqualError(t, err, "lock duration must be a positive number of seconds")
 	fmt.Printf("Test failed as expected with error: %s\n", err)
 
 	// Test failure with GetState(contractId) fail to read the world state
@@ -1203,6 +1499,187 @@ func TestIsFungibleAssetLockedQueryUsingContractId(t *testing.T) {
 	fmt.Printf("Test success as expected since a valid contractId is specified.\n")
 }
 
+func TestGetFungibleAssetTimeToRelease(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+	chaincodeStub.SplitCompositeKeyCalls(func(key string) (string, []string, error) {
+		parts := strings.Split(key, ":")
+		return parts[0], parts[1:], nil
+	})
+
+	assetAgreement := &common.FungibleAssetExchangeAgreement{
+		AssetType: "cbdc",
+		NumUnits:  10,
+		Locker:    "Alice",
+		Recipient: "Bob",
+	}
+	expiryTimeSecs := uint64(time.Now().Unix()) + defaultTimeLockSecs
+	assetLockVal := assetexchange.FungibleAssetLockValue{Type: assetAgreement.AssetType, NumUnits: assetAgreement.NumUnits,
+		Locker: assetAgreement.Locker, Recipient: assetAgreement.Recipient, ExpiryTimeSecs: expiryTimeSecs, ChaincodeId: localCCId}
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+	otherLockVal := assetLockVal
+	otherLockVal.NumUnits = 20
+	otherLockValBytes, _ := json.Marshal(otherLockVal)
+
+	// Test failure with no fungible asset locked for the agreement
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KV{Key: "FungibleLockedUnits:" + localCCId + ":cbdc:contract2", Value: []byte("20")}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyReturnsOnCall(0, iterator, nil)
+	chaincodeStub.GetStateReturnsOnCall(0, otherLockValBytes, nil)
+	_, err := interopcc.GetFungibleAssetTimeToRelease(ctx, assetAgreement.AssetType, assetAgreement.NumUnits, assetAgreement.Recipient, assetAgreement.Locker)
+	require.EqualError(t, err, "no 10 units of asset type cbdc are locked by Alice for Bob")
+	objectType, attributes := chaincodeStub.GetStateByPartialCompositeKeyArgsForCall(0)
+	require.Equal(t, "FungibleLockedUnits", objectType)
+	require.Equal(t, []string{localCCId, assetAgreement.AssetType}, attributes)
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with one of the locks of the asset type matching the agreement
+	iterator = &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KV{Key: "FungibleLockedUnits:" + localCCId + ":cbdc:contract2", Value: []byte("20")}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KV{Key: "FungibleLockedUnits:" + localCCId + ":cbdc:contract1", Value: []byte("10")}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyReturnsOnCall(1, iterator, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, otherLockValBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, assetLockValBytes, nil)
+	timeToRelease, err := interopcc.GetFungibleAssetTimeToRelease(ctx, assetAgreement.AssetType, assetAgreement.NumUnits, assetAgreement.Recipient, assetAgreement.Locker)
+	require.NoError(t, err)
+	require.Equal(t, expiryTimeSecs, timeToRelease)
+	require.Equal(t, 1, iterator.CloseCallCount())
+	fmt.Println("Test success as expected since the fungible asset is locked")
+
+	// Test failure with several locks matching the agreement
+	iterator = &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KV{Key: "FungibleLockedUnits:" + localCCId + ":cbdc:contract1", Value: []byte("10")}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KV{Key: "FungibleLockedUnits:" + localCCId + ":cbdc:contract3", Value: []byte("10")}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyReturnsOnCall(2, iterator, nil)
+	chaincodeStub.GetStateReturnsOnCall(3, assetLockValBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(4, assetLockValBytes, nil)
+	_, err = interopcc.GetFungibleAssetTimeToRelease(ctx, assetAgreement.AssetType, assetAgreement.NumUnits, assetAgreement.Recipient, assetAgreement.Locker)
+	require.EqualError(t, err, "2 locks of 10 units of asset type cbdc are held by Alice for Bob; query the lock by contractId instead")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+}
+
+func TestGetFungibleAssetTimeToReleaseByContractId(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+
+	assetAgreement := &common.FungibleAssetExchangeAgreement{
+		AssetType: "cbdc",
+		NumUnits:  10,
+		Locker:    "Alice",
+		Recipient: "Bob",
+	}
+	contractId := assetexchange.GenerateFungibleAssetLockContractId(ctx, localCCId, assetAgreement)
+	expiryTimeSecs := uint64(time.Now().Unix()) + defaultTimeLockSecs
+
+	// Test failure with the call coming from a chaincode other than the one that locked the asset
+	chaincodeStub.GetStateReturnsOnCall(0, []byte("othercc"), nil)
+	_, err := interopcc.GetFungibleAssetTimeToReleaseByContractId(ctx, contractId)
+	require.EqualError(t, err, "Illegal access: GetFungibleAssetTimeToReleaseByContractId being called from chaincode Id mycc; expected othercc")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test failure with no fungible asset locked using contractId
+	chaincodeStub.GetStateReturnsOnCall(1, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(2, nil, nil)
+	_, err = interopcc.GetFungibleAssetTimeToReleaseByContractId(ctx, contractId)
+	require.EqualError(t, err, "contractId "+contractId+" is not associated with any currently locked asset")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with the fungible asset locked using contractId
+	assetLockVal := assetexchange.FungibleAssetLockValue{Type: assetAgreement.AssetType, NumUnits: assetAgreement.NumUnits,
+		Locker: assetAgreement.Locker, Recipient: assetAgreement.Recipient, ExpiryTimeSecs: expiryTimeSecs}
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(3, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(4, assetLockValBytes, nil)
+	timeToRelease, err := interopcc.GetFungibleAssetTimeToReleaseByContractId(ctx, contractId)
+	require.NoError(t, err)
+	require.Equal(t, expiryTimeSecs, timeToRelease)
+	fmt.Println("Test success as expected since the fungible asset is locked")
+}
+
+func TestGetFungibleAssetRemainingUnits(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+	contractId := "contract1"
+
+	// Test failure with the call coming from a chaincode other than the one that locked the asset
+	chaincodeStub.GetStateReturnsOnCall(0, []byte("othercc"), nil)
+	_, err := interopcc.GetFungibleAssetRemainingUnits(ctx, contractId)
+	require.EqualError(t, err, "Illegal access: GetFungibleAssetRemainingUnits being called from chaincode Id mycc; expected othercc")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with the units that were not claimed yet
+	assetLockVal := assetexchange.FungibleAssetLockValue{Type: "cbdc", NumUnits: 10, ClaimedUnits: 4, Locker: "Alice", Recipient: "Bob",
+		ExpiryTimeSecs: uint64(time.Now().Unix()) + defaultTimeLockSecs, ChaincodeId: localCCId}
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(1, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(2, assetLockValBytes, nil)
+	remainingUnits, err := interopcc.GetFungibleAssetRemainingUnits(ctx, contractId)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), remainingUnits)
+	fmt.Println("Test success as expected since 6 units remain locked")
+}
+
+func TestMigrateFungibleLockedUnits(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	interopcc := SmartContract{}
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+
+	// Test failure when the caller is not an admin
+	_, err := interopcc.MigrateFungibleLockedUnits(ctx)
+	require.EqualError(t, err, "Caller not a network admin; access denied")
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetAttributeValueCalls(setClientAdmin)
+	ctx.GetClientIdentityReturns(clientIdentity)
+
+	// Test that only the fungible locks made before the locked units were recorded are migrated
+	migratedLockVal := assetexchange.FungibleAssetLockValue{Type: "cbdc", NumUnits: 5, ChaincodeId: localCCId}
+	migratedLockValBytes, _ := json.Marshal(migratedLockVal)
+	legacyLockVal := assetexchange.FungibleAssetLockValue{Type: "cbdc", NumUnits: 10, ClaimedUnits: 4}
+	legacyLockValBytes, _ := json.Marshal(legacyLockVal)
+	nonFungibleLockValBytes, _ := json.Marshal("AssetExchangeContract:mycc:bond01:a01")
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, true)
+	iterator.HasNextReturnsOnCall(3, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KV{Key: "ContractId_contract1", Value: nonFungibleLockValBytes}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KV{Key: "ContractId_contract2", Value: migratedLockValBytes}, nil)
+	iterator.NextReturnsOnCall(2, &queryresult.KV{Key: "ContractId_contract3", Value: legacyLockValBytes}, nil)
+	chaincodeStub.GetStateByRangeReturns(iterator, nil)
+	chaincodeStub.GetStateReturns([]byte(localCCId), nil)
+	migratedLocks, err := interopcc.MigrateFungibleLockedUnits(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, migratedLocks)
+	require.Equal(t, callerCCIdPrefix+"contract3", chaincodeStub.GetStateArgsForCall(0))
+	require.Equal(t, "FungibleLockedUnits::cbdc:contract3", chaincodeStub.DelStateArgsForCall(0))
+	key, assetLockValBytes := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "ContractId_contract3", key)
+	require.NoError(t, json.Unmarshal(assetLockValBytes, &legacyLockVal))
+	require.Equal(t, localCCId, legacyLockVal.ChaincodeId)
+	key, lockedUnitsBytes := chaincodeStub.PutStateArgsForCall(1)
+	require.Equal(t, "FungibleLockedUnits:mycc:cbdc:contract3", key)
+	require.Equal(t, "6", string(lockedUnitsBytes))
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+	require.Equal(t, 1, iterator.CloseCallCount())
+}
+
 func TestClaimFungibleAsset(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	localCCId := "mycc"
@@ -1461,6 +1938,395 @@ func TestClaimFungibleAssetUsingContractId(t *testing.T) {
 	fmt.Printf("Test failed as expected with error: %s\n", err)
 }
 
+func TestClaimFungibleAssetUnits(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	interopcc := SmartContract{}
+
+	assetType := "cbdc"
+	numUnits := uint64(10)
+	locker := getTxCreatorECertBase64()
+	recipient := getTxCreatorECertBase64()
+	preimage := "abcd"
+	hashBase64 := assetexchange.GenerateSHA256HashInBase64Form(preimage)
+	preimageBase64 := base64.StdEncoding.EncodeToString([]byte(preimage))
+	currentTimeSecs := uint64(time.Now().Unix())
+	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+
+	assetAgreement := &common.FungibleAssetExchangeAgreement{
+		AssetType: assetType,
+		NumUnits:  numUnits,
+		Locker:    locker,
+		Recipient: recipient,
+	}
+	contractId := assetexchange.GenerateFungibleAssetLockContractId(ctx, localCCId, assetAgreement)
+	lockedUnitsKey := "FungibleLockedUnits:" + localCCId + ":" + assetType + ":" + contractId
+
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashMechanism: common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte(preimageBase64),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_HTLC,
+		ClaimInfo:     claimInfoHTLCBytes,
+	}
+	claimInfoBytes, _ := proto.Marshal(claimInfo)
+
+	hashLock := assetexchange.HashLock{HashMechanism: common.HashMechanism_SHA256, HashBase64: hashBase64}
+	assetLockVal := assetexchange.FungibleAssetLockValue{Type: assetType, NumUnits: numUnits, Locker: locker, Recipient: recipient,
+		LockInfo: hashLock, ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs, ChaincodeId: localCCId}
+	assetLockValBytes, _ := json.Marshal(assetLockVal)
+
+	// Test failure with no units to claim
+	chaincodeStub.GetStateReturnsOnCall(0, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(1, assetLockValBytes, nil)
+	_, err := interopcc.ClaimFungibleAssetUnits(ctx, contractId, 0, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.EqualError(t, err, "number of units to claim must be a positive number")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with part of the locked units being claimed
+	chaincodeStub.GetStateReturnsOnCall(2, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(3, assetLockValBytes, nil)
+	remainingUnits, err := interopcc.ClaimFungibleAssetUnits(ctx, contractId, 4, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), remainingUnits)
+	require.Equal(t, 0, chaincodeStub.DelStateCallCount())
+	_, preimageBytes := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, preimageBase64, string(preimageBytes))
+	_, putStateBytes := chaincodeStub.PutStateArgsForCall(1)
+	require.NoError(t, json.Unmarshal(putStateBytes, &assetLockVal))
+	require.Equal(t, uint64(4), assetLockVal.ClaimedUnits)
+	require.Equal(t, uint64(6), assetLockVal.GetRemainingUnits())
+	key, lockedUnitsBytes := chaincodeStub.PutStateArgsForCall(2)
+	require.Equal(t, lockedUnitsKey, key)
+	require.Equal(t, "6", string(lockedUnitsBytes))
+	log.Info(fmt.Println("Test success as expected since the claimed units are locked."))
+
+	// Test failure with more units to claim than remain locked
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(4, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(5, assetLockValBytes, nil)
+	_, err = interopcc.ClaimFungibleAssetUnits(ctx, contractId, 7, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.EqualError(t, err, "cannot claim 7 units of the asset associated with contractId "+contractId+" as only 6 units remain locked")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test that the total locked units, summed over the locks of the asset type, reflect the partial claim
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KV{Key: lockedUnitsKey, Value: []byte("6")}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KV{Key: "other", Value: []byte("15")}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+	totalUnits, err := interopcc.GetTotalFungibleLockedAssets(ctx, assetType)
+	require.NoError(t, err)
+	require.Equal(t, uint64(21), totalUnits)
+	objectType, attributes := chaincodeStub.GetStateByPartialCompositeKeyArgsForCall(0)
+	require.Equal(t, "FungibleLockedUnits", objectType)
+	require.Equal(t, []string{localCCId, assetType}, attributes)
+	require.Equal(t, 1, iterator.CloseCallCount())
+
+	// Test success with the locker unlocking the remaining units after expiry
+	assetLockVal.ExpiryTimeSecs = currentTimeSecs - defaultTimeLockSecs
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(6, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(7, assetLockValBytes, nil)
+	err = interopcc.UnlockFungibleAsset(ctx, contractId)
+	require.NoError(t, err)
+	key = chaincodeStub.DelStateArgsForCall(1)
+	require.Equal(t, lockedUnitsKey, key)
+	log.Info(fmt.Println("Test success as expected since the remaining units are unlocked after expiry."))
+
+	// Test success with all the remaining units being claimed
+	assetLockVal.ExpiryTimeSecs = currentTimeSecs + defaultTimeLockSecs
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(8, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(9, assetLockValBytes, nil)
+	remainingUnits, err = interopcc.ClaimFungibleAssetUnits(ctx, contractId, 6, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), remainingUnits)
+	require.Equal(t, generateContractIdMapCCKey(contractId), chaincodeStub.DelStateArgsForCall(chaincodeStub.DelStateCallCount() - 1))
+	log.Info(fmt.Println("Test success as expected since all the remaining units are claimed."))
+}
+
+func TestBasketLock(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + ":" + strings.Join(attributes, ":"), nil
+	})
+	interopcc := SmartContract{}
+
+	// the transaction creator is both the locker and the recipient, to test claims and unlocks with the same identity
+	locker := getTxCreatorECertBase64()
+	recipient := getTxCreatorECertBase64()
+	preimage := "abcd"
+	hashBase64 := assetexchange.GenerateSHA256HashInBase64Form(preimage)
+	currentTimeSecs := uint64(time.Now().Unix())
+
+	lockInfoHTLC := &common.AssetLockHTLC{
+		HashMechanism:  common.HashMechanism_SHA256,
+		HashBase64:     []byte(hashBase64),
+		ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs,
+		TimeSpec:       common.TimeSpec_EPOCH,
+	}
+	lockInfoHTLCBytes, _ := proto.Marshal(lockInfoHTLC)
+	lockInfo := &common.AssetLock{
+		LockMechanism: common.LockMechanism_HTLC,
+		LockInfo:      lockInfoHTLCBytes,
+	}
+	lockInfoBytes, _ := proto.Marshal(lockInfo)
+
+	// a bond traded for tokens plus a fee
+	basketAgreement := &common.BasketAssetExchangeAgreement{
+		Assets: []*common.AssetExchangeAgreement{
+			{AssetType: "bond", Id: "A001"},
+		},
+		FungibleAssets: []*common.FungibleAssetExchangeAgreement{
+			{AssetType: "cbdc", NumUnits: 100},
+			{AssetType: "fee", NumUnits: 5},
+		},
+		Recipient: recipient,
+	}
+	basketAgreementBytes, _ := proto.Marshal(basketAgreement)
+
+	// Test success with all the assets locked under a single contractId
+	chaincodeStub.GetStateReturnsOnCall(0, []byte("interopcc"), nil)
+	contractId, err := interopcc.LockBasket(ctx, base64.StdEncoding.EncodeToString(basketAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.NoError(t, err)
+	require.Equal(t, 5, chaincodeStub.PutStateCallCount())
+	key, assetLockValBytes := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "AssetExchangeContract:"+localCCId+":bond:A001", key)
+	assetLockVal := assetexchange.AssetLockValue{}
+	require.NoError(t, json.Unmarshal(assetLockValBytes, &assetLockVal))
+	require.Equal(t, contractId, assetLockVal.ContractId)
+	require.True(t, assetLockVal.BasketLock)
+	key, lockedUnitsBytes := chaincodeStub.PutStateArgsForCall(1)
+	require.Equal(t, "FungibleLockedUnits:"+localCCId+":cbdc:"+contractId, key)
+	require.Equal(t, "100", string(lockedUnitsBytes))
+	key, lockedUnitsBytes = chaincodeStub.PutStateArgsForCall(2)
+	require.Equal(t, "FungibleLockedUnits:"+localCCId+":fee:"+contractId, key)
+	require.Equal(t, "5", string(lockedUnitsBytes))
+	key, basketLockValBytes := chaincodeStub.PutStateArgsForCall(3)
+	require.Equal(t, "BasketContractId_"+contractId, key)
+	basketLockVal := assetexchange.BasketAssetLockValue{}
+	require.NoError(t, json.Unmarshal(basketLockValBytes, &basketLockVal))
+	require.Equal(t, []assetexchange.BasketAsset{{Type: "bond", Id: "A001"}}, basketLockVal.Assets)
+	require.Len(t, basketLockVal.FungibleAssets, 2)
+	require.Equal(t, locker, basketLockVal.Locker)
+	key, ccIdBytes := chaincodeStub.PutStateArgsForCall(4)
+	require.Equal(t, generateContractIdMapCCKey(contractId), key)
+	require.Equal(t, localCCId, string(ccIdBytes))
+	log.Info(fmt.Println("Test success as expected since the basket is locked."))
+
+	// Test failure with an asset appearing more than once in the basket
+	basketAgreement.Assets = append(basketAgreement.Assets, &common.AssetExchangeAgreement{AssetType: "bond", Id: "A001"})
+	basketAgreementDupBytes, _ := proto.Marshal(basketAgreement)
+	basketAgreement.Assets = basketAgreement.Assets[:1]
+	chaincodeStub.GetStateReturnsOnCall(3, []byte("interopcc"), nil)
+	_, err = interopcc.LockBasket(ctx, base64.StdEncoding.EncodeToString(basketAgreementDupBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "asset of type bond and ID A001 appears more than once in the basket")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test failure with an asset in the basket already locked
+	chaincodeStub.GetStateReturnsOnCall(6, []byte("interopcc"), nil)
+	chaincodeStub.GetStateReturnsOnCall(8, assetLockValBytes, nil)
+	_, err = interopcc.LockBasket(ctx, base64.StdEncoding.EncodeToString(basketAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "asset of type bond and ID A001 is already locked")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test failure with an asset in the basket being claimed on its own
+	assetAgreementBytes, _ := proto.Marshal(&common.AssetExchangeAgreement{AssetType: "bond", Id: "A001", Locker: locker})
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashMechanism:      common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte(base64.StdEncoding.EncodeToString([]byte(preimage))),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfoBytes, _ := proto.Marshal(&common.AssetClaim{LockMechanism: common.LockMechanism_HTLC, ClaimInfo: claimInfoHTLCBytes})
+	chaincodeStub.GetStateReturnsOnCall(9, assetLockValBytes, nil)
+	err = interopcc.ClaimAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.EqualError(t, err, "cannot claim asset of type bond and ID A001 on its own as it is locked in the basket with contractId "+contractId)
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test that the basket is reported as locked
+	chaincodeStub.GetStateReturnsOnCall(10, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(11, basketLockValBytes, nil)
+	isLocked, err := interopcc.IsBasketLocked(ctx, contractId)
+	require.NoError(t, err)
+	require.True(t, isLocked)
+
+	// Test failure with the locker trying to unlock the basket before expiry
+	chaincodeStub.GetStateReturnsOnCall(12, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(13, basketLockValBytes, nil)
+	_, err = interopcc.UnlockBasket(ctx, contractId)
+	require.EqualError(t, err, "cannot unlock asset associated with the contractId "+contractId+" as the expiry time is not yet elapsed")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test failure with a wrong preimage
+	wrongClaimInfoHTLCBytes, _ := proto.Marshal(&common.AssetClaimHTLC{
+		HashMechanism:      common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte(base64.StdEncoding.EncodeToString([]byte("wxyz"))),
+	})
+	wrongClaimInfoBytes, _ := proto.Marshal(&common.AssetClaim{LockMechanism: common.LockMechanism_HTLC, ClaimInfo: wrongClaimInfoHTLCBytes})
+	chaincodeStub.GetStateReturnsOnCall(14, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(15, basketLockValBytes, nil)
+	_, err = interopcc.ClaimBasket(ctx, contractId, base64.StdEncoding.EncodeToString(wrongClaimInfoBytes))
+	require.EqualError(t, err, "cannot claim asset associated with contractId "+contractId+" as the hash preimage is not matching")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with all the assets in the basket claimed together
+	chaincodeStub.GetStateReturnsOnCall(16, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(17, basketLockValBytes, nil)
+	claimedAgreementBase64, err := interopcc.ClaimBasket(ctx, contractId, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	claimedAgreementBytes, _ := base64.StdEncoding.DecodeString(claimedAgreementBase64)
+	claimedAgreement := &common.BasketAssetExchangeAgreement{}
+	require.NoError(t, proto.Unmarshal(claimedAgreementBytes, claimedAgreement))
+	require.Equal(t, "A001", claimedAgreement.Assets[0].Id)
+	require.Equal(t, uint64(100), claimedAgreement.FungibleAssets[0].NumUnits)
+	require.Equal(t, recipient, claimedAgreement.Recipient)
+	deletedKeys := []string{}
+	for i := 0; i < chaincodeStub.DelStateCallCount(); i++ {
+		deletedKeys = append(deletedKeys, chaincodeStub.DelStateArgsForCall(i))
+	}
+	require.Equal(t, []string{"AssetExchangeContract:" + localCCId + ":bond:A001", "FungibleLockedUnits:" + localCCId + ":cbdc:" + contractId,
+		"FungibleLockedUnits:" + localCCId + ":fee:" + contractId, "BasketContractId_" + contractId, generateContractIdMapCCKey(contractId)}, deletedKeys)
+	log.Info(fmt.Println("Test success as expected since the basket is claimed."))
+
+	// Test success with the locker unlocking the basket after expiry
+	basketLockVal.ExpiryTimeSecs = currentTimeSecs - defaultTimeLockSecs
+	basketLockValBytes, _ = json.Marshal(basketLockVal)
+	chaincodeStub.GetStateReturnsOnCall(18, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(19, basketLockValBytes, nil)
+	_, err = interopcc.UnlockBasket(ctx, contractId)
+	require.NoError(t, err)
+	require.Equal(t, 10, chaincodeStub.DelStateCallCount())
+	log.Info(fmt.Println("Test success as expected since the basket is unlocked after expiry."))
+}
+
+func TestHybridAssetLock(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	localCCId := "mycc"
+	wtest.SetMockStubCCId(chaincodeStub, localCCId)
+	chaincodeStub.GetCreatorReturns([]byte(getCreator()), nil)
+	interopcc := SmartContract{}
+
+	// the transaction creator is both the locker and the recipient, to test claims and unlocks with the same identity
+	locker := getTxCreatorECertBase64()
+	recipient := getTxCreatorECertBase64()
+	preimage := "abcd"
+	hashBase64 := assetexchange.GenerateSHA256HashInBase64Form(preimage)
+	currentTimeSecs := uint64(time.Now().Unix())
+
+	lockInfoHTLC := &common.AssetLockHTLC{
+		HashMechanism:  common.HashMechanism_SHA256,
+		HashBase64:     []byte(hashBase64),
+		ExpiryTimeSecs: currentTimeSecs + defaultTimeLockSecs,
+		TimeSpec:       common.TimeSpec_EPOCH,
+	}
+	lockInfoHTLCBytes, _ := proto.Marshal(lockInfoHTLC)
+	lockInfoBytes, _ := proto.Marshal(&common.AssetLock{LockMechanism: common.LockMechanism_HTLC, LockInfo: lockInfoHTLCBytes})
+
+	// a batch of units from a specific commodity lot
+	assetAgreement := &common.HybridAssetExchangeAgreement{
+		AssetType: "coffee",
+		Id:        "lot-42",
+		AssetData: []byte("grade=AA"),
+		NumUnits:  0,
+		Recipient: recipient,
+	}
+
+	// Test failure with no units to lock
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+	chaincodeStub.GetStateReturnsOnCall(0, []byte("interopcc"), nil)
+	_, err := interopcc.LockHybridAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "number of units of hybrid asset of type coffee and ID lot-42 must be a positive number")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success
+	assetAgreement.NumUnits = 50
+	assetAgreementBytes, _ = proto.Marshal(assetAgreement)
+	chaincodeStub.GetStateReturnsOnCall(1, []byte("interopcc"), nil)
+	contractId, err := interopcc.LockHybridAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.NoError(t, err)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+	key, assetLockValBytes := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, "HybridContractId_"+contractId, key)
+	assetLockVal := assetexchange.HybridAssetLockValue{}
+	require.NoError(t, json.Unmarshal(assetLockValBytes, &assetLockVal))
+	require.Equal(t, "lot-42", assetLockVal.Id)
+	require.Equal(t, []byte("grade=AA"), assetLockVal.AssetData)
+	require.Equal(t, uint64(50), assetLockVal.NumUnits)
+	require.Equal(t, locker, assetLockVal.Locker)
+	key, ccIdBytes := chaincodeStub.PutStateArgsForCall(1)
+	require.Equal(t, generateContractIdMapCCKey(contractId), key)
+	require.Equal(t, localCCId, string(ccIdBytes))
+	log.Info(fmt.Println("Test success as expected since the hybrid asset is locked."))
+
+	// Test that the hybrid asset is reported as locked, and with the lock's expiry time
+	chaincodeStub.GetStateReturnsOnCall(3, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(4, assetLockValBytes, nil)
+	isLocked, err := interopcc.IsHybridAssetLocked(ctx, contractId)
+	require.NoError(t, err)
+	require.True(t, isLocked)
+	chaincodeStub.GetStateReturnsOnCall(5, assetLockValBytes, nil)
+	expiryTimeSecs, err := interopcc.GetHybridAssetTimeToRelease(ctx, contractId)
+	require.NoError(t, err)
+	require.Equal(t, lockInfoHTLC.ExpiryTimeSecs, expiryTimeSecs)
+
+	// Test failure with a hybrid asset lock being claimed as a fungible asset lock
+	claimInfoHTLCBytes, _ := proto.Marshal(&common.AssetClaimHTLC{
+		HashMechanism:      common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte(base64.StdEncoding.EncodeToString([]byte(preimage))),
+	})
+	claimInfoBytes, _ := proto.Marshal(&common.AssetClaim{LockMechanism: common.LockMechanism_HTLC, ClaimInfo: claimInfoHTLCBytes})
+	chaincodeStub.GetStateReturnsOnCall(6, []byte(localCCId), nil)
+	err = interopcc.ClaimFungibleAsset(ctx, contractId, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.EqualError(t, err, "contractId "+contractId+" is not associated with any currently locked asset")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with the hybrid asset claimed
+	chaincodeStub.GetStateReturnsOnCall(8, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(9, assetLockValBytes, nil)
+	claimedAgreementBase64, err := interopcc.ClaimHybridAsset(ctx, contractId, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	claimedAgreementBytes, _ := base64.StdEncoding.DecodeString(claimedAgreementBase64)
+	claimedAgreement := &common.HybridAssetExchangeAgreement{}
+	require.NoError(t, proto.Unmarshal(claimedAgreementBytes, claimedAgreement))
+	require.Equal(t, "lot-42", claimedAgreement.Id)
+	require.Equal(t, uint64(50), claimedAgreement.NumUnits)
+	require.Equal(t, locker, claimedAgreement.Locker)
+	require.Equal(t, 2, chaincodeStub.DelStateCallCount())
+	require.Equal(t, "HybridContractId_"+contractId, chaincodeStub.DelStateArgsForCall(0))
+	require.Equal(t, generateContractIdMapCCKey(contractId), chaincodeStub.DelStateArgsForCall(1))
+	log.Info(fmt.Println("Test success as expected since the hybrid asset is claimed."))
+
+	// Test failure with the locker trying to unlock the hybrid asset before expiry
+	chaincodeStub.GetStateReturnsOnCall(10, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(11, assetLockValBytes, nil)
+	_, err = interopcc.UnlockHybridAsset(ctx, contractId)
+	require.EqualError(t, err, "cannot unlock asset associated with the contractId "+contractId+" as the expiry time is not yet elapsed")
+	log.Info(fmt.Println("Test failed as expected with error:", err))
+
+	// Test success with the locker unlocking the hybrid asset after expiry
+	assetLockVal.ExpiryTimeSecs = currentTimeSecs - defaultTimeLockSecs
+	assetLockValBytes, _ = json.Marshal(assetLockVal)
+	chaincodeStub.GetStateReturnsOnCall(12, []byte(localCCId), nil)
+	chaincodeStub.GetStateReturnsOnCall(13, assetLockValBytes, nil)
+	unlockedAgreementBase64, err := interopcc.UnlockHybridAsset(ctx, contractId)
+	require.NoError(t, err)
+	require.Equal(t, claimedAgreementBase64, unlockedAgreementBase64)
+	require.Equal(t, 4, chaincodeStub.DelStateCallCount())
+	log.Info(fmt.Println("Test success as expected since the hybrid asset is unlocked after expiry."))
+}
+
 func TestUnlockFungibleAsset(t *testing.T) {
 	ctx, chaincodeStub := wtest.PrepMockStub()
 	localCCId := "mycc"
@@ -13,6 +13,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
@@ -24,15 +25,25 @@ import (
 const membershipObjectType = "membership"
 const membershipLocalSecurityDomain = "local-security-domain"
 
-// Check the validity of each certificate chain in this membership
-func validateMemberCertChains(membership *common.Membership) error {
+// Check the validity of each certificate chain and certificate revocation list in this membership
+func validateMemberCertChains(membership *common.Membership, currentTime time.Time) error {
 	for _, member := range membership.Members {
 		if len(member.Chain) > 1 {
-			err := verifyCertificateChain(nil, member.Chain)
+			err := verifyCertificateChain(nil, member.Chain, member.Crls, currentTime)
 			if err != nil {
 				return fmt.Errorf("Certificate chain corresponding to member %+v in security domain %s is invalid: %s", member, membership.SecurityDomain, err)
 			}
 		}
+		if len(member.Crls) > 0 {
+			caCerts := member.Chain
+			if member.Type == "ca" {
+				caCerts = append([]string{member.Value}, member.Chain...)
+			}
+			err := validateCRLsFromCAs(member.Crls, caCerts)
+			if err != nil {
+				return fmt.Errorf("Certificate revocation lists corresponding to member %+v in security domain %s are invalid: %s", member, membership.SecurityDomain, err)
+			}
+		}
 	}
 	return nil
 }
@@ -93,7 +104,7 @@ func parseCounterAttestedMembership(counterAttestedMembershipSerialized string)
  * 2. Membership of attester in the given membership
  * 3. One attester from each member of membership
  */
-func validateAttestationsList(membership *common.Membership, attestations []*identity.Attestation, messageBytes string) error {
+func validateAttestationsList(membership *common.Membership, attestations []*identity.Attestation, messageBytes string, currentTime time.Time) error {
 	// Ensure authentic and valid attestations from all foreign IIN Agents
 	var attestationsMap = make(map[string]bool)
 	for _, attestation := range attestations {
@@ -109,7 +120,7 @@ func validateAttestationsList(membership *common.Membership, attestations []*ide
 		}
 
 		// Verify membership of attester
-		err = verifyMemberInSecurityDomain2("", attesterCert, membership, attestation.UnitIdentity.MemberId)
+		err = verifyMemberInSecurityDomain2("", attesterCert, membership, attestation.UnitIdentity.MemberId, currentTime)
 		if err != nil {
 			return fmt.Errorf("Attester with certificate %+v is not a designated IIN Agent of org %s in security domain %s: %+v",
 				attesterCert, attestation.UnitIdentity.MemberId, attestation.UnitIdentity.SecurityDomain, err)
@@ -150,19 +161,23 @@ func validateCounterAttestedMembership(s *SmartContract, ctx contractapi.Transac
 	if err != nil {
 		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
 	}
-	err = validateAttestationsList(localMembership, counterAttestedMembership.Attestations, counterAttestedMembership.GetAttestedMembershipSet() + matchedNonce)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateAttestationsList(localMembership, counterAttestedMembership.Attestations, counterAttestedMembership.GetAttestedMembershipSet() + matchedNonce, txTime)
 	if err != nil {
 		return err
 	}
 
 	// Validate foreign membership cert chains
-	err = validateMemberCertChains(foreignMembership)
+	err = validateMemberCertChains(foreignMembership, txTime)
 	if err != nil {
 		return err
 	}
 
 	// Ensure authentic and valid attestations from all foreign IIN Agents
-	err = validateAttestationsList(foreignMembership, attestedMembershipSet.Attestations, attestedMembershipSet.Membership + matchedNonce)
+	err = validateAttestationsList(foreignMembership, attestedMembershipSet.Attestations, attestedMembershipSet.Membership + matchedNonce, txTime)
 	if err != nil {
 		return err
 	}
@@ -194,7 +209,11 @@ func (s *SmartContract) CreateLocalMembership(ctx contractapi.TransactionContext
 	}
 
 	// Check if certificates chains in this membership record are valid
-	err = validateMemberCertChains(membership)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateMemberCertChains(membership, txTime)
 	if err != nil {
 		return err
 	}
@@ -228,7 +247,11 @@ func (s *SmartContract) UpdateLocalMembership(ctx contractapi.TransactionContext
 	}
 
 	// Check if certificates chains in this membership record are valid
-	err = validateMemberCertChains(membership)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateMemberCertChains(membership, txTime)
 	if err != nil {
 		return err
 	}
@@ -295,7 +318,11 @@ func createMembership(ctx contractapi.TransactionContextInterface, membershipJSO
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
 	// Check if certificates chains in this membership record are valid
-	err = validateMemberCertChains(membership)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateMemberCertChains(membership, txTime)
 	if err != nil {
 		return err
 	}
@@ -367,7 +394,11 @@ func updateMembership(s *SmartContract, ctx contractapi.TransactionContextInterf
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
 	// Check if certificates chains in this membership record are valid
-	err = validateMemberCertChains(membership)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateMemberCertChains(membership, txTime)
 	if err != nil {
 		return err
 	}
@@ -481,15 +512,19 @@ func verifyMemberInSecurityDomain1(s *SmartContract, ctx contractapi.Transaction
 	if err != nil {
 		return fmt.Errorf("Failed to unmarshal membership: %s", err.Error())
 	}
-	return verifyMemberInSecurityDomain2(certPEM, cert, membership, requestingOrg)
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+	return verifyMemberInSecurityDomain2(certPEM, cert, membership, requestingOrg, txTime)
 }
 
 // verifyMemberInSecurityDomain2 function verifies the identity of the requester according to
 // the Membership for the external network the request originated from.
 // This takes a decoded X.509 certificate as argument (and optionally the certificate in PEM format too).
 // It takes a membership structure as argument.
-func verifyMemberInSecurityDomain2(certPEM string, cert *x509.Certificate, membership *common.Membership, requestingOrg string) error {
-	err := isCertificateWithinExpiry(cert)
+func verifyMemberInSecurityDomain2(certPEM string, cert *x509.Certificate, membership *common.Membership, requestingOrg string, currentTime time.Time) error {
+	err := isCertificateWithinExpiry(cert, currentTime)
 	if err != nil {
 		return err
 	}
@@ -503,7 +538,7 @@ func verifyMemberInSecurityDomain2(certPEM string, cert *x509.Certificate, membe
 			return fmt.Errorf("CA member certificate is blank")
 		}
 		if certPEM != member.Value {	// The CA is automatically a member of the security domain
-			err := verifyCaCertificate(cert, member.Value)
+			err := verifyCaCertificate(cert, member.Value, member.Crls, currentTime)
 			if err != nil {
 				return err
 			}
@@ -513,7 +548,7 @@ func verifyMemberInSecurityDomain2(certPEM string, cert *x509.Certificate, membe
 		if len(chain) == 0 {
 			chain = []string{member.Value}
 		}
-		err := verifyCertificateChain(cert, chain)
+		err := verifyCertificateChain(cert, chain, member.Crls, currentTime)
 		if err != nil {
 			return err
 		}
@@ -77,7 +77,7 @@ func createX509Certificate(caCert *x509.Certificate, caCertPrivKey *ecdsa.Privat
 		Subject: pkix.Name{
 			Organization: []string{"Hyperledger"},
 		},
-		NotBefore:			   time.Now(),
+		NotBefore:			   time.Now().Add(-time.Hour),	// valid at the transaction timestamp set before the certificate is created
 		NotAfter:			   time.Now().AddDate(1, 0, 0),		// 1 year expiry duration
 		KeyUsage:			   x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:		   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
@@ -1142,6 +1142,37 @@ func TestVerifyMembership(t *testing.T) {
 	err = verifyMemberInSecurityDomain(&interopcc, ctx, string(pemCert), "test", "unknown_member")
 	require.EqualError(t, err, "Member does not exist for org: unknown_member")
 
+	// Test: Revoked requestor certificate
+	rootCert, rootKey, err := createCACertificate("root-ca", 1, nil, nil)
+	require.NoError(t, err)
+	requestorCert, _, err := createCACertificate("user1", 2, rootCert, rootKey)
+	require.NoError(t, err)
+	rootCRL, err := createCRLPEM(rootCert, rootKey, []int64{2})
+	require.NoError(t, err)
+	revocationMembership := common.Membership{
+		SecurityDomain: securityDomainId,
+		Members: map[string]*common.Member{"member1": {
+			Value: x509ToPem(rootCert),
+			Type:  "ca",
+		}},
+	}
+	err = validateMemberCertChains(&revocationMembership, time.Now())
+	require.NoError(t, err)
+	err = verifyMemberInSecurityDomain2("", requestorCert, &revocationMembership, "member1", time.Now())
+	require.NoError(t, err)
+	revocationMembership.Members["member1"].Crls = []string{rootCRL}
+	err = validateMemberCertChains(&revocationMembership, time.Now())
+	require.NoError(t, err)
+	err = verifyMemberInSecurityDomain2("", requestorCert, &revocationMembership, "member1", time.Now())
+	require.EqualError(t, err, "Certificate CN=user1 with serial number 2 has been revoked by CN=root-ca")
+	revocationMembership.Members["member1"].Type = "certificate"
+	revocationMembership.Members["member1"].Chain = []string{x509ToPem(rootCert)}
+	err = verifyMemberInSecurityDomain2("", requestorCert, &revocationMembership, "member1", time.Now())
+	require.EqualError(t, err, "Certificate CN=user1 with serial number 2 has been revoked by CN=root-ca")
+	revocationMembership.Members["member1"].Crls = []string{"invalid"}
+	err = validateMemberCertChains(&revocationMembership, time.Now())
+	require.ErrorContains(t, err, "Unable to decode CRL PEM")
+
 	// Test: Unknown cert type
 	membershipAsset.Members["member1"].Type = "unknown"
 	membershipBytes, err = json.Marshal(&membershipAsset)
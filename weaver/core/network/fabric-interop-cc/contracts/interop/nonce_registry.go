@@ -0,0 +1,214 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// nonce_registry contains the code to check the nonces of queries exchanged with external networks
+// so that stale and replayed queries can be detected and rejected.
+//
+// If a freshness window is configured, nonces must be of the form `<issue time in unix seconds>:<random string>`,
+// and queries whose nonce was issued outside the window (relative to the transaction timestamp) are rejected.
+// The source network checks this when handling a query; as the relay driver evaluates queries without
+// committing them, nothing is recorded there. The destination network records the nonce of every view it
+// consumes in WriteExternalState, against the source network and the fingerprint of the requestor's
+// certificate, so that a view cannot be used twice. Recorded nonces that are older than the window can no
+// longer be replayed, as the freshness check rejects them, and are removed using PruneQueryNonces, which keeps
+// the registry bounded.
+//
+// No freshness window is set by default, in which case any non-empty nonce is accepted by the source network, and
+// recorded nonces are never pruned automatically: a network admin should set one with SetNonceFreshnessWindow once
+// the clients of the network issue timestamped nonces.
+//
+// An event subscription query is reused for every event published for it, so its nonce is not checked. The view of
+// each event instead carries a nonce derived from the transaction publishing it (see getEventViewNonce).
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const queryNonceObjectType = "queryNonce"
+const nonceFreshnessWindowKey = "nonceFreshnessWindowSecs"
+const nonceTimestampSeparator = ":"
+
+// SetNonceFreshnessWindow cc is used to set the maximum age (in seconds) of the nonce of a query
+// from an external network. A window of 0, the default, disables the freshness check.
+func (s *SmartContract) SetNonceFreshnessWindow(ctx contractapi.TransactionContextInterface, windowSecs uint64) error {
+	// Check if the caller has network admin privileges
+	if isAdmin, err := wutils.IsClientNetworkAdmin(ctx); err != nil {
+		return fmt.Errorf("Admin client check error: %s", err)
+	} else if !isAdmin {
+		return fmt.Errorf("Caller not a network admin; access denied")
+	}
+	return ctx.GetStub().PutState(nonceFreshnessWindowKey, []byte(strconv.FormatUint(windowSecs, 10)))
+}
+
+// GetNonceFreshnessWindow cc gets the maximum age (in seconds) of the nonce of a query from an external network
+func (s *SmartContract) GetNonceFreshnessWindow(ctx contractapi.TransactionContextInterface) (uint64, error) {
+	windowBytes, err := ctx.GetStub().GetState(nonceFreshnessWindowKey)
+	if err != nil {
+		return 0, err
+	}
+	if windowBytes == nil {
+		return 0, nil
+	}
+	windowSecs, err := strconv.ParseUint(string(windowBytes), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid nonce freshness window recorded: %s", err)
+	}
+	return windowSecs, nil
+}
+
+// PruneQueryNonces cc deletes recorded query nonces that were recorded more than olderThanSecs seconds before
+// the current transaction. If olderThanSecs is 0, the configured freshness window is used. Returns the number
+// of nonces deleted.
+func (s *SmartContract) PruneQueryNonces(ctx contractapi.TransactionContextInterface, olderThanSecs uint64) (int, error) {
+	// Check if the caller has network admin privileges
+	if isAdmin, err := wutils.IsClientNetworkAdmin(ctx); err != nil {
+		return 0, fmt.Errorf("Admin client check error: %s", err)
+	} else if !isAdmin {
+		return 0, fmt.Errorf("Caller not a network admin; access denied")
+	}
+
+	if olderThanSecs == 0 {
+		windowSecs, err := s.GetNonceFreshnessWindow(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if windowSecs == 0 {
+			return 0, fmt.Errorf("No nonce freshness window is set; an age must be specified for pruning")
+		}
+		olderThanSecs = windowSecs
+	}
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	txTimeSecs := txTime.Unix()
+	cutoff := txTimeSecs - int64(olderThanSecs)
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(queryNonceObjectType, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer iterator.Close()
+
+	pruned := 0
+	for iterator.HasNext() {
+		entry, err := iterator.Next()
+		if err != nil {
+			return pruned, err
+		}
+		recordedAt, err := strconv.ParseInt(string(entry.Value), 10, 64)
+		// entries that cannot be parsed are never valid and are removed
+		if err == nil && recordedAt > cutoff {
+			continue
+		}
+		if err := ctx.GetStub().DelState(entry.Key); err != nil {
+			return pruned, fmt.Errorf("failed to delete query nonce %s: %v", entry.Key, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// parseNonceTimestamp extracts the issue time from a nonce of the form `<unix seconds>:<random string>`
+func parseNonceTimestamp(nonce string) (int64, bool) {
+	parts := strings.SplitN(nonce, nonceTimestampSeparator, 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return 0, false
+	}
+	issuedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || issuedAt < 0 {
+		return 0, false
+	}
+	return issuedAt, true
+}
+
+func getQueryNonceKey(ctx contractapi.TransactionContextInterface, securityDomain string, cert *x509.Certificate, nonce string) (string, error) {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return ctx.GetStub().CreateCompositeKey(queryNonceObjectType, []string{securityDomain, hex.EncodeToString(fingerprint[:]), nonce})
+}
+
+// getEventViewNonce returns the nonce of the view of a published event, of the form `<issue time>:<transaction id>`,
+// which is fresh when the event is published and distinct for every event, so that the destination network
+// can record it when consuming the view
+func getEventViewNonce(ctx contractapi.TransactionContextInterface) (string, error) {
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(txTime.Unix(), 10) + nonceTimestampSeparator + ctx.GetStub().GetTxID(), nil
+}
+
+// checkQueryNonceFreshness rejects empty nonces and, if a freshness window is set, nonces issued outside the window.
+// Returns the transaction time in unix seconds.
+func checkQueryNonceFreshness(s *SmartContract, ctx contractapi.TransactionContextInterface, nonce string) (int64, error) {
+	if nonce == "" {
+		return 0, fmt.Errorf("Query nonce is empty")
+	}
+	txTime, err := getTxTime(ctx)
+	if err != nil {
+		return 0, err
+	}
+	txTimeSecs := txTime.Unix()
+	windowSecs, err := s.GetNonceFreshnessWindow(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if windowSecs > 0 {
+		issuedAt, ok := parseNonceTimestamp(nonce)
+		if !ok {
+			return 0, fmt.Errorf("Query nonce does not carry an issue timestamp")
+		}
+		if txTimeSecs-issuedAt > int64(windowSecs) {
+			return 0, fmt.Errorf("Query is stale: nonce issued at %d is older than the freshness window of %d seconds", issuedAt, windowSecs)
+		}
+		// allow for the same amount of clock skew in the other direction
+		if issuedAt-txTimeSecs > int64(windowSecs) {
+			return 0, fmt.Errorf("Query nonce issued at %d is too far in the future", issuedAt)
+		}
+	}
+	return txTimeSecs, nil
+}
+
+// checkAndRecordViewNonces rejects views whose query nonce is stale or has already been used by the same requestor
+// with the same source network, including earlier in this transaction, and otherwise records the nonces
+func checkAndRecordViewNonces(s *SmartContract, ctx contractapi.TransactionContextInterface, securityDomains []string, cert *x509.Certificate, nonces []string) error {
+	recordedKeys := map[string]bool{}
+	for i, nonce := range nonces {
+		txTimeSecs, err := checkQueryNonceFreshness(s, ctx, nonce)
+		if err != nil {
+			return err
+		}
+		nonceKey, err := getQueryNonceKey(ctx, securityDomains[i], cert, nonce)
+		if err != nil {
+			return err
+		}
+		// writes of this transaction are not visible to GetState, so repeated nonces are tracked here
+		if recordedKeys[nonceKey] {
+			return fmt.Errorf("Query nonce has already been used: %s", nonce)
+		}
+		recorded, err := ctx.GetStub().GetState(nonceKey)
+		if err != nil {
+			return err
+		}
+		if recorded != nil {
+			return fmt.Errorf("Query nonce has already been used: %s", nonce)
+		}
+		if err := ctx.GetStub().PutState(nonceKey, []byte(strconv.FormatInt(txTimeSecs, 10))); err != nil {
+			return err
+		}
+		recordedKeys[nonceKey] = true
+	}
+	return nil
+}
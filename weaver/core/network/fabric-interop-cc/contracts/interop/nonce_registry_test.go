@@ -0,0 +1,182 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"testing"
+
+	wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils/mocks"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNonceFreshnessWindow(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+
+	// Case when caller is not an admin
+	err := interopcc.SetNonceFreshnessWindow(ctx, 300)
+	require.EqualError(t, err, "Caller not a network admin; access denied")
+	// Set caller to be admin now
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetAttributeValueCalls(setClientAdmin)
+	ctx.GetClientIdentityReturns(clientIdentity)
+	err = interopcc.SetNonceFreshnessWindow(ctx, 300)
+	require.NoError(t, err)
+	key, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, nonceFreshnessWindowKey, key)
+	require.Equal(t, "300", string(value))
+
+	// Window defaults to 0 (disabled) when not set
+	windowSecs, err := interopcc.GetNonceFreshnessWindow(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), windowSecs)
+	chaincodeStub.GetStateReturns([]byte("300"), nil)
+	windowSecs, err = interopcc.GetNonceFreshnessWindow(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(300), windowSecs)
+}
+
+func TestParseNonceTimestamp(t *testing.T) {
+	issuedAt, ok := parseNonceTimestamp("1697030400:ZjE2ZjMxMjItNGE3Mi00")
+	require.True(t, ok)
+	require.Equal(t, int64(1697030400), issuedAt)
+
+	_, ok = parseNonceTimestamp("ZjE2ZjMxMjItNGE3Mi00")
+	require.False(t, ok)
+	_, ok = parseNonceTimestamp("1697030400:")
+	require.False(t, ok)
+	_, ok = parseNonceTimestamp("-5:abc")
+	require.False(t, ok)
+	_, ok = parseNonceTimestamp("abc:def")
+	require.False(t, ok)
+}
+
+func TestCheckQueryNonceFreshness(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	txTime := int64(1697030400)
+	chaincodeStub.GetTxTimestampReturns(&timestamppb.Timestamp{Seconds: txTime}, nil)
+
+	// Freshness window not set: any nonce is accepted, and nothing is recorded
+	txTimeSecs, err := checkQueryNonceFreshness(&interopcc, ctx, "nonce")
+	require.NoError(t, err)
+	require.Equal(t, txTime, txTimeSecs)
+	require.Equal(t, 0, chaincodeStub.PutStateCallCount())
+
+	// Empty nonce
+	_, err = checkQueryNonceFreshness(&interopcc, ctx, "")
+	require.EqualError(t, err, "Query nonce is empty")
+
+	// Missing transaction timestamp
+	chaincodeStub.GetTxTimestampReturns(nil, nil)
+	_, err = checkQueryNonceFreshness(&interopcc, ctx, "nonce")
+	require.EqualError(t, err, "Unable to get transaction timestamp: timestamp is missing")
+
+	// Freshness window set
+	chaincodeStub.GetTxTimestampReturns(&timestamppb.Timestamp{Seconds: txTime}, nil)
+	chaincodeStub.GetStateReturns([]byte("60"), nil)
+	_, err = checkQueryNonceFreshness(&interopcc, ctx, "nonce")
+	require.EqualError(t, err, "Query nonce does not carry an issue timestamp")
+	_, err = checkQueryNonceFreshness(&interopcc, ctx, fmt.Sprintf("%d:random", txTime-61))
+	require.EqualError(t, err, fmt.Sprintf("Query is stale: nonce issued at %d is older than the freshness window of 60 seconds", txTime-61))
+	_, err = checkQueryNonceFreshness(&interopcc, ctx, fmt.Sprintf("%d:random", txTime+61))
+	require.EqualError(t, err, fmt.Sprintf("Query nonce issued at %d is too far in the future", txTime+61))
+	_, err = checkQueryNonceFreshness(&interopcc, ctx, fmt.Sprintf("%d:random", txTime-30))
+	require.NoError(t, err)
+}
+
+func TestCheckAndRecordViewNonces(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	cert := &x509.Certificate{Raw: []byte("requestor cert")}
+	txTime := int64(1697030400)
+	chaincodeStub.GetTxTimestampReturns(&timestamppb.Timestamp{Seconds: txTime}, nil)
+	chaincodeStub.CreateCompositeKeyCalls(func(objectType string, attributes []string) (string, error) {
+		return objectType + strings.Join(attributes, ""), nil
+	})
+	recorded := map[string][]byte{}
+	chaincodeStub.GetStateCalls(func(key string) ([]byte, error) {
+		return recorded[key], nil
+	})
+	chaincodeStub.PutStateCalls(func(key string, value []byte) error {
+		recorded[key] = value
+		return nil
+	})
+
+	// Unused nonces are recorded against the source network and the requestor
+	err := checkAndRecordViewNonces(&interopcc, ctx, []string{"network1", "network2"}, cert, []string{"nonce", "nonce"})
+	require.NoError(t, err)
+	require.Equal(t, 2, chaincodeStub.PutStateCallCount())
+	_, value := chaincodeStub.PutStateArgsForCall(0)
+	require.Equal(t, fmt.Sprintf("%d", txTime), string(value))
+	objectType, attributes := chaincodeStub.CreateCompositeKeyArgsForCall(0)
+	require.Equal(t, queryNonceObjectType, objectType)
+	require.Equal(t, "network1", attributes[0])
+	require.Equal(t, "nonce", attributes[2])
+
+	// Replayed nonce
+	err = checkAndRecordViewNonces(&interopcc, ctx, []string{"network1"}, cert, []string{"nonce"})
+	require.EqualError(t, err, "Query nonce has already been used: nonce")
+	// The same nonce is used by another requestor
+	err = checkAndRecordViewNonces(&interopcc, ctx, []string{"network1"}, &x509.Certificate{Raw: []byte("other cert")}, []string{"nonce"})
+	require.NoError(t, err)
+
+	// Nonce repeated within a transaction, whose writes are not visible to GetState
+	chaincodeStub.PutStateReturns(nil)
+	chaincodeStub.PutStateCalls(nil)
+	err = checkAndRecordViewNonces(&interopcc, ctx, []string{"network1", "network1"}, cert, []string{"other", "other"})
+	require.EqualError(t, err, "Query nonce has already been used: other")
+
+	// Stale nonce
+	recorded[nonceFreshnessWindowKey] = []byte("60")
+	err = checkAndRecordViewNonces(&interopcc, ctx, []string{"network1"}, cert, []string{fmt.Sprintf("%d:random", txTime-61)})
+	require.EqualError(t, err, fmt.Sprintf("Query is stale: nonce issued at %d is older than the freshness window of 60 seconds", txTime-61))
+}
+
+func TestPruneQueryNonces(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+	txTime := int64(1697030400)
+	chaincodeStub.GetTxTimestampReturns(&timestamppb.Timestamp{Seconds: txTime}, nil)
+
+	// Case when caller is not an admin
+	_, err := interopcc.PruneQueryNonces(ctx, 60)
+	require.EqualError(t, err, "Caller not a network admin; access denied")
+	// Set caller to be admin now
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetAttributeValueCalls(setClientAdmin)
+	ctx.GetClientIdentityReturns(clientIdentity)
+
+	// No age specified and no freshness window set
+	_, err = interopcc.PruneQueryNonces(ctx, 0)
+	require.EqualError(t, err, "No nonce freshness window is set; an age must be specified for pruning")
+
+	iterator := &mocks.StateQueryIterator{}
+	iterator.HasNextReturnsOnCall(0, true)
+	iterator.HasNextReturnsOnCall(1, true)
+	iterator.HasNextReturnsOnCall(2, true)
+	iterator.HasNextReturnsOnCall(3, false)
+	iterator.NextReturnsOnCall(0, &queryresult.KV{Key: "old", Value: []byte(fmt.Sprintf("%d", txTime-120))}, nil)
+	iterator.NextReturnsOnCall(1, &queryresult.KV{Key: "recent", Value: []byte(fmt.Sprintf("%d", txTime-30))}, nil)
+	iterator.NextReturnsOnCall(2, &queryresult.KV{Key: "invalid", Value: []byte("invalid")}, nil)
+	chaincodeStub.GetStateByPartialCompositeKeyReturns(iterator, nil)
+	chaincodeStub.GetStateReturns([]byte("60"), nil)
+
+	pruned, err := interopcc.PruneQueryNonces(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+	require.Equal(t, 2, chaincodeStub.DelStateCallCount())
+	require.Equal(t, "old", chaincodeStub.DelStateArgsForCall(0))
+	require.Equal(t, "invalid", chaincodeStub.DelStateArgsForCall(1))
+	require.Equal(t, 1, iterator.CloseCallCount())
+}
@@ -0,0 +1,382 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// policy_dsl contains the parser and evaluator for verification policy expressions, as specified
+// by the policy DSL (see weaver/common/policy-dsl and weaver/rfcs/formats/policies/dsl.md).
+// Expressions combine signer identifiers and `count` thresholds with `&&` and `||`, e.g.
+// `Org1MSP && count >= 3` or `(Org1MSP || Org2MSP) && count > 4`. As in the grammar, `&&` binds
+// tighter than `||`; parentheses can be used to override this.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+)
+
+// Supported values of Policy.Type (compared case-insensitively). Any type other than
+// policyTypeExpression or policyTypeFabricSignature is treated as a signature policy, where
+// the criteria list the signers that are all required, to remain compatible with existing policies.
+// Fabric signature policies are parsed and evaluated in fabric_signature_policy.go.
+const (
+	policyTypeSignature       = "signature"
+	policyTypeExpression      = "expression"
+	policyTypeFabricSignature = "fabricsignaturepolicy"
+)
+
+const policyCountKeyword = "count"
+
+type policyTokenKind int
+
+const (
+	policyTokenEOF policyTokenKind = iota
+	policyTokenID
+	policyTokenCount
+	policyTokenInt
+	policyTokenAnd
+	policyTokenOr
+	policyTokenCmp
+	policyTokenLParen
+	policyTokenRParen
+)
+
+type policyToken struct {
+	kind  policyTokenKind
+	value string
+	pos   int
+}
+
+// policyExpression is a node of a parsed policy expression. It is evaluated against the
+// set of distinct signers whose notarizations have been verified.
+type policyExpression interface {
+	evaluate(signers map[string]bool) bool
+}
+
+type policySignerExpression struct {
+	id string
+}
+
+func (e *policySignerExpression) evaluate(signers map[string]bool) bool {
+	return signers[e.id]
+}
+
+type policyAndExpression struct {
+	left, right policyExpression
+}
+
+func (e *policyAndExpression) evaluate(signers map[string]bool) bool {
+	return e.left.evaluate(signers) && e.right.evaluate(signers)
+}
+
+type policyOrExpression struct {
+	left, right policyExpression
+}
+
+func (e *policyOrExpression) evaluate(signers map[string]bool) bool {
+	return e.left.evaluate(signers) || e.right.evaluate(signers)
+}
+
+// policyCountExpression is always normalized to the form `count <op> threshold`
+type policyCountExpression struct {
+	op        string
+	threshold int
+}
+
+func (e *policyCountExpression) evaluate(signers map[string]bool) bool {
+	count := len(signers)
+	switch e.op {
+	case ">":
+		return count > e.threshold
+	case ">=":
+		return count >= e.threshold
+	case "<":
+		return count < e.threshold
+	case "<=":
+		return count <= e.threshold
+	}
+	return false
+}
+
+// flipComparison maps `threshold <op> count` to the equivalent operator for `count <op> threshold`
+var flipComparison = map[string]string{
+	">":  "<",
+	">=": "<=",
+	"<":  ">",
+	"<=": ">=",
+}
+
+func isPolicyIDStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isPolicyIDChar(c byte) bool {
+	return isPolicyIDStart(c) || (c >= '0' && c <= '9') || c == '.' || c == '-'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tokenizePolicyExpression splits an expression into tokens. Identifiers are more lenient than in the
+// grammar so that MSP IDs and Corda party names (e.g. Org1MSP, PartyA) can be used directly.
+func tokenizePolicyExpression(expression string) ([]policyToken, error) {
+	tokens := []policyToken{}
+	i := 0
+	for i < len(expression) {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, policyToken{kind: policyTokenLParen, value: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, policyToken{kind: policyTokenRParen, value: ")", pos: i})
+			i++
+		case strings.HasPrefix(expression[i:], "&&"):
+			tokens = append(tokens, policyToken{kind: policyTokenAnd, value: "&&", pos: i})
+			i += 2
+		case strings.HasPrefix(expression[i:], "||"):
+			tokens = append(tokens, policyToken{kind: policyTokenOr, value: "||", pos: i})
+			i += 2
+		case strings.HasPrefix(expression[i:], ">="), strings.HasPrefix(expression[i:], "<="):
+			tokens = append(tokens, policyToken{kind: policyTokenCmp, value: expression[i : i+2], pos: i})
+			i += 2
+		case c == '>' || c == '<':
+			tokens = append(tokens, policyToken{kind: policyTokenCmp, value: string(c), pos: i})
+			i++
+		case isDigit(c):
+			start := i
+			for i < len(expression) && isDigit(expression[i]) {
+				i++
+			}
+			tokens = append(tokens, policyToken{kind: policyTokenInt, value: expression[start:i], pos: start})
+		case isPolicyIDStart(c):
+			start := i
+			for i < len(expression) && isPolicyIDChar(expression[i]) {
+				i++
+			}
+			kind := policyTokenID
+			if expression[start:i] == policyCountKeyword {
+				kind = policyTokenCount
+			}
+			tokens = append(tokens, policyToken{kind: kind, value: expression[start:i], pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", c, i)
+		}
+	}
+	tokens = append(tokens, policyToken{kind: policyTokenEOF, pos: len(expression)})
+	return tokens, nil
+}
+
+type policyParser struct {
+	tokens []policyToken
+	pos    int
+}
+
+func (p *policyParser) peek() policyToken {
+	return p.tokens[p.pos]
+}
+
+func (p *policyParser) next() policyToken {
+	token := p.tokens[p.pos]
+	if token.kind != policyTokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *policyParser) expect(kind policyTokenKind, description string) (policyToken, error) {
+	token := p.next()
+	if token.kind != kind {
+		return token, unexpectedPolicyToken(token, description)
+	}
+	return token, nil
+}
+
+func unexpectedPolicyToken(token policyToken, description string) error {
+	if token.kind == policyTokenEOF {
+		return fmt.Errorf("expected %s at end of expression", description)
+	}
+	return fmt.Errorf("expected %s at position %d, found '%s'", description, token.pos, token.value)
+}
+
+// parseOr parses: and_expression ( '||' and_expression )*
+func (p *policyParser) parseOr() (policyExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == policyTokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &policyOrExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: primary ( '&&' primary )*
+func (p *policyParser) parseAnd() (policyExpression, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == policyTokenAnd {
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &policyAndExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+// parsePrimary parses: ID | count_expression | '(' expression ')'
+func (p *policyParser) parsePrimary() (policyExpression, error) {
+	token := p.next()
+	switch token.kind {
+	case policyTokenID:
+		return &policySignerExpression{id: token.value}, nil
+	case policyTokenLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(policyTokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case policyTokenCount:
+		op, err := p.expect(policyTokenCmp, "comparison operator")
+		if err != nil {
+			return nil, err
+		}
+		threshold, err := p.parseThreshold()
+		if err != nil {
+			return nil, err
+		}
+		return &policyCountExpression{op: op.value, threshold: threshold}, nil
+	case policyTokenInt:
+		threshold, err := strconv.Atoi(token.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer '%s' at position %d", token.value, token.pos)
+		}
+		op, err := p.expect(policyTokenCmp, "comparison operator")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(policyTokenCount, "'count'"); err != nil {
+			return nil, err
+		}
+		return &policyCountExpression{op: flipComparison[op.value], threshold: threshold}, nil
+	}
+	return nil, unexpectedPolicyToken(token, "identifier, 'count' or '('")
+}
+
+func (p *policyParser) parseThreshold() (int, error) {
+	token, err := p.expect(policyTokenInt, "integer")
+	if err != nil {
+		return 0, err
+	}
+	threshold, err := strconv.Atoi(token.value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer '%s' at position %d", token.value, token.pos)
+	}
+	return threshold, nil
+}
+
+// parsePolicyExpression parses a policy DSL expression into an evaluable tree
+func parsePolicyExpression(expression string) (policyExpression, error) {
+	tokens, err := tokenizePolicyExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &policyParser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.expect(policyTokenEOF, "end of expression"); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+func isExpressionPolicy(policy *common.Policy) bool {
+	return strings.EqualFold(policy.Type, policyTypeExpression)
+}
+
+// validatePolicy checks that an expression policy has criteria that are all well-formed expressions, and that
+// a Fabric signature policy has a single valid criterion. Signature policies may have no criteria, in which case
+// no particular signer is required, as before expression policies were introduced.
+func validatePolicy(policy *common.Policy) error {
+	if policy == nil {
+		return fmt.Errorf("policy is missing")
+	}
+	if isFabricSignaturePolicy(policy) {
+		if len(policy.Criteria) != 1 {
+			return fmt.Errorf("Fabric signature policy must have a single criterion, found %d", len(policy.Criteria))
+		}
+		_, err := parseFabricSignaturePolicy(policy.Criteria[0])
+		return err
+	}
+	if !isExpressionPolicy(policy) {
+		return nil
+	}
+	if len(policy.Criteria) == 0 {
+		return fmt.Errorf("policy has no criteria")
+	}
+	for _, criterion := range policy.Criteria {
+		if _, err := parsePolicyExpression(criterion); err != nil {
+			return fmt.Errorf("invalid policy expression '%s': %s", criterion, err.Error())
+		}
+	}
+	return nil
+}
+
+// verifyPolicySatisfied checks that the verified signers of a view satisfy the verification policy.
+// For expression policies, every criterion must evaluate to true; `count` refers to the number of
+// distinct signers. For signature policies, every criterion must be present in the list of signers.
+// Fabric signature policies are evaluated with the signers only holding the member role of their MSPs;
+// use verifySignersSatisfyPolicy to take the roles in the signers' certificates into account.
+func verifyPolicySatisfied(policy *common.Policy, signerList []string) error {
+	if err := validatePolicy(policy); err != nil {
+		return fmt.Errorf("Invalid verification policy: %s", err.Error())
+	}
+	if isFabricSignaturePolicy(policy) {
+		signers := make([]policySigner, len(signerList))
+		for i, signer := range signerList {
+			signers[i] = policySigner{id: signer}
+		}
+		return verifyFabricSignaturePolicySatisfied(policy.Criteria[0], signers)
+	}
+	if !isExpressionPolicy(policy) {
+		for _, signer := range policy.Criteria {
+			if !Contains(signerList, signer) {
+				return fmt.Errorf("Notarizations missing signer: %s", signer)
+			}
+		}
+		return nil
+	}
+	signers := map[string]bool{}
+	for _, signer := range signerList {
+		signers[signer] = true
+	}
+	for _, criterion := range policy.Criteria {
+		// criteria have been validated above
+		expr, _ := parsePolicyExpression(criterion)
+		if !expr.evaluate(signers) {
+			return fmt.Errorf("Notarizations do not satisfy verification policy: %s", criterion)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicyExpression(t *testing.T) {
+	signers := map[string]bool{"Org1MSP": true, "Org2MSP": true, "Org3MSP": true}
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		{"Org1MSP", true},
+		{"Org4MSP", false},
+		{"Org1MSP && Org2MSP", true},
+		{"Org1MSP && Org4MSP", false},
+		{"Org4MSP || Org2MSP", true},
+		{"count >= 3", true},
+		{"count > 3", false},
+		{"count < 4", true},
+		{"count <= 2", false},
+		{"4 > count", true},
+		{"3 <= count", true},
+		{"Org1MSP && count >= 3", true},
+		{"Org1MSP && Org2MSP || count > 4", true},
+		// && binds tighter than ||
+		{"Org4MSP && Org1MSP || Org2MSP", true},
+		{"Org4MSP && (Org1MSP || Org2MSP)", false},
+		{"(Org4MSP || Org5MSP) && count >= 3", false},
+		{"((Org1MSP))&&count>=2", true},
+	}
+	for _, testCase := range testCases {
+		expr, err := parsePolicyExpression(testCase.expression)
+		require.NoError(t, err, testCase.expression)
+		require.Equal(t, testCase.expected, expr.evaluate(signers), testCase.expression)
+	}
+
+	// Error cases
+	_, err := parsePolicyExpression("")
+	require.EqualError(t, err, "expected identifier, 'count' or '(' at end of expression")
+	_, err = parsePolicyExpression("Org1MSP &&")
+	require.EqualError(t, err, "expected identifier, 'count' or '(' at end of expression")
+	_, err = parsePolicyExpression("Org1MSP & Org2MSP")
+	require.EqualError(t, err, "unexpected character '&' at position 8")
+	_, err = parsePolicyExpression("count >= Org1MSP")
+	require.EqualError(t, err, "expected integer at position 9, found 'Org1MSP'")
+	_, err = parsePolicyExpression("3 >= 4")
+	require.EqualError(t, err, "expected 'count' at position 5, found '4'")
+	_, err = parsePolicyExpression("(Org1MSP || Org2MSP")
+	require.EqualError(t, err, "expected ')' at end of expression")
+	_, err = parsePolicyExpression("Org1MSP Org2MSP")
+	require.EqualError(t, err, "expected end of expression at position 8, found 'Org2MSP'")
+	_, err = parsePolicyExpression("count")
+	require.EqualError(t, err, "expected comparison operator at end of expression")
+	// As in the grammar, counts can only be compared with <, <=, > and >=
+	_, err = parsePolicyExpression("count == 3")
+	require.EqualError(t, err, "unexpected character '=' at position 6")
+}
+
+func TestVerifyPolicySatisfied(t *testing.T) {
+	// Signature policies require every signer in the criteria
+	signaturePolicy := &common.Policy{Type: "Signature", Criteria: []string{"Org1MSP", "Org2MSP"}}
+	require.NoError(t, verifyPolicySatisfied(signaturePolicy, []string{"Org2MSP", "Org1MSP"}))
+	require.EqualError(t, verifyPolicySatisfied(signaturePolicy, []string{"Org1MSP"}), "Notarizations missing signer: Org2MSP")
+	// Signature policies without criteria require no particular signer
+	require.NoError(t, verifyPolicySatisfied(&common.Policy{Type: "Signature"}, []string{"Org1MSP"}))
+
+	// Expression policies count distinct signers
+	thresholdPolicy := &common.Policy{Type: "Expression", Criteria: []string{"count >= 2"}}
+	require.NoError(t, verifyPolicySatisfied(thresholdPolicy, []string{"Org1MSP", "Org2MSP"}))
+	require.EqualError(t, verifyPolicySatisfied(thresholdPolicy, []string{"Org1MSP", "Org1MSP"}), "Notarizations do not satisfy verification policy: count >= 2")
+
+	// Every criterion of an expression policy must be satisfied
+	expressionPolicy := &common.Policy{Type: "expression", Criteria: []string{"Org1MSP || Org2MSP", "count >= 2"}}
+	require.NoError(t, verifyPolicySatisfied(expressionPolicy, []string{"Org2MSP", "Org3MSP"}))
+	require.EqualError(t, verifyPolicySatisfied(expressionPolicy, []string{"Org3MSP", "Org4MSP"}), "Notarizations do not satisfy verification policy: Org1MSP || Org2MSP")
+
+	// Malformed policies
+	require.EqualError(t, verifyPolicySatisfied(nil, []string{"Org1MSP"}), "Invalid verification policy: policy is missing")
+	require.EqualError(t, verifyPolicySatisfied(&common.Policy{Type: "expression"}, []string{"Org1MSP"}), "Invalid verification policy: policy has no criteria")
+	require.EqualError(t, verifyPolicySatisfied(&common.Policy{Type: "FabricSignaturePolicy"}, []string{"Org1MSP"}), "Invalid verification policy: Fabric signature policy must have a single criterion, found 0")
+	malformedPolicy := &common.Policy{Type: "expression", Criteria: []string{"count >= "}}
+	require.EqualError(t, verifyPolicySatisfied(malformedPolicy, []string{"Org1MSP"}), "Invalid verification policy: invalid policy expression 'count >= ': expected integer at end of expression")
+}
@@ -13,8 +13,10 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/patternmatcher"
 )
 
 const verificationPolicyObjectType = "verificationPolicy"
@@ -32,6 +34,9 @@ func (s *SmartContract) CreateVerificationPolicy(ctx contractapi.TransactionCont
 	if err != nil {
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
+	if err := validateVerificationPolicy(verificationPolicy); err != nil {
+		return fmt.Errorf("Invalid verification policy: %s", err)
+	}
 	verificationPolicyKey, err := ctx.GetStub().CreateCompositeKey(verificationPolicyObjectType, []string{verificationPolicy.SecurityDomain})
 	acp, getErr := ctx.GetStub().GetState(verificationPolicyKey)
 	if getErr != nil {
@@ -61,6 +66,9 @@ func (s *SmartContract) UpdateVerificationPolicy(ctx contractapi.TransactionCont
 	if err != nil {
 		return fmt.Errorf("Unmarshal error: %s", err)
 	}
+	if err := validateVerificationPolicy(verificationPolicy); err != nil {
+		return fmt.Errorf("Invalid verification policy: %s", err)
+	}
 	verificationPolicyKey, err := ctx.GetStub().CreateCompositeKey(verificationPolicyObjectType, []string{verificationPolicy.SecurityDomain})
 	_, err = s.GetVerificationPolicyBySecurityDomain(ctx, verificationPolicy.SecurityDomain)
 	if err != nil {
@@ -130,23 +138,37 @@ func resolvePolicy(s *SmartContract, ctx contractapi.TransactionContextInterface
 	if err != nil {
 		return nil, fmt.Errorf("Failed to unmarshal verification policy: %s", err.Error())
 	}
-	currentBestMatch := &common.Identifier{}
+	// The most specific pattern matching the view address determines the policy
+	patterns := make([]string, len(verificationPolicy.Identifiers))
+	for i, identifier := range verificationPolicy.Identifiers {
+		patterns[i] = identifier.GetPattern()
+	}
+	log.Debugf("Resolving verification policy: %s", patternmatcher.Explain(patterns, viewAddress))
+	bestMatch := patternmatcher.BestMatch(patterns, viewAddress)
+	if bestMatch < 0 {
+		return nil, fmt.Errorf("Verification Policy Error: Failed to find verification policy matching view address: %s", viewAddress)
+	}
+	currentBestMatch := verificationPolicy.Identifiers[bestMatch]
+	// policies may have been recorded before they were validated on write
+	if err := validatePolicy(currentBestMatch.Policy); err != nil {
+		return nil, fmt.Errorf("Verification Policy Error: Invalid policy for view address %s: %s", viewAddress, err.Error())
+	}
+	return currentBestMatch.Policy, nil
+}
+
+// validateVerificationPolicy checks that every identifier in a VerificationPolicy has a valid pattern
+// and a well-formed policy
+func validateVerificationPolicy(verificationPolicy *common.VerificationPolicy) error {
 	for _, identifier := range verificationPolicy.Identifiers {
-		// short circuit if there is an exact match
-		if identifier.Pattern == viewAddress {
-			return identifier.Policy, nil
+		if identifier == nil {
+			return fmt.Errorf("identifier is missing")
 		}
-
-		// check if the identifier pattern is valid, that it matches the address and it's longer (i.e. more specific) than the currentBestMatch
-		if validPatternString(identifier.Pattern) && isPatternAndAddressMatch(identifier.Pattern, viewAddress) && len(identifier.Pattern) > len(currentBestMatch.Pattern) {
-			currentBestMatch = identifier
+		if err := patternmatcher.Validate(identifier.Pattern); err != nil {
+			return fmt.Errorf("identifier pattern '%s' is not valid: %s", identifier.Pattern, err.Error())
+		}
+		if err := validatePolicy(identifier.Policy); err != nil {
+			return fmt.Errorf("identifier '%s': %s", identifier.Pattern, err.Error())
 		}
 	}
-
-	// return the bestMatch if there was one
-	if currentBestMatch.Pattern != "" {
-		return currentBestMatch.Policy, nil
-	}
-
-	return nil, fmt.Errorf("Verification Policy Error: Failed to find verification policy matching view address: %s", viewAddress)
+	return nil
 }
@@ -73,6 +73,26 @@ func TestCreateVerificationPolicy(t *testing.T) {
 	err = interopcc.CreateVerificationPolicy(ctx, string(verificationPolicyBytes))
 	require.EqualError(t, err, fmt.Sprintf("VerificationPolicy already exists with id: %s", verificationPolicyAsset.SecurityDomain))
 
+	// Malformed policy expression
+	invalidVerificationPolicy := common.VerificationPolicy{
+		SecurityDomain: "2345",
+		Identifiers: []*common.Identifier{{
+			Pattern: "Identifier",
+			Policy:  &common.Policy{Type: "expression", Criteria: []string{"Org1MSP &&"}},
+		}},
+	}
+	invalidVerificationPolicyBytes, err := json.Marshal(&invalidVerificationPolicy)
+	require.NoError(t, err)
+	err = interopcc.CreateVerificationPolicy(ctx, string(invalidVerificationPolicyBytes))
+	require.EqualError(t, err, "Invalid verification policy: identifier 'Identifier': invalid policy expression 'Org1MSP &&': expected identifier, 'count' or '(' at end of expression")
+	// Invalid identifier pattern
+	invalidVerificationPolicy.Identifiers[0].Pattern = "Identifier:[*"
+	invalidVerificationPolicy.Identifiers[0].Policy.Criteria = []string{"count >= 2"}
+	invalidVerificationPolicyBytes, err = json.Marshal(&invalidVerificationPolicy)
+	require.NoError(t, err)
+	err = interopcc.CreateVerificationPolicy(ctx, string(invalidVerificationPolicyBytes))
+	require.EqualError(t, err, "Invalid verification policy: identifier pattern 'Identifier:[*' is not valid: unterminated character class at position 11")
+
 }
 
 func TestUpdateVerificationPolicy(t *testing.T) {
@@ -128,3 +148,59 @@ func TestDeleteVerificationPolicy(t *testing.T) {
 	err = interopcc.DeleteVerificationPolicy(ctx, "2343")
 	require.EqualError(t, err, fmt.Sprintf("unable to retrieve asset"))
 }
+
+func TestResolvePolicy(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	interopcc := SmartContract{}
+
+	verificationPolicy := common.VerificationPolicy{
+		SecurityDomain: "network1",
+		Identifiers: []*common.Identifier{
+			{Pattern: "mychannel:*", Policy: &common.Policy{Type: "signature", Criteria: []string{"Org1MSP"}}},
+			{Pattern: "mychannel:simplestate:Read:**", Policy: &common.Policy{Type: "signature", Criteria: []string{"Org2MSP"}}},
+			{Pattern: "mychannel:simplestate:Read:key[0-9]", Policy: &common.Policy{Type: "signature", Criteria: []string{"Org3MSP"}}},
+			{Pattern: "mychannel:simplestate:Read:key1", Policy: &common.Policy{Type: "signature", Criteria: []string{"Org4MSP"}}},
+		},
+	}
+	verificationPolicyBytes, err := json.Marshal(&verificationPolicy)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(verificationPolicyBytes, nil)
+
+	// The most specific matching pattern determines the policy
+	for address, signer := range map[string]string{
+		"mychannel:simplestate:Read:key1": "Org4MSP",
+		"mychannel:simplestate:Read:key2": "Org3MSP",
+		"mychannel:simplestate:Read:a:b":  "Org2MSP",
+		"mychannel:simplestate:Write:a":   "Org1MSP",
+	} {
+		resolvedPolicy, err := resolvePolicy(&interopcc, ctx, "network1", address)
+		require.NoError(t, err)
+		require.Equal(t, []string{signer}, resolvedPolicy.Criteria, address)
+	}
+	_, err = resolvePolicy(&interopcc, ctx, "network1", "otherchannel:simplestate:Read:a")
+	require.EqualError(t, err, "Verification Policy Error: Failed to find verification policy matching view address: otherchannel:simplestate:Read:a")
+
+	// Views are matched by their canonical view segment, however their address is encoded
+	verificationPolicy.Identifiers = verificationPolicy.Identifiers[3:]
+	verificationPolicyBytes, err = json.Marshal(&verificationPolicy)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(verificationPolicyBytes, nil)
+	view := &common.View{Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: "Unknown"}}
+	for _, address := range []string{"localhost:9080/network1/mychannel:simplestate:Read:key1",
+		"localhost:9080/network1/@v2:mychannel:simplestate:Read:%6Bey1", "localhost:9080/network1/@v2:mychannel:simplestate:Read:key%31"} {
+		_, err = verifyView(&interopcc, ctx, view, address)
+		require.EqualError(t, err, "Proof type not supported: Unknown", address)
+	}
+	_, err = verifyView(&interopcc, ctx, view, "localhost:9080/network1/mychannel:simplestate:Read:key2")
+	require.ErrorContains(t, err, "Unable to resolve verification policy")
+
+	// Patterns with a literal '%' keep matching the plain view segments they were written for
+	verificationPolicy.Identifiers[0].Pattern = "mychannel:simplestate:Read:100%"
+	verificationPolicyBytes, err = json.Marshal(&verificationPolicy)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturns(verificationPolicyBytes, nil)
+	_, err = verifyView(&interopcc, ctx, view, "localhost:9080/network1/mychannel:simplestate:Read:100%")
+	require.EqualError(t, err, "Proof type not supported: Unknown")
+	_, err = verifyView(&interopcc, ctx, view, "localhost:9080/network1/@v2:mychannel:simplestate:Read:100%25")
+	require.ErrorContains(t, err, "Unable to resolve verification policy")
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/corda"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/fabric"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/viewaddress"
 	protoV2 "google.golang.org/protobuf/proto"
 )
 
@@ -29,55 +30,98 @@ type interop interface {
 	WriteExternalState(state string) error
 }
 
-// Extract data (i.e., query response) from view
-// TODO - Also take verification policy as parameter and determine if enough matching responses exist (current logic mandates unanimity among payloads)
-func ExtractAndValidateDataFromView(view *common.View, b64ViewContentList []string) ([]byte, error) {
+// Extract the interop payloads generated by each endorser or notary from a view, along with the identity (organization or
+// notary) of the signer of each payload and, for Fabric endorsers, its certificate. A Besu view carries a single payload,
+// attested by the validators that sealed the block rather than by a signer of its own, so no signers are returned for it.
+func getInteropPayloadsFromView(view *common.View) ([]*common.InteropPayload, []policySigner, error) {
 	var interopPayloadList []*common.InteropPayload
+	var signerList []policySigner
 	if view.Meta.Protocol == common.Meta_FABRIC {
 		var fabricViewData fabric.FabricView
 		err := protoV2.Unmarshal(view.Data, &fabricViewData)
 		if err != nil {
-			return nil, fmt.Errorf("FabricView Unmarshal error: %s", err)
+			return nil, nil, fmt.Errorf("FabricView Unmarshal error: %s", err)
 		}
 		interopPayloadList = make([]*common.InteropPayload, len(fabricViewData.EndorsedProposalResponses))
+		signerList = make([]policySigner, len(fabricViewData.EndorsedProposalResponses))
 		for i, endorsedProposalResponse := range fabricViewData.EndorsedProposalResponses {
 			var chaincodeAction peer.ChaincodeAction
 			err = proto.Unmarshal(endorsedProposalResponse.Payload.Extension, &chaincodeAction)
 			if err != nil {
-				return nil, fmt.Errorf("Unable to Unmarshal ChaincodeAction: %s", err.Error())
+				return nil, nil, fmt.Errorf("Unable to Unmarshal ChaincodeAction: %s", err.Error())
 			}
 			var interopPayload common.InteropPayload
 			err = protoV2.Unmarshal(chaincodeAction.Response.Payload, &interopPayload)
 			if err != nil {
-				return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+				return nil, nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+			}
+			var serialisedIdentity msp.SerializedIdentity
+			err = proto.Unmarshal(endorsedProposalResponse.Endorsement.Endorser, &serialisedIdentity)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Unable to Unmarshal endorser identity: %s", err.Error())
 			}
 			interopPayloadList[i] = &interopPayload
+			signerList[i] = policySigner{id: serialisedIdentity.Mspid}
+			// The endorser certificates are validated when verifying the view; an endorser whose certificate cannot be
+			// parsed here only holds the member role of its MSP
+			if x509Cert, err := parseCert(string(serialisedIdentity.IdBytes)); err == nil {
+				signerList[i].cert = x509Cert
+			}
 		}
 	} else if view.Meta.Protocol == common.Meta_CORDA {
 		var cordaViewData corda.ViewData
 		err := protoV2.Unmarshal(view.Data, &cordaViewData)
 		if err != nil {
-			return nil, fmt.Errorf("CordaView Unmarshal error: %s", err)
+			return nil, nil, fmt.Errorf("CordaView Unmarshal error: %s", err)
 		}
 		interopPayloadList = make([]*common.InteropPayload, len(cordaViewData.NotarizedPayloads))
+		signerList = make([]policySigner, len(cordaViewData.NotarizedPayloads))
 		for i, notarizedPayload := range cordaViewData.NotarizedPayloads {
 			var interopPayload common.InteropPayload
 			err = protoV2.Unmarshal(notarizedPayload.Payload, &interopPayload)
 			if err != nil {
-				return nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
+				return nil, nil, fmt.Errorf("Unable to Unmarshal interopPayload: %s", err.Error())
 			}
 			interopPayloadList[i] = &interopPayload
+			signerList[i] = policySigner{id: notarizedPayload.Id}
+		}
+	} else if view.Meta.Protocol == common.Meta_ETHEREUM {
+		// A Besu view carries a single interop payload, in the log proven to be part of a block sealed by the validators
+		interopPayload, err := getBesuInteropPayload(view.Data)
+		if err != nil {
+			return nil, nil, err
 		}
+		interopPayloadList = []*common.InteropPayload{interopPayload}
 	} else {
-		return nil, fmt.Errorf("Cannot extract data from view; unsupported DLT type: %+v", view.Meta.Protocol)
+		return nil, nil, fmt.Errorf("Cannot extract data from view; unsupported DLT type: %+v", view.Meta.Protocol)
+	}
+	return interopPayloadList, signerList, nil
+}
+
+// Extract data (i.e., query response) from view
+// Payloads are grouped by content, and the payload whose signers satisfy the verification policy is accepted, so that
+// a single endorser or notary returning a different (e.g., stale) payload does not cause the view to be rejected.
+// Signers that returned a different payload than the accepted one are reported.
+func ExtractAndValidateDataFromView(view *common.View, b64ViewContentList []string, verificationPolicy *common.Policy) ([]byte, error) {
+	interopPayloadList, signerList, err := getInteropPayloadsFromView(view)
+	if err != nil {
+		return nil, err
 	}
 
 	var payloadConfidential bool
-	var viewPayload []byte
+	viewPayloadList := make([][]byte, len(interopPayloadList))
 	for i, interopPayload := range interopPayloadList {
 		// If view data is encrypted, match it to supplied decrypted data using the hash in the view payload
 		if interopPayload.Confidential {
 			// Unmarshal the (decrypted) confidential payload contents supplied by the caller
+			if i == 0 {
+				if len(b64ViewContentList) != len(interopPayloadList) {
+					return nil, fmt.Errorf("Number of decrypted payloads (%d) does not match number of view contents (%d)", len(b64ViewContentList), len(interopPayloadList))
+				}
+				payloadConfidential = true
+			} else if !payloadConfidential {
+				return nil, fmt.Errorf("Mismatching confidentiality flags among interop payloads")
+			}
 			viewB64ContentBytes, err := base64.StdEncoding.DecodeString(b64ViewContentList[i])
 			if err != nil {
 				return nil, fmt.Errorf("Unable to base64 decode decrypted view content: %s", err.Error())
@@ -92,17 +136,6 @@ func ExtractAndValidateDataFromView(view *common.View, b64ViewContentList []stri
 			if err != nil {
 				return nil, fmt.Errorf("ConfidentialPayload Unmarshal error: %s", err)
 			}
-			if i == 0 {
-				if len(b64ViewContentList) != len(interopPayloadList) {
-					return nil, fmt.Errorf("Number of decrypted payloads (%d) does not match number of view contents (%d)", len(b64ViewContentList), len(interopPayloadList))
-				}
-				payloadConfidential = true
-				viewPayload = confidentialPayloadContents.Payload
-			} else if !payloadConfidential {
-				return nil, fmt.Errorf("Mismatching confidentiality flags among interop payloads")
-			} else if !bytes.Equal(viewPayload, confidentialPayloadContents.Payload) {
-				return nil, fmt.Errorf("Mismatching payloads in proposal responses: 0 - %+v, %d - %+v", viewPayload, i, confidentialPayloadContents.Payload)
-			}
 			if confidentialPayload.HashType == common.ConfidentialPayload_HMAC {
 				payloadHMAC := hmac.New(sha256.New, confidentialPayloadContents.Random)
 				payloadHMAC.Write(confidentialPayloadContents.Payload)
@@ -113,22 +146,109 @@ func ExtractAndValidateDataFromView(view *common.View, b64ViewContentList []stri
 			} else {
 				return nil, fmt.Errorf("Unsupported hash type in interop view payload: %+v", confidentialPayload.HashType)
 			}
+			viewPayloadList[i] = confidentialPayloadContents.Payload
 		} else {
 			if i == 0 {
-				viewPayload = interopPayload.Payload
 				payloadConfidential = false
 			} else if payloadConfidential {
 				return nil, fmt.Errorf("Mismatching confidentiality flags among interop payloads")
-			} else if !bytes.Equal(viewPayload, interopPayload.Payload) {
-				return nil, fmt.Errorf("Mismatching payloads in proposal responses: 0 - %+v, %d - %+v", viewPayload, i, interopPayload.Payload)
 			}
+			viewPayloadList[i] = interopPayload.Payload
+		}
+	}
+	if len(viewPayloadList) == 0 {
+		return nil, fmt.Errorf("View contains no interop payloads")
+	}
+
+	// Group the payloads by content, in the order in which they first appear in the view
+	var payloadGroups [][]byte
+	var payloadGroupSigners [][]policySigner
+	for i, viewPayload := range viewPayloadList {
+		j := 0
+		for j < len(payloadGroups) && !bytes.Equal(payloadGroups[j], viewPayload) {
+			j++
+		}
+		if j == len(payloadGroups) {
+			payloadGroups = append(payloadGroups, viewPayload)
+			payloadGroupSigners = append(payloadGroupSigners, []policySigner{})
+		}
+		if signerList != nil {
+			payloadGroupSigners[j] = append(payloadGroupSigners[j], signerList[i])
 		}
 	}
-	return viewPayload, nil
+
+	// Accept the payload whose signers satisfy the verification policy
+	acceptedGroup := -1
+	for j := range payloadGroups {
+		// A view without signers of individual payloads carries a single payload, attested by the view's proof as a whole
+		if signerList != nil && verifySignersSatisfyPolicy(verificationPolicy, payloadGroupSigners[j]) != nil {
+			continue
+		}
+		if acceptedGroup >= 0 {
+			return nil, fmt.Errorf("Conflicting payloads returned by %v and %v both satisfy the verification policy", policySignerIDs(payloadGroupSigners[acceptedGroup]), policySignerIDs(payloadGroupSigners[j]))
+		}
+		acceptedGroup = j
+	}
+	if acceptedGroup < 0 {
+		payloadGroupSignerIDs := make([][]string, len(payloadGroupSigners))
+		for j := range payloadGroupSigners {
+			payloadGroupSignerIDs[j] = policySignerIDs(payloadGroupSigners[j])
+		}
+		return nil, fmt.Errorf("Mismatching payloads returned by %v; no payload satisfies the verification policy", payloadGroupSignerIDs)
+	}
+	if len(payloadGroups) > 1 {
+		dissentingSigners := []string{}
+		for j := range payloadGroups {
+			if j != acceptedGroup {
+				dissentingSigners = append(dissentingSigners, policySignerIDs(payloadGroupSigners[j])...)
+			}
+		}
+		log.Warnf("Payloads returned by %v do not match the payload accepted for the view, returned by %v", dissentingSigners, policySignerIDs(payloadGroupSigners[acceptedGroup]))
+	}
+	return payloadGroups[acceptedGroup], nil
 }
 
-// Validate view against address, and extract data (i.e., query response) from view
-func (s *SmartContract) ParseAndValidateView(ctx contractapi.TransactionContextInterface, address, b64ViewProto string, b64ViewContentList []string) (string, error) {
+// validateViewBinding checks that every interop payload in the view was generated in response to a query
+// for the given address and nonce, made by a requestor whose certificate matches the submitter of this
+// transaction. This prevents a view obtained by one client from being submitted by another.
+func validateViewBinding(ctx contractapi.TransactionContextInterface, view *common.View, address, nonce string) error {
+	if nonce == "" {
+		return fmt.Errorf("Expected nonce is empty")
+	}
+	interopPayloadList, _, err := getInteropPayloadsFromView(view)
+	if err != nil {
+		return err
+	}
+	if len(interopPayloadList) == 0 {
+		return fmt.Errorf("View contains no interop payloads")
+	}
+	submitterCert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return fmt.Errorf("Unable to get transaction submitter certificate: %s", err.Error())
+	}
+	if submitterCert == nil {
+		return fmt.Errorf("Unable to get transaction submitter certificate")
+	}
+	for i, interopPayload := range interopPayloadList {
+		if interopPayload.Address != address {
+			return fmt.Errorf("Address in interop payload %d does not match original address: Original: %s Payload: %s", i, address, interopPayload.Address)
+		}
+		if interopPayload.Nonce != nonce {
+			return fmt.Errorf("Nonce in interop payload %d does not match expected nonce: Expected: %s Payload: %s", i, nonce, interopPayload.Nonce)
+		}
+		requestorCert, err := parseCert(interopPayload.RequestorCertificate)
+		if err != nil {
+			return fmt.Errorf("Unable to parse requestor certificate in interop payload %d: %s", i, err.Error())
+		}
+		if !bytes.Equal(requestorCert.Raw, submitterCert.Raw) {
+			return fmt.Errorf("Requestor certificate in interop payload %d does not match transaction submitter", i)
+		}
+	}
+	return nil
+}
+
+// Validate view against address and the nonce of the query that produced it, and extract data (i.e., query response) from view
+func (s *SmartContract) ParseAndValidateView(ctx contractapi.TransactionContextInterface, address, b64ViewProto string, b64ViewContentList []string, nonce string) (string, error) {
 	viewB64Bytes, err := base64.StdEncoding.DecodeString(b64ViewProto)
 	if err != nil {
 		return "", fmt.Errorf("Unable to base64 decode data: %s", err.Error())
@@ -140,14 +260,20 @@ func (s *SmartContract) ParseAndValidateView(ctx contractapi.TransactionContextI
 	}
 
 	// 1. Verify proof
-	err = s.VerifyView(ctx, b64ViewProto, address)
+	verificationPolicy, err := verifyView(s, ctx, &view, address)
 	if err != nil {
 		log.Errorf("Proof obtained from foreign network for query '%s' is INVALID", address)
 		return "", fmt.Errorf("VerifyView error: %s", err)
 	}
 
-	// 2. Extract response data for consumption by application chaincode
-	viewData, err := ExtractAndValidateDataFromView(&view, b64ViewContentList)
+	// 2. Verify that the view was requested by the submitter of this transaction using the given nonce
+	err = validateViewBinding(ctx, &view, address, nonce)
+	if err != nil {
+		return "", fmt.Errorf("View binding error: %s", err)
+	}
+
+	// 3. Extract response data for consumption by application chaincode
+	viewData, err := ExtractAndValidateDataFromView(&view, b64ViewContentList, verificationPolicy)
 	if err != nil {
 		return "", err
 	}
@@ -157,9 +283,10 @@ func (s *SmartContract) ParseAndValidateView(ctx contractapi.TransactionContextI
 }
 
 // WriteExternalState flow is used to process a response from a foreign network for state.
-// 1. Verify Proofs that are returned
-// 2. Call application chaincode
-func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInterface, applicationID string, applicationChannel string, applicationFunction string, applicationArgs []string, argIndicesForSubstitution []int, addresses []string, b64ViewProtos []string, b64ViewContents [][]string) error {
+// 1. Verify Proofs that are returned, and that they were produced for queries made by the submitter with the given nonces
+// 2. Check that the nonces are fresh and have not been used before by the submitter, and record them
+// 3. Call application chaincode
+func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInterface, applicationID string, applicationChannel string, applicationFunction string, applicationArgs []string, argIndicesForSubstitution []int, addresses []string, b64ViewProtos []string, b64ViewContents [][]string, nonces []string) error {
 	if len(argIndicesForSubstitution) != len(addresses) {
 		return fmt.Errorf("Number of argument indices for substitution (%d) does not match number of addresses (%d)", len(argIndicesForSubstitution), len(addresses))
 	}
@@ -169,6 +296,9 @@ func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInt
 	if len(addresses) != len(b64ViewContents) {
 		return fmt.Errorf("Number of addresses (%d) does not match number of view contents (%d)", len(addresses), len(b64ViewContents))
 	}
+	if len(addresses) != len(nonces) {
+		return fmt.Errorf("Number of addresses (%d) does not match number of nonces (%d)", len(addresses), len(nonces))
+	}
 
 	arr := append([]string{applicationFunction}, applicationArgs...)
 
@@ -179,7 +309,7 @@ func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInt
 			return fmt.Errorf("Index %d out of bounds of array (length %d)", argIndex, len(applicationArgs))
 		}
 		// Validate proof and extract view data
-		viewData, err := s.ParseAndValidateView(ctx, addresses[i], b64ViewProtos[i], b64ViewContents[i])
+		viewData, err := s.ParseAndValidateView(ctx, addresses[i], b64ViewProtos[i], b64ViewContents[i], nonces[i])
 		if err != nil {
 			return err
 		}
@@ -187,7 +317,24 @@ func (s *SmartContract) WriteExternalState(ctx contractapi.TransactionContextInt
 		arr[argIndex + 1] = viewData        // First argument is the CC function name
 	}
 
-	// 2. Call application chaincode with created state as the argument
+	// 2. Check and record the nonces of the queries, so that the views cannot be replayed
+	securityDomains := make([]string, len(addresses))
+	for i, address := range addresses {
+		addressStruct, err := parseAddress(address)
+		if err != nil {
+			return err
+		}
+		securityDomains[i] = addressStruct.LedgerSegment
+	}
+	submitterCert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return fmt.Errorf("Unable to get transaction submitter certificate: %s", err.Error())
+	}
+	if err := checkAndRecordViewNonces(s, ctx, securityDomains, submitterCert, nonces); err != nil {
+		return fmt.Errorf("Replay check failed: %s", err.Error())
+	}
+
+	// 3. Call application chaincode with created state as the argument
 	byteArgs := strArrToBytesArr(arr)
 	log.Info(fmt.Sprintf("Calling invoke chaincode. AppId: %s, appChannel: %s", applicationID, applicationChannel))
 	pbResp := ctx.GetStub().InvokeChaincode(applicationID, byteArgs, applicationChannel)
@@ -212,27 +359,37 @@ func (s *SmartContract) VerifyView(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return fmt.Errorf("View Unmarshal error: %s", err)
 	}
+	_, err = verifyView(s, ctx, &view, address)
+	return err
+}
+
+// verifyView verifies a view against the verification policy for the network and view address, and returns the policy
+func verifyView(s *SmartContract, ctx contractapi.TransactionContextInterface, view *common.View, address string) (*common.Policy, error) {
 	addressStruct, err := parseAddress(address)
 	if err != nil {
-		return fmt.Errorf("Unable to parse address: %s", err.Error())
+		return nil, fmt.Errorf("Unable to parse address: %s", err.Error())
 	}
-	// Find the verification policy for the network and view.
-	verificationPolicy, err := resolvePolicy(s, ctx, addressStruct.LedgerSegment, addressStruct.ViewSegment)
+	// Find the verification policy for the network and view, matching the canonical view segment.
+	canonicalViewSegment, err := viewaddress.Canonical(addressStruct.ViewSegment)
 	if err != nil {
-		return fmt.Errorf("Unable to resolve verification policy: %s", err.Error())
+		return nil, fmt.Errorf("Unable to parse address: %s", err.Error())
+	}
+	verificationPolicy, err := resolvePolicy(s, ctx, addressStruct.LedgerSegment, canonicalViewSegment)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resolve verification policy: %s", err.Error())
 	}
 	switch view.Meta.Protocol {
 	case common.Meta_CORDA:
 		switch view.Meta.ProofType {
 		case "Notarization":
-			return verifyCordaNotarization(s, ctx, view.Data, verificationPolicy, addressStruct.LedgerSegment, address)
+			err = verifyCordaNotarization(s, ctx, view.Data, verificationPolicy, addressStruct.LedgerSegment, address)
 		default:
-			return fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
+			return nil, fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
 		}
 	case common.Meta_FABRIC:
 		switch view.Meta.ProofType {
 		case "Notarization":
-			return verifyFabricNotarization(
+			err = verifyFabricNotarization(
 				s,
 				ctx,
 				view.Data,
@@ -240,13 +397,23 @@ func (s *SmartContract) VerifyView(ctx contractapi.TransactionContextInterface,
 				addressStruct.LedgerSegment,
 				address)
 		default:
-			return fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
+			return nil, fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
+		}
+	case common.Meta_ETHEREUM:
+		switch view.Meta.ProofType {
+		case "Notarization":
+			err = verifyBesuNotarization(s, ctx, view.Data, verificationPolicy, addressStruct.LedgerSegment, address)
+		default:
+			return nil, fmt.Errorf("Proof type not supported: %s", view.Meta.ProofType)
 		}
 	default:
-		return fmt.Errorf("Verification Error: Unrecognised protocol %s", view.Meta.Protocol)
+		return nil, fmt.Errorf("Verification Error: Unrecognised protocol %s", view.Meta.Protocol)
 	}
-
-	// TODO: Somewhere, we need to validate the requestor certificate and the nonce within the InteropPayload
+	if err != nil {
+		return nil, err
+	}
+	// The requestor certificate and nonce within the InteropPayload are validated in ParseAndValidateView
+	return verificationPolicy, nil
 }
 
 // The verifyCordaNotarization function is used to verify views that come from a Corda network
@@ -254,7 +421,7 @@ func (s *SmartContract) VerifyView(ctx contractapi.TransactionContextInterface,
 //
 // Verification requires the following steps:
 // 1. Create [CordaViewData] from the view.
-// 2. TODO: Verify address in payload is the same as original address
+// 2. Verify address in payload is the same as original address
 // 3. Verify each of the signatures in the Notarization array according to the data bytes and certificate.
 // 4. Check the certificates are valid according to the Membership.
 // 5. Check the notarizations fulfill the verification policy of the request.
@@ -281,6 +448,10 @@ func verifyCordaNotarization(s *SmartContract, ctx contractapi.TransactionContex
 		if err != nil {
 			return fmt.Errorf("Unable to decode corda view data: %s", err.Error())
 		}
+		// 2. Verify address in payload is the same as original address
+		if address != interopPayload.Address {
+			return fmt.Errorf("Address in response does not match original address: Original: %s Response: %s", address, interopPayload.Address)
+		}
 		decodedSignature, err := base64.StdEncoding.DecodeString(value.Signature)
 		if err != nil {
 			return fmt.Errorf("Corda signature could not be decoded from base64: %s", err.Error())
@@ -298,11 +469,9 @@ func verifyCordaNotarization(s *SmartContract, ctx contractapi.TransactionContex
 	}
 
 	// 5. Check the notarizations fulfill the verification policy of the request.
-	requiredSigners := verificationPolicy.Criteria
-	for _, signer := range requiredSigners {
-		if !Contains(signerList, signer) {
-			return fmt.Errorf("Notarizations missing signer: %s", signer)
-		}
+	err = verifyPolicySatisfied(verificationPolicy, signerList)
+	if err != nil {
+		return err
 	}
 	log.Infof("Proof associated with response '%s' from Corda network for query '%s' is VALID", string(viewPayload), address)
 	return nil
@@ -325,7 +494,7 @@ func verifyFabricNotarization(s *SmartContract, ctx contractapi.TransactionConte
 	if err != nil {
 		return fmt.Errorf("Unable to decode fabric view data: %s", err.Error())
 	}
-	signerList := []string{}
+	signerList := []policySigner{}
 	var viewPayload []byte
 	for i, endorsedProposalResponse := range fabricViewData.EndorsedProposalResponses {
 		// 2. Verify address in each proposal response payload is the same as original address
@@ -369,14 +538,12 @@ func verifyFabricNotarization(s *SmartContract, ctx contractapi.TransactionConte
 		if err != nil {
 			return fmt.Errorf("Verify membership failed. Certificate not valid: %s", err.Error())
 		}
-		signerList = append(signerList, org)
+		signerList = append(signerList, policySigner{id: org, cert: x509Cert})
 	}
 	// 5. Check the notarizations fulfill the verification policy of the request.
-	requiredSigners := verificationPolicy.Criteria
-	for _, signer := range requiredSigners {
-		if !Contains(signerList, signer) {
-			return fmt.Errorf("Notarizations missing signer: %s", signer)
-		}
+	err = verifySignersSatisfyPolicy(verificationPolicy, signerList)
+	if err != nil {
+		return err
 	}
 	log.Infof("Proof associated with response '%s' from Fabric network for query '%s' is VALID", string(viewPayload), address)
 	return nil
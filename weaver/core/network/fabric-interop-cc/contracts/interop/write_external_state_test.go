@@ -9,14 +9,23 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"testing"
 	"io/ioutil"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-protos-go/msp"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/require"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/corda"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/fabric"
+	protoV2 "google.golang.org/protobuf/proto"
 	wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils/mocks"
 )
 
 
@@ -69,6 +78,15 @@ func TestWriteExternalState(t *testing.T) {
 	json.Unmarshal(fabricTestDataBytes_1_Org, &fabricTestData_1_Org)
 	json.Unmarshal(fabricTestDataBytes_2_Orgs, &fabricTestData_2_Orgs)
 
+	// nonces and requestor certificate of the queries that produced the test views
+	var fabricNonce_1_Org = "47252321-f79e-44d8-97d9-5021aaba281c"
+	var fabricNonce_1_Org_Confidential = "d12ba8f3-4645-4bac-97de-882496acc047"
+	var fabricNonce_2_Orgs = "2f71aab4-acb3-4a33-894b-145b437a4822"
+	var fabricNonce_2_Orgs_Confidential = "a46c8e73-72a8-4905-a717-b1602f5690b9"
+	var fabricRequestorCert, _ = ioutil.ReadFile("./test_data/fabric_requestor_cert.pem")
+	var cordaNonce = "1697030400:ZjE2ZjMxMjItNGE3Mi00"
+	var cordaViewAddress = "localhost:9080/Corda_Network/localhost:10006#com.cordaSimpleApplication.flow.GetStateByKey:H"
+
 	var fabricCaCertNetwork1, _ = ioutil.ReadFile("./test_data/fabric_cacert_org1.pem")
 
 	var network1Member = common.Member{
@@ -119,6 +137,7 @@ func TestWriteExternalState(t *testing.T) {
 
 	// Happy case: Fabric: 1 Org
 	ctx, chaincodeStub := wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
 	interopcc := SmartContract{}
 	// mock all the calls to the chaincode stub
 	network1VerificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy_1_Org)
@@ -136,34 +155,45 @@ func TestWriteExternalState(t *testing.T) {
 	decContents := []string{""}
 	decContentsList := make([][]string, 1)
 	decContentsList[0] = decContents
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList, []string{fabricNonce_1_Org})
 	require.NoError(t, err)
 
 	// Test success with encrypted view payload
-	chaincodeStub.GetStateReturnsOnCall(2, network1VerificationPolicyBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(3, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(4, network1VerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(5, network1MembershipBytes, nil)
 	decContents = fabricTestData_1_Org.B64ViewContents
 	decContentsList[0] = decContents
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64ViewConfidential}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64ViewConfidential}, decContentsList, []string{fabricNonce_1_Org_Confidential})
 	require.NoError(t, err)
 
+	// Test failure when the view is replayed: its nonce has been recorded by the first transaction
+	chaincodeStub.GetStateReturnsOnCall(8, network1VerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(9, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(11, []byte("1697030400"), nil)
+	decContents = []string{""}
+	decContentsList[0] = decContents
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList, []string{fabricNonce_1_Org})
+	require.EqualError(t, err, "Replay check failed: Query nonce has already been used: "+fabricNonce_1_Org)
+	require.Equal(t, 2, chaincodeStub.InvokeChaincodeCallCount())
+
 	// Test failures when invalid or insufficient arguments are supplied
 	decContents = []string{""}
 	decContentsList[0] = decContents
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{2}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{2}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList, []string{fabricNonce_1_Org})
 	require.EqualError(t, err, "Index 2 out of bounds of array (length 2)")
 
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{0, 1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{0, 1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, decContentsList, []string{fabricNonce_1_Org})
 	require.EqualError(t, err, "Number of argument indices for substitution (2) does not match number of addresses (1)")
 
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{}, decContentsList, []string{fabricNonce_1_Org})
 	require.EqualError(t, err, "Number of addresses (1) does not match number of views (0)")
 
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, [][]string{})
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_1_Org.B64View}, [][]string{}, []string{fabricNonce_1_Org})
 	require.EqualError(t, err, "Number of addresses (1) does not match number of view contents (0)")
 
 	// Happy case: Fabric: 2 Orgs
 	ctx, chaincodeStub = wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
 	interopcc = SmartContract{}
 	// mock all the calls to the chaincode stub
 	network1VerificationPolicyBytes, err = json.Marshal(&network1VerificationPolicy_2_Orgs)
@@ -181,35 +211,36 @@ func TestWriteExternalState(t *testing.T) {
 
 	decContents = []string{"", ""}
 	decContentsList[0] = decContents
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.NoError(t, err)
 
 	// Test success with encrypted view payload
-	chaincodeStub.GetStateReturnsOnCall(3, network1VerificationPolicyBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(4, network1MembershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(5, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(5, network1VerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(6, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(7, network1MembershipBytes, nil)
 	decContents = fabricTestData_2_Orgs.B64ViewContents
 	decContentsList[0] = decContents
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64ViewConfidential}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64ViewConfidential}, decContentsList, []string{fabricNonce_2_Orgs_Confidential})
 	require.NoError(t, err)
 
 	// Test failures when invalid or insufficient arguments are supplied
 	decContents = []string{"", ""}
 	decContentsList[0] = decContents
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{2}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{2}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.EqualError(t, err, "Index 2 out of bounds of array (length 2)")
 
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{0, 1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{0, 1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.EqualError(t, err, "Number of argument indices for substitution (2) does not match number of addresses (1)")
 
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.EqualError(t, err, "Number of addresses (1) does not match number of views (0)")
 
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, [][]string{})
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, [][]string{}, []string{fabricNonce_2_Orgs})
 	require.EqualError(t, err, "Number of addresses (1) does not match number of view contents (0)")
 
-	// Happy case: Corda
+	// Happy case: Corda view verification
 	ctx, chaincodeStub = wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
 	interopcc = SmartContract{}
 	// mock all the calls to the chaincode stub
 	cordaVerificationPolicyBytes, err := json.Marshal(&cordaVerificationPolicy)
@@ -218,17 +249,128 @@ func TestWriteExternalState(t *testing.T) {
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, cordaVerificationPolicyBytes, nil)
 	chaincodeStub.GetStateReturnsOnCall(1, cordaMembershipBytes, nil)
-	chaincodeStub.GetStateReturnsOnCall(2, cordaMembershipBytes, nil)
+	err = interopcc.VerifyView(ctx, cordaTestData.B64View, cordaViewAddress)
+	require.NoError(t, err)
+
+	// Test case: Corda view address does not match the requested address
+	chaincodeStub.GetStateReturnsOnCall(2, cordaVerificationPolicyBytes, nil)
+	err = interopcc.VerifyView(ctx, cordaTestData.B64View, "localhost:9081/Corda_Network/localhost:10006#com.cordaSimpleApplication.flow.GetStateByKey:H")
+	require.EqualError(t, err, "Address in response does not match original address: Original: localhost:9081/Corda_Network/localhost:10006#com.cordaSimpleApplication.flow.GetStateByKey:H Response: " + cordaViewAddress)
+
+	// Test case: Corda view without nonce is rejected
+	chaincodeStub.GetStateReturnsOnCall(3, cordaVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(4, cordaMembershipBytes, nil)
 	chaincodeStub.InvokeChaincodeReturns(peer.Response{
 		Status:  200,
 		Message: "",
 		Payload: []byte("I am a result"),
 	})
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{"localhost:9081/Corda_Network/localhost:10006#com.cordaSimpleApplication.flow.GetStateByKey:H"}, []string{cordaTestData.B64View}, decContentsList)
+	decContentsList[0] = []string{""}
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{cordaViewAddress}, []string{cordaTestData.B64View}, decContentsList, []string{cordaNonce})
+	require.EqualError(t, err, "View binding error: Nonce in interop payload 0 does not match expected nonce: Expected: "+cordaNonce+" Payload: ")
+
+	// Happy case: Corda view requested by the submitter with the given nonce
+	boundCordaView, cordaChain := cordaViewWithBinding(t, cordaTestData.B64View, cordaNonce, string(fabricRequestorCert))
+	boundCordaMembershipBytes, err := json.Marshal(&common.Membership{
+		SecurityDomain: "Corda_Network",
+		Members:        map[string]*common.Member{"PartyA": {Type: "certificate", Chain: cordaChain}},
+	})
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(5, cordaVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(6, boundCordaMembershipBytes, nil)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{cordaViewAddress}, []string{boundCordaView}, decContentsList, []string{cordaNonce})
+	require.NoError(t, err)
+	require.Equal(t, 1, chaincodeStub.InvokeChaincodeCallCount())
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, "[SimpleState(key=H, value=1, owner=O=PartyA, L=London, C=GB, linearId=2314d6b7-1eca-4892-88f8-76d85b8a85cd)]", string(args[2]))
+
+	// Test case: Fabric view submitted with the wrong nonce, by a different client, or without nonces
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
+	interopcc = SmartContract{}
+	chaincodeStub.GetStateReturns(network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(0, network1VerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(3, network1VerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(6, network1VerificationPolicyBytes, nil)
+	decContents = []string{"", ""}
+	decContentsList[0] = decContents
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_1_Org})
+	require.EqualError(t, err, "View binding error: Nonce in interop payload 0 does not match expected nonce: Expected: " + fabricNonce_1_Org + " Payload: " + fabricNonce_2_Orgs)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{""})
+	require.EqualError(t, err, "View binding error: Expected nonce is empty")
+	setClientCertificate(t, ctx, string(fabricCaCertNetwork1))
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
+	require.EqualError(t, err, "View binding error: Requestor certificate in interop payload 0 does not match transaction submitter")
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{})
+	require.EqualError(t, err, "Number of addresses (1) does not match number of nonces (0)")
+
+	// Happy case: Fabric: 2 Orgs with a threshold expression policy
+	ctx, chaincodeStub = wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
+	interopcc = SmartContract{}
+	network1VerificationPolicy_2_Orgs.Identifiers[0].Policy = &common.Policy{
+		Criteria: []string{"(Org1MSP || Org3MSP) && count >= 2"},
+		Type:     "expression",
+	}
+	expressionVerificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy_2_Orgs)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(0, expressionVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(1, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(2, network1MembershipBytes, nil)
+	chaincodeStub.InvokeChaincodeReturns(peer.Response{
+		Status:  200,
+		Message: "",
+		Payload: []byte("I am a result"),
+	})
+	decContents = []string{"", ""}
+	decContentsList[0] = decContents
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.NoError(t, err)
 
+	// Test case: threshold in expression policy not met
+	network1VerificationPolicy_2_Orgs.Identifiers[0].Policy.Criteria = []string{"count >= 3"}
+	expressionVerificationPolicyBytes, err = json.Marshal(&network1VerificationPolicy_2_Orgs)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(5, expressionVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(6, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(7, network1MembershipBytes, nil)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
+	require.EqualError(t, err, "VerifyView error: Notarizations do not satisfy verification policy: count >= 3")
+
+	// Test case: malformed expression policy recorded in the ledger
+	network1VerificationPolicy_2_Orgs.Identifiers[0].Policy.Criteria = []string{"count >="}
+	expressionVerificationPolicyBytes, err = json.Marshal(&network1VerificationPolicy_2_Orgs)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(8, expressionVerificationPolicyBytes, nil)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
+	require.EqualError(t, err, "VerifyView error: Unable to resolve verification policy: Verification Policy Error: Invalid policy for view address " + fabricPattern + ": invalid policy expression 'count >=': expected integer at end of expression")
+
+	// Happy case: Fabric: 2 Orgs with a Fabric signature policy requiring peers of both orgs
+	network1VerificationPolicy_2_Orgs.Identifiers[0].Policy = &common.Policy{
+		Criteria: []string{"OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer', 'Org3MSP.peer')"},
+		Type:     "FabricSignaturePolicy",
+	}
+	fabricSignatureVerificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy_2_Orgs)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(9, fabricSignatureVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(10, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(11, network1MembershipBytes, nil)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
+	require.NoError(t, err)
+
+	// Test case: Fabric signature policy requiring an admin endorsement not met
+	network1VerificationPolicy_2_Orgs.Identifiers[0].Policy.Criteria = []string{"AND('Org1MSP.admin', 'Org2MSP.peer')"}
+	fabricSignatureVerificationPolicyBytes, err = json.Marshal(&network1VerificationPolicy_2_Orgs)
+	require.NoError(t, err)
+	chaincodeStub.GetStateReturnsOnCall(14, fabricSignatureVerificationPolicyBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(15, network1MembershipBytes, nil)
+	chaincodeStub.GetStateReturnsOnCall(16, network1MembershipBytes, nil)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
+	require.EqualError(t, err, "VerifyView error: Notarizations do not satisfy Fabric signature policy: AND('Org1MSP.admin', 'Org2MSP.peer')")
+
 	// Test case: Invalid cert in Membership
 	ctx, chaincodeStub = wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
 	interopcc = SmartContract{}
 	network1Membership_2_Orgs.Members["Org1MSP"].Value = "invalid cert"
 	invalidMembershipBytes, err := json.Marshal(&network1Membership_2_Orgs)
@@ -236,16 +378,126 @@ func TestWriteExternalState(t *testing.T) {
 	chaincodeStub.GetStateReturnsOnCall(0, network1VerificationPolicyBytes, nil)
 	chaincodeStub.GetStateReturnsOnCall(1, invalidMembershipBytes, nil)
 	chaincodeStub.GetStateReturnsOnCall(2, invalidMembershipBytes, nil)
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.EqualError(t, err, "VerifyView error: Verify membership failed. Certificate not valid: Client cert not in a known PEM format")
 
 	// Test case: Invalid policy in verification policy
 	ctx, chaincodeStub = wtest.PrepMockStub()
+	setClientCertificate(t, ctx, string(fabricRequestorCert))
 	interopcc = SmartContract{}
 	network1VerificationPolicy_2_Orgs.Identifiers[0].Pattern = "not matching policy"
 	invalidVerificationPolicyBytes, err := json.Marshal(&network1VerificationPolicy_2_Orgs)
 	require.NoError(t, err)
 	chaincodeStub.GetStateReturnsOnCall(0, invalidVerificationPolicyBytes, nil)
-	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList)
+	err = interopcc.WriteExternalState(ctx, fabricNetwork, "mychannel", "Write", []string{"test-key", ""}, []int{1}, []string{fabricViewAddress}, []string{fabricTestData_2_Orgs.B64View}, decContentsList, []string{fabricNonce_2_Orgs})
 	require.EqualError(t, err, "VerifyView error: Unable to resolve verification policy: Verification Policy Error: Failed to find verification policy matching view address: " + fabricPattern)
 }
+
+func TestExtractAndValidateDataFromView(t *testing.T) {
+	// Org3MSP lags behind and returns a stale value
+	view := fabricViewWithPayloads(t, []string{"Org1MSP", "Org2MSP", "Org3MSP"}, []string{"new value", "new value", "stale value"})
+	decContents := []string{"", "", ""}
+
+	// Happy case: the signers of the majority payload satisfy the verification policy
+	policy := &common.Policy{Criteria: []string{"Org1MSP", "Org2MSP"}, Type: "signature"}
+	viewData, err := ExtractAndValidateDataFromView(view, decContents, policy)
+	require.NoError(t, err)
+	require.Equal(t, "new value", string(viewData))
+
+	policy = &common.Policy{Criteria: []string{"count >= 2"}, Type: "expression"}
+	viewData, err = ExtractAndValidateDataFromView(view, decContents, policy)
+	require.NoError(t, err)
+	require.Equal(t, "new value", string(viewData))
+
+	// Happy case: the payload of a single signer is accepted if its signer alone satisfies the verification policy
+	policy = &common.Policy{Criteria: []string{"Org3MSP"}, Type: "signature"}
+	viewData, err = ExtractAndValidateDataFromView(view, decContents, policy)
+	require.NoError(t, err)
+	require.Equal(t, "stale value", string(viewData))
+
+	// Test case: no payload is returned by signers satisfying the verification policy
+	policy = &common.Policy{Criteria: []string{"Org1MSP", "Org3MSP"}, Type: "signature"}
+	_, err = ExtractAndValidateDataFromView(view, decContents, policy)
+	require.EqualError(t, err, "Mismatching payloads returned by [[Org1MSP Org2MSP] [Org3MSP]]; no payload satisfies the verification policy")
+
+	// Test case: conflicting payloads both satisfy the verification policy
+	policy = &common.Policy{Criteria: []string{"count >= 1"}, Type: "expression"}
+	_, err = ExtractAndValidateDataFromView(view, decContents, policy)
+	require.EqualError(t, err, "Conflicting payloads returned by [Org1MSP Org2MSP] and [Org3MSP] both satisfy the verification policy")
+
+	// Happy case: unanimous payloads
+	view = fabricViewWithPayloads(t, []string{"Org1MSP", "Org2MSP"}, []string{"new value", "new value"})
+	policy = &common.Policy{Criteria: []string{"Org1MSP", "Org2MSP"}, Type: "signature"}
+	viewData, err = ExtractAndValidateDataFromView(view, []string{"", ""}, policy)
+	require.NoError(t, err)
+	require.Equal(t, "new value", string(viewData))
+}
+
+// fabricViewWithPayloads creates a Fabric view with a proposal response endorsed by each organization, carrying the corresponding
+// payload. The endorsements are not signed, so the view can only be used to test data extraction.
+func fabricViewWithPayloads(t *testing.T, orgs []string, payloads []string) *common.View {
+	var fabricView fabric.FabricView
+	for i, org := range orgs {
+		interopPayloadBytes, err := protoV2.Marshal(&common.InteropPayload{Payload: []byte(payloads[i]), Address: "relay-network1:9080/network1/mychannel:simplestate:Read:a"})
+		require.NoError(t, err)
+		chaincodeActionBytes, err := proto.Marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200, Payload: interopPayloadBytes}})
+		require.NoError(t, err)
+		endorserBytes, err := proto.Marshal(&msp.SerializedIdentity{Mspid: org})
+		require.NoError(t, err)
+		fabricView.EndorsedProposalResponses = append(fabricView.EndorsedProposalResponses, &fabric.FabricView_EndorsedProposalResponse{
+			Payload:     &peer.ProposalResponsePayload{Extension: chaincodeActionBytes},
+			Endorsement: &peer.Endorsement{Endorser: endorserBytes},
+		})
+	}
+	fabricViewBytes, err := protoV2.Marshal(&fabricView)
+	require.NoError(t, err)
+	return &common.View{Meta: &common.Meta{Protocol: common.Meta_FABRIC, ProofType: "Notarization"}, Data: fabricViewBytes}
+}
+
+// cordaViewWithBinding binds the interop payloads of a Corda view to the given nonce and requestor certificate, and notarizes
+// them again with a node certificate issued by a new certificate chain, which is returned for use as the member's chain
+func cordaViewWithBinding(t *testing.T, b64View string, nonce string, requestorCertPEM string) (string, []string) {
+	chain, keys, err := generateCertChain(3)
+	require.NoError(t, err)
+	nodeCACert, err := parseCert(chain[2])
+	require.NoError(t, err)
+	nodeCertBytes, nodeKey, err := createX509Certificate(nodeCACert, keys[2])
+	require.NoError(t, err)
+	nodeCertPEM, err := x509CertToPem(nodeCertBytes)
+	require.NoError(t, err)
+
+	viewBytes, err := base64.StdEncoding.DecodeString(b64View)
+	require.NoError(t, err)
+	var view common.View
+	require.NoError(t, protoV2.Unmarshal(viewBytes, &view))
+	var cordaViewData corda.ViewData
+	require.NoError(t, protoV2.Unmarshal(view.Data, &cordaViewData))
+	for _, notarizedPayload := range cordaViewData.NotarizedPayloads {
+		var interopPayload common.InteropPayload
+		require.NoError(t, protoV2.Unmarshal(notarizedPayload.Payload, &interopPayload))
+		interopPayload.Nonce = nonce
+		interopPayload.RequestorCertificate = requestorCertPEM
+		notarizedPayload.Payload, err = protoV2.Marshal(&interopPayload)
+		require.NoError(t, err)
+		hashed, err := computeSHA2Hash(notarizedPayload.Payload, nodeKey.PublicKey.Params().BitSize)
+		require.NoError(t, err)
+		signature, err := ecdsa.SignASN1(rand.Reader, nodeKey, hashed)
+		require.NoError(t, err)
+		notarizedPayload.Signature = base64.StdEncoding.EncodeToString(signature)
+		notarizedPayload.Certificate = nodeCertPEM
+	}
+	view.Data, err = protoV2.Marshal(&cordaViewData)
+	require.NoError(t, err)
+	viewBytes, err = protoV2.Marshal(&view)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(viewBytes), chain
+}
+
+// setClientCertificate makes the mock transaction context return the given certificate as that of the transaction submitter
+func setClientCertificate(t *testing.T, ctx *mocks.TransactionContext, certPEM string) {
+	cert, err := parseCert(certPEM)
+	require.NoError(t, err)
+	clientIdentity := &mocks.ClientIdentity{}
+	clientIdentity.GetX509CertificateReturns(cert, nil)
+	ctx.GetClientIdentityReturns(clientIdentity)
+}
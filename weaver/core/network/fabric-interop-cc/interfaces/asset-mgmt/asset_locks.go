@@ -65,6 +65,19 @@ func (am *AssetManagement) validateInteropccContractId(contractId string) (bool,
     return true, nil
 }
 
+// expiry time is either an epoch time or, with the DURATION time spec, a duration in seconds from the time of locking
+func validateTimeSpec(timeSpec common.TimeSpec, expiryTimeSecs uint64) error {
+    if timeSpec == common.TimeSpec_DURATION {
+        if expiryTimeSecs == 0 {
+            return logThenErrorf("lock duration must be a positive number of seconds")
+        }
+    } else if timeSpec != common.TimeSpec_EPOCH {
+        return logThenErrorf("unsupported time spec: %+v", timeSpec)
+    }
+
+    return nil
+}
+
 func (am *AssetManagement) validateLockInfo(lockInfo *common.AssetLock) error {
     if len(lockInfo.LockInfo) == 0 {
         return logThenErrorf("empty lock info")
@@ -78,8 +91,25 @@ func (am *AssetManagement) validateLockInfo(lockInfo *common.AssetLock) error {
         if len(lockInfoHTLC.HashBase64) == 0 {
             return logThenErrorf("empty lock hash value")
         }
-        if lockInfoHTLC.TimeSpec != common.TimeSpec_EPOCH {
-            return logThenErrorf("only EPOCH time is supported at present")
+        if _, ok := common.HashMechanism_name[int32(lockInfoHTLC.HashMechanism)]; !ok {
+            return logThenErrorf("unsupported hash mechanism: %+v", lockInfoHTLC.HashMechanism)
+        }
+        err = validateTimeSpec(lockInfoHTLC.TimeSpec, lockInfoHTLC.ExpiryTimeSecs)
+        if err != nil {
+            return err
+        }
+    } else if (lockInfo.LockMechanism == common.LockMechanism_SIGNATURE) {
+        lockInfoSignature := &common.AssetLockSignature{}
+        err := proto.Unmarshal(lockInfo.LockInfo, lockInfoSignature)
+        if err != nil {
+            return logThenErrorf(err.Error())
+        }
+        if len(lockInfoSignature.ArbiterPublicKey) == 0 {
+            return logThenErrorf("empty arbiter public key")
+        }
+        err = validateTimeSpec(lockInfoSignature.TimeSpec, lockInfoSignature.ExpiryTimeSecs)
+        if err != nil {
+            return err
         }
     } else {
         return logThenErrorf("unsupported lock mechanism: %+v", lockInfo.LockMechanism)
@@ -101,6 +131,18 @@ func (am *AssetManagement) validateClaimInfo(claimInfo *common.AssetClaim) error
         if len(claimInfoHTLC.HashPreimageBase64) == 0 {
             return logThenErrorf("empty lock hash preimage")
         }
+        if _, ok := common.HashMechanism_name[int32(claimInfoHTLC.HashMechanism)]; !ok {
+            return logThenErrorf("unsupported hash mechanism: %+v", claimInfoHTLC.HashMechanism)
+        }
+    } else if (claimInfo.LockMechanism == common.LockMechanism_SIGNATURE) {
+        claimInfoSignature := &common.AssetClaimSignature{}
+        err := proto.Unmarshal(claimInfo.ClaimInfo, claimInfoSignature)
+        if err != nil {
+            return logThenErrorf(err.Error())
+        }
+        if len(claimInfoSignature.Signature) == 0 {
+            return logThenErrorf("empty arbiter signature")
+        }
     } else {
         return logThenErrorf("unsupported lock mechanism: %+v", claimInfo.LockMechanism)
     }
@@ -327,6 +369,40 @@ func (am *AssetManagement) ClaimFungibleAsset(stub shim.ChaincodeStubInterface,
     return true, nil
 }
 
+// Claim 'numUnits' of the units locked using contractId, leaving the rest locked; returns the number of units that remain locked
+// The caller chaincode should credit the claimed units to the recipient, and unlock the remaining units (if any) for the locker after expiry
+func (am *AssetManagement) ClaimFungibleAssetUnits(stub shim.ChaincodeStubInterface, contractId string, numUnits uint64, claimInfo *common.AssetClaim) (uint64, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return 0, err
+    }
+    if numUnits == 0 {
+        return 0, logThenErrorf("number of units to claim must be a positive number")
+    }
+
+    err = am.validateClaimInfo(claimInfo)
+    if err != nil {
+	return 0, err
+    }
+
+    claimInfoBytes, err := proto.Marshal(claimInfo)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+    claimInfoBytes64 := base64.StdEncoding.EncodeToString(claimInfoBytes)
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("ClaimFungibleAssetUnits"), []byte(contractId), []byte(strconv.FormatUint(numUnits, 10)), []byte(claimInfoBytes64)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return 0, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    remainingUnits, err := strconv.ParseUint(string(iccResp.Payload), 10, 64)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+    fmt.Printf("%d units of fungible asset locked using contractId %s are claimed; %d units remain locked\n", numUnits, contractId, remainingUnits)
+    return remainingUnits, nil
+}
+
 func (am *AssetManagement) ClaimAssetUsingContractId(stub shim.ChaincodeStubInterface, contractId string, claimInfo *common.AssetClaim) (bool, error) {
     _, err := am.validateInteropccContractId(contractId)
     if err != nil {
@@ -406,6 +482,265 @@ func (am *AssetManagement) UnlockAssetUsingContractId(stub shim.ChaincodeStubInt
 }
 
 
+// Basket lock functions: several assets are locked under a single hash lock and contractId, and claimed or unlocked together
+
+func (am *AssetManagement) LockBasket(stub shim.ChaincodeStubInterface, basketAgreement *common.BasketAssetExchangeAgreement, lockInfo *common.AssetLock) (string, error) {
+    if len(am.interopChaincodeId) == 0 {
+        return "", logThenErrorf("interoperation chaincode ID not set. Run the 'Configure(...)' function first.")
+    }
+
+    if len(basketAgreement.Assets) == 0 && len(basketAgreement.FungibleAssets) == 0 {
+        return "", logThenErrorf("empty basket")
+    }
+    for _, assetAgreement := range basketAgreement.Assets {
+        if len(assetAgreement.AssetType) == 0 {
+            return "", logThenErrorf("empty asset type")
+        }
+        if len(assetAgreement.Id) == 0 {
+            return "", logThenErrorf("empty asset id")
+        }
+    }
+    for _, assetAgreement := range basketAgreement.FungibleAssets {
+        if len(assetAgreement.AssetType) == 0 {
+            return "", logThenErrorf("empty asset type")
+        }
+        if assetAgreement.NumUnits <= 0 {
+            return "", logThenErrorf("invalid number of asset units")
+        }
+    }
+    if len(basketAgreement.Recipient) == 0 {
+        return "", logThenErrorf("empty lock recipient")
+    }
+
+    basketAgreementBytes, err := proto.Marshal(basketAgreement)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+    if lockInfo.LockMechanism != common.LockMechanism_HTLC {
+        return "", logThenErrorf("a basket of assets can only be locked with a hash lock")
+    }
+    err = am.validateLockInfo(lockInfo)
+    if err != nil {
+        return "", err
+    }
+    lockInfoBytes, err := proto.Marshal(lockInfo)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+    basketAgreementBytes64 := base64.StdEncoding.EncodeToString(basketAgreementBytes)
+    lockInfoBytes64 := base64.StdEncoding.EncodeToString(lockInfoBytes)
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("LockBasket"), []byte(basketAgreementBytes64), []byte(lockInfoBytes64)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return "", logThenErrorf(string(iccResp.GetMessage()))
+    }
+    contractId := string(iccResp.GetPayload())
+    fmt.Printf("Basket of %d assets and %d fungible assets locked for %s using contractId %s\n", len(basketAgreement.Assets), len(basketAgreement.FungibleAssets), basketAgreement.Recipient, contractId)
+    return contractId, nil
+}
+
+func (am *AssetManagement) IsBasketLocked(stub shim.ChaincodeStubInterface, contractId string) (bool, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return false, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("IsBasketLocked"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return false, errors.New(string(iccResp.GetMessage()))
+    }
+    isLocked := (string(iccResp.Payload) == fmt.Sprintf("%t", true))
+    if isLocked {
+        fmt.Printf("contractId %s is associated with a locked basket\n", contractId)
+    } else {
+        fmt.Printf("contractId %s is not associated with a locked basket\n", contractId)
+    }
+    return isLocked, nil
+}
+
+// Claim all the assets in the basket locked using contractId; returns the basket agreement, listing the assets to transfer to the recipient
+func (am *AssetManagement) ClaimBasket(stub shim.ChaincodeStubInterface, contractId string, claimInfo *common.AssetClaim) (*common.BasketAssetExchangeAgreement, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return nil, err
+    }
+
+    err = am.validateClaimInfo(claimInfo)
+    if err != nil {
+	return nil, err
+    }
+
+    claimInfoBytes, err := proto.Marshal(claimInfo)
+    if err != nil {
+        return nil, logThenErrorf(err.Error())
+    }
+    claimInfoBytes64 := base64.StdEncoding.EncodeToString(claimInfoBytes)
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("ClaimBasket"), []byte(contractId), []byte(claimInfoBytes64)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return nil, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    basketAgreement := &common.BasketAssetExchangeAgreement{}
+    err = extractAgreement(string(iccResp.GetPayload()), basketAgreement)
+    if err != nil {
+        return nil, err
+    }
+    fmt.Printf("Basket locked using contractId %s is claimed\n", contractId)
+    return basketAgreement, nil
+}
+
+// Unlock all the assets in the basket locked using contractId; returns the basket agreement, listing the assets released to the locker
+func (am *AssetManagement) UnlockBasket(stub shim.ChaincodeStubInterface, contractId string) (*common.BasketAssetExchangeAgreement, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return nil, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("UnlockBasket"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return nil, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    basketAgreement := &common.BasketAssetExchangeAgreement{}
+    err = extractAgreement(string(iccResp.GetPayload()), basketAgreement)
+    if err != nil {
+        return nil, err
+    }
+    fmt.Printf("Basket locked using contractId %s is unlocked\n", contractId)
+    return basketAgreement, nil
+}
+
+func (am *AssetManagement) LockHybridAsset(stub shim.ChaincodeStubInterface, assetAgreement *common.HybridAssetExchangeAgreement, lockInfo *common.AssetLock) (string, error) {
+    if len(am.interopChaincodeId) == 0 {
+        return "", logThenErrorf("interoperation chaincode ID not set. Run the 'Configure(...)' function first.")
+    }
+
+    if len(assetAgreement.AssetType) == 0 {
+        return "", logThenErrorf("empty asset type")
+    }
+    if len(assetAgreement.Id) == 0 {
+        return "", logThenErrorf("empty asset id")
+    }
+    if assetAgreement.NumUnits <= 0 {
+        return "", logThenErrorf("invalid number of asset units")
+    }
+    if len(assetAgreement.Recipient) == 0 {
+        return "", logThenErrorf("empty lock recipient")
+    }
+
+    assetAgreementBytes, err := proto.Marshal(assetAgreement)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+    err = am.validateLockInfo(lockInfo)
+    if err != nil {
+        return "", err
+    }
+    lockInfoBytes, err := proto.Marshal(lockInfo)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+    assetAgreementBytes64 := base64.StdEncoding.EncodeToString(assetAgreementBytes)
+    lockInfoBytes64 := base64.StdEncoding.EncodeToString(lockInfoBytes)
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("LockHybridAsset"), []byte(assetAgreementBytes64), []byte(lockInfoBytes64)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return "", errors.New(string(iccResp.GetMessage()))
+    }
+    contractId := string(iccResp.GetPayload())
+    fmt.Printf("%d units of asset %s of type %s locked for %s using contractId %s\n", assetAgreement.NumUnits, assetAgreement.Id, assetAgreement.AssetType, assetAgreement.Recipient, contractId)
+    return contractId, nil
+}
+
+func (am *AssetManagement) IsHybridAssetLocked(stub shim.ChaincodeStubInterface, contractId string) (bool, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return false, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("IsHybridAssetLocked"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return false, errors.New(string(iccResp.GetMessage()))
+    }
+    isLocked := (string(iccResp.Payload) == fmt.Sprintf("%t", true))
+    if isLocked {
+        fmt.Printf("contractId %s is associated with a locked hybrid asset\n", contractId)
+    } else {
+        fmt.Printf("contractId %s is not associated with a locked hybrid asset\n", contractId)
+    }
+    return isLocked, nil
+}
+
+// Claim the hybrid asset locked using contractId; returns the hybrid asset agreement, listing the units of the asset to transfer to the recipient
+func (am *AssetManagement) ClaimHybridAsset(stub shim.ChaincodeStubInterface, contractId string, claimInfo *common.AssetClaim) (*common.HybridAssetExchangeAgreement, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return nil, err
+    }
+
+    err = am.validateClaimInfo(claimInfo)
+    if err != nil {
+	return nil, err
+    }
+
+    claimInfoBytes, err := proto.Marshal(claimInfo)
+    if err != nil {
+        return nil, logThenErrorf(err.Error())
+    }
+    claimInfoBytes64 := base64.StdEncoding.EncodeToString(claimInfoBytes)
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("ClaimHybridAsset"), []byte(contractId), []byte(claimInfoBytes64)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return nil, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    assetAgreement := &common.HybridAssetExchangeAgreement{}
+    err = extractAgreement(string(iccResp.GetPayload()), assetAgreement)
+    if err != nil {
+        return nil, err
+    }
+    fmt.Printf("Hybrid asset locked using contractId %s is claimed\n", contractId)
+    return assetAgreement, nil
+}
+
+// Unlock the hybrid asset locked using contractId; returns the hybrid asset agreement, listing the units of the asset released to the locker
+func (am *AssetManagement) UnlockHybridAsset(stub shim.ChaincodeStubInterface, contractId string) (*common.HybridAssetExchangeAgreement, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return nil, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("UnlockHybridAsset"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return nil, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    assetAgreement := &common.HybridAssetExchangeAgreement{}
+    err = extractAgreement(string(iccResp.GetPayload()), assetAgreement)
+    if err != nil {
+        return nil, err
+    }
+    fmt.Printf("Hybrid asset locked using contractId %s is unlocked\n", contractId)
+    return assetAgreement, nil
+}
+
+// Deserialize an asset agreement returned (in base64 form) by the interop chaincode
+func extractAgreement(agreementBytes64 string, agreement proto.Message) error {
+    agreementBytes, err := base64.StdEncoding.DecodeString(agreementBytes64)
+    if err != nil {
+        return logThenErrorf(err.Error())
+    }
+    err = proto.Unmarshal(agreementBytes, agreement)
+    if err != nil {
+        return logThenErrorf(err.Error())
+    }
+    return nil
+}
+
+
 // Ledger query functions
 
 func (am *AssetManagement) GetTotalFungibleLockedAssets(stub shim.ChaincodeStubInterface, assetType string) (uint64, error) {
@@ -572,6 +907,65 @@ func (am *AssetManagement) GetFungibleAssetTimeToRelease(stub shim.ChaincodeStub
     return uint64(timeToReleaseSecs), nil
 }
 
+func (am *AssetManagement) GetFungibleAssetTimeToReleaseByContractId(stub shim.ChaincodeStubInterface, contractId string) (uint64, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+        return 0, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("GetFungibleAssetTimeToReleaseByContractId"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return 0, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    timeToReleaseSecs, err := strconv.ParseUint(string(iccResp.Payload), 10, 64)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+    fmt.Printf("Fungible asset locked using contractId %s locked until %+v\n", contractId, time.Unix(int64(timeToReleaseSecs), 0))
+    return timeToReleaseSecs, nil
+}
+
+// Returns the number of units of the fungible asset locked using contractId that are not claimed yet
+// The caller chaincode should credit only these units to the locker when unlocking the asset after a partial claim
+func (am *AssetManagement) GetFungibleAssetRemainingUnits(stub shim.ChaincodeStubInterface, contractId string) (uint64, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+        return 0, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("GetFungibleAssetRemainingUnits"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return 0, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    remainingUnits, err := strconv.ParseUint(string(iccResp.Payload), 10, 64)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+    fmt.Printf("%d units of fungible asset locked using contractId %s remain locked\n", remainingUnits, contractId)
+    return remainingUnits, nil
+}
+
+func (am *AssetManagement) GetHybridAssetTimeToRelease(stub shim.ChaincodeStubInterface, contractId string) (uint64, error) {
+    _, err := am.validateInteropccContractId(contractId)
+    if err != nil {
+	return 0, err
+    }
+
+    iccResp := stub.InvokeChaincode(am.interopChaincodeId, [][]byte{[]byte("GetHybridAssetTimeToRelease"), []byte(contractId)}, "")
+    fmt.Printf("Response from Interop CC: %+v\n", iccResp)
+    if iccResp.GetStatus() != shim.OK {
+        return 0, logThenErrorf(string(iccResp.GetMessage()))
+    }
+    timeToReleaseSecs, err := strconv.ParseUint(string(iccResp.Payload), 10, 64)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+    fmt.Printf("Hybrid asset locked using contractId %s locked until %+v\n", contractId, time.Unix(int64(timeToReleaseSecs), 0))
+    return timeToReleaseSecs, nil
+}
+
 // Assumption is that the caller is either the recipient or the locker in each element in the list, but we will let the interop CC take care of it
 func (am *AssetManagement) GetAllAssetsLockedUntil(stub shim.ChaincodeStubInterface, lockExpiryTimeSecs uint64) ([]string, error) {
     var assets []string
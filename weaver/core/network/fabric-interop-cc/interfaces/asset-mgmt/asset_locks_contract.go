@@ -11,6 +11,7 @@ import (
     "encoding/json"
 
     "github.com/golang/protobuf/proto"
+    "github.com/hyperledger/fabric-chaincode-go/shim"
     "github.com/hyperledger/fabric-contract-api-go/contractapi"
     log "github.com/sirupsen/logrus"
     "github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
@@ -251,6 +252,42 @@ func (amc *AssetManagementContract) ValidateAndExtractFungibleAssetAgreement(fun
     return assetAgreement, nil
 }
 
+func (amc *AssetManagementContract) ValidateAndExtractHybridAssetAgreement(hybridAssetExchangeAgreementSerializedProto64 string) (*common.HybridAssetExchangeAgreement, error) {
+    assetAgreement := &common.HybridAssetExchangeAgreement{}
+    // Decoding from base64
+    hybridAssetExchangeAgreementSerializedProto, err := base64.StdEncoding.DecodeString(hybridAssetExchangeAgreementSerializedProto64)
+    if err != nil {
+      return assetAgreement, logThenErrorf(err.Error())
+    }
+    if len(hybridAssetExchangeAgreementSerializedProto) == 0 {
+        return assetAgreement, logThenErrorf("empty asset agreement")
+    }
+    err = proto.Unmarshal([]byte(hybridAssetExchangeAgreementSerializedProto), assetAgreement)
+    if err != nil {
+        return assetAgreement, logThenErrorf(err.Error())
+    }
+
+    return assetAgreement, nil
+}
+
+func (amc *AssetManagementContract) ValidateAndExtractBasketAgreement(basketAgreementSerializedProto64 string) (*common.BasketAssetExchangeAgreement, error) {
+    basketAgreement := &common.BasketAssetExchangeAgreement{}
+    // Decoding from base64
+    basketAgreementSerializedProto, err := base64.StdEncoding.DecodeString(basketAgreementSerializedProto64)
+    if err != nil {
+      return basketAgreement, logThenErrorf(err.Error())
+    }
+    if len(basketAgreementSerializedProto) == 0 {
+        return basketAgreement, logThenErrorf("empty asset agreement")
+    }
+    err = proto.Unmarshal([]byte(basketAgreementSerializedProto), basketAgreement)
+    if err != nil {
+        return basketAgreement, logThenErrorf(err.Error())
+    }
+
+    return basketAgreement, nil
+}
+
 func (amc *AssetManagementContract) ValidateAndExtractLockInfo(lockInfoSerializedProto64 string) (*common.AssetLock, error) {
     lockInfo := &common.AssetLock{}
     // Decoding from base64
@@ -287,6 +324,18 @@ func (amc *AssetManagementContract) ValidateAndExtractClaimInfo(claimInfoSeriali
     return claimInfo, nil
 }
 
+// Lock events report the expiry time recorded by the interop chaincode, which resolves a lock duration against the transaction timestamp
+func getEpochExpiryTimeSecs(stub shim.ChaincodeStubInterface, timeSpec common.TimeSpec, expiryTimeSecs uint64) (uint64, error) {
+    if timeSpec != common.TimeSpec_DURATION {
+        return expiryTimeSecs, nil
+    }
+    txTimestamp, err := stub.GetTxTimestamp()
+    if err != nil {
+        return 0, err
+    }
+    return uint64(txTimestamp.GetSeconds()) + expiryTimeSecs, nil
+}
+
 // Ledger transaction (invocation) functions
 
 func (amc *AssetManagementContract) LockAsset(ctx contractapi.TransactionContextInterface, assetAgreementSerializedProto64 string, lockInfoSerializedProto64 string) (string, error) {
@@ -299,13 +348,18 @@ func (amc *AssetManagementContract) LockAsset(ctx contractapi.TransactionContext
         return "", err
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     contractId, err := amc.assetManagement.LockAsset(ctx.GetStub(), assetAgreement, lockInfo)
     if err == nil {
 	var contractInfoBytes []byte
+        eventName := "LockAsset"
         if lockInfo.LockMechanism == common.LockMechanism_HTLC {
             lockInfoVal := &common.AssetLockHTLC{}
             err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+            if err == nil {
+                lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+                lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+            }
             if err == nil {
                 contractInfo := &common.AssetContractHTLC {
                     ContractId: contractId,
@@ -314,13 +368,29 @@ func (amc *AssetManagementContract) LockAsset(ctx contractapi.TransactionContext
                 }
                 contractInfoBytes, err = proto.Marshal(contractInfo)
             }
+        } else if lockInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            lockInfoVal := &common.AssetLockSignature{}
+            err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+            if err == nil {
+                lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+                lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+            }
+            if err == nil {
+                contractInfo := &common.AssetContractSignature {
+                    ContractId: contractId,
+                    Agreement: assetAgreement,
+                    Lock: lockInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "LockAssetWithSignature"
         } else {
             logWarnings("lock mechanism is not supported")
         }
         if err == nil {
-            err = ctx.GetStub().SetEvent("LockAsset", contractInfoBytes)
+            err = setEvent(ctx, eventName, contractInfoBytes)
         } else {
-            logWarnings("Unable to set 'LockAsset' event", err.Error())
+            logWarnings("Unable to set '" + eventName + "' event", err.Error())
 	}
     }
 
@@ -337,13 +407,18 @@ func (amc *AssetManagementContract) LockFungibleAsset(ctx contractapi.Transactio
         return "", err
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     contractId, err := amc.assetManagement.LockFungibleAsset(ctx.GetStub(), assetAgreement, lockInfo)
     if err == nil {
 	var contractInfoBytes []byte
+        eventName := "LockFungibleAsset"
         if lockInfo.LockMechanism == common.LockMechanism_HTLC {
             lockInfoVal := &common.AssetLockHTLC{}
             err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+            if err == nil {
+                lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+                lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+            }
             if err == nil {
                 contractInfo := &common.FungibleAssetContractHTLC {
                     ContractId: contractId,
@@ -352,13 +427,29 @@ func (amc *AssetManagementContract) LockFungibleAsset(ctx contractapi.Transactio
                 }
                 contractInfoBytes, err = proto.Marshal(contractInfo)
             }
+        } else if lockInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            lockInfoVal := &common.AssetLockSignature{}
+            err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+            if err == nil {
+                lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+                lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+            }
+            if err == nil {
+                contractInfo := &common.FungibleAssetContractSignature {
+                    ContractId: contractId,
+                    Agreement: assetAgreement,
+                    Lock: lockInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "LockFungibleAssetWithSignature"
         } else {
             logWarnings("lock mechanism is not supported")
         }
 	if err == nil {
-            err = ctx.GetStub().SetEvent("LockFungibleAsset", contractInfoBytes)
+            err = setEvent(ctx, eventName, contractInfoBytes)
         } else {
-            logWarnings("Unable to set 'LockFungibleAsset' event", err.Error())
+            logWarnings("Unable to set '" + eventName + "' event", err.Error())
         }
     }
 
@@ -398,10 +489,11 @@ func (amc *AssetManagementContract) ClaimAsset(ctx contractapi.TransactionContex
         return false, err
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     retVal, err := amc.assetManagement.ClaimAsset(ctx.GetStub(), assetAgreement, claimInfo)
     if retVal && err == nil {
 	var contractInfoBytes []byte
+        eventName := "ClaimAsset"
         if claimInfo.LockMechanism == common.LockMechanism_HTLC {
             claimInfoVal := &common.AssetClaimHTLC{}
             err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
@@ -412,13 +504,24 @@ func (amc *AssetManagementContract) ClaimAsset(ctx contractapi.TransactionContex
                 }
                 contractInfoBytes, err = proto.Marshal(contractInfo)
             }
+        } else if claimInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            claimInfoVal := &common.AssetClaimSignature{}
+            err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+            if err == nil {
+                contractInfo := &common.AssetContractSignature {
+                    Agreement: assetAgreement,
+                    Claim: claimInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "ClaimAssetWithSignature"
         } else {
             logWarnings("lock mechanism is not supported")
         }
         if err == nil {
-           err = ctx.GetStub().SetEvent("ClaimAsset", contractInfoBytes)
+           err = setEvent(ctx, eventName, contractInfoBytes)
         } else {
-	    logWarnings("Unable to set 'ClaimAsset' event", err.Error())
+	    logWarnings("Unable to set '" + eventName + "' event", err.Error())
         }
     }
     return retVal, err
@@ -433,10 +536,11 @@ func (amc *AssetManagementContract) ClaimFungibleAsset(ctx contractapi.Transacti
         return false, err
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     retVal, err := amc.assetManagement.ClaimFungibleAsset(ctx.GetStub(), contractId, claimInfo)
     if retVal && err == nil {
 	var contractInfoBytes []byte
+        eventName := "ClaimFungibleAsset"
         if claimInfo.LockMechanism == common.LockMechanism_HTLC {
             claimInfoVal := &common.AssetClaimHTLC{}
             err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
@@ -447,18 +551,82 @@ func (amc *AssetManagementContract) ClaimFungibleAsset(ctx contractapi.Transacti
                 }
                 contractInfoBytes, err = proto.Marshal(contractInfo)
             }
+        } else if claimInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            claimInfoVal := &common.AssetClaimSignature{}
+            err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+            if err == nil {
+                contractInfo := &common.FungibleAssetContractSignature {
+                    ContractId: contractId,
+                    Claim: claimInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "ClaimFungibleAssetWithSignature"
         } else {
             logWarnings("lock mechanism is not supported")
         }
         if err == nil {
-            err = ctx.GetStub().SetEvent("ClaimFungibleAsset", contractInfoBytes)
+            err = setEvent(ctx, eventName, contractInfoBytes)
         } else {
-	    logWarnings("Unable to set 'ClaimFungibleAsset' event", err.Error())
+	    logWarnings("Unable to set '" + eventName + "' event", err.Error())
         }
     }
     return retVal, err
 }
 
+func (amc *AssetManagementContract) ClaimFungibleAssetUnits(ctx contractapi.TransactionContextInterface, contractId string, numUnits uint64, claimInfoSerializedProto64 string) (uint64, error) {
+    if len(contractId) == 0 {
+        return 0, logThenErrorf("empty contract id")
+    }
+    claimInfo, err := amc.ValidateAndExtractClaimInfo(claimInfoSerializedProto64)
+    if err != nil {
+        return 0, err
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    remainingUnits, err := amc.assetManagement.ClaimFungibleAssetUnits(ctx.GetStub(), contractId, numUnits, claimInfo)
+    if err == nil {
+	var contractInfoBytes []byte
+        eventName := "ClaimFungibleAssetUnits"
+        // The agreement in the event carries the number of units claimed in this transaction
+        assetAgreement := &common.FungibleAssetExchangeAgreement {
+            NumUnits: numUnits,
+        }
+        if claimInfo.LockMechanism == common.LockMechanism_HTLC {
+            claimInfoVal := &common.AssetClaimHTLC{}
+            err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+            if err == nil {
+                contractInfo := &common.FungibleAssetContractHTLC {
+                    ContractId: contractId,
+                    Agreement: assetAgreement,
+                    Claim: claimInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+        } else if claimInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            claimInfoVal := &common.AssetClaimSignature{}
+            err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+            if err == nil {
+                contractInfo := &common.FungibleAssetContractSignature {
+                    ContractId: contractId,
+                    Agreement: assetAgreement,
+                    Claim: claimInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "ClaimFungibleAssetUnitsWithSignature"
+        } else {
+            logWarnings("lock mechanism is not supported")
+        }
+        if err == nil {
+            err = setEvent(ctx, eventName, contractInfoBytes)
+        } else {
+	    logWarnings("Unable to set '" + eventName + "' event", err.Error())
+        }
+    }
+    return remainingUnits, err
+}
+
 func (amc *AssetManagementContract) ClaimAssetUsingContractId(ctx contractapi.TransactionContextInterface, contractId, claimInfoSerializedProto64 string) (bool, error) {
     if len(contractId) == 0 {
         return false, logThenErrorf("empty contract id")
@@ -468,10 +636,11 @@ func (amc *AssetManagementContract) ClaimAssetUsingContractId(ctx contractapi.Tr
         return false, err
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     retVal, err := amc.assetManagement.ClaimAssetUsingContractId(ctx.GetStub(), contractId, claimInfo)
     if retVal && err == nil {
 	var contractInfoBytes []byte
+        eventName := "ClaimAsset"
         if claimInfo.LockMechanism == common.LockMechanism_HTLC {
             claimInfoVal := &common.AssetClaimHTLC{}
             err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
@@ -482,13 +651,24 @@ func (amc *AssetManagementContract) ClaimAssetUsingContractId(ctx contractapi.Tr
                 }
                 contractInfoBytes, err = proto.Marshal(contractInfo)
             }
+        } else if claimInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            claimInfoVal := &common.AssetClaimSignature{}
+            err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+            if err == nil {
+                contractInfo := &common.AssetContractSignature {
+                    ContractId: contractId,
+                    Claim: claimInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "ClaimAssetWithSignature"
         } else {
             logWarnings("lock mechanism is not supported")
         }
         if err == nil {
-            err = ctx.GetStub().SetEvent("ClaimAsset", contractInfoBytes)
+            err = setEvent(ctx, eventName, contractInfoBytes)
         } else {
-	    logWarnings("Unable to set 'ClaimAsset' event", err.Error())
+	    logWarnings("Unable to set '" + eventName + "' event", err.Error())
 	}
     }
 
@@ -501,7 +681,7 @@ func (amc *AssetManagementContract) UnlockAsset(ctx contractapi.TransactionConte
         return false, err
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     retVal, err := amc.assetManagement.UnlockAsset(ctx.GetStub(), assetAgreement)
     if retVal && err == nil {
         contractInfo := &common.AssetContractHTLC{
@@ -509,7 +689,7 @@ func (amc *AssetManagementContract) UnlockAsset(ctx contractapi.TransactionConte
         }
         contractInfoBytes, err := proto.Marshal(contractInfo)
         if err == nil {
-            err = ctx.GetStub().SetEvent("UnlockAsset", contractInfoBytes)
+            err = setEvent(ctx, "UnlockAsset", contractInfoBytes)
         }
         if err != nil {
             logWarnings("Unable to set 'UnlockAsset' event", err.Error())
@@ -523,7 +703,7 @@ func (amc *AssetManagementContract) UnlockFungibleAsset(ctx contractapi.Transact
         return false, logThenErrorf("empty contract id")
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     retVal, err := amc.assetManagement.UnlockFungibleAsset(ctx.GetStub(), contractId)
     if retVal && err == nil {
         contractInfo := &common.FungibleAssetContractHTLC{
@@ -531,7 +711,7 @@ func (amc *AssetManagementContract) UnlockFungibleAsset(ctx contractapi.Transact
         }
         contractInfoBytes, err := proto.Marshal(contractInfo)
         if err == nil {
-            err = ctx.GetStub().SetEvent("UnlockFungibleAsset", contractInfoBytes)
+            err = setEvent(ctx, "UnlockFungibleAsset", contractInfoBytes)
         }
         if err != nil {
             logWarnings("Unable to set 'UnlockFungibleAsset' event", err.Error())
@@ -545,7 +725,7 @@ func (amc *AssetManagementContract) UnlockAssetUsingContractId(ctx contractapi.T
         return false, logThenErrorf("empty contract id")
     }
 
-    // The below 'SetEvent' should be the last in a given transaction (if this function is being called by another), otherwise it will be overridden
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
     retVal, err := amc.assetManagement.UnlockAssetUsingContractId(ctx.GetStub(), contractId)
     if retVal && err == nil {
         contractInfo := &common.AssetContractHTLC{
@@ -553,7 +733,7 @@ func (amc *AssetManagementContract) UnlockAssetUsingContractId(ctx contractapi.T
         }
         contractInfoBytes, err := proto.Marshal(contractInfo)
         if err == nil {
-            err = ctx.GetStub().SetEvent("UnlockAsset", contractInfoBytes)
+            err = setEvent(ctx, "UnlockAsset", contractInfoBytes)
         }
         if err != nil {
 	    logWarnings("Unable to set 'UnlockAsset' event", err.Error())
@@ -563,6 +743,285 @@ func (amc *AssetManagementContract) UnlockAssetUsingContractId(ctx contractapi.T
 }
 
 
+/*
+ * Basket lock functions: several non-fungible and fungible assets are locked under a single hash lock and contractId,
+ * and claimed (or unlocked after expiry) together. ClaimBasket and UnlockBasket return the basket agreement (serialized
+ * and in base64 form, which can be extracted with ValidateAndExtractBasketAgreement), listing the assets that the
+ * calling chaincode should transfer to the recipient or release to the locker respectively.
+ */
+
+func (amc *AssetManagementContract) LockBasket(ctx contractapi.TransactionContextInterface, basketAgreementSerializedProto64 string, lockInfoSerializedProto64 string) (string, error) {
+    basketAgreement, err := amc.ValidateAndExtractBasketAgreement(basketAgreementSerializedProto64)
+    if err != nil {
+        return "", err
+    }
+    lockInfo, err := amc.ValidateAndExtractLockInfo(lockInfoSerializedProto64)
+    if err != nil {
+        return "", err
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    contractId, err := amc.assetManagement.LockBasket(ctx.GetStub(), basketAgreement, lockInfo)
+    if err == nil {
+        var contractInfoBytes []byte
+        lockInfoVal := &common.AssetLockHTLC{}
+        err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+        if err == nil {
+            lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+            lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+        }
+        if err == nil {
+            contractInfo := &common.BasketAssetContractHTLC {
+                ContractId: contractId,
+                Agreement: basketAgreement,
+                Lock: lockInfoVal,
+            }
+            contractInfoBytes, err = proto.Marshal(contractInfo)
+        }
+        if err == nil {
+            err = setEvent(ctx, "LockBasket", contractInfoBytes)
+        } else {
+            logWarnings("Unable to set 'LockBasket' event", err.Error())
+        }
+    }
+
+    return contractId, err
+}
+
+func (amc *AssetManagementContract) IsBasketLocked(ctx contractapi.TransactionContextInterface, contractId string) (bool, error) {
+    if len(contractId) == 0 {
+        return false, logThenErrorf("empty contract id")
+    }
+    return amc.assetManagement.IsBasketLocked(ctx.GetStub(), contractId)
+}
+
+func (amc *AssetManagementContract) ClaimBasket(ctx contractapi.TransactionContextInterface, contractId, claimInfoSerializedProto64 string) (string, error) {
+    if len(contractId) == 0 {
+        return "", logThenErrorf("empty contract id")
+    }
+    claimInfo, err := amc.ValidateAndExtractClaimInfo(claimInfoSerializedProto64)
+    if err != nil {
+        return "", err
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    basketAgreement, err := amc.assetManagement.ClaimBasket(ctx.GetStub(), contractId, claimInfo)
+    if err != nil {
+        return "", err
+    }
+    basketAgreementBytes, err := proto.Marshal(basketAgreement)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+
+    claimInfoVal := &common.AssetClaimHTLC{}
+    err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+    if err == nil {
+        contractInfo := &common.BasketAssetContractHTLC {
+            ContractId: contractId,
+            Agreement: basketAgreement,
+            Claim: claimInfoVal,
+        }
+        var contractInfoBytes []byte
+        contractInfoBytes, err = proto.Marshal(contractInfo)
+        if err == nil {
+            err = setEvent(ctx, "ClaimBasket", contractInfoBytes)
+        }
+    }
+    if err != nil {
+        logWarnings("Unable to set 'ClaimBasket' event", err.Error())
+    }
+    return base64.StdEncoding.EncodeToString(basketAgreementBytes), err
+}
+
+func (amc *AssetManagementContract) UnlockBasket(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+    if len(contractId) == 0 {
+        return "", logThenErrorf("empty contract id")
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    basketAgreement, err := amc.assetManagement.UnlockBasket(ctx.GetStub(), contractId)
+    if err != nil {
+        return "", err
+    }
+    basketAgreementBytes, err := proto.Marshal(basketAgreement)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+
+    contractInfo := &common.BasketAssetContractHTLC{
+        ContractId: contractId,
+        Agreement: basketAgreement,
+    }
+    contractInfoBytes, err := proto.Marshal(contractInfo)
+    if err == nil {
+        err = setEvent(ctx, "UnlockBasket", contractInfoBytes)
+    }
+    if err != nil {
+        logWarnings("Unable to set 'UnlockBasket' event", err.Error())
+    }
+    return base64.StdEncoding.EncodeToString(basketAgreementBytes), err
+}
+
+
+/*
+ * Hybrid asset functions: a number of units of an asset instance identified by its ID is locked, and referred to by the
+ * contractId. ClaimHybridAsset and UnlockHybridAsset return the hybrid asset agreement (serialized and in base64 form,
+ * which can be extracted with ValidateAndExtractHybridAssetAgreement), listing the units of the asset that the calling
+ * chaincode should transfer to the recipient or release to the locker respectively.
+ */
+
+func (amc *AssetManagementContract) LockHybridAsset(ctx contractapi.TransactionContextInterface, hybridAssetExchangeAgreementSerializedProto64 string, lockInfoSerializedProto64 string) (string, error) {
+    assetAgreement, err := amc.ValidateAndExtractHybridAssetAgreement(hybridAssetExchangeAgreementSerializedProto64)
+    if err != nil {
+        return "", err
+    }
+    lockInfo, err := amc.ValidateAndExtractLockInfo(lockInfoSerializedProto64)
+    if err != nil {
+        return "", err
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    contractId, err := amc.assetManagement.LockHybridAsset(ctx.GetStub(), assetAgreement, lockInfo)
+    if err == nil {
+	var contractInfoBytes []byte
+        eventName := "LockHybridAsset"
+        if lockInfo.LockMechanism == common.LockMechanism_HTLC {
+            lockInfoVal := &common.AssetLockHTLC{}
+            err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+            if err == nil {
+                lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+                lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+            }
+            if err == nil {
+                contractInfo := &common.HybridAssetContractHTLC {
+                    ContractId: contractId,
+                    Agreement: assetAgreement,
+                    Lock: lockInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+        } else if lockInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+            lockInfoVal := &common.AssetLockSignature{}
+            err = proto.Unmarshal(lockInfo.LockInfo, lockInfoVal)
+            if err == nil {
+                lockInfoVal.ExpiryTimeSecs, err = getEpochExpiryTimeSecs(ctx.GetStub(), lockInfoVal.TimeSpec, lockInfoVal.ExpiryTimeSecs)
+                lockInfoVal.TimeSpec = common.TimeSpec_EPOCH
+            }
+            if err == nil {
+                contractInfo := &common.HybridAssetContractSignature {
+                    ContractId: contractId,
+                    Agreement: assetAgreement,
+                    Lock: lockInfoVal,
+                }
+                contractInfoBytes, err = proto.Marshal(contractInfo)
+            }
+            eventName = "LockHybridAssetWithSignature"
+        } else {
+            logWarnings("lock mechanism is not supported")
+        }
+        if err == nil {
+            err = setEvent(ctx, eventName, contractInfoBytes)
+        } else {
+	    logWarnings("Unable to set '" + eventName + "' event", err.Error())
+        }
+    }
+
+    return contractId, err
+}
+
+func (amc *AssetManagementContract) IsHybridAssetLocked(ctx contractapi.TransactionContextInterface, contractId string) (bool, error) {
+    if len(contractId) == 0 {
+        return false, logThenErrorf("empty contract id")
+    }
+    return amc.assetManagement.IsHybridAssetLocked(ctx.GetStub(), contractId)
+}
+
+func (amc *AssetManagementContract) ClaimHybridAsset(ctx contractapi.TransactionContextInterface, contractId, claimInfoSerializedProto64 string) (string, error) {
+    if len(contractId) == 0 {
+        return "", logThenErrorf("empty contract id")
+    }
+    claimInfo, err := amc.ValidateAndExtractClaimInfo(claimInfoSerializedProto64)
+    if err != nil {
+        return "", err
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    assetAgreement, err := amc.assetManagement.ClaimHybridAsset(ctx.GetStub(), contractId, claimInfo)
+    if err != nil {
+        return "", err
+    }
+    assetAgreementBytes, err := proto.Marshal(assetAgreement)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+
+    var contractInfoBytes []byte
+    eventName := "ClaimHybridAsset"
+    if claimInfo.LockMechanism == common.LockMechanism_HTLC {
+        claimInfoVal := &common.AssetClaimHTLC{}
+        err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+        if err == nil {
+            contractInfo := &common.HybridAssetContractHTLC {
+                ContractId: contractId,
+                Agreement: assetAgreement,
+                Claim: claimInfoVal,
+            }
+            contractInfoBytes, err = proto.Marshal(contractInfo)
+        }
+    } else if claimInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+        claimInfoVal := &common.AssetClaimSignature{}
+        err = proto.Unmarshal(claimInfo.ClaimInfo, claimInfoVal)
+        if err == nil {
+            contractInfo := &common.HybridAssetContractSignature {
+                ContractId: contractId,
+                Agreement: assetAgreement,
+                Claim: claimInfoVal,
+            }
+            contractInfoBytes, err = proto.Marshal(contractInfo)
+        }
+        eventName = "ClaimHybridAssetWithSignature"
+    } else {
+        logWarnings("lock mechanism is not supported")
+    }
+    if err == nil {
+        err = setEvent(ctx, eventName, contractInfoBytes)
+    } else {
+        logWarnings("Unable to set '" + eventName + "' event", err.Error())
+    }
+    return base64.StdEncoding.EncodeToString(assetAgreementBytes), err
+}
+
+func (amc *AssetManagementContract) UnlockHybridAsset(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+    if len(contractId) == 0 {
+        return "", logThenErrorf("empty contract id")
+    }
+
+    // Unless the transaction context accumulates events (see TransactionContext), the below event should be the last set in a given transaction (if this function is being called by another), otherwise it will be overridden
+    assetAgreement, err := amc.assetManagement.UnlockHybridAsset(ctx.GetStub(), contractId)
+    if err != nil {
+        return "", err
+    }
+    assetAgreementBytes, err := proto.Marshal(assetAgreement)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+
+    contractInfo := &common.HybridAssetContractHTLC{
+        ContractId: contractId,
+        Agreement: assetAgreement,
+    }
+    contractInfoBytes, err := proto.Marshal(contractInfo)
+    if err == nil {
+        err = setEvent(ctx, "UnlockHybridAsset", contractInfoBytes)
+    }
+    if err != nil {
+        logWarnings("Unable to set 'UnlockHybridAsset' event", err.Error())
+    }
+    return base64.StdEncoding.EncodeToString(assetAgreementBytes), err
+}
+
+
 // Ledger query functions
 
 func (amc *AssetManagementContract) GetTotalFungibleLockedAssets(ctx contractapi.TransactionContextInterface, assetType string) (uint64, error) {
@@ -599,6 +1058,27 @@ func (amc *AssetManagementContract) GetFungibleAssetTimeToRelease(ctx contractap
     return amc.assetManagement.GetFungibleAssetTimeToRelease(ctx.GetStub(), assetAgreement)
 }
 
+func (amc *AssetManagementContract) GetFungibleAssetTimeToReleaseByContractId(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+    if len(contractId) == 0 {
+        return 0, logThenErrorf("empty contract id")
+    }
+    return amc.assetManagement.GetFungibleAssetTimeToReleaseByContractId(ctx.GetStub(), contractId)
+}
+
+func (amc *AssetManagementContract) GetFungibleAssetRemainingUnits(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+    if len(contractId) == 0 {
+        return 0, logThenErrorf("empty contract id")
+    }
+    return amc.assetManagement.GetFungibleAssetRemainingUnits(ctx.GetStub(), contractId)
+}
+
+func (amc *AssetManagementContract) GetHybridAssetTimeToRelease(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+    if len(contractId) == 0 {
+        return 0, logThenErrorf("empty contract id")
+    }
+    return amc.assetManagement.GetHybridAssetTimeToRelease(ctx.GetStub(), contractId)
+}
+
 func (amc *AssetManagementContract) GetAllAssetsLockedUntil(ctx contractapi.TransactionContextInterface, lockExpiryTimeSecs uint64) ([]string, error) {
     return amc.assetManagement.GetAllAssetsLockedUntil(ctx.GetStub(), lockExpiryTimeSecs)
 }
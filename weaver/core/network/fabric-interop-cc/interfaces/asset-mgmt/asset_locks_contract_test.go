@@ -7,11 +7,14 @@
 package assetmgmt_test
 
 import (
+	"encoding/base64"
 	"fmt"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/stretchr/testify/require"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	am "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/interfaces/asset-mgmt/v2"
 	wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
 )
@@ -41,3 +44,326 @@ func TestContractIsFungibleAssetLocked(t *testing.T) {
 	require.False(t, isAssetLocked)
 	fmt.Printf("Test failed as expected with error: %+v\n", err)
 }
+
+func TestContractSignatureLock(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	amc := am.AssetManagementContract{}
+	amc.Configure(interopChaincodeId)
+
+	assetAgreement := &common.FungibleAssetExchangeAgreement{
+		AssetType: "cbdc",
+		NumUnits:  1000,
+		Recipient: "Bob",
+	}
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+	assetAgreementBase64 := base64.StdEncoding.EncodeToString(assetAgreementBytes)
+	lockInfoSignature := &common.AssetLockSignature{
+		ExpiryTimeSecs: 100,
+	}
+	lockInfoSignatureBytes, _ := proto.Marshal(lockInfoSignature)
+	lockInfo := &common.AssetLock{
+		LockMechanism: common.LockMechanism_SIGNATURE,
+		LockInfo:      lockInfoSignatureBytes,
+	}
+	lockInfoBytes, _ := proto.Marshal(lockInfo)
+
+	// Test failure under the scenario that the arbiter public key is not supplied
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte("contract1")))
+	_, err := amc.LockFungibleAsset(ctx, assetAgreementBase64, base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "empty arbiter public key")
+	fmt.Printf("Test failed as expected with error: %+v\n", err)
+
+	// Test success with the lock event carrying the signature lock
+	lockInfoSignature.ArbiterPublicKey = []byte("arbiter-public-key")
+	lockInfoSignatureBytes, _ = proto.Marshal(lockInfoSignature)
+	lockInfo.LockInfo = lockInfoSignatureBytes
+	lockInfoBytes, _ = proto.Marshal(lockInfo)
+	contractId, err := amc.LockFungibleAsset(ctx, assetAgreementBase64, base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.NoError(t, err)
+	require.Equal(t, "contract1", contractId)
+	eventName, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, "LockFungibleAssetWithSignature", eventName)
+	contractInfo := &common.FungibleAssetContractSignature{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, "contract1", contractInfo.ContractId)
+	require.Equal(t, []byte("arbiter-public-key"), contractInfo.Lock.ArbiterPublicKey)
+
+	// Test failure under the scenario that the arbiter signature is not supplied
+	claimInfoSignature := &common.AssetClaimSignature{}
+	claimInfoSignatureBytes, _ := proto.Marshal(claimInfoSignature)
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_SIGNATURE,
+		ClaimInfo:     claimInfoSignatureBytes,
+	}
+	claimInfoBytes, _ := proto.Marshal(claimInfo)
+	_, err = amc.ClaimFungibleAsset(ctx, contractId, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.Error(t, err)
+	fmt.Printf("Test failed as expected with error: %+v\n", err)
+
+	// Test success with the claim event carrying the arbiter signature
+	claimInfoSignature.Signature = []byte("arbiter-signature")
+	claimInfoSignatureBytes, _ = proto.Marshal(claimInfoSignature)
+	claimInfo.ClaimInfo = claimInfoSignatureBytes
+	claimInfoBytes, _ = proto.Marshal(claimInfo)
+	chaincodeStub.InvokeChaincodeReturns(shim.Success(nil))
+	claimSuccess, err := amc.ClaimFungibleAsset(ctx, contractId, base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	require.True(t, claimSuccess)
+	eventName, payload = chaincodeStub.SetEventArgsForCall(1)
+	require.Equal(t, "ClaimFungibleAssetWithSignature", eventName)
+	contractInfo = &common.FungibleAssetContractSignature{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, []byte("arbiter-signature"), contractInfo.Claim.Signature)
+}
+
+func TestContractLockWithDuration(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	amc := am.AssetManagementContract{}
+	amc.Configure(interopChaincodeId)
+
+	assetAgreement := &common.AssetExchangeAgreement{
+		AssetType: "bond",
+		Id:        "a01",
+		Recipient: "Bob",
+	}
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+	lockInfoHTLC := &common.AssetLockHTLC{
+		HashBase64:     []byte("hash"),
+		ExpiryTimeSecs: 0,
+		TimeSpec:       common.TimeSpec_DURATION,
+	}
+	lockInfoHTLCBytes, _ := proto.Marshal(lockInfoHTLC)
+	lockInfo := &common.AssetLock{
+		LockMechanism: common.LockMechanism_HTLC,
+		LockInfo:      lockInfoHTLCBytes,
+	}
+	lockInfoBytes, _ := proto.Marshal(lockInfo)
+
+	// Test failure under the scenario that the lock duration is zero
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte("contract1")))
+	_, err := amc.LockAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.EqualError(t, err, "lock duration must be a positive number of seconds")
+	fmt.Printf("Test failed as expected with error: %+v\n", err)
+
+	// Test success with the lock event reporting the expiry time resolved against the transaction timestamp
+	lockInfoHTLC.ExpiryTimeSecs = 300
+	lockInfoHTLCBytes, _ = proto.Marshal(lockInfoHTLC)
+	lockInfo.LockInfo = lockInfoHTLCBytes
+	lockInfoBytes, _ = proto.Marshal(lockInfo)
+	_, err = amc.LockAsset(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes), base64.StdEncoding.EncodeToString(lockInfoBytes))
+	require.NoError(t, err)
+	txTimestamp, _ := chaincodeStub.GetTxTimestamp()
+	eventName, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, "LockAsset", eventName)
+	contractInfo := &common.AssetContractHTLC{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, common.TimeSpec_EPOCH, contractInfo.Lock.TimeSpec)
+	require.Equal(t, uint64(txTimestamp.GetSeconds())+300, contractInfo.Lock.ExpiryTimeSecs)
+
+	// Test success with the time to release reported by the interop chaincode
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte(fmt.Sprintf("%d", contractInfo.Lock.ExpiryTimeSecs))))
+	assetAgreement.Locker = "Alice"
+	assetAgreementBytes, _ = proto.Marshal(assetAgreement)
+	timeToRelease, err := amc.GetAssetTimeToRelease(ctx, base64.StdEncoding.EncodeToString(assetAgreementBytes))
+	require.NoError(t, err)
+	require.Equal(t, contractInfo.Lock.ExpiryTimeSecs, timeToRelease)
+	chaincodeName, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(chaincodeStub.InvokeChaincodeCallCount() - 1)
+	require.Equal(t, interopChaincodeId, chaincodeName)
+	require.Equal(t, "GetAssetTimeToRelease", string(args[0]))
+}
+
+func TestContractClaimFungibleAssetUnits(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	amc := am.AssetManagementContract{}
+	amc.Configure(interopChaincodeId)
+
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashMechanism:      common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte("cHJlaW1hZ2U="),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_HTLC,
+		ClaimInfo:     claimInfoHTLCBytes,
+	}
+	claimInfoBytes, _ := proto.Marshal(claimInfo)
+	claimInfoBase64 := base64.StdEncoding.EncodeToString(claimInfoBytes)
+
+	// Test failure under the scenario that the interop chaincode rejects the claim
+	chaincodeStub.InvokeChaincodeReturns(shim.Error("cannot claim 700 units of the asset associated with contractId contract1 as only 600 units remain locked"))
+	_, err := amc.ClaimFungibleAssetUnits(ctx, "contract1", 700, claimInfoBase64)
+	require.Error(t, err)
+	require.Equal(t, 0, chaincodeStub.SetEventCallCount())
+	fmt.Printf("Test failed as expected with error: %+v\n", err)
+
+	// Test success with the claim event carrying the number of units claimed
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte("600")))
+	remainingUnits, err := amc.ClaimFungibleAssetUnits(ctx, "contract1", 400, claimInfoBase64)
+	require.NoError(t, err)
+	require.Equal(t, uint64(600), remainingUnits)
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(1)
+	require.Equal(t, "ClaimFungibleAssetUnits", string(args[0]))
+	require.Equal(t, "400", string(args[2]))
+	eventName, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, "ClaimFungibleAssetUnits", eventName)
+	contractInfo := &common.FungibleAssetContractHTLC{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, "contract1", contractInfo.ContractId)
+	require.Equal(t, uint64(400), contractInfo.Agreement.NumUnits)
+	require.Equal(t, claimInfoHTLC.HashPreimageBase64, contractInfo.Claim.HashPreimageBase64)
+}
+
+func TestContractBasketLock(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	amc := am.AssetManagementContract{}
+	amc.Configure(interopChaincodeId)
+
+	basketAgreement := &common.BasketAssetExchangeAgreement{
+		Assets:         []*common.AssetExchangeAgreement{{AssetType: "bond", Id: "A001"}},
+		FungibleAssets: []*common.FungibleAssetExchangeAgreement{{AssetType: "cbdc", NumUnits: 100}},
+		Recipient:      "Bob",
+	}
+	basketAgreementBytes, _ := proto.Marshal(basketAgreement)
+	basketAgreementBase64 := base64.StdEncoding.EncodeToString(basketAgreementBytes)
+	lockInfoHTLC := &common.AssetLockHTLC{
+		HashMechanism:  common.HashMechanism_SHA256,
+		HashBase64:     []byte("MBQGA1UEBxMNU2FuIEZyYW5jaXNjbzEPMA0GA1UECxMGY2xpZW50MSQwIgYDVQQD"),
+		ExpiryTimeSecs: 1000,
+		TimeSpec:       common.TimeSpec_EPOCH,
+	}
+	lockInfoHTLCBytes, _ := proto.Marshal(lockInfoHTLC)
+	lockInfo := &common.AssetLock{
+		LockMechanism: common.LockMechanism_HTLC,
+		LockInfo:      lockInfoHTLCBytes,
+	}
+	lockInfoBytes, _ := proto.Marshal(lockInfo)
+	lockInfoBase64 := base64.StdEncoding.EncodeToString(lockInfoBytes)
+
+	// Test failure with an empty basket agreement
+	_, err := amc.LockBasket(ctx, "", lockInfoBase64)
+	require.EqualError(t, err, "empty asset agreement")
+	require.Equal(t, 0, chaincodeStub.InvokeChaincodeCallCount())
+
+	// Test success with the lock event carrying the basket agreement
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte("basket1")))
+	contractId, err := amc.LockBasket(ctx, basketAgreementBase64, lockInfoBase64)
+	require.NoError(t, err)
+	require.Equal(t, "basket1", contractId)
+	eventName, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, "LockBasket", eventName)
+	contractInfo := &common.BasketAssetContractHTLC{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, "basket1", contractInfo.ContractId)
+	require.Equal(t, "A001", contractInfo.Agreement.Assets[0].Id)
+	require.Equal(t, uint64(100), contractInfo.Agreement.FungibleAssets[0].NumUnits)
+	require.Equal(t, uint64(1000), contractInfo.Lock.ExpiryTimeSecs)
+
+	// Test success with the claim returning the basket agreement recorded by the interop chaincode
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashMechanism:      common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte("YW5jaXNjbzEeMBwGA1UE"),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_HTLC,
+		ClaimInfo:     claimInfoHTLCBytes,
+	}
+	claimInfoBytes, _ := proto.Marshal(claimInfo)
+	basketAgreement.Locker = "Alice"
+	basketAgreementBytes, _ = proto.Marshal(basketAgreement)
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte(base64.StdEncoding.EncodeToString(basketAgreementBytes))))
+	claimedAgreementBase64, err := amc.ClaimBasket(ctx, "basket1", base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	claimedAgreement, err := amc.ValidateAndExtractBasketAgreement(claimedAgreementBase64)
+	require.NoError(t, err)
+	require.Equal(t, "Alice", claimedAgreement.Locker)
+	eventName, payload = chaincodeStub.SetEventArgsForCall(1)
+	require.Equal(t, "ClaimBasket", eventName)
+	contractInfo = &common.BasketAssetContractHTLC{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, "Alice", contractInfo.Agreement.Locker)
+	require.Equal(t, claimInfoHTLC.HashPreimageBase64, contractInfo.Claim.HashPreimageBase64)
+
+	// Test failure under the scenario that the interop chaincode rejects the unlock
+	chaincodeStub.InvokeChaincodeReturns(shim.Error("cannot unlock basket with contractId basket1 before expiry time"))
+	_, err = amc.UnlockBasket(ctx, "basket1")
+	require.Error(t, err)
+	require.Equal(t, 2, chaincodeStub.SetEventCallCount())
+	fmt.Printf("Test failed as expected with error: %+v\n", err)
+}
+
+func TestContractHybridAssetLock(t *testing.T) {
+	ctx, chaincodeStub := wtest.PrepMockStub()
+	amc := am.AssetManagementContract{}
+	amc.Configure(interopChaincodeId)
+
+	assetAgreement := &common.HybridAssetExchangeAgreement{
+		AssetType: "coffee",
+		Id:        "lot-42",
+		AssetData: []byte("grade=AA"),
+		NumUnits:  50,
+		Recipient: "Bob",
+	}
+	assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+	assetAgreementBase64 := base64.StdEncoding.EncodeToString(assetAgreementBytes)
+	lockInfoHTLC := &common.AssetLockHTLC{
+		HashMechanism:  common.HashMechanism_SHA256,
+		HashBase64:     []byte("MBQGA1UEBxMNU2FuIEZyYW5jaXNjbzEPMA0GA1UECxMGY2xpZW50MSQwIgYDVQQD"),
+		ExpiryTimeSecs: 1000,
+		TimeSpec:       common.TimeSpec_EPOCH,
+	}
+	lockInfoHTLCBytes, _ := proto.Marshal(lockInfoHTLC)
+	lockInfoBytes, _ := proto.Marshal(&common.AssetLock{LockMechanism: common.LockMechanism_HTLC, LockInfo: lockInfoHTLCBytes})
+	lockInfoBase64 := base64.StdEncoding.EncodeToString(lockInfoBytes)
+
+	// Test failure with an empty hybrid asset agreement
+	_, err := amc.LockHybridAsset(ctx, "", lockInfoBase64)
+	require.EqualError(t, err, "empty asset agreement")
+	require.Equal(t, 0, chaincodeStub.InvokeChaincodeCallCount())
+
+	// Test success with the lock event carrying the hybrid asset agreement
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte("contract1")))
+	contractId, err := amc.LockHybridAsset(ctx, assetAgreementBase64, lockInfoBase64)
+	require.NoError(t, err)
+	require.Equal(t, "contract1", contractId)
+	_, args, _ := chaincodeStub.InvokeChaincodeArgsForCall(0)
+	require.Equal(t, "LockHybridAsset", string(args[0]))
+	eventName, payload := chaincodeStub.SetEventArgsForCall(0)
+	require.Equal(t, "LockHybridAsset", eventName)
+	contractInfo := &common.HybridAssetContractHTLC{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, "contract1", contractInfo.ContractId)
+	require.Equal(t, "lot-42", contractInfo.Agreement.Id)
+	require.Equal(t, uint64(50), contractInfo.Agreement.NumUnits)
+	require.Equal(t, uint64(1000), contractInfo.Lock.ExpiryTimeSecs)
+
+	// Test success with the claim returning the hybrid asset agreement recorded by the interop chaincode
+	claimInfoHTLC := &common.AssetClaimHTLC{
+		HashMechanism:      common.HashMechanism_SHA256,
+		HashPreimageBase64: []byte("YW5jaXNjbzEeMBwGA1UE"),
+	}
+	claimInfoHTLCBytes, _ := proto.Marshal(claimInfoHTLC)
+	claimInfoBytes, _ := proto.Marshal(&common.AssetClaim{LockMechanism: common.LockMechanism_HTLC, ClaimInfo: claimInfoHTLCBytes})
+	assetAgreement.Locker = "Alice"
+	assetAgreementBytes, _ = proto.Marshal(assetAgreement)
+	chaincodeStub.InvokeChaincodeReturns(shim.Success([]byte(base64.StdEncoding.EncodeToString(assetAgreementBytes))))
+	claimedAgreementBase64, err := amc.ClaimHybridAsset(ctx, "contract1", base64.StdEncoding.EncodeToString(claimInfoBytes))
+	require.NoError(t, err)
+	claimedAgreement, err := amc.ValidateAndExtractHybridAssetAgreement(claimedAgreementBase64)
+	require.NoError(t, err)
+	require.Equal(t, "Alice", claimedAgreement.Locker)
+	require.Equal(t, []byte("grade=AA"), claimedAgreement.AssetData)
+	eventName, payload = chaincodeStub.SetEventArgsForCall(1)
+	require.Equal(t, "ClaimHybridAsset", eventName)
+	contractInfo = &common.HybridAssetContractHTLC{}
+	require.NoError(t, proto.Unmarshal(payload, contractInfo))
+	require.Equal(t, "contract1", contractInfo.ContractId)
+	require.Equal(t, claimInfoHTLC.HashPreimageBase64, contractInfo.Claim.HashPreimageBase64)
+
+	// Test failure under the scenario that the interop chaincode rejects the unlock
+	chaincodeStub.InvokeChaincodeReturns(shim.Error("cannot unlock asset associated with the contractId contract1 as the expiry time is not yet elapsed"))
+	_, err = amc.UnlockHybridAsset(ctx, "contract1")
+	require.Error(t, err)
+	require.Equal(t, 2, chaincodeStub.SetEventCallCount())
+	fmt.Printf("Test failed as expected with error: %+v\n", err)
+}
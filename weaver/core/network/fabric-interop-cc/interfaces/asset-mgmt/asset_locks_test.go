@@ -38,6 +38,8 @@ type InteropCC struct {
     assetLockMap map[string]string
     fungibleAssetLockMap map[string]string
     fungibleAssetLockedCount map[string]int
+    basketLockMap map[string]string
+    hybridAssetLockMap map[string]string
 }
 
 func (cc *InteropCC) Init(stub shim.ChaincodeStubInterface) pb.Response {
@@ -45,6 +47,8 @@ func (cc *InteropCC) Init(stub shim.ChaincodeStubInterface) pb.Response {
     cc.assetLockMap = make(map[string]string)
     cc.fungibleAssetLockMap = make(map[string]string)
     cc.fungibleAssetLockedCount = make(map[string]int)
+    cc.basketLockMap = make(map[string]string)
+    cc.hybridAssetLockMap = make(map[string]string)
     return shim.Success(nil)
 }
 
@@ -202,6 +206,31 @@ func (cc *InteropCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
             return shim.Error(fmt.Sprintf("No fungible asset is locked associated with contractId %s", contractId))
 	}
     }
+    if function == "ClaimFungibleAssetUnits" {
+        contractId := args[0]
+	if _, contractExists := cc.fungibleAssetLockMap[contractId]; contractExists {
+		assetLockValSplit := strings.Split(cc.fungibleAssetLockMap[contractId], ":")
+		// caller need to be the recipient
+		if assetLockValSplit[3] != string(caller) {
+			return shim.Error(fmt.Sprintf("cannot claim fungible asset using contractId %s as caller is different from recipient", contractId))
+		}
+		lockedUnits, _ := strconv.Atoi(assetLockValSplit[1])
+		numUnits, _ := strconv.Atoi(args[1])
+		if numUnits > lockedUnits {
+			return shim.Error(fmt.Sprintf("cannot claim %d units of the asset associated with contractId %s as only %d units remain locked", numUnits, contractId, lockedUnits))
+		}
+		cc.fungibleAssetLockedCount[assetLockValSplit[0]] -= numUnits
+		if numUnits == lockedUnits {
+			delete(cc.fungibleAssetLockMap, contractId)
+		} else {
+			assetLockValSplit[1] = strconv.Itoa(lockedUnits - numUnits)
+			cc.fungibleAssetLockMap[contractId] = strings.Join(assetLockValSplit, ":")
+		}
+		return shim.Success([]byte(strconv.Itoa(lockedUnits - numUnits)))
+	} else {
+            return shim.Error(fmt.Sprintf("No fungible asset is locked associated with contractId %s", contractId))
+	}
+    }
     if function == "ClaimAssetUsingContractId" {
         contractId := args[0]
 	if _, contractExists := cc.assetLockMap[contractId]; contractExists {
@@ -216,6 +245,84 @@ func (cc *InteropCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
             return shim.Error(fmt.Sprintf("No asset is locked associated with contractId %s", contractId))
 	}
     }
+    if function == "LockBasket" {    // The basket agreement is recorded as is, with the caller as the locker
+        basketAgreement := &common.BasketAssetExchangeAgreement{}
+        arg0, _ := base64.StdEncoding.DecodeString(args[0])
+        _ = proto.Unmarshal([]byte(arg0), basketAgreement)
+        basketAgreement.Locker = string(caller)
+        contractId := generateSHA256HashInBase64Form(args[0])
+        if cc.basketLockMap[contractId] != "" {
+            return shim.Error(fmt.Sprintf("contractId %s already exists for the basket asset agreement", contractId))
+        }
+        basketAgreementBytes, _ := proto.Marshal(basketAgreement)
+        cc.basketLockMap[contractId] = base64.StdEncoding.EncodeToString(basketAgreementBytes)
+        return shim.Success([]byte(contractId))
+    }
+    if function == "IsBasketLocked" {
+        if _, contractExists := cc.basketLockMap[args[0]]; contractExists {
+            return shim.Success([]byte("true"))
+        } else {
+            return shim.Success([]byte("false"))
+        }
+    }
+    if function == "ClaimBasket" || function == "UnlockBasket" {
+        contractId := args[0]
+	if _, contractExists := cc.basketLockMap[contractId]; contractExists {
+		basketAgreement := &common.BasketAssetExchangeAgreement{}
+		basketAgreementBytes, _ := base64.StdEncoding.DecodeString(cc.basketLockMap[contractId])
+		_ = proto.Unmarshal(basketAgreementBytes, basketAgreement)
+		// caller need to be the recipient to claim, and the locker to unlock
+		if function == "ClaimBasket" && basketAgreement.Recipient != string(caller) {
+			return shim.Error(fmt.Sprintf("cannot claim basket using contractId %s as caller is different from recipient", contractId))
+		}
+		if function == "UnlockBasket" && basketAgreement.Locker != string(caller) {
+			return shim.Error(fmt.Sprintf("cannot unlock basket using contractId %s as caller is different from locker", contractId))
+		}
+		delete(cc.basketLockMap, contractId)
+		return shim.Success([]byte(base64.StdEncoding.EncodeToString(basketAgreementBytes)))
+	} else {
+            return shim.Error(fmt.Sprintf("contractId %s is not associated with any currently locked basket", contractId))
+	}
+    }
+    if function == "LockHybridAsset" {    // The hybrid asset agreement is recorded as is, with the caller as the locker
+        assetAgreement := &common.HybridAssetExchangeAgreement{}
+        arg0, _ := base64.StdEncoding.DecodeString(args[0])
+        _ = proto.Unmarshal([]byte(arg0), assetAgreement)
+        assetAgreement.Locker = string(caller)
+        contractId := generateSHA256HashInBase64Form(args[0])
+        assetAgreementBytes, _ := proto.Marshal(assetAgreement)
+        cc.hybridAssetLockMap[contractId] = base64.StdEncoding.EncodeToString(assetAgreementBytes)
+        return shim.Success([]byte(contractId))
+    }
+    if function == "IsHybridAssetLocked" {
+        if _, contractExists := cc.hybridAssetLockMap[args[0]]; contractExists {
+            return shim.Success([]byte("true"))
+        } else {
+            return shim.Success([]byte("false"))
+        }
+    }
+    if function == "ClaimHybridAsset" || function == "UnlockHybridAsset" {
+        contractId := args[0]
+	if _, contractExists := cc.hybridAssetLockMap[contractId]; contractExists {
+		assetAgreement := &common.HybridAssetExchangeAgreement{}
+		assetAgreementBytes, _ := base64.StdEncoding.DecodeString(cc.hybridAssetLockMap[contractId])
+		_ = proto.Unmarshal(assetAgreementBytes, assetAgreement)
+		// caller need to be the recipient to claim, and the locker to unlock
+		if function == "ClaimHybridAsset" && assetAgreement.Recipient != string(caller) {
+			return shim.Error(fmt.Sprintf("cannot claim hybrid asset using contractId %s as caller is different from recipient", contractId))
+		}
+		if function == "UnlockHybridAsset" && assetAgreement.Locker != string(caller) {
+			return shim.Error(fmt.Sprintf("cannot unlock hybrid asset using contractId %s as caller is different from locker", contractId))
+		}
+		delete(cc.hybridAssetLockMap, contractId)
+		return shim.Success([]byte(base64.StdEncoding.EncodeToString(assetAgreementBytes)))
+	} else {
+            return shim.Error(fmt.Sprintf("contractId %s is not associated with any currently locked hybrid asset", contractId))
+	}
+    }
+    if function == "GetHybridAssetTimeToRelease" {
+        return shim.Success([]byte(strconv.Itoa(len(cc.hybridAssetLockMap))))
+    }
     if function == "GetAllLockedAssets" || function == "GetAllAssetsLockedUntil" {
         assets := []string{}
         for key, val := range cc.assetLockMap {
@@ -249,6 +356,18 @@ func (cc *InteropCC) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
     if function == "GetFungibleAssetTimeToRelease" {
         return shim.Success([]byte(strconv.Itoa(len(cc.fungibleAssetLockMap))))
     }
+    if function == "GetFungibleAssetTimeToReleaseByContractId" {
+        if _, contractExists := cc.fungibleAssetLockMap[args[0]]; !contractExists {
+            return shim.Error(fmt.Sprintf("No fungible asset is locked associated with contractId %s", args[0]))
+        }
+        return shim.Success([]byte(strconv.Itoa(len(cc.fungibleAssetLockMap))))
+    }
+    if function == "GetFungibleAssetRemainingUnits" {
+        if _, contractExists := cc.fungibleAssetLockMap[args[0]]; !contractExists {
+            return shim.Error(fmt.Sprintf("No fungible asset is locked associated with contractId %s", args[0]))
+        }
+        return shim.Success([]byte(strings.Split(cc.fungibleAssetLockMap[args[0]], ":")[1]))
+    }
     if function == "GetHTLCHash" {
         return shim.Success([]byte(defaultHash))
     }
@@ -368,8 +487,17 @@ func TestAssetLock(t *testing.T) {
     require.Error(t, err)
     require.False(t, lockSuccess)
 
-    // Test success
+    // Test failure with an unsupported hash mechanism
     lockInfoHTLC.HashBase64 = hash
+    lockInfoHTLC.HashMechanism = common.HashMechanism(100)
+    lockInfoBytes, _ = proto.Marshal(lockInfoHTLC)
+    lockInfo.LockInfo = lockInfoBytes
+    contractId, err = amcc.LockAsset(amstub, assetAgreement, lockInfo)
+    require.EqualError(t, err, "unsupported hash mechanism: 100")
+    require.Empty(t, contractId)
+
+    // Test success
+    lockInfoHTLC.HashMechanism = common.HashMechanism_KECCAK256
     lockInfoBytes, _ = proto.Marshal(lockInfoHTLC)
     lockInfo.LockInfo = lockInfoBytes
     contractId, err = amcc.LockAsset(amstub, assetAgreement, lockInfo)
@@ -1303,6 +1431,266 @@ func TestFungibleAssetClaim(t *testing.T) {
     require.Equal(t, retrievedPreimage, string(hashPreimage))
 }
 
+func TestFungibleAssetPartialClaim(t *testing.T) {
+    amcc, amstub := createAssetMgmtCCInstance()
+    assetType := "cbdc"
+    numUnits := uint64(1000)
+    recipient := "Bob"
+    locker := clientId
+    hash := []byte("MBQGA1UEBxMNU2FuIEZyYW5jaXNjbzEPMA0GA1UECxMGY2xpZW50MSQwIgYDVQQD")
+    hashPreimage := []byte("YW5jaXNjbzEeMBwGA1UE")
+    claimInfoHTLC := &common.AssetClaimHTLC {
+        HashPreimageBase64: hashPreimage,
+    }
+    claimInfoBytes, _ := proto.Marshal(claimInfoHTLC)
+    claimInfo := &common.AssetClaim {
+        LockMechanism: common.LockMechanism_HTLC,
+        ClaimInfo: claimInfoBytes,
+    }
+    assetAgreement := &common.FungibleAssetExchangeAgreement {
+        AssetType: assetType,
+        NumUnits: numUnits,
+        Recipient: recipient,
+        Locker: locker,
+    }
+
+    // Test failure when interop CC is not set
+    remainingUnits, err := amcc.ClaimFungibleAssetUnits(amstub, "", 400, claimInfo)
+    require.Error(t, err)
+    require.Equal(t, uint64(0), remainingUnits)
+
+    _, istub := associateInteropCCInstance(amcc, amstub)
+
+    lockInfoHTLC := &common.AssetLockHTLC {
+        HashBase64: hash,
+        ExpiryTimeSecs: 0,
+    }
+    lockInfoBytes, _ := proto.Marshal(lockInfoHTLC)
+    lockInfo := &common.AssetLock {
+        LockMechanism: common.LockMechanism_HTLC,
+        LockInfo: lockInfoBytes,
+    }
+    contractId, err := amcc.LockFungibleAsset(amstub, assetAgreement, lockInfo)
+    require.NoError(t, err)
+
+    setCreator(amstub, recipient)
+    setCreator(istub, recipient)
+
+    // Test failure with no units to claim
+    _, err = amcc.ClaimFungibleAssetUnits(amstub, contractId, 0, claimInfo)
+    require.EqualError(t, err, "number of units to claim must be a positive number")
+
+    // Test success with part of the locked units being claimed
+    remainingUnits, err = amcc.ClaimFungibleAssetUnits(amstub, contractId, 400, claimInfo)
+    require.NoError(t, err)
+    require.Equal(t, uint64(600), remainingUnits)
+    lockSuccess, err := amcc.IsFungibleAssetLocked(amstub, contractId)
+    require.NoError(t, err)
+    require.True(t, lockSuccess)
+    lockedCount, err := amcc.GetTotalFungibleLockedAssets(amstub, assetType)
+    require.NoError(t, err)
+    require.Equal(t, uint64(600), lockedCount)
+    lockedUnits, err := amcc.GetFungibleAssetRemainingUnits(amstub, contractId)
+    require.NoError(t, err)
+    require.Equal(t, uint64(600), lockedUnits)
+
+    // Test failure with more units to claim than remain locked
+    _, err = amcc.ClaimFungibleAssetUnits(amstub, contractId, 700, claimInfo)
+    require.Error(t, err)
+
+    // Test success with the remaining units being claimed
+    remainingUnits, err = amcc.ClaimFungibleAssetUnits(amstub, contractId, 600, claimInfo)
+    require.NoError(t, err)
+    require.Equal(t, uint64(0), remainingUnits)
+    setCreator(amstub, locker)
+    setCreator(istub, locker)
+    lockSuccess, err = amcc.IsFungibleAssetLocked(amstub, contractId)
+    require.NoError(t, err)
+    require.False(t, lockSuccess)
+    lockedCount, err = amcc.GetTotalFungibleLockedAssets(amstub, assetType)
+    require.NoError(t, err)
+    require.Equal(t, uint64(0), lockedCount)
+    _, err = amcc.GetFungibleAssetRemainingUnits(amstub, contractId)
+    require.Error(t, err)
+}
+
+func TestBasketLock(t *testing.T) {
+    amcc, amstub := createAssetMgmtCCInstance()
+    recipient := "Bob"
+    locker := clientId
+    hash := []byte("MBQGA1UEBxMNU2FuIEZyYW5jaXNjbzEPMA0GA1UECxMGY2xpZW50MSQwIgYDVQQD")
+    hashPreimage := []byte("YW5jaXNjbzEeMBwGA1UE")
+    basketAgreement := &common.BasketAssetExchangeAgreement {
+        Assets: []*common.AssetExchangeAgreement {
+            { AssetType: "bond", Id: "A001" },
+        },
+        FungibleAssets: []*common.FungibleAssetExchangeAgreement {
+            { AssetType: "cbdc", NumUnits: 100 },
+        },
+        Recipient: recipient,
+    }
+    lockInfoHTLC := &common.AssetLockHTLC {
+        HashBase64: hash,
+        ExpiryTimeSecs: uint64(time.Now().Unix()) + 300,
+    }
+    lockInfoBytes, _ := proto.Marshal(lockInfoHTLC)
+    lockInfo := &common.AssetLock {
+        LockMechanism: common.LockMechanism_HTLC,
+        LockInfo: lockInfoBytes,
+    }
+    claimInfoHTLC := &common.AssetClaimHTLC {
+        HashPreimageBase64: hashPreimage,
+    }
+    claimInfoBytes, _ := proto.Marshal(claimInfoHTLC)
+    claimInfo := &common.AssetClaim {
+        LockMechanism: common.LockMechanism_HTLC,
+        ClaimInfo: claimInfoBytes,
+    }
+
+    // Test failure when interop CC is not set
+    _, err := amcc.LockBasket(amstub, basketAgreement, lockInfo)
+    require.Error(t, err)
+
+    _, istub := associateInteropCCInstance(amcc, amstub)
+
+    // Test failures with invalid baskets
+    _, err = amcc.LockBasket(amstub, &common.BasketAssetExchangeAgreement{ Recipient: recipient }, lockInfo)
+    require.EqualError(t, err, "empty basket")
+    basketAgreement.FungibleAssets[0].NumUnits = 0
+    _, err = amcc.LockBasket(amstub, basketAgreement, lockInfo)
+    require.EqualError(t, err, "invalid number of asset units")
+    basketAgreement.FungibleAssets[0].NumUnits = 100
+    lockInfo.LockMechanism = common.LockMechanism_SIGNATURE
+    _, err = amcc.LockBasket(amstub, basketAgreement, lockInfo)
+    require.EqualError(t, err, "a basket of assets can only be locked with a hash lock")
+    lockInfo.LockMechanism = common.LockMechanism_HTLC
+
+    // Test success
+    contractId, err := amcc.LockBasket(amstub, basketAgreement, lockInfo)
+    require.NoError(t, err)
+    lockSuccess, err := amcc.IsBasketLocked(amstub, contractId)
+    require.NoError(t, err)
+    require.True(t, lockSuccess)
+
+    // Test failure to unlock by someone other than the locker
+    setCreator(amstub, recipient)
+    setCreator(istub, recipient)
+    _, err = amcc.UnlockBasket(amstub, contractId)
+    require.Error(t, err)
+
+    // Claim the basket and confirm that the claimed assets are returned
+    claimedAgreement, err := amcc.ClaimBasket(amstub, contractId, claimInfo)
+    require.NoError(t, err)
+    require.Equal(t, locker, claimedAgreement.Locker)
+    require.Equal(t, recipient, claimedAgreement.Recipient)
+    require.Equal(t, 1, len(claimedAgreement.Assets))
+    require.Equal(t, "A001", claimedAgreement.Assets[0].Id)
+    require.Equal(t, 1, len(claimedAgreement.FungibleAssets))
+    require.Equal(t, uint64(100), claimedAgreement.FungibleAssets[0].NumUnits)
+    lockSuccess, err = amcc.IsBasketLocked(amstub, contractId)
+    require.NoError(t, err)
+    require.False(t, lockSuccess)
+
+    // Test failure to claim or unlock a basket that is no longer locked
+    _, err = amcc.ClaimBasket(amstub, contractId, claimInfo)
+    require.Error(t, err)
+    setCreator(amstub, locker)
+    setCreator(istub, locker)
+    _, err = amcc.UnlockBasket(amstub, contractId)
+    require.Error(t, err)
+
+    // Lock again, and unlock
+    contractId, err = amcc.LockBasket(amstub, basketAgreement, lockInfo)
+    require.NoError(t, err)
+    unlockedAgreement, err := amcc.UnlockBasket(amstub, contractId)
+    require.NoError(t, err)
+    require.Equal(t, "bond", unlockedAgreement.Assets[0].AssetType)
+    require.Equal(t, "cbdc", unlockedAgreement.FungibleAssets[0].AssetType)
+}
+
+func TestHybridAssetLock(t *testing.T) {
+    amcc, amstub := createAssetMgmtCCInstance()
+    recipient := "Bob"
+    locker := clientId
+    hash := []byte("MBQGA1UEBxMNU2FuIEZyYW5jaXNjbzEPMA0GA1UECxMGY2xpZW50MSQwIgYDVQQD")
+    hashPreimage := []byte("YW5jaXNjbzEeMBwGA1UE")
+    assetAgreement := &common.HybridAssetExchangeAgreement {
+        AssetType: "coffee",
+        Id: "lot-42",
+        AssetData: []byte("grade=AA"),
+        NumUnits: 50,
+        Recipient: recipient,
+    }
+    lockInfoHTLC := &common.AssetLockHTLC {
+        HashBase64: hash,
+        ExpiryTimeSecs: uint64(time.Now().Unix()) + 300,
+    }
+    lockInfoBytes, _ := proto.Marshal(lockInfoHTLC)
+    lockInfo := &common.AssetLock {
+        LockMechanism: common.LockMechanism_HTLC,
+        LockInfo: lockInfoBytes,
+    }
+    claimInfoHTLC := &common.AssetClaimHTLC {
+        HashPreimageBase64: hashPreimage,
+    }
+    claimInfoBytes, _ := proto.Marshal(claimInfoHTLC)
+    claimInfo := &common.AssetClaim {
+        LockMechanism: common.LockMechanism_HTLC,
+        ClaimInfo: claimInfoBytes,
+    }
+
+    // Test failure when interop CC is not set
+    _, err := amcc.LockHybridAsset(amstub, assetAgreement, lockInfo)
+    require.Error(t, err)
+
+    _, istub := associateInteropCCInstance(amcc, amstub)
+
+    // Test failures with invalid agreements
+    assetAgreement.Id = ""
+    _, err = amcc.LockHybridAsset(amstub, assetAgreement, lockInfo)
+    require.EqualError(t, err, "empty asset id")
+    assetAgreement.Id = "lot-42"
+    assetAgreement.NumUnits = 0
+    _, err = amcc.LockHybridAsset(amstub, assetAgreement, lockInfo)
+    require.EqualError(t, err, "invalid number of asset units")
+    assetAgreement.NumUnits = 50
+
+    // Test success
+    contractId, err := amcc.LockHybridAsset(amstub, assetAgreement, lockInfo)
+    require.NoError(t, err)
+    lockSuccess, err := amcc.IsHybridAssetLocked(amstub, contractId)
+    require.NoError(t, err)
+    require.True(t, lockSuccess)
+    _, err = amcc.GetHybridAssetTimeToRelease(amstub, contractId)
+    require.NoError(t, err)
+
+    // Claim the hybrid asset and confirm that the claimed units are returned
+    setCreator(amstub, recipient)
+    setCreator(istub, recipient)
+    claimedAgreement, err := amcc.ClaimHybridAsset(amstub, contractId, claimInfo)
+    require.NoError(t, err)
+    require.Equal(t, locker, claimedAgreement.Locker)
+    require.Equal(t, "lot-42", claimedAgreement.Id)
+    require.Equal(t, []byte("grade=AA"), claimedAgreement.AssetData)
+    require.Equal(t, uint64(50), claimedAgreement.NumUnits)
+    lockSuccess, err = amcc.IsHybridAssetLocked(amstub, contractId)
+    require.NoError(t, err)
+    require.False(t, lockSuccess)
+
+    // Test failure to claim a hybrid asset that is no longer locked
+    _, err = amcc.ClaimHybridAsset(amstub, contractId, claimInfo)
+    require.Error(t, err)
+
+    // Lock again, and unlock
+    setCreator(amstub, locker)
+    setCreator(istub, locker)
+    contractId, err = amcc.LockHybridAsset(amstub, assetAgreement, lockInfo)
+    require.NoError(t, err)
+    unlockedAgreement, err := amcc.UnlockHybridAsset(amstub, contractId)
+    require.NoError(t, err)
+    require.Equal(t, "coffee", unlockedAgreement.AssetType)
+}
+
 func TestFungibleAssetCountFunctions(t *testing.T) {
     amcc, amstub := createAssetMgmtCCInstance()
     assetType := "cbdc"
@@ -1502,7 +1890,7 @@ func TestAssetTimeFunctions(t *testing.T) {
         Locker: locker,
     }
     fungibleAssetExchangeAgreement := &common.FungibleAssetExchangeAgreement {
-        AssetType: assetType,
+        AssetType: fungibleAssetType,
         NumUnits: numUnits,
         Recipient: recipient,
         Locker: locker,
@@ -1528,6 +1916,10 @@ func TestAssetTimeFunctions(t *testing.T) {
     require.Error(t, err)
     require.Equal(t, uint64(0), getSuccess)
 
+    getSuccess, err = amcc.GetFungibleAssetTimeToReleaseByContractId(amstub, "some-contract-id")
+    require.Error(t, err)
+    require.Equal(t, uint64(0), getSuccess)
+
     endTime := currTime.Add(30 * time.Second)     // 30 seconds time window
     getListSuccess, err := amcc.GetAllAssetsLockedUntil(amstub, uint64(endTime.Unix()))
     require.Error(t, err)
@@ -1596,6 +1988,15 @@ func TestAssetTimeFunctions(t *testing.T) {
     require.Error(t, err)
     require.Equal(t, uint64(0), getSuccess)
 
+    getSuccess, err = amcc.GetFungibleAssetTimeToReleaseByContractId(amstub, "")
+    require.Error(t, err)
+    require.Equal(t, uint64(0), getSuccess)
+
+    // Test failure when no fungible asset is locked with the contractId
+    getSuccess, err = amcc.GetFungibleAssetTimeToReleaseByContractId(amstub, "some-contract-id")
+    require.Error(t, err)
+    require.Equal(t, uint64(0), getSuccess)
+
     getListSuccess, err = amcc.GetAllAssetsLockedUntil(amstub, 0)
     require.Error(t, err)
     require.Equal(t, 0, len(getListSuccess))
@@ -1613,16 +2014,20 @@ func TestAssetTimeFunctions(t *testing.T) {
     require.Less(t, uint64(0), getSuccess)
 
     // Lock a fungible asset
-    _, err = amcc.LockFungibleAsset(amstub, fungibleAssetExchangeAgreement, lockInfo)
+    fungibleAssetExchangeAgreement.Recipient = recipient
+    fungibleAssetExchangeAgreement.Locker = locker
+    fungibleContractId, err := amcc.LockFungibleAsset(amstub, fungibleAssetExchangeAgreement, lockInfo)
     require.NoError(t, err)
 
     // Test success
-    fungibleAssetExchangeAgreement.Recipient = recipient
-    fungibleAssetExchangeAgreement.Locker = locker
     getSuccess, err = amcc.GetFungibleAssetTimeToRelease(amstub, fungibleAssetExchangeAgreement)
     require.NoError(t, err)
     require.Less(t, uint64(0), getSuccess)
 
+    getSuccess, err = amcc.GetFungibleAssetTimeToReleaseByContractId(amstub, fungibleContractId)
+    require.NoError(t, err)
+    require.Less(t, uint64(0), getSuccess)
+
     // Test success
     getListSuccess, err = amcc.GetAllAssetsLockedUntil(amstub, uint64(endTime.Unix()))
     require.NoError(t, err)
@@ -0,0 +1,43 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package assetmgmt
+
+import (
+    "github.com/hyperledger/fabric-contract-api-go/contractapi"
+    "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/events"
+)
+
+const (
+    // Name of the chaincode event carrying a ChaincodeEventEnvelope
+    EventEnvelopeName = events.EnvelopeName
+    // Version of the envelope format set by EventAccumulator
+    EventEnvelopeVersion = events.EnvelopeVersion
+)
+
+// EventAccumulator collects the events emitted within a transaction, to set them together as one chaincode event
+// (Fabric retains only the last event set in a transaction). It is shared with the asset exchange library and the
+// interop chaincode (see the libs/utils events package), so their events can be accumulated in the same envelope.
+type EventAccumulator = events.Accumulator
+
+// EventAccumulatorContextInterface is a transaction context that accumulates the events emitted in the transaction
+type EventAccumulatorContextInterface = events.AccumulatorContextInterface
+
+// TransactionContext is a transaction context in which the events emitted by AssetManagementContract functions are
+// accumulated instead of being set directly, so that they are not overridden by other events set in the transaction.
+// To use it, set it as the TransactionContextHandler of the contract, and FlushEvents as its AfterTransaction function.
+// Application events should then be added to the accumulator of the context too.
+type TransactionContext = events.TransactionContext
+
+// FlushEvents sets the events accumulated in the transaction context as one chaincode event
+func FlushEvents(ctx EventAccumulatorContextInterface) error {
+    return events.Flush(ctx)
+}
+
+// Accumulate the event if the transaction context accumulates events, else set it as the chaincode event of the transaction
+func setEvent(ctx contractapi.TransactionContextInterface, eventName string, payload []byte) error {
+    return events.SetEvent(ctx, eventName, payload)
+}
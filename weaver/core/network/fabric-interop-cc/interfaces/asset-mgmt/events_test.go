@@ -0,0 +1,67 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package assetmgmt_test
+
+import (
+    "testing"
+
+    "github.com/golang/protobuf/proto"
+    "github.com/hyperledger/fabric-chaincode-go/shim"
+    "github.com/stretchr/testify/require"
+    "github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+    am "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/interfaces/asset-mgmt/v2"
+    wtest "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/testutils"
+)
+
+func TestEventAccumulator(t *testing.T) {
+    mockCtx, chaincodeStub := wtest.PrepMockStub()
+    chaincodeStub.InvokeChaincodeReturns(shim.Success(nil))
+    amc := am.AssetManagementContract{}
+    amc.Configure(interopChaincodeId)
+
+    // Test that events are set directly without an accumulating transaction context
+    unlocked, err := amc.UnlockFungibleAsset(mockCtx, "contract1")
+    require.NoError(t, err)
+    require.True(t, unlocked)
+    require.Equal(t, 1, chaincodeStub.SetEventCallCount())
+    eventName, _ := chaincodeStub.SetEventArgsForCall(0)
+    require.Equal(t, "UnlockFungibleAsset", eventName)
+
+    // Test that events are accumulated along with application events, and set as one envelope
+    ctx := &am.TransactionContext{}
+    ctx.SetStub(chaincodeStub)
+    _, err = amc.UnlockFungibleAsset(ctx, "contract1")
+    require.NoError(t, err)
+    ctx.GetEventAccumulator().AddEvent("TransferAsset", []byte("asset1"))
+    _, err = amc.UnlockAssetUsingContractId(ctx, "contract2")
+    require.NoError(t, err)
+    require.Equal(t, 1, chaincodeStub.SetEventCallCount())
+    require.Len(t, ctx.GetEventAccumulator().Events(), 3)
+
+    err = am.FlushEvents(ctx)
+    require.NoError(t, err)
+    require.Equal(t, 2, chaincodeStub.SetEventCallCount())
+    eventName, payload := chaincodeStub.SetEventArgsForCall(1)
+    require.Equal(t, am.EventEnvelopeName, eventName)
+    envelope := &common.ChaincodeEventEnvelope{}
+    require.NoError(t, proto.Unmarshal(payload, envelope))
+    require.Equal(t, uint32(am.EventEnvelopeVersion), envelope.Version)
+    require.Len(t, envelope.Events, 3)
+    require.Equal(t, "UnlockFungibleAsset", envelope.Events[0].EventName)
+    require.Equal(t, "TransferAsset", envelope.Events[1].EventName)
+    require.Equal(t, []byte("asset1"), envelope.Events[1].Payload)
+    require.Equal(t, "UnlockAsset", envelope.Events[2].EventName)
+    contractInfo := &common.AssetContractHTLC{}
+    require.NoError(t, proto.Unmarshal(envelope.Events[2].Payload, contractInfo))
+    require.Equal(t, "contract2", contractInfo.ContractId)
+
+    // Test that nothing is set when no events are accumulated
+    require.Empty(t, ctx.GetEventAccumulator().Events())
+    err = am.FlushEvents(ctx)
+    require.NoError(t, err)
+    require.Equal(t, 2, chaincodeStub.SetEventCallCount())
+}
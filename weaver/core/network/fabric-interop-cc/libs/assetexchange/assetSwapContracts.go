@@ -12,10 +12,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	log "github.com/sirupsen/logrus"
 )
@@ -42,7 +42,7 @@ func LockAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID, a
 		return "", logThenErrorf("error in locker validation: %+v", err)
 	}
 
-	lockInfo, expiryTimeSecs, err := getLockInfoAndExpiryTimeSecs(lockInfoBytesBase64)
+	lockInfo, expiryTimeSecs, err := getLockInfoAndExpiryTimeSecs(ctx, lockInfoBytesBase64)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -107,7 +107,7 @@ func LockFungibleAsset(ctx contractapi.TransactionContextInterface, callerChainc
 		return "", logThenErrorf("error in locker validation: %+v", err)
 	}
 
-	lockInfo, expiryTimeSecs, err := getLockInfoAndExpiryTimeSecs(lockInfoBytesBase64)
+	lockInfo, expiryTimeSecs, err := getLockInfoAndExpiryTimeSecs(ctx, lockInfoBytesBase64)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -116,7 +116,7 @@ func LockFungibleAsset(ctx contractapi.TransactionContextInterface, callerChainc
 	contractId := GenerateFungibleAssetLockContractId(ctx, callerChaincodeID, assetAgreement)
 
 	assetLockVal := FungibleAssetLockValue{Type: assetAgreement.AssetType, NumUnits: assetAgreement.NumUnits, Locker: assetAgreement.Locker,
-		Recipient: assetAgreement.Recipient, LockInfo: lockInfo, ExpiryTimeSecs: expiryTimeSecs}
+		Recipient: assetAgreement.Recipient, LockInfo: lockInfo, ExpiryTimeSecs: expiryTimeSecs, ChaincodeId: callerChaincodeID}
 
 	assetLockValBytes, err := ctx.GetStub().GetState(contractId)
 	if err != nil {
@@ -137,6 +137,11 @@ func LockFungibleAsset(ctx contractapi.TransactionContextInterface, callerChainc
 		return "", logThenErrorf("failed to write to the world state: %+v", err)
 	}
 
+	err = recordFungibleLockedUnits(ctx, callerChaincodeID, assetAgreement.AssetType, contractId, assetAgreement.NumUnits)
+	if err != nil {
+		return "", err
+	}
+
 	return contractId, nil
 }
 
@@ -185,6 +190,9 @@ func ClaimAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID,
 	if assetLockVal.Locker != assetAgreement.Locker || assetLockVal.Recipient != assetAgreement.Recipient {
 		return "", logThenErrorf("cannot claim asset of type %s and ID %s as it is locked by %s for %s", assetAgreement.AssetType, assetAgreement.Id, assetLockVal.Locker, assetLockVal.Recipient)
 	}
+	if assetLockVal.BasketLock {
+		return "", logThenErrorf("cannot claim asset of type %s and ID %s on its own as it is locked in the basket with contractId %s", assetAgreement.AssetType, assetAgreement.Id, assetLockVal.ContractId)
+	}
 
 	return assetLockVal.ContractId, claimAssetCommon(ctx, assetLockVal.LockInfo, assetLockVal.ExpiryTimeSecs, assetLockVal.Recipient, assetLockKey, assetLockVal.ContractId, claimInfoBytesBase64)
 }
@@ -196,8 +204,55 @@ func ClaimFungibleAsset(ctx contractapi.TransactionContextInterface, contractId,
 	if err != nil {
 		return logThenErrorf(err.Error())
 	}
-	
-	return claimAssetCommon(ctx, assetLockVal.LockInfo, assetLockVal.ExpiryTimeSecs, assetLockVal.Recipient, "", contractId, claimInfoBytesBase64)
+
+	err = claimAssetCommon(ctx, assetLockVal.LockInfo, assetLockVal.ExpiryTimeSecs, assetLockVal.Recipient, "", contractId, claimInfoBytesBase64)
+	if err != nil {
+		return err
+	}
+
+	// the units not claimed earlier in partial claims are claimed now
+	return recordFungibleLockedUnits(ctx, assetLockVal.ChaincodeId, assetLockVal.Type, contractId, 0)
+}
+
+/*
+ * ClaimFungibleAssetUnits cc is used to record the claim of numUnits out of the units of a fungible asset locked on the
+ * ledger. The remaining units stay locked, and can be claimed later (with the same claim information) before the lock
+ * expires, or unlocked by the locker after it expires. It returns the number of units that remain locked.
+ */
+func ClaimFungibleAssetUnits(ctx contractapi.TransactionContextInterface, contractId string, numUnits uint64, claimInfoBytesBase64 string) (uint64, error) {
+
+	assetLockVal, err := fetchFungibleAssetLocked(ctx, contractId)
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+
+	remainingUnits := assetLockVal.GetRemainingUnits()
+	if numUnits == 0 {
+		return remainingUnits, logThenErrorf("number of units to claim must be a positive number")
+	}
+	if numUnits > remainingUnits {
+		return remainingUnits, logThenErrorf("cannot claim %d units of the asset associated with contractId %s as only %d units remain locked", numUnits, contractId, remainingUnits)
+	}
+
+	if numUnits == remainingUnits {
+		err = claimAssetCommon(ctx, assetLockVal.LockInfo, assetLockVal.ExpiryTimeSecs, assetLockVal.Recipient, "", contractId, claimInfoBytesBase64)
+	} else {
+		err = validateAssetClaim(ctx, assetLockVal.LockInfo, assetLockVal.ExpiryTimeSecs, assetLockVal.Recipient, contractId, claimInfoBytesBase64)
+		if err == nil {
+			assetLockVal.ClaimedUnits += numUnits
+			err = putFungibleAssetLocked(ctx, contractId, assetLockVal)
+		}
+	}
+	if err != nil {
+		return remainingUnits, err
+	}
+
+	err = recordFungibleLockedUnits(ctx, assetLockVal.ChaincodeId, assetLockVal.Type, contractId, remainingUnits-numUnits)
+	if err != nil {
+		return remainingUnits, err
+	}
+
+	return remainingUnits - numUnits, nil
 }
 
 // ClaimAsset cc is used to record claim of an asset on the ledger (this uses the contractId)
@@ -207,14 +262,47 @@ func ClaimAssetUsingContractId(ctx contractapi.TransactionContextInterface, cont
 	if err != nil {
 		return logThenErrorf(err.Error())
 	}
-	
-	return claimAssetCommon(ctx, assetLockVal.GetLockInfo(), assetLockVal.GetExpiryTimeSecs(), assetLockVal.GetRecipient(), assetLockKey, contractId, claimInfoBytesBase64)
+
+	err = claimAssetCommon(ctx, assetLockVal.GetLockInfo(), assetLockVal.GetExpiryTimeSecs(), assetLockVal.GetRecipient(), assetLockKey, contractId, claimInfoBytesBase64)
+	if err != nil {
+		return err
+	}
+
+	return releaseFungibleLockedUnits(ctx, contractId, assetLockVal)
 }
 
-// Common Claim function for both fungible and non-fungible assets, 
+// Common Claim function for both fungible and non-fungible assets,
 // with or without contractId
 func claimAssetCommon(ctx contractapi.TransactionContextInterface, lockInfo interface{}, expiryTimeSecs uint64, recipient, assetLockKey, contractId, claimInfoBytesBase64 string) error {
 
+	err := validateAssetClaim(ctx, lockInfo, expiryTimeSecs, recipient, contractId, claimInfoBytesBase64)
+	if err != nil {
+		return err
+	}
+
+	if assetLockKey != "" {
+		err = ctx.GetStub().DelState(assetLockKey)
+		if err != nil {
+			return logThenErrorf("failed to delete lock for the asset associated with the contractId %s: %+v", contractId, err)
+		}
+
+		err = ctx.GetStub().PutState(generateAssetLockMapKey(assetLockKey), []byte(contractId))
+		if err != nil {
+			return logThenErrorf("failed to write to the world state: %+v", err)
+		}
+	}
+
+	err = ctx.GetStub().DelState(generateContractIdMapKey(contractId))
+	if err != nil {
+		return logThenErrorf("failed to delete the contractId %s as part of asset claim: %+v", contractId, err)
+	}
+
+	return nil
+}
+
+// Check that the transaction creator is authorised to claim the asset locked under contractId with the claim information
+func validateAssetClaim(ctx contractapi.TransactionContextInterface, lockInfo interface{}, expiryTimeSecs uint64, recipient, contractId, claimInfoBytesBase64 string) error {
+
 	txCreatorECertBase64, err := getECertOfTxCreatorBase64(ctx)
 	if err != nil {
 		return logThenErrorf("unable to get the transaction creator information: %+v", err)
@@ -230,7 +318,10 @@ func claimAssetCommon(ctx contractapi.TransactionContextInterface, lockInfo inte
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= expiryTimeSecs {
 		return logThenErrorf("cannot claim asset associated with contractId %s as the expiry time is already elapsed", contractId)
 	}
@@ -254,25 +345,16 @@ func claimAssetCommon(ctx contractapi.TransactionContextInterface, lockInfo inte
 		if err != nil {
 			return logThenErrorf("failed to write to the world state: %+v", err)
 		}
-	}
-
-	if assetLockKey != "" {
-		err = ctx.GetStub().DelState(assetLockKey)
+	} else if claimInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+		isAuthorized, err := validateClaimSignature(claimInfo, lockInfo, contractId, recipient)
 		if err != nil {
-			return logThenErrorf("failed to delete lock for the asset associated with the contractId %s: %+v", contractId, err)
+			return logThenErrorf("claim asset associated with contractId %s failed with error: %v", contractId, err)
 		}
-		
-		err = ctx.GetStub().PutState(generateAssetLockMapKey(assetLockKey), []byte(contractId))
-		if err != nil {
-			return logThenErrorf("failed to write to the world state: %+v", err)
+		if !isAuthorized {
+			return logThenErrorf("cannot claim asset associated with contractId %s as the claim is not authorised by the arbiter", contractId)
 		}
 	}
 
-	err = ctx.GetStub().DelState(generateContractIdMapKey(contractId))
-	if err != nil {
-		return logThenErrorf("failed to delete the contractId %s as part of asset claim: %+v", contractId, err)
-	}
-
 	return nil
 }
 
@@ -321,6 +403,9 @@ func UnlockAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID,
 	if assetLockVal.Locker != assetAgreement.Locker || assetLockVal.Recipient != assetAgreement.Recipient {
 		return "", logThenErrorf("cannot unlock asset of type %s and ID %s as it is locked by %s for %s", assetAgreement.AssetType, assetAgreement.Id, assetLockVal.Locker, assetLockVal.Recipient)
 	}
+	if assetLockVal.BasketLock {
+		return "", logThenErrorf("cannot unlock asset of type %s and ID %s on its own as it is locked in the basket with contractId %s", assetAgreement.AssetType, assetAgreement.Id, assetLockVal.ContractId)
+	}
 
 	// Check if expiry time is elapsed
 	return assetLockVal.ContractId, unlockAssetCommon(ctx, assetLockVal.ExpiryTimeSecs, assetLockVal.Locker, assetLockKey, assetLockVal.ContractId)
@@ -333,8 +418,14 @@ func UnlockFungibleAsset(ctx contractapi.TransactionContextInterface, contractId
 	if err != nil {
 		return logThenErrorf(err.Error())
 	}
-	
-	return unlockAssetCommon(ctx, assetLockVal.ExpiryTimeSecs, assetLockVal.Locker, "", contractId)
+
+	err = unlockAssetCommon(ctx, assetLockVal.ExpiryTimeSecs, assetLockVal.Locker, "", contractId)
+	if err != nil {
+		return err
+	}
+
+	// only the units not claimed in partial claims are returned to the locker
+	return recordFungibleLockedUnits(ctx, assetLockVal.ChaincodeId, assetLockVal.Type, contractId, 0)
 }
 
 // UnlockAssetUsingContractId cc is used to record unlocking of an asset on the ledger (this uses the contractId)
@@ -344,28 +435,32 @@ func UnlockAssetUsingContractId(ctx contractapi.TransactionContextInterface, con
 	if err != nil {
 		return logThenErrorf(err.Error())
 	}
-	
-	return unlockAssetCommon(ctx, assetLockVal.GetExpiryTimeSecs(), assetLockVal.GetLocker(), assetLockKey, contractId)
-}
 
-// Common unlock functions for both fungible and non-fungible assets,
-// with or without contractId
-func unlockAssetCommon(ctx contractapi.TransactionContextInterface, expiryTimeSecs uint64, locker, assetLockKey, contractId string) error {
-	
-	txCreatorECertBase64, err := getECertOfTxCreatorBase64(ctx)
+	err = unlockAssetCommon(ctx, assetLockVal.GetExpiryTimeSecs(), assetLockVal.GetLocker(), assetLockKey, contractId)
 	if err != nil {
-		return logThenErrorf("unable to get the transaction creator information: %+v", err)
+		return err
 	}
 
-	// transaction creator needs to be the locker of the locked fungible asset
-	if locker != txCreatorECertBase64 {
-		return logThenErrorf("asset is not locked for %s to unlock", txCreatorECertBase64)
+	return releaseFungibleLockedUnits(ctx, contractId, assetLockVal)
+}
+
+// Remove the record of the units that remain locked, if the released lock is a fungible asset lock
+func releaseFungibleLockedUnits(ctx contractapi.TransactionContextInterface, contractId string, assetLockVal AssetLockInterface) error {
+	fungibleAssetLockVal, ok := assetLockVal.(FungibleAssetLockValue)
+	if !ok {
+		return nil
 	}
 
-	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
-	if currentTimeSecs < expiryTimeSecs {
-		return logThenErrorf("cannot unlock asset associated with the contractId %s as the expiry time is not yet elapsed", contractId)
+	return recordFungibleLockedUnits(ctx, fungibleAssetLockVal.ChaincodeId, fungibleAssetLockVal.Type, contractId, 0)
+}
+
+// Common unlock functions for both fungible and non-fungible assets,
+// with or without contractId
+func unlockAssetCommon(ctx contractapi.TransactionContextInterface, expiryTimeSecs uint64, locker, assetLockKey, contractId string) error {
+
+	err := validateAssetUnlock(ctx, expiryTimeSecs, locker, contractId)
+	if err != nil {
+		return err
 	}
 
 	if assetLockKey != "" {
@@ -383,6 +478,31 @@ func unlockAssetCommon(ctx contractapi.TransactionContextInterface, expiryTimeSe
 	return nil
 }
 
+// Check that the transaction creator is the locker of the asset locked under contractId, and that the lock has expired
+func validateAssetUnlock(ctx contractapi.TransactionContextInterface, expiryTimeSecs uint64, locker, contractId string) error {
+
+	txCreatorECertBase64, err := getECertOfTxCreatorBase64(ctx)
+	if err != nil {
+		return logThenErrorf("unable to get the transaction creator information: %+v", err)
+	}
+
+	// transaction creator needs to be the locker of the locked fungible asset
+	if locker != txCreatorECertBase64 {
+		return logThenErrorf("asset is not locked for %s to unlock", txCreatorECertBase64)
+	}
+
+	// Check if expiry time is elapsed
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return logThenErrorf(err.Error())
+	}
+	if currentTimeSecs < expiryTimeSecs {
+		return logThenErrorf("cannot unlock asset associated with the contractId %s as the expiry time is not yet elapsed", contractId)
+	}
+
+	return nil
+}
+
 // IsLocked Query Functions
 // IsAssetLocked cc is used to query the ledger and findout if an asset is locked or not
 func IsAssetLocked(ctx contractapi.TransactionContextInterface, callerChaincodeID, assetAgreementBytesBase64 string) (bool, error) {
@@ -422,7 +542,10 @@ func IsAssetLocked(ctx contractapi.TransactionContextInterface, callerChaincodeI
 	log.Infof("assetLockVal: %+v", assetLockVal)
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return false, nil
 	}
@@ -455,7 +578,10 @@ func IsFungibleAssetLocked(ctx contractapi.TransactionContextInterface, contract
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
 		return false, nil
 	}
@@ -470,7 +596,7 @@ func IsAssetLockedQueryUsingContractId(ctx contractapi.TransactionContextInterfa
 	if err != nil {
 		errStr := fmt.Sprintf("no contractId %s exists on the ledger", contractId)
 		errStrFungible := fmt.Sprintf("contractId %s is not associated with any currently locked asset", contractId)
-		
+
 		// Reporting no error only if the lock contract doesn't exist at all
 		if err.Error() == errStr || err.Error() == errStrFungible {
 			return false, nil
@@ -479,12 +605,13 @@ func IsAssetLockedQueryUsingContractId(ctx contractapi.TransactionContextInterfa
 	}
 
 	// Check if expiry time is elapsed
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
 	if currentTimeSecs >= assetLockVal.GetExpiryTimeSecs() {
 		return false, nil
 	}
 
 	return true, nil
 }
-
-
@@ -0,0 +1,280 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// manage_assets is a chaincode that contains all the code related to asset management operations (e.g., Lock, Unlock, Claim)
+// and any related utility functions
+package assetexchange
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+ * A basket lock locks several non-fungible and fungible assets under a single hash lock and contractId, so that they are
+ * claimed (or unlocked after expiry) together in a single transaction, and a trade involving several assets cannot
+ * partially fail. The non-fungible assets in a basket are reported as locked by IsAssetLocked, but cannot be claimed or
+ * unlocked on their own.
+ */
+
+// LockBasket cc is used to record locking of a basket of assets on the ledger
+func LockBasket(ctx contractapi.TransactionContextInterface, callerChaincodeID, basketAgreementBytesBase64, lockInfoBytesBase64 string) (string, error) {
+
+	basketAgreementBytes, err := base64.StdEncoding.DecodeString(basketAgreementBytesBase64)
+	if err != nil {
+		return "", logThenErrorf("error in base64 decode of basket asset agreement: %+v", err)
+	}
+
+	basketAgreement := &common.BasketAssetExchangeAgreement{}
+	err = proto.Unmarshal([]byte(basketAgreementBytes), basketAgreement)
+	if err != nil {
+		return "", logThenErrorf("unmarshal error: %s", err)
+	}
+	//display the requested basket asset agreement
+	log.Infof("basketAssetExchangeAgreement: %+v", basketAgreement)
+
+	if len(basketAgreement.Assets) == 0 && len(basketAgreement.FungibleAssets) == 0 {
+		return "", logThenErrorf("no assets in the basket asset agreement")
+	}
+
+	err = validateAndSetLockerOfBasketAgreement(ctx, basketAgreement)
+	if err != nil {
+		return "", logThenErrorf("error in locker validation: %+v", err)
+	}
+
+	lockInfo, expiryTimeSecs, err := getLockInfoAndExpiryTimeSecs(ctx, lockInfoBytesBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	if _, ok := lockInfo.(HashLock); !ok {
+		return "", logThenErrorf("a basket of assets can only be locked with a hash lock")
+	}
+
+	contractId := GenerateBasketLockContractId(ctx, callerChaincodeID, basketAgreement)
+
+	basketLockValBytes, err := ctx.GetStub().GetState(generateBasketContractIdMapKey(contractId))
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	if basketLockValBytes != nil {
+		return "", logThenErrorf("contractId %s already exists for the basket asset agreement", contractId)
+	}
+
+	basketLockVal := BasketAssetLockValue{Locker: basketAgreement.Locker, Recipient: basketAgreement.Recipient, LockInfo: lockInfo,
+		ExpiryTimeSecs: expiryTimeSecs, ChaincodeId: callerChaincodeID}
+
+	// Lock each of the non-fungible assets under the basket's contractId
+	assetLockVal := AssetLockValue{ContractId: contractId, Locker: basketAgreement.Locker, Recipient: basketAgreement.Recipient, LockInfo: lockInfo,
+		ExpiryTimeSecs: expiryTimeSecs, BasketLock: true}
+	assetLockValBytes, err := json.Marshal(assetLockVal)
+	if err != nil {
+		return "", logThenErrorf("marshal error: %+v", err)
+	}
+	for _, assetAgreement := range basketAgreement.Assets {
+		assetLockKey, _, err := GenerateAssetLockKeyAndContractId(ctx, callerChaincodeID, assetAgreement)
+		if err != nil {
+			return "", logThenErrorf(err.Error())
+		}
+		// writes in a transaction are not visible to its reads, so duplicates within the basket are checked here
+		for _, lockedAsset := range basketLockVal.Assets {
+			if lockedAsset.Type == assetAgreement.AssetType && lockedAsset.Id == assetAgreement.Id {
+				return "", logThenErrorf("asset of type %s and ID %s appears more than once in the basket", assetAgreement.AssetType, assetAgreement.Id)
+			}
+		}
+
+		lockedAssetValBytes, err := ctx.GetStub().GetState(assetLockKey)
+		if err != nil {
+			return "", logThenErrorf(err.Error())
+		}
+		if lockedAssetValBytes != nil {
+			return "", logThenErrorf("asset of type %s and ID %s is already locked", assetAgreement.AssetType, assetAgreement.Id)
+		}
+
+		err = ctx.GetStub().PutState(assetLockKey, assetLockValBytes)
+		if err != nil {
+			return "", logThenErrorf(err.Error())
+		}
+		basketLockVal.Assets = append(basketLockVal.Assets, BasketAsset{Type: assetAgreement.AssetType, Id: assetAgreement.Id})
+	}
+
+	// Fungible assets are locked by the basket lock itself
+	for _, assetAgreement := range basketAgreement.FungibleAssets {
+		if assetAgreement.NumUnits == 0 {
+			return "", logThenErrorf("number of units of fungible asset type %s in the basket must be a positive number", assetAgreement.AssetType)
+		}
+		basketLockVal.FungibleAssets = append(basketLockVal.FungibleAssets, BasketFungibleAsset{Type: assetAgreement.AssetType, NumUnits: assetAgreement.NumUnits})
+	}
+	err = recordBasketFungibleLockedUnits(ctx, contractId, basketLockVal, true)
+	if err != nil {
+		return "", err
+	}
+
+	basketLockValBytes, err = json.Marshal(basketLockVal)
+	if err != nil {
+		return "", logThenErrorf("marshal error: %+v", err)
+	}
+
+	err = ctx.GetStub().PutState(generateBasketContractIdMapKey(contractId), basketLockValBytes)
+	if err != nil {
+		return "", logThenErrorf("failed to write to the world state: %+v", err)
+	}
+
+	return contractId, nil
+}
+
+/*
+ * ClaimBasket cc is used to record claim of all the assets locked in a basket on the ledger. It returns the basket
+ * asset agreement, listing the assets that the caller chaincode should now transfer to the recipient.
+ */
+func ClaimBasket(ctx contractapi.TransactionContextInterface, contractId, claimInfoBytesBase64 string) (*common.BasketAssetExchangeAgreement, error) {
+
+	basketLockVal, err := fetchBasketAssetLocked(ctx, contractId)
+	if err != nil {
+		return nil, logThenErrorf(err.Error())
+	}
+
+	err = validateAssetClaim(ctx, basketLockVal.LockInfo, basketLockVal.ExpiryTimeSecs, basketLockVal.Recipient, contractId, claimInfoBytesBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	basketAgreement := basketLockVal.GetAgreement()
+	for _, assetAgreement := range basketAgreement.Assets {
+		assetLockKey, _, err := GenerateAssetLockKeyAndContractId(ctx, basketLockVal.ChaincodeId, assetAgreement)
+		if err != nil {
+			return nil, logThenErrorf(err.Error())
+		}
+
+		err = ctx.GetStub().DelState(assetLockKey)
+		if err != nil {
+			return nil, logThenErrorf("failed to delete lock for the asset associated with the contractId %s: %+v", contractId, err)
+		}
+
+		err = ctx.GetStub().PutState(generateAssetLockMapKey(assetLockKey), []byte(contractId))
+		if err != nil {
+			return nil, logThenErrorf("failed to write to the world state: %+v", err)
+		}
+	}
+
+	return basketAgreement, releaseBasket(ctx, contractId, basketLockVal, "claim")
+}
+
+/*
+ * UnlockBasket cc is used to record unlocking of all the assets locked in a basket on the ledger. It returns the basket
+ * asset agreement, listing the assets that are now released to the locker.
+ */
+func UnlockBasket(ctx contractapi.TransactionContextInterface, contractId string) (*common.BasketAssetExchangeAgreement, error) {
+
+	basketLockVal, err := fetchBasketAssetLocked(ctx, contractId)
+	if err != nil {
+		return nil, logThenErrorf(err.Error())
+	}
+
+	// Check if expiry time is elapsed
+	err = validateAssetUnlock(ctx, basketLockVal.ExpiryTimeSecs, basketLockVal.Locker, contractId)
+	if err != nil {
+		return nil, err
+	}
+
+	basketAgreement := basketLockVal.GetAgreement()
+	for _, assetAgreement := range basketAgreement.Assets {
+		assetLockKey, _, err := GenerateAssetLockKeyAndContractId(ctx, basketLockVal.ChaincodeId, assetAgreement)
+		if err != nil {
+			return nil, logThenErrorf(err.Error())
+		}
+
+		err = ctx.GetStub().DelState(assetLockKey)
+		if err != nil {
+			return nil, logThenErrorf("failed to delete lock for the asset associated with the contractId %s: %v", contractId, err)
+		}
+	}
+
+	return basketAgreement, releaseBasket(ctx, contractId, basketLockVal, "unlock")
+}
+
+// Remove the record of the fungible units of a claimed or unlocked basket, and delete the basket lock
+func releaseBasket(ctx contractapi.TransactionContextInterface, contractId string, basketLockVal BasketAssetLockValue, operation string) error {
+	err := recordBasketFungibleLockedUnits(ctx, contractId, basketLockVal, false)
+	if err != nil {
+		return err
+	}
+
+	err = ctx.GetStub().DelState(generateBasketContractIdMapKey(contractId))
+	if err != nil {
+		return logThenErrorf("failed to delete the contractId %s as part of basket %s: %v", contractId, operation, err)
+	}
+
+	return nil
+}
+
+// Record the fungible units in a basket as locked under its contractId (or remove the record), one record per asset type
+func recordBasketFungibleLockedUnits(ctx contractapi.TransactionContextInterface, contractId string, basketLockVal BasketAssetLockValue, locking bool) error {
+	// the units of an asset type appearing more than once in the basket share a record, which is written once, in the
+	// order in which the asset type first appears in the basket
+	var assetTypes []string
+	fungibleUnits := map[string]uint64{}
+	for _, fungibleAsset := range basketLockVal.FungibleAssets {
+		if _, ok := fungibleUnits[fungibleAsset.Type]; !ok {
+			assetTypes = append(assetTypes, fungibleAsset.Type)
+		}
+		fungibleUnits[fungibleAsset.Type] += fungibleAsset.NumUnits
+	}
+
+	for _, assetType := range assetTypes {
+		lockedUnits := uint64(0)
+		if locking {
+			lockedUnits = fungibleUnits[assetType]
+		}
+		err := recordFungibleLockedUnits(ctx, basketLockVal.ChaincodeId, assetType, contractId, lockedUnits)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsBasketLocked cc is used to query the ledger and find out if a basket of assets is locked or not
+func IsBasketLocked(ctx contractapi.TransactionContextInterface, contractId string) (bool, error) {
+
+	basketLockVal, err := fetchBasketAssetLocked(ctx, contractId)
+	if err != nil {
+		errStr := fmt.Sprintf("contractId %s is not associated with any currently locked basket", contractId)
+		// Reporting no error only if the lock contract doesn't exist at all
+		if err.Error() == errStr {
+			return false, nil
+		}
+		return false, logThenErrorf(err.Error())
+	}
+
+	// Check if expiry time is elapsed
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
+	if currentTimeSecs >= basketLockVal.ExpiryTimeSecs {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// GetBasketHTLCHash returns the hash (in base64 form) of the HTLC locking the basket with contractId
+func GetBasketHTLCHash(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+	basketLockVal, err := fetchBasketAssetLocked(ctx, contractId)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	return getHTLCHashHelper(ctx, basketLockVal.LockInfo)
+}
@@ -9,16 +9,25 @@
 package assetexchange
 
 import (
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/ed25519"
     "crypto/sha256"
     "crypto/sha512"
+    "crypto/x509"
+    "encoding/pem"
     "encoding/base64"
     "encoding/json"
     "errors"
     "fmt"
+    "strconv"
 
     "github.com/golang/protobuf/proto"
     "github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
     "github.com/hyperledger/fabric-contract-api-go/contractapi"
+    "golang.org/x/crypto/blake2b"
+    "golang.org/x/crypto/sha3"
+    wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
     mspProtobuf "github.com/hyperledger/fabric-protos-go/msp"
     log "github.com/sirupsen/logrus"
 )
@@ -48,6 +57,52 @@ func GenerateSHA512HashInBase64Form(preimage string) string {
     return shaHashBase64
 }
 
+// function to generate a "SHA3-256" hash in base64 format for a given preimage
+func GenerateSHA3_256HashInBase64Form(preimage string) string {
+    shaHash := sha3.Sum256([]byte(preimage))
+    return base64.StdEncoding.EncodeToString(shaHash[:])
+}
+
+// function to generate a "Keccak-256" hash (as used by Ethereum) in base64 format for a given preimage
+func GenerateKeccak256HashInBase64Form(preimage string) string {
+    hasher := sha3.NewLegacyKeccak256()
+    hasher.Write([]byte(preimage))
+    return base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+// function to generate a "BLAKE2b-256" hash in base64 format for a given preimage
+func GenerateBLAKE2b256HashInBase64Form(preimage string) string {
+    blakeHash := blake2b.Sum256([]byte(preimage))
+    return base64.StdEncoding.EncodeToString(blakeHash[:])
+}
+
+// function to get the base64 hash generator for a hash mechanism
+func getHashGenerator(hashMechanism common.HashMechanism) (func(string) string, error) {
+    switch hashMechanism {
+    case common.HashMechanism_SHA256:
+        return GenerateSHA256HashInBase64Form, nil
+    case common.HashMechanism_SHA512:
+        return GenerateSHA512HashInBase64Form, nil
+    case common.HashMechanism_SHA3_256:
+        return GenerateSHA3_256HashInBase64Form, nil
+    case common.HashMechanism_KECCAK256:
+        return GenerateKeccak256HashInBase64Form, nil
+    case common.HashMechanism_BLAKE2B256:
+        return GenerateBLAKE2b256HashInBase64Form, nil
+    default:
+        return nil, fmt.Errorf("hash mechanism %d is not supported", hashMechanism)
+    }
+}
+
+// function to generate a hash in base64 format for a given preimage using the given hash mechanism
+func GenerateHashInBase64Form(preimage string, hashMechanism common.HashMechanism) (string, error) {
+    generateHash, err := getHashGenerator(hashMechanism)
+    if err != nil {
+        return "", logThenErrorf(err.Error())
+    }
+    return generateHash(preimage), nil
+}
+
 // function to get the caller identity from the transaction context
 func getECertOfTxCreatorBase64(ctx contractapi.TransactionContextInterface) (string, error) {
 
@@ -107,6 +162,62 @@ func validateAndSetLockerOfFungibleAssetAgreement(ctx contractapi.TransactionCon
     return nil
 }
 
+/*
+ * Function to validate the locker in hybrid asset agreement.
+ * If locker is not set, it will be set to the caller.
+ * If the locker is set already, it ensures that the locker is same as the creator of the transaction.
+ */
+func validateAndSetLockerOfHybridAssetAgreement(ctx contractapi.TransactionContextInterface, assetAgreement *common.HybridAssetExchangeAgreement) error {
+    txCreatorECertBase64, err := getECertOfTxCreatorBase64(ctx)
+    if err != nil {
+        return logThenErrorf(err.Error())
+    }
+    if len(assetAgreement.Locker) == 0 {
+        assetAgreement.Locker = txCreatorECertBase64
+    } else if assetAgreement.Locker != txCreatorECertBase64 {
+        return logThenErrorf("locker %s in the hybrid asset agreement is not same as the transaction creator %s", assetAgreement.Locker, txCreatorECertBase64)
+    }
+
+    return nil
+}
+
+/*
+ * Function to validate the locker in basket asset agreement.
+ * If locker is not set, it will be set to the caller.
+ * If the locker is set already, it ensures that the locker is same as the creator of the transaction.
+ * The locker and recipient of each asset in the basket, if set, must be the same as those of the basket.
+ */
+func validateAndSetLockerOfBasketAgreement(ctx contractapi.TransactionContextInterface, basketAgreement *common.BasketAssetExchangeAgreement) error {
+    txCreatorECertBase64, err := getECertOfTxCreatorBase64(ctx)
+    if err != nil {
+        return logThenErrorf(err.Error())
+    }
+    if len(basketAgreement.Locker) == 0 {
+        basketAgreement.Locker = txCreatorECertBase64
+    } else if basketAgreement.Locker != txCreatorECertBase64 {
+        return logThenErrorf("locker %s in the basket asset agreement is not same as the transaction creator %s", basketAgreement.Locker, txCreatorECertBase64)
+    }
+
+    for _, assetAgreement := range basketAgreement.Assets {
+        if (assetAgreement.Locker != "" && assetAgreement.Locker != basketAgreement.Locker) ||
+            (assetAgreement.Recipient != "" && assetAgreement.Recipient != basketAgreement.Recipient) {
+            return logThenErrorf("asset of type %s and ID %s has a different locker or recipient than the basket", assetAgreement.AssetType, assetAgreement.Id)
+        }
+        assetAgreement.Locker = basketAgreement.Locker
+        assetAgreement.Recipient = basketAgreement.Recipient
+    }
+    for _, assetAgreement := range basketAgreement.FungibleAssets {
+        if (assetAgreement.Locker != "" && assetAgreement.Locker != basketAgreement.Locker) ||
+            (assetAgreement.Recipient != "" && assetAgreement.Recipient != basketAgreement.Recipient) {
+            return logThenErrorf("fungible asset of type %s has a different locker or recipient than the basket", assetAgreement.AssetType)
+        }
+        assetAgreement.Locker = basketAgreement.Locker
+        assetAgreement.Recipient = basketAgreement.Recipient
+    }
+
+    return nil
+}
+
 /*
  * Function to validate the recipient in asset agreement.
  * If recipient is not set, it will be set to the caller.
@@ -126,7 +237,25 @@ func validateAndSetRecipientOfAssetAgreement(ctx contractapi.TransactionContextI
     return nil
 }
 
-func getLockInfoAndExpiryTimeSecs(lockInfoBytesBase64 string) (interface{}, uint64, error) {
+// function to resolve the expiry time of a lock, which is specified either as an epoch time or as a duration
+// from the timestamp of the locking transaction, into an epoch time
+func resolveExpiryTimeSecs(ctx contractapi.TransactionContextInterface, timeSpec common.TimeSpec, expiryTimeSecs uint64) (uint64, error) {
+    if timeSpec == common.TimeSpec_EPOCH {
+        return expiryTimeSecs, nil
+    } else if timeSpec == common.TimeSpec_DURATION {
+        if expiryTimeSecs == 0 {
+            return 0, logThenErrorf("lock duration must be a positive number of seconds")
+        }
+        currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+        if err != nil {
+            return 0, logThenErrorf(err.Error())
+        }
+        return currentTimeSecs + expiryTimeSecs, nil
+    }
+    return 0, logThenErrorf("time spec %s is not supported", timeSpec)
+}
+
+func getLockInfoAndExpiryTimeSecs(ctx contractapi.TransactionContextInterface, lockInfoBytesBase64 string) (interface{}, uint64, error) {
     var lockInfoVal interface{}
     var expiryTimeSecs uint64
 
@@ -149,12 +278,36 @@ func getLockInfoAndExpiryTimeSecs(lockInfoBytesBase64 string) (interface{}, uint
         }
         //display the passed hash lock information
         log.Infof("lockInfoHTLC: %+v", lockInfoHTLC)
+        // reject the lock upfront if the hash mechanism cannot be used to verify claims
+        _, err = getHashGenerator(lockInfoHTLC.HashMechanism)
+        if err != nil {
+            return lockInfoVal, 0, logThenErrorf(err.Error())
+        }
         lockInfoVal = HashLock{HashMechanism: lockInfoHTLC.HashMechanism, HashBase64: string(lockInfoHTLC.HashBase64)}
         // process time lock details here
-        if lockInfoHTLC.TimeSpec != common.TimeSpec_EPOCH {
-            return lockInfoVal, 0, logThenErrorf("only EPOCH time is supported at present")
+        expiryTimeSecs, err = resolveExpiryTimeSecs(ctx, lockInfoHTLC.TimeSpec, lockInfoHTLC.ExpiryTimeSecs)
+        if err != nil {
+            return lockInfoVal, 0, err
+        }
+    } else if lockInfo.LockMechanism == common.LockMechanism_SIGNATURE {
+        lockInfoSignature := &common.AssetLockSignature{}
+        err := proto.Unmarshal(lockInfo.LockInfo, lockInfoSignature)
+        if err != nil {
+            return lockInfoVal, 0, logThenErrorf("unmarshal error: %s", err)
+        }
+        //display the passed signature lock information
+        log.Infof("lockInfoSignature: %+v", lockInfoSignature)
+        // reject the lock upfront if the arbiter key cannot be used to verify claims
+        _, err = parseArbiterPublicKey(string(lockInfoSignature.ArbiterPublicKey))
+        if err != nil {
+            return lockInfoVal, 0, logThenErrorf(err.Error())
+        }
+        lockInfoVal = SignatureLock{ArbiterPublicKey: string(lockInfoSignature.ArbiterPublicKey)}
+        // process time lock details here
+        expiryTimeSecs, err = resolveExpiryTimeSecs(ctx, lockInfoSignature.TimeSpec, lockInfoSignature.ExpiryTimeSecs)
+        if err != nil {
+            return lockInfoVal, 0, err
         }
-        expiryTimeSecs = lockInfoHTLC.ExpiryTimeSecs
     } else {
         return lockInfoVal, 0, logThenErrorf("lock mechanism is not supported")
     }
@@ -172,14 +325,9 @@ func checkIfCorrectPreimage(preimageBase64 string, hashBase64 string, hashMechan
         return false, logThenErrorf("base64 decode preimage error: %s", err)
     }
 
-    shaHashBase64 := ""
-    if hashMechanism == common.HashMechanism_SHA256 {
-        shaHashBase64 = GenerateSHA256HashInBase64Form(string(preimage))
-    } else if hashMechanism == common.HashMechanism_SHA512 {
-        shaHashBase64 = GenerateSHA512HashInBase64Form(string(preimage))
-    } else {
-        log.Infof("hashMechanism %d is not supported currently", hashMechanism)
-        return false, nil
+    shaHashBase64, err := GenerateHashInBase64Form(string(preimage), hashMechanism)
+    if err != nil {
+        return false, err
     }
     if shaHashBase64 == hashBase64 {
         log.Infof("%s: preimage %s is passed correctly", funName, preimage)
@@ -209,12 +357,91 @@ func validateHashPreimage(claimInfo *common.AssetClaim, lockInfo interface{}) (b
     }
     log.Infof("HashLock: %+v\n", lockInfoVal)
 
+    // the claim must name the hash mechanism of the lock, which is then used to hash the preimage
+    _, err = getHashGenerator(claimInfoHTLC.HashMechanism)
+    if err != nil {
+        return false, logThenErrorf(err.Error())
+    }
     if lockInfoVal.HashMechanism != claimInfoHTLC.HashMechanism {
         return false, logThenErrorf("hash mechanism used while locking is different from the one supplied: %d", claimInfoHTLC.HashMechanism)
     }
 
     // match the hash passed during claim with the hash stored during asset locking
-    return checkIfCorrectPreimage(string(claimInfoHTLC.HashPreimageBase64), lockInfoVal.HashBase64, lockInfoVal.HashMechanism)
+    return checkIfCorrectPreimage(string(claimInfoHTLC.HashPreimageBase64), lockInfoVal.HashBase64, claimInfoHTLC.HashMechanism)
+}
+
+// function to generate the message that the arbiter of a signature lock signs to authorise the claim by the recipient
+func GenerateClaimAuthorizationMessage(contractId, recipient string) []byte {
+    return []byte(contractId + ":" + recipient)
+}
+
+// parses the PEM-encoded public key or X.509 certificate of an arbiter, which must hold an ECDSA or Ed25519 key
+func parseArbiterPublicKey(arbiterPublicKeyPEM string) (crypto.PublicKey, error) {
+    block, _ := pem.Decode([]byte(arbiterPublicKeyPEM))
+    if block == nil {
+        return nil, fmt.Errorf("arbiter public key is not PEM-encoded")
+    }
+    var publicKey crypto.PublicKey
+    if block.Type == "CERTIFICATE" {
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("unable to parse arbiter certificate: %+v", err)
+        }
+        publicKey = cert.PublicKey
+    } else {
+        var err error
+        publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("unable to parse arbiter public key: %+v", err)
+        }
+    }
+    switch publicKey.(type) {
+    case *ecdsa.PublicKey, ed25519.PublicKey:
+        return publicKey, nil
+    default:
+        return nil, fmt.Errorf("arbiter public key type %T is not supported", publicKey)
+    }
+}
+
+/*
+ * Function to check if the signature in the claim information is the arbiter's signature authorising
+ * the recipient to claim the asset associated with contractId.
+ */
+func validateClaimSignature(claimInfo *common.AssetClaim, lockInfo interface{}, contractId, recipient string) (bool, error) {
+    claimInfoSignature := &common.AssetClaimSignature{}
+    err := proto.Unmarshal(claimInfo.ClaimInfo, claimInfoSignature)
+    if err != nil {
+        return false, logThenErrorf("unmarshal claimInfo.ClaimInfo error: %s", err)
+    }
+    lockInfoVal := SignatureLock{}
+    lockInfoBytes, err := json.Marshal(lockInfo)
+    if err != nil {
+        return false, logThenErrorf("marshal lockInfo error: %s", err)
+    }
+    err = json.Unmarshal(lockInfoBytes, &lockInfoVal)
+    if err != nil {
+        return false, logThenErrorf("unmarshal lockInfoBytes error: %s", err)
+    }
+    if len(lockInfoVal.ArbiterPublicKey) == 0 {
+        return false, logThenErrorf("asset is not locked with the signature lock mechanism")
+    }
+
+    publicKey, err := parseArbiterPublicKey(lockInfoVal.ArbiterPublicKey)
+    if err != nil {
+        return false, logThenErrorf(err.Error())
+    }
+    message := GenerateClaimAuthorizationMessage(contractId, recipient)
+    isValid := false
+    switch key := publicKey.(type) {
+    case *ecdsa.PublicKey:
+        digest := sha256.Sum256(message)
+        isValid = ecdsa.VerifyASN1(key, digest[:], claimInfoSignature.Signature)
+    case ed25519.PublicKey:
+        isValid = ed25519.Verify(key, message, claimInfoSignature.Signature)
+    }
+    log.Infof("validateClaimSignature: signature of the arbiter is valid: %t", isValid)
+
+    return isValid, nil
 }
 
 // fetches common.AssetClaim from the input parameter and checks if the lock mechanism is valid or not
@@ -231,7 +458,7 @@ func getClaimInfo(claimInfoBytesBase64 string) (*common.AssetClaim, error) {
         return claimInfo, logThenErrorf("unmarshal error: %s", err)
     }
     // check if a valid lock mechanism is provided
-    if claimInfo.LockMechanism != common.LockMechanism_HTLC {
+    if claimInfo.LockMechanism != common.LockMechanism_HTLC && claimInfo.LockMechanism != common.LockMechanism_SIGNATURE {
         return claimInfo, logThenErrorf("lock mechanism is not supported")
     }
 
@@ -295,6 +522,109 @@ func fetchFungibleAssetLocked(ctx contractapi.TransactionContextInterface, contr
     return assetLockVal, nil
 }
 
+// function to write the fungible asset-lock value to the ledger using contractId
+func putFungibleAssetLocked(ctx contractapi.TransactionContextInterface, contractId string, assetLockVal FungibleAssetLockValue) error {
+    assetLockValBytes, err := json.Marshal(assetLockVal)
+    if err != nil {
+        return logThenErrorf("marshal error: %s", err)
+    }
+
+    err = ctx.GetStub().PutState(generateContractIdMapKey(contractId), assetLockValBytes)
+    if err != nil {
+        return logThenErrorf("failed to write to the world state: %+v", err)
+    }
+
+    return nil
+}
+
+// function to fetch the basket lock value from the ledger using contractId
+func fetchBasketAssetLocked(ctx contractapi.TransactionContextInterface, contractId string) (BasketAssetLockValue, error) {
+    var basketLockVal = BasketAssetLockValue{}
+
+    basketLockValBytes, err := ctx.GetStub().GetState(generateBasketContractIdMapKey(contractId))
+    if err != nil {
+        return basketLockVal, logThenErrorf("failed to retrieve from the world state: %+v", err)
+    }
+
+    if basketLockValBytes == nil {
+        return basketLockVal, logThenErrorf("contractId %s is not associated with any currently locked basket", contractId)
+    }
+
+    err = json.Unmarshal(basketLockValBytes, &basketLockVal)
+    if err != nil {
+        return basketLockVal, logThenErrorf("unmarshal error: %s", err)
+    }
+    log.Infof("contractId: %s and basketLockVal: %+v", contractId, basketLockVal)
+
+    return basketLockVal, nil
+}
+
+// function to fetch the hybrid asset-lock value from the ledger using contractId
+func fetchHybridAssetLocked(ctx contractapi.TransactionContextInterface, contractId string) (HybridAssetLockValue, error) {
+    var assetLockVal = HybridAssetLockValue{}
+
+    assetLockValBytes, err := ctx.GetStub().GetState(generateHybridContractIdMapKey(contractId))
+    if err != nil {
+        return assetLockVal, logThenErrorf("failed to retrieve from the world state: %+v", err)
+    }
+
+    if assetLockValBytes == nil {
+        return assetLockVal, logThenErrorf("contractId %s is not associated with any currently locked hybrid asset", contractId)
+    }
+
+    err = json.Unmarshal(assetLockValBytes, &assetLockVal)
+    if err != nil {
+        return assetLockVal, logThenErrorf("unmarshal error: %s", err)
+    }
+    log.Infof("contractId: %s and hybridAssetLockVal: %+v", contractId, assetLockVal)
+
+    return assetLockVal, nil
+}
+
+// function to fetch the total units of an asset type locked by a chaincode, summed over the units recorded for each lock
+func getTotalFungibleLockedUnits(ctx contractapi.TransactionContextInterface, chaincodeId, assetType string) (uint64, error) {
+    iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(fungibleLockedUnitsObjectType, []string{chaincodeId, assetType})
+    if err != nil {
+        return 0, logThenErrorf("failed to retrieve from the world state: %+v", err)
+    }
+    defer iterator.Close()
+
+    total := uint64(0)
+    for iterator.HasNext() {
+        lockedUnitsKV, err := iterator.Next()
+        if err != nil {
+            return 0, logThenErrorf("failed to retrieve from the world state: %+v", err)
+        }
+        lockedUnits, err := strconv.ParseUint(string(lockedUnitsKV.Value), 10, 64)
+        if err != nil {
+            return 0, logThenErrorf("parse error of the locked units: %s", err)
+        }
+        total += lockedUnits
+    }
+
+    return total, nil
+}
+
+// function to record the units of an asset type that remain locked by a chaincode under contractId, removing the record
+// once no units remain locked. Each lock has its own record, written without being read, so that concurrent locks,
+// claims and unlocks of an asset type don't conflict over a shared total.
+func recordFungibleLockedUnits(ctx contractapi.TransactionContextInterface, chaincodeId, assetType, contractId string, lockedUnits uint64) error {
+    lockedUnitsKey, err := generateFungibleLockedUnitsKey(ctx, chaincodeId, assetType, contractId)
+    if err != nil {
+        return logThenErrorf(err.Error())
+    }
+    if lockedUnits == 0 {
+        err = ctx.GetStub().DelState(lockedUnitsKey)
+    } else {
+        err = ctx.GetStub().PutState(lockedUnitsKey, []byte(strconv.FormatUint(lockedUnits, 10)))
+    }
+    if err != nil {
+        return logThenErrorf("failed to update the locked units of asset type %s: %+v", assetType, err)
+    }
+
+    return nil
+}
+
 // function to fetch the AssetLockInterface from ledger using contractId, which can be
 // either AssetLockValue or FungibleAssetLockValue
 func fetchLockStateUsingContractId(ctx contractapi.TransactionContextInterface, contractId string) (string, AssetLockInterface, error) {
@@ -0,0 +1,176 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// manage_assets is a chaincode that contains all the code related to asset management operations (e.g., Lock, Unlock, Claim)
+// and any related utility functions
+package assetexchange
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	wutils "github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	log "github.com/sirupsen/logrus"
+)
+
+/*
+ * A hybrid asset is a number of units of an asset instance identified by its ID (e.g., a batch of N units from a specific
+ * serialised commodity lot), optionally with some asset data. As several locks may be placed on units of the same asset
+ * instance, hybrid asset locks are only referred to by their contractId.
+ */
+
+// LockHybridAsset cc is used to record locking of a number of units of a hybrid asset on the ledger
+func LockHybridAsset(ctx contractapi.TransactionContextInterface, callerChaincodeID, hybridAssetAgreementBytesBase64, lockInfoBytesBase64 string) (string, error) {
+
+	hybridAssetAgreementBytes, err := base64.StdEncoding.DecodeString(hybridAssetAgreementBytesBase64)
+	if err != nil {
+		return "", logThenErrorf("error in base64 decode of asset agreement: %+v", err)
+	}
+
+	assetAgreement := &common.HybridAssetExchangeAgreement{}
+	err = proto.Unmarshal([]byte(hybridAssetAgreementBytes), assetAgreement)
+	if err != nil {
+		return "", logThenErrorf("unmarshal error: %s", err)
+	}
+	//display the requested hybrid asset agreement
+	log.Infof("hybridAssetExchangeAgreement: %+v", assetAgreement)
+
+	if assetAgreement.NumUnits == 0 {
+		return "", logThenErrorf("number of units of hybrid asset of type %s and ID %s must be a positive number", assetAgreement.AssetType, assetAgreement.Id)
+	}
+
+	err = validateAndSetLockerOfHybridAssetAgreement(ctx, assetAgreement)
+	if err != nil {
+		return "", logThenErrorf("error in locker validation: %+v", err)
+	}
+
+	lockInfo, expiryTimeSecs, err := getLockInfoAndExpiryTimeSecs(ctx, lockInfoBytesBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// generate the contractId for the hybrid asset lock agreement
+	contractId := GenerateHybridAssetLockContractId(ctx, callerChaincodeID, assetAgreement)
+
+	assetLockValBytes, err := ctx.GetStub().GetState(generateHybridContractIdMapKey(contractId))
+	if err != nil {
+		return "", logThenErrorf("failed to retrieve from the world state: %+v", err)
+	}
+
+	if assetLockValBytes != nil {
+		return "", logThenErrorf("contractId %s already exists for the requested hybrid asset agreement", contractId)
+	}
+
+	assetLockVal := HybridAssetLockValue{Type: assetAgreement.AssetType, Id: assetAgreement.Id, AssetData: assetAgreement.AssetData, NumUnits: assetAgreement.NumUnits,
+		Locker: assetAgreement.Locker, Recipient: assetAgreement.Recipient, LockInfo: lockInfo, ExpiryTimeSecs: expiryTimeSecs}
+	assetLockValBytes, err = json.Marshal(assetLockVal)
+	if err != nil {
+		return "", logThenErrorf("marshal error: %s", err)
+	}
+
+	err = ctx.GetStub().PutState(generateHybridContractIdMapKey(contractId), assetLockValBytes)
+	if err != nil {
+		return "", logThenErrorf("failed to write to the world state: %+v", err)
+	}
+
+	return contractId, nil
+}
+
+/*
+ * ClaimHybridAsset cc is used to record claim of a hybrid asset on the ledger. It returns the hybrid asset agreement,
+ * listing the units of the asset that the caller chaincode should now transfer to the recipient.
+ */
+func ClaimHybridAsset(ctx contractapi.TransactionContextInterface, contractId, claimInfoBytesBase64 string) (*common.HybridAssetExchangeAgreement, error) {
+
+	assetLockVal, err := fetchHybridAssetLocked(ctx, contractId)
+	if err != nil {
+		return nil, logThenErrorf(err.Error())
+	}
+
+	err = validateAssetClaim(ctx, assetLockVal.LockInfo, assetLockVal.ExpiryTimeSecs, assetLockVal.Recipient, contractId, claimInfoBytesBase64)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.GetStub().DelState(generateHybridContractIdMapKey(contractId))
+	if err != nil {
+		return nil, logThenErrorf("failed to delete the contractId %s as part of hybrid asset claim: %+v", contractId, err)
+	}
+
+	return assetLockVal.GetAgreement(), nil
+}
+
+/*
+ * UnlockHybridAsset cc is used to record unlocking of a hybrid asset on the ledger. It returns the hybrid asset
+ * agreement, listing the units of the asset that are now released to the locker.
+ */
+func UnlockHybridAsset(ctx contractapi.TransactionContextInterface, contractId string) (*common.HybridAssetExchangeAgreement, error) {
+
+	assetLockVal, err := fetchHybridAssetLocked(ctx, contractId)
+	if err != nil {
+		return nil, logThenErrorf(err.Error())
+	}
+
+	// Check if expiry time is elapsed
+	err = validateAssetUnlock(ctx, assetLockVal.ExpiryTimeSecs, assetLockVal.Locker, contractId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ctx.GetStub().DelState(generateHybridContractIdMapKey(contractId))
+	if err != nil {
+		return nil, logThenErrorf("failed to delete the contractId %s as part of hybrid asset unlock: %v", contractId, err)
+	}
+
+	return assetLockVal.GetAgreement(), nil
+}
+
+// IsHybridAssetLocked cc is used to query the ledger and find out if a hybrid asset is locked or not
+func IsHybridAssetLocked(ctx contractapi.TransactionContextInterface, contractId string) (bool, error) {
+
+	assetLockVal, err := fetchHybridAssetLocked(ctx, contractId)
+	if err != nil {
+		errStr := fmt.Sprintf("contractId %s is not associated with any currently locked hybrid asset", contractId)
+		// Reporting no error only if the lock contract doesn't exist at all
+		if err.Error() == errStr {
+			return false, nil
+		}
+		return false, logThenErrorf(err.Error())
+	}
+
+	// Check if expiry time is elapsed
+	currentTimeSecs, err := wutils.GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return false, logThenErrorf(err.Error())
+	}
+	if currentTimeSecs >= assetLockVal.ExpiryTimeSecs {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// GetHybridAssetHTLCHash returns the hash lock (hash mechanism and hash in base64 form) of the hybrid asset locked with contractId
+func GetHybridAssetHTLCHash(ctx contractapi.TransactionContextInterface, contractId string) (string, error) {
+	assetLockVal, err := fetchHybridAssetLocked(ctx, contractId)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	return getHTLCHashHelper(ctx, assetLockVal.LockInfo)
+}
+
+// GetHybridAssetTimeToRelease returns the expiry time (in seconds since the epoch) of the lock on the hybrid asset locked with contractId
+func GetHybridAssetTimeToRelease(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+	assetLockVal, err := fetchHybridAssetLocked(ctx, contractId)
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	return assetLockVal.ExpiryTimeSecs, nil
+}
@@ -26,6 +26,18 @@ func generateClaimContractIdMapKey(contractId string) string {
 func generateAssetLockMapKey(assetLockKey string) string {
     return claimAssetKeyPrefix + assetLockKey
 }
+// function to return the key to fetch a basket lock from the map using contractId
+func generateBasketContractIdMapKey(contractId string) string {
+    return basketContractIdPrefix + contractId
+}
+// function to return the key to fetch a hybrid asset lock from the map using contractId
+func generateHybridContractIdMapKey(contractId string) string {
+    return hybridContractIdPrefix + contractId
+}
+// function to return the key to fetch the units of an asset type locked by a chaincode under contractId
+func generateFungibleLockedUnitsKey(ctx contractapi.TransactionContextInterface, chaincodeId, assetType, contractId string) (string, error) {
+    return ctx.GetStub().CreateCompositeKey(fungibleLockedUnitsObjectType, []string{chaincodeId, assetType, contractId})
+}
 
 /*
  * Function to generate asset-lock key (which is combination of asset-type and asset-id)
@@ -62,3 +74,29 @@ func GenerateFungibleAssetLockContractId(ctx contractapi.TransactionContextInter
     contractId := GenerateSHA256HashInBase64Form(preimage + ctx.GetStub().GetTxID())
     return contractId
 }
+
+/*
+ * Function to generate contract-id for locking a basket of assets on the ledger (which is
+ * a hash on the attributes of the assets in the basket exchange agreement)
+ */
+func GenerateBasketLockContractId(ctx contractapi.TransactionContextInterface, chaincodeId string, basketAgreement *common.BasketAssetExchangeAgreement) string {
+    preimage := "BasketAssetExchangeContract" + chaincodeId + basketAgreement.Locker + basketAgreement.Recipient
+    for _, assetAgreement := range basketAgreement.Assets {
+        preimage += assetAgreement.AssetType + assetAgreement.Id
+    }
+    for _, assetAgreement := range basketAgreement.FungibleAssets {
+        preimage += assetAgreement.AssetType + strconv.FormatUint(assetAgreement.NumUnits, 10)
+    }
+    contractId := GenerateSHA256HashInBase64Form(preimage + ctx.GetStub().GetTxID())
+    return contractId
+}
+
+/*
+ * Function to generate contract-id for hybrid asset-locking on the ledger (which is
+ * a hash on the attributes of the hybrid asset exchange agreement)
+ */
+func GenerateHybridAssetLockContractId(ctx contractapi.TransactionContextInterface, chaincodeId string, assetAgreement *common.HybridAssetExchangeAgreement) string {
+    preimage := "HybridAssetExchangeContract" + chaincodeId + assetAgreement.AssetType + assetAgreement.Id + strconv.FormatUint(assetAgreement.NumUnits, 10) + assetAgreement.Locker + assetAgreement.Recipient
+    contractId := GenerateSHA256HashInBase64Form(preimage + ctx.GetStub().GetTxID())
+    return contractId
+}
@@ -25,13 +25,20 @@ type HashLock struct {
     HashBase64 string `json:"hashBase64"`
 }
 
+// Object used to capture the details of a lock claimable with a signature from an arbiter
+type SignatureLock struct {
+    ArbiterPublicKey string `json:"arbiterPublicKey"`
+}
+
 // Object used in the map, <asset-type, asset-id> --> <contractId, locker, recipient, ...> (for non-fungible assets)
+// BasketLock is set if the asset is locked in a basket, in which case ContractId is that of the basket
 type AssetLockValue struct {
     ContractId     string      `json:"contractId"`
     Locker         string      `json:"locker"`
     Recipient      string      `json:"recipient"`
     LockInfo       interface{} `json:"lockInfo"`
     ExpiryTimeSecs uint64      `json:"expiryTimeSecs"`
+    BasketLock     bool        `json:"basketLock,omitempty"`
 }
 
 func (a AssetLockValue) GetLocker() string {
@@ -48,6 +55,7 @@ func (a AssetLockValue) GetExpiryTimeSecs() uint64 {
 }
 
 // Object used in the map, contractId --> <asset-type, num-units, locker, ...> (for fungible assets)
+// NumUnits is the number of units locked, of which ClaimedUnits have been claimed so far by the recipient (in partial claims)
 type FungibleAssetLockValue struct {
     Type           string      `json:"type"`
     NumUnits       uint64      `json:"numUnits"`
@@ -55,6 +63,8 @@ type FungibleAssetLockValue struct {
     Recipient      string      `json:"recipient"`
     LockInfo       interface{} `json:"lockInfo"`
     ExpiryTimeSecs uint64      `json:"expiryTimeSecs"`
+    ClaimedUnits   uint64      `json:"claimedUnits,omitempty"`
+    ChaincodeId    string      `json:"chaincodeId,omitempty"` // chaincode that locked the asset, for which the total locked units are tracked
 }
 
 func (a FungibleAssetLockValue) GetLocker() string {
@@ -69,6 +79,84 @@ func (a FungibleAssetLockValue) GetLockInfo() interface{} {
 func (a FungibleAssetLockValue) GetExpiryTimeSecs() uint64 {
     return a.ExpiryTimeSecs
 }
+func (a FungibleAssetLockValue) GetRemainingUnits() uint64 {
+    return a.NumUnits - a.ClaimedUnits
+}
+
+// Object used in the map, hybrid contractId --> <asset-type, asset-id, asset-data, num-units, locker, ...> (for hybrid assets,
+// i.e., a number of units of an asset instance identified by its ID, like a batch from a specific commodity lot)
+type HybridAssetLockValue struct {
+    Type           string      `json:"type"`
+    Id             string      `json:"id"`
+    AssetData      []byte      `json:"assetData,omitempty"`
+    NumUnits       uint64      `json:"numUnits"`
+    Locker         string      `json:"locker"`
+    Recipient      string      `json:"recipient"`
+    LockInfo       interface{} `json:"lockInfo"`
+    ExpiryTimeSecs uint64      `json:"expiryTimeSecs"`
+}
+
+func (a HybridAssetLockValue) GetLocker() string {
+    return a.Locker
+}
+func (a HybridAssetLockValue) GetRecipient() string {
+    return a.Recipient
+}
+func (a HybridAssetLockValue) GetLockInfo() interface{} {
+    return a.LockInfo
+}
+func (a HybridAssetLockValue) GetExpiryTimeSecs() uint64 {
+    return a.ExpiryTimeSecs
+}
+func (a HybridAssetLockValue) GetAgreement() *common.HybridAssetExchangeAgreement {
+    return &common.HybridAssetExchangeAgreement{AssetType: a.Type, Id: a.Id, AssetData: a.AssetData, NumUnits: a.NumUnits, Locker: a.Locker, Recipient: a.Recipient}
+}
+
+// Non-fungible asset locked in a basket
+type BasketAsset struct {
+    Type string `json:"type"`
+    Id   string `json:"id"`
+}
+
+// Fungible asset units locked in a basket
+type BasketFungibleAsset struct {
+    Type     string `json:"type"`
+    NumUnits uint64 `json:"numUnits"`
+}
+
+// Object used in the map, basket contractId --> <assets, fungible assets, locker, ...> (for assets locked together in a basket)
+type BasketAssetLockValue struct {
+    Assets         []BasketAsset         `json:"assets"`
+    FungibleAssets []BasketFungibleAsset `json:"fungibleAssets"`
+    Locker         string                `json:"locker"`
+    Recipient      string                `json:"recipient"`
+    LockInfo       interface{}           `json:"lockInfo"`
+    ExpiryTimeSecs uint64                `json:"expiryTimeSecs"`
+    ChaincodeId    string                `json:"chaincodeId"`
+}
+
+func (a BasketAssetLockValue) GetLocker() string {
+    return a.Locker
+}
+func (a BasketAssetLockValue) GetRecipient() string {
+    return a.Recipient
+}
+func (a BasketAssetLockValue) GetLockInfo() interface{} {
+    return a.LockInfo
+}
+func (a BasketAssetLockValue) GetExpiryTimeSecs() uint64 {
+    return a.ExpiryTimeSecs
+}
+func (a BasketAssetLockValue) GetAgreement() *common.BasketAssetExchangeAgreement {
+    basketAgreement := &common.BasketAssetExchangeAgreement{Locker: a.Locker, Recipient: a.Recipient}
+    for _, asset := range a.Assets {
+        basketAgreement.Assets = append(basketAgreement.Assets, &common.AssetExchangeAgreement{AssetType: asset.Type, Id: asset.Id, Locker: a.Locker, Recipient: a.Recipient})
+    }
+    for _, fungibleAsset := range a.FungibleAssets {
+        basketAgreement.FungibleAssets = append(basketAgreement.FungibleAssets, &common.FungibleAssetExchangeAgreement{AssetType: fungibleAsset.Type, NumUnits: fungibleAsset.NumUnits, Locker: a.Locker, Recipient: a.Recipient})
+    }
+    return basketAgreement
+}
 
 const (
     assetKeyPrefix    = "AssetKey_"   // prefix for the map, asset-key --> asset-object
@@ -76,4 +164,7 @@ const (
     contractIdPrefix  = "ContractId_" // prefix for the map, contractId --> asset-key
     claimAssetKeyPrefix = "ClaimAssetKey_"
     claimContractIdPrefix = "ClaimContractId_"
+    basketContractIdPrefix = "BasketContractId_" // prefix for the map, basket contractId --> basket lock
+    hybridContractIdPrefix = "HybridContractId_" // prefix for the map, hybrid contractId --> hybrid asset lock
+    fungibleLockedUnitsObjectType = "FungibleLockedUnits" // object type of the map, <chaincode-id, asset-type, contractId> --> units locked
 )
@@ -11,6 +11,7 @@ package assetexchange
 import (
     "encoding/base64"
     "encoding/json"
+    "unicode/utf8"
 
     "github.com/golang/protobuf/proto"
     "github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
@@ -126,3 +127,153 @@ func getHashPreImageHelper(ctx contractapi.TransactionContextInterface, key stri
 
     return string(hashPreImageBase64Bytes), nil
 }
+
+// GetAssetTimeToRelease returns the expiry time (in seconds since the epoch) of the lock on an asset, as recorded at
+// lock time (a lock specified with a duration is recorded with the resolved expiry time)
+func GetAssetTimeToRelease(ctx contractapi.TransactionContextInterface, callerChaincodeID, assetType, assetId, recipient, locker string) (uint64, error) {
+    assetAgreement := &common.AssetExchangeAgreement{
+        AssetType: assetType,
+        Id: assetId,
+        Recipient: recipient,
+        Locker: locker,
+    }
+    assetLockKey, _, err := GenerateAssetLockKeyAndContractId(ctx, callerChaincodeID, assetAgreement)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+
+    assetLockValBytes, err := ctx.GetStub().GetState(assetLockKey)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+
+    if assetLockValBytes == nil {
+        return 0, logThenErrorf("no asset of type %s and ID %s is locked", assetType, assetId)
+    }
+
+    assetLockVal := AssetLockValue{}
+    err = json.Unmarshal(assetLockValBytes, &assetLockVal)
+    if err != nil {
+        return 0, logThenErrorf("unmarshal error: %s", err)
+    }
+
+    if assetLockVal.Locker != locker || assetLockVal.Recipient != recipient {
+        return 0, logThenErrorf("asset of type %s and ID %s is not locked by %s for %s", assetType, assetId, locker, recipient)
+    }
+
+    return assetLockVal.ExpiryTimeSecs, nil
+}
+
+// GetTotalFungibleLockedAssets returns the total units of an asset type currently locked by a chaincode, net of partial claims
+func GetTotalFungibleLockedAssets(ctx contractapi.TransactionContextInterface, callerChaincodeID, assetType string) (uint64, error) {
+    return getTotalFungibleLockedUnits(ctx, callerChaincodeID, assetType)
+}
+
+// GetFungibleAssetRemainingUnits returns the number of units of the fungible asset locked with contractId that remain
+// locked, i.e., that were not claimed yet. These are the units settled by the final claim or by the unlock of the lock.
+func GetFungibleAssetRemainingUnits(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+    assetLockVal, err := fetchFungibleAssetLocked(ctx, contractId)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+
+    return assetLockVal.GetRemainingUnits(), nil
+}
+
+// MigrateFungibleLockedUnits records the units that remain locked under the fungible asset locks made before the locked
+// units were recorded per lock, so that GetTotalFungibleLockedAssets accounts for them. lockerChaincodeID returns the ID
+// of the chaincode that made the lock with a contractId. It returns the number of locks migrated.
+func MigrateFungibleLockedUnits(ctx contractapi.TransactionContextInterface, lockerChaincodeID func(contractId string) (string, error)) (int, error) {
+    iterator, err := ctx.GetStub().GetStateByRange(contractIdPrefix, contractIdPrefix+string(utf8.MaxRune))
+    if err != nil {
+        return 0, logThenErrorf("failed to retrieve from the world state: %+v", err)
+    }
+    defer iterator.Close()
+
+    migratedLocks := 0
+    for iterator.HasNext() {
+        assetLockKV, err := iterator.Next()
+        if err != nil {
+            return migratedLocks, logThenErrorf("failed to retrieve from the world state: %+v", err)
+        }
+        // Non-fungible asset locks are mapped to the key of the locked asset, and are skipped along with the migrated locks
+        assetLockVal := FungibleAssetLockValue{}
+        if json.Unmarshal(assetLockKV.Value, &assetLockVal) != nil || assetLockVal.ChaincodeId != "" {
+            continue
+        }
+        contractId := assetLockKV.Key[len(contractIdPrefix):]
+        chaincodeId, err := lockerChaincodeID(contractId)
+        if err != nil {
+            return migratedLocks, logThenErrorf("failed to get the chaincode that locked the asset associated with contractId %s: %+v", contractId, err)
+        }
+
+        // Drop the record of any partial claim made before the migration, which was not attributed to the chaincode
+        err = recordFungibleLockedUnits(ctx, "", assetLockVal.Type, contractId, 0)
+        if err != nil {
+            return migratedLocks, err
+        }
+        assetLockVal.ChaincodeId = chaincodeId
+        err = putFungibleAssetLocked(ctx, contractId, assetLockVal)
+        if err != nil {
+            return migratedLocks, err
+        }
+        err = recordFungibleLockedUnits(ctx, chaincodeId, assetLockVal.Type, contractId, assetLockVal.GetRemainingUnits())
+        if err != nil {
+            return migratedLocks, err
+        }
+        migratedLocks++
+    }
+
+    return migratedLocks, nil
+}
+
+// GetFungibleAssetTimeToRelease returns the expiry time (in seconds since the epoch) of the lock on numUnits units of
+// assetType locked by a chaincode by locker for recipient, as recorded at lock time. As fungible locks are identified by
+// contract ID, the locks of the asset type are searched for the agreement, which must match exactly one of them.
+func GetFungibleAssetTimeToRelease(ctx contractapi.TransactionContextInterface, callerChaincodeID, assetType string, numUnits uint64, recipient, locker string) (uint64, error) {
+    iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(fungibleLockedUnitsObjectType, []string{callerChaincodeID, assetType})
+    if err != nil {
+        return 0, logThenErrorf("failed to retrieve from the world state: %+v", err)
+    }
+    defer iterator.Close()
+
+    matchingLocks := 0
+    expiryTimeSecs := uint64(0)
+    for iterator.HasNext() {
+        lockedUnitsKV, err := iterator.Next()
+        if err != nil {
+            return 0, logThenErrorf("failed to retrieve from the world state: %+v", err)
+        }
+        _, attributes, err := ctx.GetStub().SplitCompositeKey(lockedUnitsKV.Key)
+        if err != nil || len(attributes) != 3 {
+            return 0, logThenErrorf("invalid locked units key %s: %+v", lockedUnitsKV.Key, err)
+        }
+        assetLockVal, err := fetchFungibleAssetLocked(ctx, attributes[2])
+        if err != nil {
+            return 0, logThenErrorf(err.Error())
+        }
+        if assetLockVal.NumUnits == numUnits && assetLockVal.Recipient == recipient && assetLockVal.Locker == locker {
+            matchingLocks++
+            expiryTimeSecs = assetLockVal.ExpiryTimeSecs
+        }
+    }
+
+    if matchingLocks == 0 {
+        return 0, logThenErrorf("no %d units of asset type %s are locked by %s for %s", numUnits, assetType, locker, recipient)
+    }
+    if matchingLocks > 1 {
+        return 0, logThenErrorf("%d locks of %d units of asset type %s are held by %s for %s; query the lock by contractId instead", matchingLocks, numUnits, assetType, locker, recipient)
+    }
+    return expiryTimeSecs, nil
+}
+
+// GetFungibleAssetTimeToReleaseByContractId returns the expiry time (in seconds since the epoch) of the lock on the
+// fungible assets locked with contractId, as recorded at lock time
+func GetFungibleAssetTimeToReleaseByContractId(ctx contractapi.TransactionContextInterface, contractId string) (uint64, error) {
+    assetLockVal, err := fetchFungibleAssetLocked(ctx, contractId)
+    if err != nil {
+        return 0, logThenErrorf(err.Error())
+    }
+
+    return assetLockVal.ExpiryTimeSecs, nil
+}
@@ -0,0 +1,73 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+///////////////////////////////////////////////////////
+//////        TRANSACTION TIME FUNCTIONS       ////////
+///////////////////////////////////////////////////////
+
+// Chaincode must not use the local time of the peer (time.Now()) for expiry checks, as it differs
+// across endorsing peers and causes endorsement mismatches close to expiry boundaries. Instead, the
+// transaction timestamp set by the submitting client is used, which is identical on every peer.
+//
+// As the client chooses the transaction timestamp, a skew tolerance can optionally be configured
+// (e.g., when the chaincode starts) to reject transactions whose timestamp differs from the local
+// time of the peer by more than the tolerance. The check is disabled when the tolerance is 0.
+var txTimeSkewTolerance time.Duration
+
+// SetTxTimeSkewTolerance sets the maximum permitted difference between the transaction timestamp and the local time of the peer
+func SetTxTimeSkewTolerance(tolerance time.Duration) {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	txTimeSkewTolerance = tolerance
+}
+
+// GetTxTimeSkewTolerance returns the maximum permitted difference between the transaction timestamp and the local time of the peer
+func GetTxTimeSkewTolerance() time.Duration {
+	return txTimeSkewTolerance
+}
+
+// GetTxTime returns the timestamp of the current transaction, which should be used in place of time.Now() in chaincode
+func GetTxTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if txTimestamp == nil {
+		return time.Time{}, fmt.Errorf("timestamp is missing")
+	}
+	txTime := txTimestamp.AsTime()
+	if txTimeSkewTolerance > 0 {
+		localTime := time.Now()
+		skew := txTime.Sub(localTime)
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > txTimeSkewTolerance {
+			return time.Time{}, fmt.Errorf("transaction timestamp %s differs from the local time %s by more than the tolerated skew of %s",
+				txTime.UTC().Format(time.RFC3339), localTime.UTC().Format(time.RFC3339), txTimeSkewTolerance)
+		}
+	}
+	return txTime, nil
+}
+
+// GetTxTimeSecs returns the timestamp of the current transaction in seconds since the Unix epoch
+func GetTxTimeSecs(stub shim.ChaincodeStubInterface) (uint64, error) {
+	txTime, err := GetTxTime(stub)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get transaction timestamp: %s", err)
+	}
+	return uint64(txTime.Unix()), nil
+}
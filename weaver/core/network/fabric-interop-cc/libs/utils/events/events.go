@@ -0,0 +1,101 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package events accumulates the chaincode events emitted within a transaction, to set them together as one versioned
+// envelope (Fabric retains only the last event set in a transaction). It is shared by the asset management interface,
+// the asset exchange library and the interop chaincode, so that the events they emit in the same transaction as an
+// application chaincode are not overridden.
+package events
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// Name of the chaincode event carrying a ChaincodeEventEnvelope
+	EnvelopeName = "ChaincodeEventEnvelope"
+	// Version of the envelope format set by Accumulator
+	EnvelopeVersion = 1
+)
+
+// Accumulator collects the events emitted within a transaction, to set them together as one chaincode event
+type Accumulator struct {
+	events []*common.ChaincodeEventEntry
+}
+
+// AddEvent adds an event to be set when the accumulator is flushed
+func (a *Accumulator) AddEvent(eventName string, payload []byte) {
+	a.events = append(a.events, &common.ChaincodeEventEntry{
+		EventName: eventName,
+		Payload:   payload,
+	})
+}
+
+// Events returns the events accumulated so far, in the order they were added
+func (a *Accumulator) Events() []*common.ChaincodeEventEntry {
+	return a.events
+}
+
+// Flush sets the accumulated events as a single chaincode event named EnvelopeName, and clears them.
+// No event is set if no events were accumulated.
+func (a *Accumulator) Flush(stub shim.ChaincodeStubInterface) error {
+	if len(a.events) == 0 {
+		return nil
+	}
+	envelope := &common.ChaincodeEventEnvelope{
+		Version: EnvelopeVersion,
+		Events:  a.events,
+	}
+	envelopeBytes, err := proto.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("Failed to marshal event envelope: %+v", err)
+	}
+	err = stub.SetEvent(EnvelopeName, envelopeBytes)
+	if err != nil {
+		return fmt.Errorf("Failed to set event envelope: %+v", err)
+	}
+	a.events = nil
+	return nil
+}
+
+// AccumulatorContextInterface is a transaction context that accumulates the events emitted in the transaction
+type AccumulatorContextInterface interface {
+	contractapi.TransactionContextInterface
+	GetEventAccumulator() *Accumulator
+}
+
+// TransactionContext is a transaction context in which the events emitted with SetEvent are accumulated instead of
+// being set directly. To use it, set it as the TransactionContextHandler of the contract, and Flush as its
+// AfterTransaction function. Application events should then be added to the accumulator of the context too.
+type TransactionContext struct {
+	contractapi.TransactionContext
+	accumulator Accumulator
+}
+
+// GetEventAccumulator returns the accumulator of the events emitted in the transaction
+func (ctx *TransactionContext) GetEventAccumulator() *Accumulator {
+	return &ctx.accumulator
+}
+
+// Flush sets the events accumulated in the transaction context as one chaincode event
+func Flush(ctx AccumulatorContextInterface) error {
+	return ctx.GetEventAccumulator().Flush(ctx.GetStub())
+}
+
+// SetEvent accumulates an event if the transaction context accumulates events, else sets it as the chaincode event of
+// the transaction
+func SetEvent(ctx contractapi.TransactionContextInterface, eventName string, payload []byte) error {
+	if accumulatorCtx, ok := ctx.(AccumulatorContextInterface); ok {
+		accumulatorCtx.GetEventAccumulator().AddEvent(eventName, payload)
+		return nil
+	}
+	return ctx.GetStub().SetEvent(eventName, payload)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package events
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEvent(t *testing.T) {
+	stub := shimtest.NewMockStub("interop", nil)
+
+	// Events are set directly without an accumulating transaction context
+	plainCtx := &contractapi.TransactionContext{}
+	plainCtx.SetStub(stub)
+	require.NoError(t, SetEvent(plainCtx, "LockAsset", []byte("lock")))
+	event := <-stub.ChaincodeEventsChannel
+	require.Equal(t, "LockAsset", event.EventName)
+
+	// Events are otherwise accumulated, and set as one envelope when flushed
+	ctx := &TransactionContext{}
+	ctx.SetStub(stub)
+	require.NoError(t, SetEvent(ctx, "LockAsset", []byte("lock")))
+	ctx.GetEventAccumulator().AddEvent("TransferAsset", []byte("asset1"))
+	require.Len(t, ctx.GetEventAccumulator().Events(), 2)
+	require.Empty(t, stub.ChaincodeEventsChannel)
+	require.NoError(t, Flush(ctx))
+	event = <-stub.ChaincodeEventsChannel
+	require.Equal(t, EnvelopeName, event.EventName)
+	envelope := &common.ChaincodeEventEnvelope{}
+	require.NoError(t, proto.Unmarshal(event.Payload, envelope))
+	require.Equal(t, uint32(EnvelopeVersion), envelope.Version)
+	require.Len(t, envelope.Events, 2)
+	require.Equal(t, "LockAsset", envelope.Events[0].EventName)
+	require.Equal(t, "TransferAsset", envelope.Events[1].EventName)
+
+	// Nothing is set when no events are accumulated
+	require.Empty(t, ctx.GetEventAccumulator().Events())
+	require.NoError(t, Flush(ctx))
+	require.Empty(t, stub.ChaincodeEventsChannel)
+}
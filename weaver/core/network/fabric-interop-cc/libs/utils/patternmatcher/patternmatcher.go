@@ -0,0 +1,382 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package patternmatcher matches view addresses (and their arguments) against the patterns in verification and access
+// control policies. It is shared by the interop chaincode and the Fabric SDK so that both resolve the same rule for an
+// address.
+//
+// A pattern is a sequence of segments separated by ':', matched against the segments of the address:
+//   - a literal character matches itself; '\' escapes the character that follows it (e.g. '\*' or '\[')
+//   - '?' matches any single character within a segment
+//   - '[abc]', '[a-z]' match any single character in the class within a segment; '[!a-z]' or '[^a-z]' any character not in it
+//   - '*' matches any sequence of characters within a segment, so a segment '*' matches exactly one segment (e.g. one argument)
+//   - a segment '**' matches any number of segments, including none (e.g. any number of arguments)
+//   - a '*' at the end of the pattern matches the remainder of the address, as in `mychannel:simplestate:*`
+//
+// When several patterns match an address, the most specific one takes precedence:
+//  1. a pattern without wildcards, or identical to the address
+//  2. the pattern with more literal characters
+//  3. the pattern with fewer wildcards matching any number of segments ('**' or a trailing '*')
+//  4. the pattern with fewer '*' wildcards
+//  5. the pattern with more character classes
+//  6. the pattern with fewer '?' wildcards
+//  7. the pattern appearing first
+package patternmatcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+const segmentSeparator = ':'
+
+type tokenKind int
+
+const (
+	tokenLiteral tokenKind = iota
+	tokenAnyChar
+	tokenClass
+	tokenStar
+)
+
+type classRange struct {
+	low  rune
+	high rune
+}
+
+type token struct {
+	kind    tokenKind
+	char    rune
+	negated bool
+	ranges  []classRange
+	anyTail bool // a trailing '*' matching the remainder of the address, across segments
+}
+
+// Specificity summarizes how specific a pattern is, and determines the precedence among patterns matching an address
+type Specificity struct {
+	Exact             bool
+	Literals          int
+	MultiSegmentStars int
+	Stars             int
+	AnyChars          int
+	Classes           int
+}
+
+func (s Specificity) String() string {
+	if s.Exact {
+		return "exact"
+	}
+	return fmt.Sprintf("%d literal characters, %d multi-segment wildcards, %d '*' wildcards, %d '?' wildcards, %d character classes",
+		s.Literals, s.MultiSegmentStars, s.Stars, s.AnyChars, s.Classes)
+}
+
+// Compare returns a positive number if s takes precedence over other, a negative number if other takes precedence
+// over s, and 0 if neither does
+func (s Specificity) Compare(other Specificity) int {
+	result, _ := s.compare(other)
+	return result
+}
+
+// compare compares two specificities and describes the precedence rule that decided between them
+func (s Specificity) compare(other Specificity) (int, string) {
+	if s.Exact != other.Exact {
+		if s.Exact {
+			return 1, "exact match"
+		}
+		return -1, "exact match"
+	}
+	if s.Literals != other.Literals {
+		return s.Literals - other.Literals, "more literal characters"
+	}
+	if s.MultiSegmentStars != other.MultiSegmentStars {
+		return other.MultiSegmentStars - s.MultiSegmentStars, "fewer multi-segment wildcards"
+	}
+	if s.Stars != other.Stars {
+		return other.Stars - s.Stars, "fewer '*' wildcards"
+	}
+	if s.Classes != other.Classes {
+		return s.Classes - other.Classes, "more character classes"
+	}
+	if s.AnyChars != other.AnyChars {
+		return other.AnyChars - s.AnyChars, "fewer '?' wildcards"
+	}
+	return 0, "appears first"
+}
+
+// Pattern is a compiled pattern
+type Pattern struct {
+	raw         string
+	segments    [][]token
+	multiStar   []bool // segments that are '**'
+	specificity Specificity
+}
+
+// Compile parses a pattern
+func Compile(pattern string) (*Pattern, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern is empty")
+	}
+	p := &Pattern{raw: pattern}
+	runes := []rune(pattern)
+	var segment []token
+	endSegment := func() error {
+		if len(segment) == 2 && segment[0].kind == tokenStar && segment[1].kind == tokenStar {
+			p.segments = append(p.segments, nil)
+			p.multiStar = append(p.multiStar, true)
+			p.specificity.MultiSegmentStars++
+		} else {
+			for i := 1; i < len(segment); i++ {
+				if segment[i-1].kind == tokenStar && segment[i].kind == tokenStar {
+					return fmt.Errorf("'**' must be a whole segment")
+				}
+			}
+			p.segments = append(p.segments, segment)
+			p.multiStar = append(p.multiStar, false)
+		}
+		segment = nil
+		return nil
+	}
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("pattern ends with an escape character")
+			}
+			i++
+			if runes[i] == segmentSeparator {
+				return nil, fmt.Errorf("segment separator '%c' cannot be escaped", segmentSeparator)
+			}
+			segment = append(segment, token{kind: tokenLiteral, char: runes[i]})
+			p.specificity.Literals++
+		case segmentSeparator:
+			if err := endSegment(); err != nil {
+				return nil, err
+			}
+			p.specificity.Literals++
+		case '?':
+			segment = append(segment, token{kind: tokenAnyChar})
+			p.specificity.AnyChars++
+		case '*':
+			segment = append(segment, token{kind: tokenStar})
+		case '[':
+			classToken, end, err := parseClass(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			segment = append(segment, classToken)
+			p.specificity.Classes++
+			i = end
+		default:
+			segment = append(segment, token{kind: tokenLiteral, char: runes[i]})
+			p.specificity.Literals++
+		}
+	}
+	if err := endSegment(); err != nil {
+		return nil, err
+	}
+	for i, segment := range p.segments {
+		for j := range segment {
+			if segment[j].kind != tokenStar {
+				continue
+			}
+			if i == len(p.segments)-1 && j == len(segment)-1 {
+				segment[j].anyTail = true
+				p.specificity.MultiSegmentStars++
+			} else {
+				p.specificity.Stars++
+			}
+		}
+	}
+	p.specificity.Exact = p.specificity.MultiSegmentStars == 0 && p.specificity.Stars == 0 && p.specificity.AnyChars == 0 &&
+		p.specificity.Classes == 0
+	return p, nil
+}
+
+// parseClass parses the character class starting at runes[start], returning its token and the position of its closing ']'
+func parseClass(runes []rune, start int) (token, int, error) {
+	classToken := token{kind: tokenClass}
+	i := start + 1
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		classToken.negated = true
+		i++
+	}
+	for ; i < len(runes) && (runes[i] != ']' || len(classToken.ranges) == 0); i++ {
+		low := runes[i]
+		if low == '\\' {
+			if i+1 >= len(runes) {
+				break
+			}
+			i++
+			low = runes[i]
+		}
+		high := low
+		if i+2 < len(runes) && runes[i+1] == '-' && runes[i+2] != ']' {
+			high = runes[i+2]
+			i += 2
+			if high < low {
+				return token{}, 0, fmt.Errorf("invalid range '%c-%c' in character class at position %d", low, high, start)
+			}
+		}
+		classToken.ranges = append(classToken.ranges, classRange{low: low, high: high})
+	}
+	if i >= len(runes) {
+		return token{}, 0, fmt.Errorf("unterminated character class at position %d", start)
+	}
+	return classToken, i, nil
+}
+
+// Validate checks that a pattern is well-formed
+func Validate(pattern string) error {
+	_, err := Compile(pattern)
+	return err
+}
+
+// String returns the pattern as written
+func (p *Pattern) String() string {
+	return p.raw
+}
+
+// Specificity returns the specificity of the pattern
+func (p *Pattern) Specificity() Specificity {
+	return p.specificity
+}
+
+// Match reports whether the address matches the pattern
+func (p *Pattern) Match(address string) bool {
+	return p.matchSegments(0, strings.Split(address, string(segmentSeparator)))
+}
+
+func (p *Pattern) matchSegments(patternIndex int, addressSegments []string) bool {
+	if patternIndex == len(p.segments) {
+		return len(addressSegments) == 0
+	}
+	if p.multiStar[patternIndex] {
+		for consumed := 0; consumed <= len(addressSegments); consumed++ {
+			if p.matchSegments(patternIndex+1, addressSegments[consumed:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(addressSegments) == 0 {
+		return false
+	}
+	if patternIndex == len(p.segments)-1 {
+		// the last segment can match the remainder of the address when it ends with a '*'
+		segment := p.segments[patternIndex]
+		if len(segment) > 0 && segment[len(segment)-1].anyTail {
+			return matchTokens(segment, []rune(strings.Join(addressSegments, string(segmentSeparator))))
+		}
+		return len(addressSegments) == 1 && matchTokens(segment, []rune(addressSegments[0]))
+	}
+	return matchTokens(p.segments[patternIndex], []rune(addressSegments[0])) && p.matchSegments(patternIndex+1, addressSegments[1:])
+}
+
+// matchTokens matches the tokens of a segment against text, where matched[i][j] records whether tokens[i:] match text[j:]
+func matchTokens(tokens []token, text []rune) bool {
+	matched := make([][]bool, len(tokens)+1)
+	for i := range matched {
+		matched[i] = make([]bool, len(text)+1)
+	}
+	matched[len(tokens)][len(text)] = true
+	for i := len(tokens) - 1; i >= 0; i-- {
+		for j := len(text); j >= 0; j-- {
+			t := tokens[i]
+			switch t.kind {
+			case tokenStar:
+				matched[i][j] = matched[i+1][j] || (j < len(text) && (t.anyTail || text[j] != segmentSeparator) && matched[i][j+1])
+			default:
+				matched[i][j] = j < len(text) && text[j] != segmentSeparator && t.matchChar(text[j]) && matched[i+1][j+1]
+			}
+		}
+	}
+	return matched[0][0]
+}
+
+func (t token) matchChar(c rune) bool {
+	switch t.kind {
+	case tokenLiteral:
+		return t.char == c
+	case tokenAnyChar:
+		return true
+	case tokenClass:
+		for _, r := range t.ranges {
+			if c >= r.low && c <= r.high {
+				return !t.negated
+			}
+		}
+		return t.negated
+	}
+	return false
+}
+
+// Match reports whether the address matches the pattern; an invalid pattern matches nothing
+func Match(pattern string, address string) bool {
+	p, err := Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return p.Match(address)
+}
+
+// specificityFor returns the specificity with which a pattern matches an address, treating a pattern identical to the
+// address as an exact match. It returns false if the pattern does not match the address.
+func specificityFor(pattern string, address string) (Specificity, bool, error) {
+	if pattern == address {
+		return Specificity{Exact: true, Literals: len([]rune(pattern))}, true, nil
+	}
+	p, err := Compile(pattern)
+	if err != nil {
+		return Specificity{}, false, err
+	}
+	return p.specificity, p.Match(address), nil
+}
+
+// BestMatch returns the index of the pattern that takes precedence among those matching the address, or -1 if none
+// matches. Invalid patterns are ignored.
+func BestMatch(patterns []string, address string) int {
+	bestIndex := -1
+	var bestSpecificity Specificity
+	for i, pattern := range patterns {
+		specificity, matched, err := specificityFor(pattern, address)
+		if err != nil || !matched {
+			continue
+		}
+		if bestIndex < 0 || specificity.Compare(bestSpecificity) > 0 {
+			bestIndex = i
+			bestSpecificity = specificity
+		}
+	}
+	return bestIndex
+}
+
+// Explain describes, for debugging, which of the patterns matches the address and why the others do not take precedence
+func Explain(patterns []string, address string) string {
+	bestIndex := BestMatch(patterns, address)
+	var explanation strings.Builder
+	if bestIndex < 0 {
+		fmt.Fprintf(&explanation, "address '%s' matches none of the patterns", address)
+	} else {
+		bestSpecificity, _, _ := specificityFor(patterns[bestIndex], address)
+		fmt.Fprintf(&explanation, "address '%s' matches pattern %d '%s' (%s)", address, bestIndex, patterns[bestIndex], bestSpecificity)
+	}
+	for i, pattern := range patterns {
+		if i == bestIndex {
+			continue
+		}
+		specificity, matched, err := specificityFor(pattern, address)
+		if err != nil {
+			fmt.Fprintf(&explanation, "\n  pattern %d '%s' is invalid: %s", i, pattern, err.Error())
+		} else if !matched {
+			fmt.Fprintf(&explanation, "\n  pattern %d '%s' does not match", i, pattern)
+		} else {
+			bestSpecificity, _, _ := specificityFor(patterns[bestIndex], address)
+			_, reason := bestSpecificity.compare(specificity)
+			fmt.Fprintf(&explanation, "\n  pattern %d '%s' matches (%s) but pattern %d takes precedence: %s", i, pattern, specificity, bestIndex, reason)
+		}
+	}
+	return explanation.String()
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package patternmatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	// Happy cases
+	for _, pattern := range []string{"valid:no:star", "valid:star:*", "*", "One*:*too:many", "test:*:star", "mychannel:cc:Read:**",
+		"mychannel:cc:[RW]*:?", "mychannel:cc:[!a-z]:x", `literal\*star`, "[]]"} {
+		require.NoError(t, Validate(pattern), pattern)
+	}
+	// Unhappy cases
+	require.EqualError(t, Validate(""), "pattern is empty")
+	require.EqualError(t, Validate("mychannel:cc:[Read"), "unterminated character class at position 13")
+	require.EqualError(t, Validate("mychannel:cc:[z-a]"), "invalid range 'z-a' in character class at position 13")
+	require.EqualError(t, Validate("mychannel:cc**"), "'**' must be a whole segment")
+	require.EqualError(t, Validate(`mychannel\`), "pattern ends with an escape character")
+	require.EqualError(t, Validate(`mychannel\:cc`), "segment separator ':' cannot be escaped")
+}
+
+func TestMatch(t *testing.T) {
+	// A trailing star matches the remainder of the address
+	require.True(t, Match("test:*", "test:star"))
+	require.True(t, Match("mychannel:*", "mychannel:simplestate:Read:a"))
+	require.True(t, Match("*", "test:exact"))
+	require.True(t, Match("test:exact", "test:exact"))
+	require.False(t, Match("notMatch:*", "test:exact"))
+	require.False(t, Match("exact*", "test:exact"))
+	require.False(t, Match("test:*", "test"))
+
+	// A star elsewhere matches within a single segment
+	require.True(t, Match("mychannel:*:Read:a", "mychannel:simplestate:Read:a"))
+	require.False(t, Match("mychannel:*:Read:a", "mychannel:simple:state:Read:a"))
+	require.True(t, Match("mychannel:simple*:Read:a", "mychannel:simplestate:Read:a"))
+	require.True(t, Match("mychannel:simplestate:Read:*", "mychannel:simplestate:Read:a:b"))
+
+	// Argument wildcards
+	require.True(t, Match("mychannel:simplestate:Read:**", "mychannel:simplestate:Read"))
+	require.True(t, Match("mychannel:simplestate:Read:**", "mychannel:simplestate:Read:a:b"))
+	require.True(t, Match("mychannel:simplestate:**:a", "mychannel:simplestate:Read:a"))
+	require.False(t, Match("mychannel:simplestate:**:a", "mychannel:simplestate:Read:b"))
+	require.True(t, Match("mychannel:simplestate:Read:*:b", "mychannel:simplestate:Read:a:b"))
+	require.False(t, Match("mychannel:simplestate:Read:*:b", "mychannel:simplestate:Read:b"))
+
+	// Single characters and character classes
+	require.True(t, Match("mychannel:simplestate:Read:key?", "mychannel:simplestate:Read:key1"))
+	require.False(t, Match("mychannel:simplestate:Read:key?", "mychannel:simplestate:Read:key10"))
+	require.True(t, Match("mychannel:simplestate:Read:key[0-9]", "mychannel:simplestate:Read:key7"))
+	require.False(t, Match("mychannel:simplestate:Read:key[0-9]", "mychannel:simplestate:Read:keyA"))
+	require.True(t, Match("mychannel:simplestate:Read:key[!0-9]", "mychannel:simplestate:Read:keyA"))
+	require.True(t, Match("mychannel:simplestate:[RW]*:a", "mychannel:simplestate:Write:a"))
+	require.True(t, Match(`mychannel:simplestate:Read:\*`, "mychannel:simplestate:Read:*"))
+	require.False(t, Match(`mychannel:simplestate:Read:\*`, "mychannel:simplestate:Read:a"))
+
+	// Invalid patterns match nothing
+	require.False(t, Match("mychannel:[", "mychannel:["))
+}
+
+func TestBestMatch(t *testing.T) {
+	address := "mychannel:simplestate:Read:a"
+	require.Equal(t, -1, BestMatch([]string{"otherchannel:*", "mychannel:simplestate:Write:*"}, address))
+	// Exact matches take precedence
+	require.Equal(t, 1, BestMatch([]string{"mychannel:simplestate:Read:*", address, "mychannel:simplestate:Read:a"}, address))
+	// Then patterns with more literal characters
+	require.Equal(t, 1, BestMatch([]string{"mychannel:*", "mychannel:simplestate:*", "*"}, address))
+	// Then patterns with fewer multi-segment wildcards
+	require.Equal(t, 0, BestMatch([]string{"mychannel:simplestate:*:a", "mychannel:simplestate:**:a"}, address))
+	// Then patterns with fewer stars
+	require.Equal(t, 1, BestMatch([]string{"mychannel:simplestate:*:a", "mychannel:simplestate:????:a"}, address))
+	require.Equal(t, 1, BestMatch([]string{"mychannel:simplestate:R*d:?", "mychannel:simplestate:R??d:?"}, address))
+	// Then patterns with more character classes
+	require.Equal(t, 1, BestMatch([]string{"mychannel:simplestate:Read:*", "mychannel:simplestate:Read:[a-c]*"}, address))
+	require.Equal(t, 1, BestMatch([]string{"mychannel:simplestate:Read:?", "mychannel:simplestate:Read:[a-c]"}, address))
+	// Then the first pattern
+	require.Equal(t, 0, BestMatch([]string{"mychannel:simplestate:R*:a", "mychannel:simplestate:*d:a"}, address))
+	// Invalid patterns are ignored
+	require.Equal(t, 1, BestMatch([]string{"mychannel:[", "mychannel:*"}, address))
+}
+
+func TestExplain(t *testing.T) {
+	explanation := Explain([]string{"mychannel:*", "mychannel:simplestate:*", "otherchannel:*", "mychannel:["}, "mychannel:simplestate:Read:a")
+	require.Equal(t, "address 'mychannel:simplestate:Read:a' matches pattern 1 'mychannel:simplestate:*' "+
+		"(22 literal characters, 1 multi-segment wildcards, 0 '*' wildcards, 0 '?' wildcards, 0 character classes)\n"+
+		"  pattern 0 'mychannel:*' matches (10 literal characters, 1 multi-segment wildcards, 0 '*' wildcards, 0 '?' wildcards, 0 character classes) "+
+		"but pattern 1 takes precedence: more literal characters\n"+
+		"  pattern 2 'otherchannel:*' does not match\n"+
+		"  pattern 3 'mychannel:[' is invalid: unterminated character class at position 10", explanation)
+	require.Equal(t, "address 'a:b' matches none of the patterns\n  pattern 0 'b:*' does not match", Explain([]string{"b:*"}, "a:b"))
+}
+
+// The patterns accepted by the matchers this package replaced keep matching the same addresses
+func TestLegacyPatterns(t *testing.T) {
+	// Patterns with no star, or a single star at the end
+	for _, pattern := range []string{"valid:no:star", "valid:star:*", "*", "abcd*", "abcd"} {
+		require.NoError(t, Validate(pattern), pattern)
+	}
+	// A pattern without stars matches the address exactly
+	require.True(t, Match("test:exact", "test:exact"))
+	require.False(t, Match("test:exac", "test:exact"))
+	// A trailing star matches the addresses the pattern is a prefix of
+	require.True(t, Match("test:*", "test:star"))
+	require.True(t, Match("*", "test:exact"))
+	require.True(t, Match("abcd*", "abcdef"))
+	require.False(t, Match("notMatch:*", "test:exact"))
+	// Unhappy case: pattern matches in the middle of the value
+	require.False(t, Match("exact*", "test:exact"))
+	require.False(t, Match("abcd*", "xabcdef"))
+
+	// Patterns with several stars, or a star before the end, were rejected and are now valid
+	for _, pattern := range []string{"One*:*too:many", "test:*:star", "ab*cd*", "ab*cd"} {
+		require.NoError(t, Validate(pattern), pattern)
+	}
+	require.True(t, Match("test:*:star", "test:a:star"))
+	require.True(t, Match("ab*cd", "abxcd"))
+}
@@ -15,7 +15,6 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
@@ -260,7 +259,10 @@ func PledgeAsset(ctx contractapi.TransactionContextInterface, assetJSON []byte,
 	}
 
 	// Make sure the pledge has an expiry time in the future
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return "", err
+	}
 	if currentTimeSecs >= expiryTimeSecs {
 		return "", fmt.Errorf("expiry time cannot be less than current time")
 	}
@@ -306,7 +308,10 @@ func ClaimRemoteAsset(ctx contractapi.TransactionContextInterface, pledgeId, rem
 	}
 
 	// Make sure the pledge has not expired (we assume the expiry timestamp set by the remote network)
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return nil, err
+	}
 	if currentTimeSecs >= pledge.ExpiryTimeSecs {
 		return nil, fmt.Errorf("cannot claim asset with pledgeId %s as the expiry time has elapsed", pledgeId)
 	}
@@ -375,7 +380,10 @@ func ReclaimAsset(ctx contractapi.TransactionContextInterface, pledgeId, recipie
 	if err != nil {
 		return nil, nil, err
 	}
-	currentTimeSecs := uint64(time.Now().Unix())
+	currentTimeSecs, err := GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return nil, nil, err
+	}
 	if currentTimeSecs < pledge.ExpiryTimeSecs {
 		return nil, nil, fmt.Errorf("cannot reclaim asset with pledgeId %s as the expiry time is not yet elapsed", pledgeId)
 	}
@@ -506,6 +514,10 @@ func GetAssetPledgeDetails(ctx contractapi.TransactionContextInterface, pledgeId
 func GetAssetClaimStatus(ctx contractapi.TransactionContextInterface, pledgeId, recipientCert, pledgerNetworkId string, pledgeExpiryTimeSecs uint64, blankAssetJSON []byte) ([]byte, string, string, error) {
 	// (Optional) Ensure that this function is being called by the relay via the Fabric Interop CC
 
+	currentTimeSecs, err := GetTxTimeSecs(ctx.GetStub())
+	if err != nil {
+		return nil, "", "", err
+	}
 	claimStatus := &common.AssetClaimStatus{
 		AssetDetails: blankAssetJSON,
 		LocalNetworkID: "",
@@ -513,7 +525,7 @@ func GetAssetClaimStatus(ctx contractapi.TransactionContextInterface, pledgeId,
 		Recipient: "",
 		ClaimStatus: false,
 		ExpiryTimeSecs: pledgeExpiryTimeSecs,
-		ExpirationStatus: (uint64(time.Now().Unix()) >= pledgeExpiryTimeSecs),
+		ExpirationStatus: (currentTimeSecs >= pledgeExpiryTimeSecs),
 	}
 	claimStatusBytes64, err := marshalAssetClaimStatus(claimStatus)
 	if err != nil {
@@ -0,0 +1,119 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package viewaddress parses and creates the view segments of view addresses. It is shared by the interop chaincode
+// and the Fabric SDK so that both agree on the arguments of a view and on the form its policies are matched against.
+//
+// A view segment is a sequence of sections separated by ':' (e.g. channel, contract, function and arguments). In an
+// escaped view segment, which starts with the EscapedMarker, the '%', ':' and '/' characters (and control characters)
+// in each section are percent-encoded (e.g. '%3A' for ':'), so that arguments can contain them, e.g.
+// "@v2:mychannel:simplestate:Read:%7B%22id%22%3A%22a%2Fb%22%7D" for the argument `{"id":"a/b"}`.
+//
+// Verification and access control policy patterns are matched against the canonical form of a view segment. An escaped
+// view segment is canonicalized by escaping its sections again and dropping the marker, so that the many spellings of
+// the same view (plain, escaped, with needlessly encoded characters) resolve to the same policy rules. A plain view
+// segment is its own canonical form, so that existing patterns with a literal '%' keep matching it.
+package viewaddress
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Encodings of a view segment
+const (
+	VersionPlain        = 1
+	VersionEscaped      = 2
+	EscapedMarker       = "@v2:"
+	versionMarkerPrefix = "@v"
+)
+
+// GetVersion returns the version of the encoding of a view segment
+func GetVersion(viewSegment string) (int, error) {
+	if strings.HasPrefix(viewSegment, EscapedMarker) {
+		return VersionEscaped, nil
+	}
+	if strings.HasPrefix(viewSegment, versionMarkerPrefix) {
+		return 0, fmt.Errorf("Unsupported address encoding version: %s", strings.SplitN(viewSegment, ":", 2)[0])
+	}
+	return VersionPlain, nil
+}
+
+// Split splits a view segment on ':' into its sections, unescaping the sections of an escaped view segment
+func Split(viewSegment string) ([]string, error) {
+	version, err := GetVersion(viewSegment)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(viewSegment, "/") {
+		return nil, fmt.Errorf("View segment contains a '/' %s", viewSegment)
+	}
+	sections := strings.Split(strings.TrimPrefix(viewSegment, EscapedMarker), ":")
+	if version == VersionEscaped {
+		for i, section := range sections {
+			sections[i], err = url.PathUnescape(section)
+			if err != nil {
+				return nil, fmt.Errorf("View segment section %d is not escaped correctly %s: %s", i, viewSegment, err.Error())
+			}
+		}
+	}
+	return sections, nil
+}
+
+// Canonical returns the form of a view segment that policy patterns are matched against. The sections of an escaped
+// view segment are escaped with EscapeSection and joined without the marker, whereas a plain view segment is unchanged.
+func Canonical(viewSegment string) (string, error) {
+	sections, err := Split(viewSegment)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(viewSegment, EscapedMarker) {
+		return viewSegment, nil
+	}
+	for i, section := range sections {
+		sections[i] = EscapeSection(section)
+	}
+	return strings.Join(sections, ":"), nil
+}
+
+func needsEscaping(c byte) bool {
+	return c == '%' || c == ':' || c == '/' || c < 0x20 || c == 0x7f
+}
+
+// EscapeSection percent-encodes the '%', ':' and '/' characters and the control characters of a section of an escaped
+// view segment
+func EscapeSection(section string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(section); i++ {
+		if needsEscaping(section[i]) {
+			fmt.Fprintf(&escaped, "%%%02X", section[i])
+		} else {
+			escaped.WriteByte(section[i])
+		}
+	}
+	return escaped.String()
+}
+
+// Create builds a view segment from its sections. The view segment is escaped only if a section contains a character that
+// needs escaping, so that other view segments remain in the plain format.
+func Create(sections []string) string {
+	escape := len(sections) > 0 && strings.HasPrefix(sections[0], versionMarkerPrefix)
+	for _, section := range sections {
+		if strings.IndexFunc(section, func(r rune) bool { return r < 0x80 && needsEscaping(byte(r)) }) >= 0 {
+			escape = true
+			break
+		}
+	}
+	if !escape {
+		return strings.Join(sections, ":")
+	}
+	escapedSections := make([]string, len(sections))
+	for i, section := range sections {
+		escapedSections[i] = EscapeSection(section)
+	}
+	return EscapedMarker + strings.Join(escapedSections, ":")
+}
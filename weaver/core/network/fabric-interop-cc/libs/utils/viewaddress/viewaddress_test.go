@@ -0,0 +1,88 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package viewaddress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersion(t *testing.T) {
+	version, err := GetVersion("mychannel:simplestate:Read:a")
+	require.NoError(t, err)
+	require.Equal(t, VersionPlain, version)
+	version, err = GetVersion("@v2:mychannel:simplestate:Read:a")
+	require.NoError(t, err)
+	require.Equal(t, VersionEscaped, version)
+	_, err = GetVersion("@v3:mychannel:simplestate:Read:a")
+	require.EqualError(t, err, "Unsupported address encoding version: @v3")
+}
+
+func TestSplit(t *testing.T) {
+	sections, err := Split("mychannel:simplestate:Read:a:b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"mychannel", "simplestate", "Read", "a", "b"}, sections)
+	// Plain sections are not unescaped
+	sections, err = Split("mychannel:simplestate:Read:a%3Ab")
+	require.NoError(t, err)
+	require.Equal(t, []string{"mychannel", "simplestate", "Read", "a%3Ab"}, sections)
+	sections, err = Split("@v2:mychannel:simplestate:Read:%7B%22id%22%3A%22a%2Fb%22%7D:100%25:")
+	require.NoError(t, err)
+	require.Equal(t, []string{"mychannel", "simplestate", "Read", `{"id":"a/b"}`, "100%", ""}, sections)
+
+	_, err = Split("mychannel/simplestate:Read:a")
+	require.EqualError(t, err, "View segment contains a '/' mychannel/simplestate:Read:a")
+	_, err = Split("@v2:mychannel:simplestate:Read:100%")
+	require.EqualError(t, err, "View segment section 3 is not escaped correctly @v2:mychannel:simplestate:Read:100%: invalid URL escape \"%\"")
+}
+
+func TestCanonical(t *testing.T) {
+	// Every spelling of a view has the same canonical form
+	for _, viewSegment := range []string{"mychannel:simplestate:Read:secret", "@v2:mychannel:simplestate:Read:secret",
+		"@v2:mychannel:simplestate:Read:%73ecret", "@v2:%6Dychannel:simplestate:%52ead:%73%65%63%72%65%74"} {
+		canonical, err := Canonical(viewSegment)
+		require.NoError(t, err)
+		require.Equal(t, "mychannel:simplestate:Read:secret", canonical, viewSegment)
+	}
+	// Characters that need escaping in escaped view segments are escaped with upper case hexadecimal digits
+	canonical, err := Canonical("@v2:mychannel:simplestate:Read:100%25")
+	require.NoError(t, err)
+	require.Equal(t, "mychannel:simplestate:Read:100%25", canonical)
+	canonical, err = Canonical("@v2:mychannel:simplestate:Read:a%3ab%2fc")
+	require.NoError(t, err)
+	require.Equal(t, "mychannel:simplestate:Read:a%3Ab%2Fc", canonical)
+	// Plain view segments are not escaped, so that patterns with a literal '%' keep matching them
+	for _, viewSegment := range []string{"mychannel:simplestate:Read:100%", "mychannel:simplestate:Read:a%3Ab"} {
+		canonical, err = Canonical(viewSegment)
+		require.NoError(t, err)
+		require.Equal(t, viewSegment, canonical)
+	}
+
+	_, err = Canonical("@v2:mychannel:simplestate:Read:100%")
+	require.Error(t, err)
+	_, err = Canonical("mychannel/simplestate:Read:a")
+	require.Error(t, err)
+}
+
+func TestCreate(t *testing.T) {
+	// View segments without characters to escape keep the plain format
+	require.Equal(t, "mychannel:simplestate:Read:a:b", Create([]string{"mychannel", "simplestate", "Read", "a", "b"}))
+	// Otherwise all sections are escaped, and split back to their original values
+	sections := []string{"mychannel", "simplestate", "Read", `{"id":"a/b"}`, "https://example.com/?q=100%", "line\nbreak", ""}
+	viewSegment := Create(sections)
+	require.Equal(t, "@v2:mychannel:simplestate:Read:{\"id\"%3A\"a%2Fb\"}:https%3A%2F%2Fexample.com%2F?q=100%25:line%0Abreak:", viewSegment)
+	splitSections, err := Split(viewSegment)
+	require.NoError(t, err)
+	require.Equal(t, sections, splitSections)
+	// A first section that looks like a version marker is escaped so that it is not taken for one
+	viewSegment = Create([]string{"@v3", "simplestate", "Read"})
+	require.Equal(t, "@v2:@v3:simplestate:Read", viewSegment)
+	splitSections, err = Split(viewSegment)
+	require.NoError(t, err)
+	require.Equal(t, []string{"@v3", "simplestate", "Read"}, splitSections)
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+// Package x25519 encrypts and decrypts data for holders of Ed25519 keys, as used for X25519_CHACHA20_POLY1305
+// confidential payloads. It is shared by the interop chaincode, which encrypts view payloads for the requestor, and
+// the Fabric SDK, which decrypts them.
+//
+// The Ed25519 key is converted to its X25519 equivalent and combined with an ephemeral X25519 key pair to derive a
+// shared secret, from which a ChaCha20-Poly1305 key is derived using HKDF-SHA256. The encrypted data is the
+// concatenation of the ephemeral X25519 public key, the nonce and the ciphertext.
+package x25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Context string used to derive encryption keys
+const encryptionInfo = "weaver-confidential-payload-x25519-chacha20poly1305"
+
+// curve25519P is the prime 2^255 - 19 underlying both Curve25519 and Edwards25519
+var curve25519P, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed", 16)
+
+// Ed25519PublicKeyToX25519 converts an Ed25519 public key into the equivalent X25519 public key
+// using the birational map u = (1 + y) / (1 - y) between Edwards25519 and Curve25519 (RFC 7748)
+func Ed25519PublicKeyToX25519(pubKey []byte) ([]byte, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("Invalid Ed25519 public key length: %d", len(pubKey))
+	}
+	// The key is the little-endian encoding of y, with the sign of x in the most significant bit
+	yBytes := make([]byte, ed25519.PublicKeySize)
+	for i, b := range pubKey {
+		yBytes[len(pubKey)-1-i] = b
+	}
+	yBytes[0] &= 0x7f
+	y := new(big.Int).SetBytes(yBytes)
+	if y.Cmp(curve25519P) >= 0 {
+		return nil, errors.New("Invalid Ed25519 public key: non-canonical encoding")
+	}
+	denominator := new(big.Int).Sub(big.NewInt(1), y)
+	denominator.Mod(denominator, curve25519P)
+	if denominator.Sign() == 0 {
+		return nil, errors.New("Invalid Ed25519 public key: identity point")
+	}
+	u := new(big.Int).Add(big.NewInt(1), y)
+	u.Mul(u, new(big.Int).ModInverse(denominator, curve25519P))
+	u.Mod(u, curve25519P)
+	uBytes := u.FillBytes(make([]byte, curve25519.PointSize))
+	// Convert back to little-endian
+	for i, j := 0, len(uBytes)-1; i < j; i, j = i+1, j-1 {
+		uBytes[i], uBytes[j] = uBytes[j], uBytes[i]
+	}
+	return uBytes, nil
+}
+
+// Ed25519PrivateKeyToX25519 converts an Ed25519 private key into the X25519 private key matching
+// the public key returned by Ed25519PublicKeyToX25519
+func Ed25519PrivateKeyToX25519(privKey ed25519.PrivateKey) []byte {
+	digest := sha512.Sum512(privKey.Seed())
+	scalar := digest[:curve25519.ScalarSize]
+	scalar[0] &= 248
+	scalar[31] &= 127
+	scalar[31] |= 64
+	return scalar
+}
+
+// deriveEncryptionKey derives the ChaCha20-Poly1305 key from an X25519 shared secret, binding
+// it to both parties' public keys
+func deriveEncryptionKey(sharedSecret, ephemeralPubKey, recipientPubKey []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeralPubKey...), recipientPubKey...)
+	kdf := hkdf.New(sha256.New, sharedSecret, salt, []byte(encryptionInfo))
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// EncryptWithEd25519PublicKey encrypts a message for the holder of an Ed25519 public key
+func EncryptWithEd25519PublicKey(message []byte, pubKey []byte) ([]byte, error) {
+	recipientPubKey, err := Ed25519PublicKeyToX25519(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPrivKey := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(ephemeralPrivKey); err != nil {
+		return nil, err
+	}
+	ephemeralPubKey, err := curve25519.X25519(ephemeralPrivKey, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := curve25519.X25519(ephemeralPrivKey, recipientPubKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveEncryptionKey(sharedSecret, ephemeralPubKey, recipientPubKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	encBytes := append(append([]byte{}, ephemeralPubKey...), nonce...)
+	return aead.Seal(encBytes, nonce, message, ephemeralPubKey), nil
+}
+
+// DecryptWithEd25519PrivateKey decrypts a message encrypted by EncryptWithEd25519PublicKey
+func DecryptWithEd25519PrivateKey(data []byte, privKey ed25519.PrivateKey) ([]byte, error) {
+	if len(data) < curve25519.PointSize+chacha20poly1305.NonceSize+chacha20poly1305.Overhead {
+		return nil, errors.New("Encrypted payload is too short")
+	}
+	ephemeralPubKey := data[:curve25519.PointSize]
+	nonce := data[curve25519.PointSize : curve25519.PointSize+chacha20poly1305.NonceSize]
+	ciphertext := data[curve25519.PointSize+chacha20poly1305.NonceSize:]
+
+	recipientPubKey, err := Ed25519PublicKeyToX25519(privKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := curve25519.X25519(Ed25519PrivateKeyToX25519(privKey), ephemeralPubKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveEncryptionKey(sharedSecret, ephemeralPubKey, recipientPubKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	message, err := aead.Open(nil, nonce, ciphertext, ephemeralPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to decrypt payload: %s", err)
+	}
+	return message, nil
+}
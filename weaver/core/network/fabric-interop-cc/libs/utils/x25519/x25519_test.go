@@ -0,0 +1,61 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package x25519
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestEd25519KeyConversion(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// The converted public and private keys must match
+	x25519PubKey, err := Ed25519PublicKeyToX25519(pubKey)
+	require.NoError(t, err)
+	derivedPubKey, err := curve25519.X25519(Ed25519PrivateKeyToX25519(privKey), curve25519.Basepoint)
+	require.NoError(t, err)
+	require.Equal(t, derivedPubKey, x25519PubKey)
+
+	_, err = Ed25519PublicKeyToX25519([]byte("short"))
+	require.EqualError(t, err, "Invalid Ed25519 public key length: 5")
+}
+
+func TestEncryptWithEd25519PublicKey(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// Encrypt and decrypt some random bytes
+	message := []byte("random-message")
+	encBytes, err := EncryptWithEd25519PublicKey(message, pubKey)
+	require.NoError(t, err)
+	require.Equal(t, curve25519.PointSize+12+len(message)+16, len(encBytes))
+	decBytes, err := DecryptWithEd25519PrivateKey(encBytes, privKey)
+	require.NoError(t, err)
+	require.Equal(t, message, decBytes)
+
+	// Tampered ciphertext
+	encBytes[len(encBytes)-1] ^= 0xff
+	_, err = DecryptWithEd25519PrivateKey(encBytes, privKey)
+	require.EqualError(t, err, "Unable to decrypt payload: chacha20poly1305: message authentication failed")
+
+	// Wrong private key
+	encBytes[len(encBytes)-1] ^= 0xff
+	_, otherPrivKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = DecryptWithEd25519PrivateKey(encBytes, otherPrivKey)
+	require.EqualError(t, err, "Unable to decrypt payload: chacha20poly1305: message authentication failed")
+
+	_, err = DecryptWithEd25519PrivateKey(encBytes[:40], privKey)
+	require.EqualError(t, err, "Encrypted payload is too short")
+	_, err = EncryptWithEd25519PublicKey(message, []byte("short"))
+	require.EqualError(t, err, "Invalid Ed25519 public key length: 5")
+}
@@ -877,7 +877,7 @@ func interop(key string, localNetwork string, requestingOrg string, address stri
 		signkeyPEM: []byte(keyUser),
 	}
 
-	interopFlowResponse, _, err := interoperablehelper.InteropFlow(contract, networkName, invokeObject, requestingOrg, relayEnv.RelayEndPoint, interopArgIndices, interopJSONs, signer, certUser, false, false)
+	interopFlowResponse, _, err := interoperablehelper.InteropFlow(contract, networkName, invokeObject, requestingOrg, relayEnv.RelayEndPoint, interopArgIndices, interopJSONs, signer, certUser, false, false, nil)
 	if err != nil {
 		log.Fatalf("failed interoperablehelper.InteropFlow with error: %s", err.Error())
 	}
@@ -225,7 +225,7 @@ func (s *SmartContract) ClaimFungibleAsset(ctx contractapi.TransactionContextInt
 			return false, logThenErrorf(err.Error())
 		}
 
-		// Fetch the contracted token asset type and numUnits from the ledger
+		// Fetch the contracted token asset type and the numUnits that remain locked from the ledger
 		assetType, numUnits, err := s.amc.FetchFromContractIdFungibleAssetLookupMap(ctx, contractId)
 		if err != nil {
 			return false, logThenErrorf(err.Error())
@@ -245,6 +245,41 @@ func (s *SmartContract) ClaimFungibleAsset(ctx contractapi.TransactionContextInt
 	}
 }
 
+// ClaimFungibleAssetUnits claims numUnits of the tokens locked using contractId, leaving the rest locked.
+// The lookup map is updated to the units that remain locked, so that only these are credited when the rest of the
+// tokens are claimed or unlocked.
+func (s *SmartContract) ClaimFungibleAssetUnits(ctx contractapi.TransactionContextInterface, contractId string, numUnits uint64, claimInfoSerializedProto64 string) (uint64, error) {
+	remainingUnits, err := s.amc.ClaimFungibleAssetUnits(ctx, contractId, numUnits, claimInfoSerializedProto64)
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	// Add the claimed tokens into the wallet of the claimant
+	recipientECertBase64, err := getECertOfTxCreatorBase64(ctx)
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+
+	// Fetch the contracted token asset type from the ledger
+	assetType, _, err := s.amc.FetchFromContractIdFungibleAssetLookupMap(ctx, contractId)
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+
+	err = s.IssueTokenAssets(ctx, assetType, numUnits, recipientECertBase64)
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	if remainingUnits == 0 {
+		err = s.amc.DeleteFungibleAssetLookupMap(ctx, contractId)
+	} else {
+		err = s.amc.ContractIdFungibleAssetsLookupMap(ctx, assetType, remainingUnits, contractId)
+	}
+	if err != nil {
+		return 0, logThenErrorf(err.Error())
+	}
+	return remainingUnits, nil
+}
+
 func (s *SmartContract) UnlockAsset(ctx contractapi.TransactionContextInterface, assetAgreementSerializedProto64 string) (bool, error) {
 	assetAgreement, err := s.amc.ValidateAndExtractAssetAgreement(assetAgreementSerializedProto64)
 	if err != nil {
@@ -296,7 +331,7 @@ func (s *SmartContract) UnlockFungibleAsset(ctx contractapi.TransactionContextIn
 			return false, logThenErrorf(err.Error())
 		}
 
-		// Fetch the contracted token asset type and numUnits from the ledger
+		// Fetch the contracted token asset type and the numUnits that remain locked from the ledger
 		assetType, numUnits, err := s.amc.FetchFromContractIdFungibleAssetLookupMap(ctx, contractId)
 		if err != nil {
 			return false, logThenErrorf(err.Error())
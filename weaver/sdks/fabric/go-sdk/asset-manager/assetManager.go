@@ -11,6 +11,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
@@ -63,13 +64,84 @@ func createFungibleAssetExchangeAgreementSerializedBase64(assetType string, numU
 	return base64.StdEncoding.EncodeToString(assetAgreementBytes), nil
 }
 
-// Create an asset lock structure
-func createAssetLockInfoSerializedBase64(hashBase64 string, expiryTimeSecs uint64) (string, error) {
+// Create a hybrid asset exchange agreement structure
+func createHybridAssetExchangeAgreementSerializedBase64(assetType string, assetId string, assetData []byte, numUnits uint64, recipientECertBase64 string, lockerECertBase64 string) (string, error) {
+	assetAgreement := &common.HybridAssetExchangeAgreement{
+		AssetType: assetType,
+		Id:        assetId,
+		AssetData: assetData,
+		NumUnits:  numUnits,
+		Recipient: recipientECertBase64,
+		Locker:    lockerECertBase64,
+	}
+	assetAgreementBytes, err := proto.Marshal(assetAgreement)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	return base64.StdEncoding.EncodeToString(assetAgreementBytes), nil
+}
+
+// HTLCOption sets an optional parameter of the lock created by CreateHTLC or CreateFungibleHTLC
+type HTLCOption func(*common.AssetLockHTLC)
+
+// WithExpiryDuration makes the lock expire expiryTimeSecs seconds after the locking transaction, instead of at the epoch time expiryTimeSecs
+func WithExpiryDuration() HTLCOption {
+	return func(lockInfoHTLC *common.AssetLockHTLC) {
+		lockInfoHTLC.TimeSpec = common.TimeSpec_DURATION
+	}
+}
+
+// WithHashMechanism sets the mechanism used to generate the hash of the lock (SHA256 by default)
+func WithHashMechanism(hashMechanism common.HashMechanism) HTLCOption {
+	return func(lockInfoHTLC *common.AssetLockHTLC) {
+		lockInfoHTLC.HashMechanism = hashMechanism
+	}
+}
+
+// HTLCClaimOption sets an optional parameter of the claim made by ClaimAssetInHTLC, ClaimFungibleAssetInHTLC, etc.
+type HTLCClaimOption func(*common.AssetClaimHTLC)
+
+// WithClaimHashMechanism sets the hash mechanism of the claim (SHA256 by default). The interop chaincode rejects claims
+// whose hash mechanism differs from the one the HTLC was locked with, so it must be set to claim non-SHA256 locks.
+func WithClaimHashMechanism(hashMechanism common.HashMechanism) HTLCClaimOption {
+	return func(claimInfoHTLC *common.AssetClaimHTLC) {
+		claimInfoHTLC.HashMechanism = hashMechanism
+	}
+}
+
+// Create an HTLC lock structure with the options applied
+func createAssetLockHTLC(hashBase64 string, expiryTimeSecs uint64, options []HTLCOption) *common.AssetLockHTLC {
 	lockInfoHTLC := &common.AssetLockHTLC{
 		HashBase64:     []byte(hashBase64),
 		ExpiryTimeSecs: expiryTimeSecs,
 		TimeSpec:       common.TimeSpec_EPOCH,
 	}
+	for _, option := range options {
+		option(lockInfoHTLC)
+	}
+
+	return lockInfoHTLC
+}
+
+// Check that the lock expires in the future, with the expiry time being either an epoch time or a duration
+func validateHTLCExpiry(lockInfoHTLC *common.AssetLockHTLC) error {
+	if lockInfoHTLC.TimeSpec == common.TimeSpec_DURATION {
+		if lockInfoHTLC.ExpiryTimeSecs == 0 {
+			return logThenErrorf("supplied expiry duration is zero")
+		}
+		return nil
+	}
+	currentTimeSecs := uint64(time.Now().Unix())
+	if lockInfoHTLC.ExpiryTimeSecs <= currentTimeSecs {
+		return logThenErrorf("supplied expirty time in the past")
+	}
+
+	return nil
+}
+
+// Create an asset lock structure
+func createAssetLockInfoSerializedBase64(lockInfoHTLC *common.AssetLockHTLC) (string, error) {
 	lockInfoHTLCBytes, err := proto.Marshal(lockInfoHTLC)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
@@ -88,10 +160,17 @@ func createAssetLockInfoSerializedBase64(hashBase64 string, expiryTimeSecs uint6
 }
 
 // Create an asset claim structure
-func createAssetClaimInfoSerializedBase64(hashPreimageBase64 string) (string, error) {
+func createAssetClaimInfoSerializedBase64(hashPreimageBase64 string, options []HTLCClaimOption) (string, error) {
 	claimInfoHTLC := &common.AssetClaimHTLC{
 		HashPreimageBase64: []byte(hashPreimageBase64),
 	}
+	for _, option := range options {
+		option(claimInfoHTLC)
+	}
+	err := validateHashMechanism(claimInfoHTLC.HashMechanism)
+	if err != nil {
+		return "", err
+	}
 	claimInfoHTLCBytes, err := proto.Marshal(claimInfoHTLC)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
@@ -120,7 +199,7 @@ func GenerateSHA256HashInBase64Form(hashPreimage string) string {
 }
 
 func CreateHTLC(contract GatewayContract, assetType string, assetId string, recipientECertBase64 string,
-	hashBase64 string, expiryTimeSecs uint64) (string, error) {
+	hashBase64 string, expiryTimeSecs uint64, options ...HTLCOption) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
@@ -136,16 +215,21 @@ func CreateHTLC(contract GatewayContract, assetType string, assetId string, reci
 	if hashBase64 == "" {
 		return "", logThenErrorf("hashBase64 is not supplied")
 	}
-	currentTimeSecs := uint64(time.Now().Unix())
-	if expiryTimeSecs <= currentTimeSecs {
-		return "", logThenErrorf("supplied expirty time in the past")
+	lockInfoHTLC := createAssetLockHTLC(hashBase64, expiryTimeSecs, options)
+	err := validateHTLCExpiry(lockInfoHTLC)
+	if err != nil {
+		return "", err
+	}
+	err = validateHashMechanism(lockInfoHTLC.HashMechanism)
+	if err != nil {
+		return "", err
 	}
 
 	assetExchangeAgreementStr, err := createAssetExchangeAgreementSerializedBase64(assetType, assetId, recipientECertBase64, "")
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
-	lockInfoStr, err := createAssetLockInfoSerializedBase64(hashBase64, expiryTimeSecs)
+	lockInfoStr, err := createAssetLockInfoSerializedBase64(lockInfoHTLC)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -160,7 +244,7 @@ func CreateHTLC(contract GatewayContract, assetType string, assetId string, reci
 }
 
 func CreateFungibleHTLC(contract GatewayContract, assetType string, numUnits uint64, recipientECertBase64 string,
-	hashBase64 string, expiryTimeSecs uint64) (string, error) {
+	hashBase64 string, expiryTimeSecs uint64, options ...HTLCOption) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
@@ -176,16 +260,21 @@ func CreateFungibleHTLC(contract GatewayContract, assetType string, numUnits uin
 	if hashBase64 == "" {
 		return "", logThenErrorf("hashBase64 is not supplied")
 	}
-	currentTimeSecs := uint64(time.Now().Unix())
-	if expiryTimeSecs <= currentTimeSecs {
-		return "", logThenErrorf("supplied expirty time in the past")
+	lockInfoHTLC := createAssetLockHTLC(hashBase64, expiryTimeSecs, options)
+	err := validateHTLCExpiry(lockInfoHTLC)
+	if err != nil {
+		return "", err
+	}
+	err = validateHashMechanism(lockInfoHTLC.HashMechanism)
+	if err != nil {
+		return "", err
 	}
 
 	assetExchangeAgreementStr, err := createFungibleAssetExchangeAgreementSerializedBase64(assetType, numUnits, recipientECertBase64, "")
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
-	lockInfoStr, err := createAssetLockInfoSerializedBase64(hashBase64, expiryTimeSecs)
+	lockInfoStr, err := createAssetLockInfoSerializedBase64(lockInfoHTLC)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -199,6 +288,55 @@ func CreateFungibleHTLC(contract GatewayContract, assetType string, numUnits uin
 	return string(result), nil
 }
 
+// CreateHybridHTLC locks numUnits units of the hybrid asset of type assetType and ID assetId (with optional assetData), and returns the contractId of the lock
+func CreateHybridHTLC(contract GatewayContract, assetType string, assetId string, assetData []byte, numUnits uint64, recipientECertBase64 string,
+	hashBase64 string, expiryTimeSecs uint64, options ...HTLCOption) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if assetType == "" {
+		return "", logThenErrorf("asset type not supplied")
+	}
+	if assetId == "" {
+		return "", logThenErrorf("asset id not supplied")
+	}
+	if numUnits <= 0 {
+		return "", logThenErrorf("asset count must be a positive number")
+	}
+	if recipientECertBase64 == "" {
+		return "", logThenErrorf("recipientECertBase64 id not supplied")
+	}
+	if hashBase64 == "" {
+		return "", logThenErrorf("hashBase64 is not supplied")
+	}
+	lockInfoHTLC := createAssetLockHTLC(hashBase64, expiryTimeSecs, options)
+	err := validateHTLCExpiry(lockInfoHTLC)
+	if err != nil {
+		return "", err
+	}
+	err = validateHashMechanism(lockInfoHTLC.HashMechanism)
+	if err != nil {
+		return "", err
+	}
+
+	assetExchangeAgreementStr, err := createHybridAssetExchangeAgreementSerializedBase64(assetType, assetId, assetData, numUnits, recipientECertBase64, "")
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	lockInfoStr, err := createAssetLockInfoSerializedBase64(lockInfoHTLC)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("LockHybridAsset", assetExchangeAgreementStr, lockInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction LockHybridAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
 func IsAssetLockedInHTLC(contract GatewayContract, assetType string, assetId string, recipientECertBase64 string, lockerECertBase64 string) (string, error) {
 
 	if contract == nil {
@@ -249,6 +387,24 @@ func IsFungibleAssetLockedInHTLC(contract GatewayContract, contractId string) (s
 	return string(result), nil
 }
 
+func IsHybridAssetLockedInHTLC(contract GatewayContract, contractId string) (string, error) {
+
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+
+	// Normal invoke function
+	result, err := contract.EvaluateTransaction("IsHybridAssetLocked", contractId)
+	if err != nil {
+		return "", logThenErrorf("error in contract.EvaluateTransaction IsHybridAssetLocked: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
 func IsAssetLockedInHTLCqueryUsingContractId(contract GatewayContract, contractId string) (string, error) {
 
 	if contract == nil {
@@ -267,7 +423,7 @@ func IsAssetLockedInHTLCqueryUsingContractId(contract GatewayContract, contractI
 	return string(result), nil
 }
 
-func ClaimAssetInHTLC(contract GatewayContract, assetType string, assetId string, lockerECertBase64 string, hashPreimageBase64 string) (string, error) {
+func ClaimAssetInHTLC(contract GatewayContract, assetType string, assetId string, lockerECertBase64 string, hashPreimageBase64 string, options ...HTLCClaimOption) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
@@ -284,7 +440,7 @@ func ClaimAssetInHTLC(contract GatewayContract, assetType string, assetId string
 		return "", logThenErrorf("hashPreimageBase64 is not supplied")
 	}
 
-	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64)
+	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64, options)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -303,7 +459,7 @@ func ClaimAssetInHTLC(contract GatewayContract, assetType string, assetId string
 	return string(result), nil
 }
 
-func ClaimFungibleAssetInHTLC(contract GatewayContract, contractId string, hashPreimageBase64 string) (string, error) {
+func ClaimFungibleAssetInHTLC(contract GatewayContract, contractId string, hashPreimageBase64 string, options ...HTLCClaimOption) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
@@ -314,7 +470,7 @@ func ClaimFungibleAssetInHTLC(contract GatewayContract, contractId string, hashP
 		return "", logThenErrorf("hashPreimageBase64 is not supplied")
 	}
 
-	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64)
+	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64, options)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -328,18 +484,73 @@ func ClaimFungibleAssetInHTLC(contract GatewayContract, contractId string, hashP
 	return string(result), nil
 }
 
-func ClaimAssetInHTLCusingContractId(contract GatewayContract, contractId string, hashPreimageBase64 string) (string, error) {
+// Claim numUnits of the units locked in a fungible HTLC, leaving the rest locked; returns the number of units that remain locked
+func ClaimFungibleAssetUnitsInHTLC(contract GatewayContract, contractId string, numUnits uint64, hashPreimageBase64 string, options ...HTLCClaimOption) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
 	}
 	if contractId == "" {
 		return "", logThenErrorf("contractId not supplied")
 	}
+	if numUnits <= 0 {
+		return "", logThenErrorf("asset count must be a positive number")
+	}
 	if hashPreimageBase64 == "" {
 		return "", logThenErrorf("hashPreimageBase64 is not supplied")
 	}
 
-	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64)
+	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64, options)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("ClaimFungibleAssetUnits", contractId, strconv.FormatUint(numUnits, 10), claimInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction ClaimFungibleAssetUnits: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+// ClaimHybridAssetInHTLC claims the hybrid asset locked with contractId, and returns the hybrid asset agreement (serialized in base64 form) listing the units claimed
+func ClaimHybridAssetInHTLC(contract GatewayContract, contractId string, hashPreimageBase64 string, options ...HTLCClaimOption) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+	if hashPreimageBase64 == "" {
+		return "", logThenErrorf("hashPreimageBase64 is not supplied")
+	}
+
+	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64, options)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("ClaimHybridAsset", contractId, claimInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction ClaimHybridAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+func ClaimAssetInHTLCusingContractId(contract GatewayContract, contractId string, hashPreimageBase64 string, options ...HTLCClaimOption) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+	if hashPreimageBase64 == "" {
+		return "", logThenErrorf("hashPreimageBase64 is not supplied")
+	}
+
+	claimInfoStr, err := createAssetClaimInfoSerializedBase64(hashPreimageBase64, options)
 	if err != nil {
 		return "", logThenErrorf(err.Error())
 	}
@@ -398,6 +609,23 @@ func ReclaimFungibleAssetInHTLC(contract GatewayContract, contractId string) (st
 	return string(result), nil
 }
 
+func ReclaimHybridAssetInHTLC(contract GatewayContract, contractId string) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("UnlockHybridAsset", contractId)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction UnlockHybridAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
 func ReclaimAssetInHTLCusingContractId(contract GatewayContract, contractId string) (string, error) {
 	if contract == nil {
 		return "", logThenErrorf("contract handle not supplied")
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -89,6 +90,18 @@ func TestCreateHTLC(t *testing.T) {
 	}
 	require.Equal(t, contractId, "contract-id")
 
+	// Test with the expiry time specified as a duration from the time of locking
+	expectedError = "supplied expiry duration is zero"
+	_, err = CreateHTLC(contract, assetType, assetId, recipientECertBase64, hashBase64, 0, WithExpiryDuration())
+	require.EqualError(t, err, expectedError)
+
+	contractId, err = CreateHTLC(contract, assetType, assetId, recipientECertBase64, hashBase64, 300, WithExpiryDuration())
+	require.NoError(t, err)
+	require.Equal(t, contractId, "contract-id")
+	lockInfoHTLC := createAssetLockHTLC(hashBase64, 300, []HTLCOption{WithExpiryDuration()})
+	require.Equal(t, common.TimeSpec_DURATION, lockInfoHTLC.TimeSpec)
+	require.Equal(t, uint64(300), lockInfoHTLC.ExpiryTimeSecs)
+
 	submitTransactionMock = func() ([]byte, error) {
 		return []byte(""), errors.New("failed submission")
 	}
@@ -429,6 +442,44 @@ func TestClaimFungibleAssetInHTLC(t *testing.T) {
 	require.EqualError(t, err, expectedError)
 }
 
+func TestClaimFungibleAssetUnitsInHTLC(t *testing.T) {
+
+	contract := gatewayContractMock{}
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("6"), nil
+	}
+
+	contractId := "contract-id"
+	hashPreimageBase64 := "hashPreimageBase64"
+
+	expectedError := "contract handle not supplied"
+	_, err := ClaimFungibleAssetUnitsInHTLC(nil, contractId, 4, hashPreimageBase64)
+	require.EqualError(t, err, expectedError)
+
+	expectedError = "contractId not supplied"
+	_, err = ClaimFungibleAssetUnitsInHTLC(contract, "", 4, hashPreimageBase64)
+	require.EqualError(t, err, expectedError)
+
+	expectedError = "asset count must be a positive number"
+	_, err = ClaimFungibleAssetUnitsInHTLC(contract, contractId, 0, hashPreimageBase64)
+	require.EqualError(t, err, expectedError)
+
+	expectedError = "hashPreimageBase64 is not supplied"
+	_, err = ClaimFungibleAssetUnitsInHTLC(contract, contractId, 4, "")
+	require.EqualError(t, err, expectedError)
+
+	remainingUnits, err := ClaimFungibleAssetUnitsInHTLC(contract, contractId, 4, hashPreimageBase64)
+	require.NoError(t, err)
+	require.Equal(t, "6", remainingUnits)
+
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte(""), errors.New("failed submission")
+	}
+	expectedError = "error in contract.SubmitTransaction ClaimFungibleAssetUnits: failed submission"
+	_, err = ClaimFungibleAssetUnitsInHTLC(contract, contractId, 4, hashPreimageBase64)
+	require.EqualError(t, err, expectedError)
+}
+
 func TestClaimAssetInHTLCusingContractId(t *testing.T) {
 
 	contract := gatewayContractMock{}
@@ -612,3 +663,59 @@ func TestReclaimAssetInHTLCusingContractId(t *testing.T) {
 	}
 	require.EqualError(t, err, expectedError)
 }
+
+func TestHybridHTLC(t *testing.T) {
+
+	contract := gatewayContractMock{}
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("contract-id"), nil
+	}
+	evaluateTransactionMock = func() ([]byte, error) {
+		return []byte("true"), nil
+	}
+
+	assetType := "coffee"
+	assetId := "lot-42"
+	assetData := []byte("grade=AA")
+	numUnits := uint64(50)
+	recipientECertBase64 := "recipientECertBase64"
+	hashBase64 := "hashBase64"
+	expiryTimeSecs := uint64(time.Now().Unix()) + 10
+
+	_, err := CreateHybridHTLC(contract, assetType, "", assetData, numUnits, recipientECertBase64, hashBase64, expiryTimeSecs)
+	require.EqualError(t, err, "asset id not supplied")
+
+	_, err = CreateHybridHTLC(contract, assetType, assetId, assetData, 0, recipientECertBase64, hashBase64, expiryTimeSecs)
+	require.EqualError(t, err, "asset count must be a positive number")
+
+	contractId, err := CreateHybridHTLC(contract, assetType, assetId, assetData, numUnits, recipientECertBase64, hashBase64, expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", contractId)
+
+	_, err = IsHybridAssetLockedInHTLC(contract, "")
+	require.EqualError(t, err, "contractId not supplied")
+
+	isLocked, err := IsHybridAssetLockedInHTLC(contract, contractId)
+	require.NoError(t, err)
+	require.Equal(t, "true", isLocked)
+
+	_, err = ClaimHybridAssetInHTLC(contract, contractId, "")
+	require.EqualError(t, err, "hashPreimageBase64 is not supplied")
+
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("agreement"), nil
+	}
+	result, err := ClaimHybridAssetInHTLC(contract, contractId, "hashPreimageBase64")
+	require.NoError(t, err)
+	require.Equal(t, "agreement", result)
+
+	result, err = ReclaimHybridAssetInHTLC(contract, contractId)
+	require.NoError(t, err)
+	require.Equal(t, "agreement", result)
+
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte(""), errors.New("failed submission")
+	}
+	_, err = ReclaimHybridAssetInHTLC(contract, contractId)
+	require.EqualError(t, err, "error in contract.SubmitTransaction UnlockHybridAsset: failed submission")
+}
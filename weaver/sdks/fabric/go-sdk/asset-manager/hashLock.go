@@ -0,0 +1,96 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assetmanager
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+/**
+ * Both legs of a swap must be locked with the same hash, generated with the same hash mechanism, for the preimage
+ * revealed by claiming one leg to be usable to claim the other. When swapping with an Ethereum-family ledger, whose
+ * HTLCs typically use Keccak-256, the Fabric leg must therefore be locked (and claimed) with HashMechanism_KECCAK256.
+ **/
+
+// Returns the hash of preimage generated with hashMechanism, or false if the mechanism is not supported
+func hashWithMechanism(hashMechanism common.HashMechanism, preimage []byte) ([]byte, bool) {
+	switch hashMechanism {
+	case common.HashMechanism_SHA256:
+		hash := sha256.Sum256(preimage)
+		return hash[:], true
+	case common.HashMechanism_SHA512:
+		hash := sha512.Sum512(preimage)
+		return hash[:], true
+	case common.HashMechanism_SHA3_256:
+		hash := sha3.Sum256(preimage)
+		return hash[:], true
+	case common.HashMechanism_KECCAK256:
+		hasher := sha3.NewLegacyKeccak256()
+		hasher.Write(preimage)
+		return hasher.Sum(nil), true
+	case common.HashMechanism_BLAKE2B256:
+		hash := blake2b.Sum256(preimage)
+		return hash[:], true
+	}
+	return nil, false
+}
+
+// Check that the hash mechanism is supported by this SDK (and the Fabric Interop CC)
+func validateHashMechanism(hashMechanism common.HashMechanism) error {
+	if _, ok := hashWithMechanism(hashMechanism, nil); !ok {
+		return logThenErrorf("hash mechanism %s is not supported", hashMechanism)
+	}
+
+	return nil
+}
+
+// function to generate a hash in base64 format for a given preimage, using the given hash mechanism
+func GenerateHashInBase64Form(hashPreimage string, hashMechanism common.HashMechanism) (string, error) {
+	err := validateHashMechanism(hashMechanism)
+	if err != nil {
+		return "", err
+	}
+	hash, _ := hashWithMechanism(hashMechanism, []byte(hashPreimage))
+
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
+
+/**
+ * ValidateSwapHashLocks checks that the HTLCs of the two legs of a swap are compatible, i.e., that the preimage revealed
+ * by claiming one of them can be used to claim the other. This is the case when both are locked with the same hash,
+ * generated with the same (supported) hash mechanism. It should be called by the recipient of the first leg before
+ * locking the second leg, and by the initiator before revealing the preimage.
+ **/
+func ValidateSwapHashLocks(hashMechanism common.HashMechanism, hashBase64 string,
+	counterpartHashMechanism common.HashMechanism, counterpartHashBase64 string) error {
+	if hashMechanism != counterpartHashMechanism {
+		return logThenErrorf("hash mechanisms %s and %s of the swap HTLCs are not compatible", hashMechanism, counterpartHashMechanism)
+	}
+	err := validateHashMechanism(hashMechanism)
+	if err != nil {
+		return err
+	}
+	expectedHash, _ := hashWithMechanism(hashMechanism, nil)
+	hash, err := base64.StdEncoding.DecodeString(hashBase64)
+	if err != nil {
+		return logThenErrorf("failed to decode hashBase64: %s", err.Error())
+	}
+	if len(hash) != len(expectedHash) {
+		return logThenErrorf("hash is not a valid %s hash", hashMechanism)
+	}
+	if hashBase64 != counterpartHashBase64 {
+		return logThenErrorf("swap HTLCs are not locked with the same hash")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assetmanager
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateHashInBase64Form(t *testing.T) {
+	// Hashes of "abc" for each hash mechanism
+	hashesHex := map[common.HashMechanism]string{
+		common.HashMechanism_SHA256:     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		common.HashMechanism_SHA3_256:   "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		common.HashMechanism_KECCAK256:  "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+		common.HashMechanism_BLAKE2B256: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319",
+	}
+	for hashMechanism, hashHex := range hashesHex {
+		hash, err := hex.DecodeString(hashHex)
+		require.NoError(t, err)
+		hashBase64, err := GenerateHashInBase64Form("abc", hashMechanism)
+		require.NoError(t, err)
+		require.Equal(t, base64.StdEncoding.EncodeToString(hash), hashBase64, hashMechanism.String())
+	}
+	hashBase64, err := GenerateHashInBase64Form("abc", common.HashMechanism_SHA256)
+	require.NoError(t, err)
+	require.Equal(t, GenerateSHA256HashInBase64Form("abc"), hashBase64)
+
+	_, err = GenerateHashInBase64Form("abc", common.HashMechanism(100))
+	require.EqualError(t, err, "hash mechanism 100 is not supported")
+
+	// Test that the watcher harvests preimages revealed in claims of Keccak-256 HTLCs
+	hashBase64, err = GenerateHashInBase64Form("abc", common.HashMechanism_KECCAK256)
+	require.NoError(t, err)
+	revealedHashBase64, err := hashPreimageBase64(common.HashMechanism_KECCAK256, base64.StdEncoding.EncodeToString([]byte("abc")))
+	require.NoError(t, err)
+	require.Equal(t, hashBase64, revealedHashBase64)
+}
+
+func TestValidateSwapHashLocks(t *testing.T) {
+	keccakHashBase64, err := GenerateHashInBase64Form("secret", common.HashMechanism_KECCAK256)
+	require.NoError(t, err)
+	sha256HashBase64 := GenerateSHA256HashInBase64Form("secret")
+
+	err = ValidateSwapHashLocks(common.HashMechanism_KECCAK256, keccakHashBase64, common.HashMechanism_KECCAK256, keccakHashBase64)
+	require.NoError(t, err)
+
+	err = ValidateSwapHashLocks(common.HashMechanism_SHA256, sha256HashBase64, common.HashMechanism_KECCAK256, keccakHashBase64)
+	require.EqualError(t, err, "hash mechanisms SHA256 and KECCAK256 of the swap HTLCs are not compatible")
+
+	err = ValidateSwapHashLocks(common.HashMechanism_KECCAK256, keccakHashBase64, common.HashMechanism_KECCAK256, sha256HashBase64)
+	require.EqualError(t, err, "swap HTLCs are not locked with the same hash")
+
+	sha512HashBase64, err := GenerateHashInBase64Form("secret", common.HashMechanism_SHA512)
+	require.NoError(t, err)
+	err = ValidateSwapHashLocks(common.HashMechanism_KECCAK256, sha512HashBase64, common.HashMechanism_KECCAK256, sha512HashBase64)
+	require.EqualError(t, err, "hash is not a valid KECCAK256 hash")
+
+	err = ValidateSwapHashLocks(common.HashMechanism(100), keccakHashBase64, common.HashMechanism(100), keccakHashBase64)
+	require.EqualError(t, err, "hash mechanism 100 is not supported")
+}
+
+func TestHTLCWithHashMechanism(t *testing.T) {
+	contract := gatewayContractMock{}
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("contract-id"), nil
+	}
+	hashBase64, err := GenerateHashInBase64Form("secret", common.HashMechanism_KECCAK256)
+	require.NoError(t, err)
+	expiryTimeSecs := uint64(time.Now().Unix()) + 300
+
+	lockInfoHTLC := createAssetLockHTLC(hashBase64, expiryTimeSecs, []HTLCOption{WithHashMechanism(common.HashMechanism_KECCAK256)})
+	require.Equal(t, common.HashMechanism_KECCAK256, lockInfoHTLC.HashMechanism)
+
+	result, err := CreateFungibleHTLC(contract, "asset-type", 10, "recipientECertBase64", hashBase64, expiryTimeSecs, WithHashMechanism(common.HashMechanism_KECCAK256))
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", result)
+
+	_, err = CreateHTLC(contract, "asset-type", "asset-id", "recipientECertBase64", hashBase64, expiryTimeSecs, WithHashMechanism(common.HashMechanism(100)))
+	require.EqualError(t, err, "hash mechanism 100 is not supported")
+
+	preimageBase64 := base64.StdEncoding.EncodeToString([]byte("secret"))
+	result, err = ClaimFungibleAssetInHTLC(contract, "contract-id", preimageBase64, WithClaimHashMechanism(common.HashMechanism_KECCAK256))
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", result)
+
+	_, err = ClaimAssetInHTLCusingContractId(contract, "contract-id", preimageBase64, WithClaimHashMechanism(common.HashMechanism(100)))
+	require.EqualError(t, err, "hash mechanism 100 is not supported")
+}
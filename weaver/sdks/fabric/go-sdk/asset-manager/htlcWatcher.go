@@ -0,0 +1,383 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assetmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/decoders"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// ChaincodeEventSource delivers the events emitted by a chaincode (implemented by *client.Network)
+type ChaincodeEventSource interface {
+	ChaincodeEvents(ctx context.Context, chaincodeName string, options ...client.ChaincodeEventsOption) (<-chan *client.ChaincodeEvent, error)
+}
+
+type HTLCEventType int
+
+const (
+	HTLCLocked HTLCEventType = iota
+	HTLCClaimed
+	HTLCUnlocked
+)
+
+func (t HTLCEventType) String() string {
+	switch t {
+	case HTLCLocked:
+		return "Locked"
+	case HTLCClaimed:
+		return "Claimed"
+	case HTLCUnlocked:
+		return "Unlocked"
+	}
+	return fmt.Sprintf("HTLCEventType(%d)", int(t))
+}
+
+// HTLCEvent is a change in the state of an HTLC, emitted by the asset management chaincode
+type HTLCEvent struct {
+	Type HTLCEventType
+	// ID of the HTLC; empty for claims and unlocks made with an asset agreement, unless the lock was seen by the watcher
+	ContractId string
+	Fungible   bool
+	AssetType  string
+	// ID of a non-fungible asset
+	AssetId string
+	// Number of units of a fungible asset; for partial claims, the number of units claimed
+	NumUnits uint64
+	// Number of units still locked after a partial claim (of a lock seen by the watcher)
+	RemainingUnits uint64
+	Locker         string
+	Recipient      string
+	// Lock details, set for lock events (and for claims and unlocks of locks seen by the watcher)
+	HashMechanism  common.HashMechanism
+	HashBase64     string
+	ExpiryTimeSecs uint64
+	// Preimage of the hash, set for claim events
+	HashPreimageBase64 string
+	TransactionId      string
+	BlockNumber        uint64
+}
+
+// Chaincode event names, as set by the asset management chaincode
+var htlcEventTypes = map[string]HTLCEventType{
+	"LockAsset":           HTLCLocked,
+	"LockFungibleAsset":   HTLCLocked,
+	"ClaimAsset":          HTLCClaimed,
+	"ClaimFungibleAsset":  HTLCClaimed,
+	"UnlockAsset":         HTLCUnlocked,
+	"UnlockFungibleAsset": HTLCUnlocked,
+	// Emitted for claims of part of the units locked in a fungible HTLC
+	"ClaimFungibleAssetUnits": HTLCClaimed,
+}
+
+func isFungibleHTLCEvent(eventName string) bool {
+	return eventName == "LockFungibleAsset" || eventName == "ClaimFungibleAsset" || eventName == "ClaimFungibleAssetUnits" ||
+		eventName == "UnlockFungibleAsset"
+}
+
+// DecodeHTLCEvent decodes a chaincode event emitted by the asset management chaincode for an HTLC
+func DecodeHTLCEvent(event *client.ChaincodeEvent) (*HTLCEvent, error) {
+	eventType, ok := htlcEventTypes[event.EventName]
+	if !ok {
+		return nil, logThenErrorf("%s is not an HTLC event", event.EventName)
+	}
+	htlcEvent := &HTLCEvent{
+		Type:          eventType,
+		Fungible:      isFungibleHTLCEvent(event.EventName),
+		TransactionId: event.TransactionID,
+		BlockNumber:   event.BlockNumber,
+	}
+	var lock *common.AssetLockHTLC
+	var claim *common.AssetClaimHTLC
+	if htlcEvent.Fungible {
+		contractInfo := &common.FungibleAssetContractHTLC{}
+		if err := proto.Unmarshal(event.Payload, contractInfo); err != nil {
+			return nil, logThenErrorf("failed to unmarshal %s event payload: %s", event.EventName, err.Error())
+		}
+		htlcEvent.ContractId = contractInfo.GetContractId()
+		htlcEvent.AssetType = contractInfo.GetAgreement().GetAssetType()
+		htlcEvent.NumUnits = contractInfo.GetAgreement().GetNumUnits()
+		htlcEvent.Locker = contractInfo.GetAgreement().GetLocker()
+		htlcEvent.Recipient = contractInfo.GetAgreement().GetRecipient()
+		lock, claim = contractInfo.GetLock(), contractInfo.GetClaim()
+	} else {
+		contractInfo := &common.AssetContractHTLC{}
+		if err := proto.Unmarshal(event.Payload, contractInfo); err != nil {
+			return nil, logThenErrorf("failed to unmarshal %s event payload: %s", event.EventName, err.Error())
+		}
+		htlcEvent.ContractId = contractInfo.GetContractId()
+		htlcEvent.AssetType = contractInfo.GetAgreement().GetAssetType()
+		htlcEvent.AssetId = contractInfo.GetAgreement().GetId()
+		htlcEvent.Locker = contractInfo.GetAgreement().GetLocker()
+		htlcEvent.Recipient = contractInfo.GetAgreement().GetRecipient()
+		lock, claim = contractInfo.GetLock(), contractInfo.GetClaim()
+	}
+	if lock != nil {
+		htlcEvent.HashMechanism = lock.GetHashMechanism()
+		htlcEvent.HashBase64 = string(lock.GetHashBase64())
+		htlcEvent.ExpiryTimeSecs = lock.GetExpiryTimeSecs()
+	}
+	if claim != nil {
+		htlcEvent.HashMechanism = claim.GetHashMechanism()
+		htlcEvent.HashPreimageBase64 = string(claim.GetHashPreimageBase64())
+	}
+	return htlcEvent, nil
+}
+
+// Returns the hash (in base64 form) of the preimage (in base64 form) revealed by an HTLC claim
+func hashPreimageBase64(hashMechanism common.HashMechanism, hashPreimageBase64 string) (string, error) {
+	preimage, err := base64.StdEncoding.DecodeString(hashPreimageBase64)
+	if err != nil {
+		return "", err
+	}
+	hash, ok := hashWithMechanism(hashMechanism, preimage)
+	if !ok {
+		return "", fmt.Errorf("hash mechanism %s is not supported", hashMechanism)
+	}
+	return base64.StdEncoding.EncodeToString(hash), nil
+}
+
+// A claim to be made with the preimage of a hash once it is revealed
+type pendingClaim struct {
+	claim  func(hashPreimageBase64 string) error
+	result chan error
+}
+
+// How long a preimage revealed by a claim of a lock not seen by the watcher (whose expiry is unknown) is kept
+const unknownLockPreimageRetentionSecs = 3600
+
+// A preimage revealed by a claim, kept for the claims registered after the reveal until the claimed lock expires
+type revealedPreimage struct {
+	hashPreimageBase64 string
+	expiryTimeSecs     uint64
+}
+
+/**
+ * HTLCWatcher watches the HTLC events emitted by a chaincode using the asset management chaincode, instead of polling
+ * for the state of HTLCs. It tracks the active locks it sees by contract ID, to fill in the contract ID and lock
+ * details of claims and unlocks made with an asset agreement. It can also claim the counterpart HTLC of a swap in
+ * another network automatically, once the preimage of the shared hash is revealed by a claim in this network.
+ **/
+type HTLCWatcher struct {
+	source        ChaincodeEventSource
+	chaincodeName string
+	mutex         sync.Mutex
+	// Active locks by contract ID
+	locks map[string]*HTLCEvent
+	// Contract IDs of the active locks of non-fungible assets by asset key
+	assetLocks map[string]string
+	// Preimages revealed by claims, by hash; an entry is removed once a claim made with it succeeds, or once the
+	// claimed lock expires
+	preimages map[string]*revealedPreimage
+	// Claims waiting for a preimage, by hash
+	pendingClaims map[string][]*pendingClaim
+}
+
+func NewHTLCWatcher(source ChaincodeEventSource, chaincodeName string) *HTLCWatcher {
+	return &HTLCWatcher{
+		source:        source,
+		chaincodeName: chaincodeName,
+		locks:         map[string]*HTLCEvent{},
+		assetLocks:    map[string]string{},
+		preimages:     map[string]*revealedPreimage{},
+		pendingClaims: map[string][]*pendingClaim{},
+	}
+}
+
+func getAssetKey(assetType, assetId string) string {
+	return assetType + ":" + assetId
+}
+
+/**
+ * Watch starts listening to the chaincode events, and sends the HTLC events to the returned channel in the order they
+ * were committed, including those carried in event envelopes. Events with other names are ignored. The channel is
+ * closed when the context is done or the event source closes the event stream. options are passed to the event source
+ * (e.g., client.WithStartBlock).
+ **/
+func (w *HTLCWatcher) Watch(ctx context.Context, options ...client.ChaincodeEventsOption) (<-chan *HTLCEvent, error) {
+	chaincodeEvents, err := w.source.ChaincodeEvents(ctx, w.chaincodeName, options...)
+	if err != nil {
+		return nil, logThenErrorf("failed to listen to events of chaincode %s: %s", w.chaincodeName, err.Error())
+	}
+	htlcEvents := make(chan *HTLCEvent)
+	go func() {
+		defer close(htlcEvents)
+		for {
+			var event *client.ChaincodeEvent
+			var ok bool
+			select {
+			case event, ok = <-chaincodeEvents:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			// Events accumulated in a transaction are emitted together in an envelope
+			expandedEvents, err := decoders.ExpandChaincodeEvent(event)
+			if err != nil {
+				log.Warnf("skipping %s event in transaction %s: %s", event.EventName, event.TransactionID, err.Error())
+				continue
+			}
+			for _, expandedEvent := range expandedEvents {
+				if _, ok = htlcEventTypes[expandedEvent.EventName]; !ok {
+					continue
+				}
+				htlcEvent, err := DecodeHTLCEvent(expandedEvent)
+				if err != nil {
+					log.Warnf("skipping %s event in transaction %s: %s", expandedEvent.EventName, expandedEvent.TransactionID, err.Error())
+					continue
+				}
+				w.process(htlcEvent)
+				select {
+				case htlcEvents <- htlcEvent:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return htlcEvents, nil
+}
+
+// Update the tracked locks with an event, fill in the details of the event from them, and trigger the pending claims
+func (w *HTLCWatcher) process(event *HTLCEvent) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	currentTimeSecs := uint64(time.Now().Unix())
+	for hashBase64, revealed := range w.preimages {
+		if revealed.expiryTimeSecs <= currentTimeSecs {
+			delete(w.preimages, hashBase64)
+		}
+	}
+
+	if event.Type == HTLCLocked {
+		lock := *event
+		w.locks[event.ContractId] = &lock
+		if !event.Fungible {
+			w.assetLocks[getAssetKey(event.AssetType, event.AssetId)] = event.ContractId
+		}
+		return
+	}
+
+	if event.ContractId == "" && !event.Fungible {
+		event.ContractId = w.assetLocks[getAssetKey(event.AssetType, event.AssetId)]
+	}
+	if lock, ok := w.locks[event.ContractId]; ok {
+		event.AssetType = lock.AssetType
+		event.AssetId = lock.AssetId
+		event.Locker = lock.Locker
+		event.Recipient = lock.Recipient
+		event.HashMechanism = lock.HashMechanism
+		event.HashBase64 = lock.HashBase64
+		event.ExpiryTimeSecs = lock.ExpiryTimeSecs
+		if event.Type == HTLCClaimed && event.NumUnits > 0 && event.NumUnits < lock.NumUnits {
+			// Partial claim: the lock remains with the units that were not claimed
+			lock.NumUnits -= event.NumUnits
+			event.RemainingUnits = lock.NumUnits
+		} else {
+			event.NumUnits = lock.NumUnits
+			delete(w.locks, event.ContractId)
+			if !lock.Fungible {
+				delete(w.assetLocks, getAssetKey(lock.AssetType, lock.AssetId))
+			}
+		}
+	}
+
+	if event.Type != HTLCClaimed || event.HashPreimageBase64 == "" {
+		return
+	}
+	hashBase64, err := hashPreimageBase64(event.HashMechanism, event.HashPreimageBase64)
+	if err != nil {
+		log.Warnf("cannot harvest preimage revealed in transaction %s: %s", event.TransactionId, err.Error())
+		return
+	}
+	expiryTimeSecs := event.ExpiryTimeSecs
+	if expiryTimeSecs == 0 {
+		expiryTimeSecs = currentTimeSecs + unknownLockPreimageRetentionSecs
+	}
+	if expiryTimeSecs > currentTimeSecs {
+		w.preimages[hashBase64] = &revealedPreimage{hashPreimageBase64: event.HashPreimageBase64, expiryTimeSecs: expiryTimeSecs}
+	}
+	for _, pending := range w.pendingClaims[hashBase64] {
+		go w.runClaim(pending, hashBase64, event.HashPreimageBase64)
+	}
+	delete(w.pendingClaims, hashBase64)
+}
+
+// Make a pending claim, and forget the preimage once the claim succeeds as it is no longer needed
+func (w *HTLCWatcher) runClaim(pending *pendingClaim, hashBase64, hashPreimageBase64 string) {
+	err := pending.claim(hashPreimageBase64)
+	if err == nil {
+		w.mutex.Lock()
+		delete(w.preimages, hashBase64)
+		w.mutex.Unlock()
+	}
+	pending.result <- err
+	close(pending.result)
+}
+
+// GetLock returns the details of an active lock seen by the watcher
+func (w *HTLCWatcher) GetLock(contractId string) (*HTLCEvent, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	lock, ok := w.locks[contractId]
+	if !ok {
+		return nil, false
+	}
+	lockCopy := *lock
+	return &lockCopy, true
+}
+
+/**
+ * ClaimOnReveal calls claim with the preimage of hashBase64 (in base64 form) as soon as a claim in the watched chaincode
+ * reveals it, or right away if it was already revealed. The result of claim is sent to the returned channel, which is
+ * then closed. This is meant to claim the counterpart HTLC of a swap, locked with the same hash in another network.
+ * A revealed preimage is only kept until a claim made with it succeeds or the claimed lock expires, so a claim
+ * registered after that waits for the preimage to be revealed again.
+ **/
+func (w *HTLCWatcher) ClaimOnReveal(hashBase64 string, claim func(hashPreimageBase64 string) error) <-chan error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	pending := &pendingClaim{claim: claim, result: make(chan error, 1)}
+	if revealed, ok := w.preimages[hashBase64]; ok && revealed.expiryTimeSecs > uint64(time.Now().Unix()) {
+		go w.runClaim(pending, hashBase64, revealed.hashPreimageBase64)
+	} else {
+		w.pendingClaims[hashBase64] = append(w.pendingClaims[hashBase64], pending)
+	}
+	return pending.result
+}
+
+/**
+ * ClaimHTLCOnReveal claims the HTLC with contractId in contract (typically in another network) with the preimage of
+ * hashBase64, as soon as it is revealed in the watched chaincode (see ClaimOnReveal). If the HTLC is not locked with
+ * a SHA256 hash, its hash mechanism must be supplied with the WithClaimHashMechanism option.
+ **/
+func (w *HTLCWatcher) ClaimHTLCOnReveal(hashBase64 string, contract GatewayContract, contractId string, fungible bool, options ...HTLCClaimOption) <-chan error {
+	return w.ClaimOnReveal(hashBase64, func(hashPreimageBase64 string) error {
+		var err error
+		if fungible {
+			_, err = ClaimFungibleAssetInHTLC(contract, contractId, hashPreimageBase64, options...)
+		} else {
+			_, err = ClaimAssetInHTLCusingContractId(contract, contractId, hashPreimageBase64, options...)
+		}
+		if err == nil {
+			log.Infof("claimed HTLC %s with the revealed preimage", contractId)
+		}
+		return err
+	})
+}
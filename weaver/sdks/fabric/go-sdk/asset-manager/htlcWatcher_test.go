@@ -0,0 +1,226 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assetmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/decoders"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/stretchr/testify/require"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Event source that delivers the events sent to its channel
+type chaincodeEventSourceMock struct {
+	events chan *client.ChaincodeEvent
+}
+
+func (s *chaincodeEventSourceMock) ChaincodeEvents(ctx context.Context, chaincodeName string, options ...client.ChaincodeEventsOption) (<-chan *client.ChaincodeEvent, error) {
+	return s.events, nil
+}
+
+func createChaincodeEvent(t *testing.T, eventName, txId string, contractInfo proto.Message) *client.ChaincodeEvent {
+	payload, err := proto.Marshal(contractInfo)
+	require.NoError(t, err)
+	return &client.ChaincodeEvent{EventName: eventName, TransactionID: txId, ChaincodeName: "simpleasset", Payload: payload}
+}
+
+func TestDecodeHTLCEvent(t *testing.T) {
+	hashBase64 := GenerateSHA256HashInBase64Form("secret")
+	event, err := DecodeHTLCEvent(createChaincodeEvent(t, "LockFungibleAsset", "tx1", &common.FungibleAssetContractHTLC{
+		ContractId: "contract1",
+		Agreement:  &common.FungibleAssetExchangeAgreement{AssetType: "token1", NumUnits: 50, Locker: "alice", Recipient: "bob"},
+		Lock:       &common.AssetLockHTLC{HashBase64: []byte(hashBase64), ExpiryTimeSecs: 100},
+	}))
+	require.NoError(t, err)
+	require.Equal(t, &HTLCEvent{
+		Type:           HTLCLocked,
+		ContractId:     "contract1",
+		Fungible:       true,
+		AssetType:      "token1",
+		NumUnits:       50,
+		Locker:         "alice",
+		Recipient:      "bob",
+		HashBase64:     hashBase64,
+		ExpiryTimeSecs: 100,
+		TransactionId:  "tx1",
+	}, event)
+
+	_, err = DecodeHTLCEvent(&client.ChaincodeEvent{EventName: "CreateAsset"})
+	require.EqualError(t, err, "CreateAsset is not an HTLC event")
+	_, err = DecodeHTLCEvent(&client.ChaincodeEvent{EventName: "ClaimAsset", Payload: []byte("invalid")})
+	require.ErrorContains(t, err, "failed to unmarshal ClaimAsset event payload")
+}
+
+func TestHTLCWatcher(t *testing.T) {
+	source := &chaincodeEventSourceMock{events: make(chan *client.ChaincodeEvent, 10)}
+	watcher := NewHTLCWatcher(source, "simpleasset")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	htlcEvents, err := watcher.Watch(ctx)
+	require.NoError(t, err)
+
+	preimageBase64 := base64.StdEncoding.EncodeToString([]byte("secret"))
+	hashBase64 := GenerateSHA256HashInBase64Form("secret")
+	var claimedContractId string
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("true"), nil
+	}
+	claimResult := watcher.ClaimOnReveal(hashBase64, func(hashPreimageBase64 string) error {
+		require.Equal(t, preimageBase64, hashPreimageBase64)
+		claimedContractId = "counterpart"
+		return nil
+	})
+
+	// Test that a lock is tracked, and fills in the details of a claim made with an asset agreement
+	source.events <- createChaincodeEvent(t, "LockAsset", "tx1", &common.AssetContractHTLC{
+		ContractId: "contract1",
+		Agreement:  &common.AssetExchangeAgreement{AssetType: "bond01", Id: "a01", Locker: "alice", Recipient: "bob"},
+		Lock:       &common.AssetLockHTLC{HashBase64: []byte(hashBase64), ExpiryTimeSecs: 100},
+	})
+	event := <-htlcEvents
+	require.Equal(t, HTLCLocked, event.Type)
+	lock, ok := watcher.GetLock("contract1")
+	require.True(t, ok)
+	require.Equal(t, "a01", lock.AssetId)
+
+	source.events <- &client.ChaincodeEvent{EventName: "CreateAsset", TransactionID: "tx2"}
+	source.events <- createChaincodeEvent(t, "ClaimAsset", "tx3", &common.AssetContractHTLC{
+		Agreement: &common.AssetExchangeAgreement{AssetType: "bond01", Id: "a01", Locker: "alice"},
+		Claim:     &common.AssetClaimHTLC{HashPreimageBase64: []byte(preimageBase64)},
+	})
+	event = <-htlcEvents
+	require.Equal(t, HTLCClaimed, event.Type)
+	require.Equal(t, "tx3", event.TransactionId)
+	require.Equal(t, "contract1", event.ContractId)
+	require.Equal(t, "bob", event.Recipient)
+	require.Equal(t, hashBase64, event.HashBase64)
+	require.Equal(t, preimageBase64, event.HashPreimageBase64)
+	_, ok = watcher.GetLock("contract1")
+	require.False(t, ok)
+
+	// Test that the revealed preimage is used to claim the counterpart HTLC
+	select {
+	case err = <-claimResult:
+		require.NoError(t, err)
+		require.Equal(t, "counterpart", claimedContractId)
+	case <-time.After(5 * time.Second):
+		t.Fatal("counterpart HTLC was not claimed")
+	}
+
+	// Test that the preimage is forgotten once the claim succeeds
+	watcher.mutex.Lock()
+	require.Empty(t, watcher.preimages)
+	watcher.mutex.Unlock()
+
+	// Test an unlock of a lock that was not seen by the watcher
+	source.events <- createChaincodeEvent(t, "UnlockFungibleAsset", "tx4", &common.FungibleAssetContractHTLC{ContractId: "contract3"})
+	event = <-htlcEvents
+	require.Equal(t, HTLCUnlocked, event.Type)
+	require.Equal(t, "contract3", event.ContractId)
+	require.True(t, event.Fungible)
+
+	// Test that a partial claim of a fungible HTLC keeps the lock with the remaining units
+	expiryTimeSecs := uint64(time.Now().Add(time.Hour).Unix())
+	source.events <- createChaincodeEvent(t, "LockFungibleAsset", "tx6", &common.FungibleAssetContractHTLC{
+		ContractId: "contract5",
+		Agreement:  &common.FungibleAssetExchangeAgreement{AssetType: "token1", NumUnits: 10, Locker: "alice", Recipient: "bob"},
+		Lock:       &common.AssetLockHTLC{HashBase64: []byte(hashBase64), ExpiryTimeSecs: expiryTimeSecs},
+	})
+	<-htlcEvents
+	source.events <- createChaincodeEvent(t, "ClaimFungibleAssetUnits", "tx7", &common.FungibleAssetContractHTLC{
+		ContractId: "contract5",
+		Agreement:  &common.FungibleAssetExchangeAgreement{NumUnits: 4},
+		Claim:      &common.AssetClaimHTLC{HashPreimageBase64: []byte(preimageBase64)},
+	})
+	event = <-htlcEvents
+	require.Equal(t, HTLCClaimed, event.Type)
+	require.True(t, event.Fungible)
+	require.Equal(t, uint64(4), event.NumUnits)
+	require.Equal(t, uint64(6), event.RemainingUnits)
+	require.Equal(t, "bob", event.Recipient)
+	lock, ok = watcher.GetLock("contract5")
+	require.True(t, ok)
+	require.Equal(t, uint64(6), lock.NumUnits)
+
+	// Test a claim registered after the preimage was revealed, which keeps the preimage until it succeeds
+	submitTransactionMock = func() ([]byte, error) {
+		return nil, errors.New("HTLC already claimed")
+	}
+	require.ErrorContains(t, <-watcher.ClaimHTLCOnReveal(hashBase64, gatewayContractMock{}, "contract2", false), "HTLC already claimed")
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("true"), nil
+	}
+	require.NoError(t, <-watcher.ClaimHTLCOnReveal(hashBase64, gatewayContractMock{}, "contract2", true))
+	watcher.mutex.Lock()
+	require.Empty(t, watcher.preimages)
+	watcher.mutex.Unlock()
+	source.events <- createChaincodeEvent(t, "ClaimFungibleAssetUnits", "tx8", &common.FungibleAssetContractHTLC{
+		ContractId: "contract5",
+		Agreement:  &common.FungibleAssetExchangeAgreement{NumUnits: 6},
+		Claim:      &common.AssetClaimHTLC{HashPreimageBase64: []byte(preimageBase64)},
+	})
+	event = <-htlcEvents
+	require.Equal(t, uint64(6), event.NumUnits)
+	require.Equal(t, uint64(0), event.RemainingUnits)
+	_, ok = watcher.GetLock("contract5")
+	require.False(t, ok)
+
+	// Test that a preimage revealed by the claim of a lock that has expired is not kept
+	watcher.mutex.Lock()
+	require.Contains(t, watcher.preimages, hashBase64)
+	watcher.preimages[hashBase64].expiryTimeSecs = uint64(time.Now().Unix())
+	watcher.mutex.Unlock()
+	source.events <- createChaincodeEvent(t, "LockAsset", "tx9", &common.AssetContractHTLC{
+		ContractId: "contract6",
+		Agreement:  &common.AssetExchangeAgreement{AssetType: "bond01", Id: "a02", Locker: "alice", Recipient: "bob"},
+		Lock:       &common.AssetLockHTLC{HashBase64: []byte(GenerateSHA256HashInBase64Form("secret2")), ExpiryTimeSecs: 100},
+	})
+	<-htlcEvents
+	source.events <- createChaincodeEvent(t, "ClaimAsset", "tx10", &common.AssetContractHTLC{
+		ContractId: "contract6",
+		Claim:      &common.AssetClaimHTLC{HashPreimageBase64: []byte(base64.StdEncoding.EncodeToString([]byte("secret2")))},
+	})
+	<-htlcEvents
+	watcher.mutex.Lock()
+	require.Empty(t, watcher.preimages)
+	watcher.mutex.Unlock()
+
+	// Test HTLC events carried in an event envelope along with application events
+	lockEvent := createChaincodeEvent(t, "LockFungibleAsset", "", &common.FungibleAssetContractHTLC{
+		ContractId: "contract4",
+		Agreement:  &common.FungibleAssetExchangeAgreement{AssetType: "token1", NumUnits: 10},
+	})
+	envelopeBytes, err := proto.Marshal(&common.ChaincodeEventEnvelope{
+		Version: decoders.EventEnvelopeVersion,
+		Events: []*common.ChaincodeEventEntry{
+			{EventName: "TransferAsset", Payload: []byte("asset1")},
+			{EventName: lockEvent.EventName, Payload: lockEvent.Payload},
+		},
+	})
+	require.NoError(t, err)
+	source.events <- &client.ChaincodeEvent{EventName: decoders.EventEnvelopeName, TransactionID: "tx5", BlockNumber: 5, Payload: envelopeBytes}
+	event = <-htlcEvents
+	require.Equal(t, HTLCLocked, event.Type)
+	require.Equal(t, "contract4", event.ContractId)
+	require.Equal(t, "tx5", event.TransactionId)
+	require.Equal(t, uint64(5), event.BlockNumber)
+	_, err = decoders.DecodeEventEnvelope([]byte("invalid"))
+	require.ErrorContains(t, err, "failed to unmarshal event envelope")
+
+	// Test that the channel is closed when the event stream ends
+	close(source.events)
+	_, ok = <-htlcEvents
+	require.False(t, ok)
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assetmanager
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+
+	"github.com/golang/protobuf/proto"
+)
+
+/**
+ * Assets locked with a signature lock are claimed by the recipient with a signature from the arbiter designated in the
+ * lock (e.g., a neutral notary in an escrow flow), instead of a hash preimage. Once the lock expires, the locker can
+ * reclaim the assets using the same functions as for HTLCs (ReclaimAssetInHTLC, ReclaimFungibleAssetInHTLC, etc.).
+ **/
+
+// Create an asset lock structure for a signature lock
+func createSignatureLockInfoSerializedBase64(arbiterPublicKeyPEM string, expiryTimeSecs uint64) (string, error) {
+	lockInfoSignature := &common.AssetLockSignature{
+		ArbiterPublicKey: []byte(arbiterPublicKeyPEM),
+		ExpiryTimeSecs:   expiryTimeSecs,
+		TimeSpec:         common.TimeSpec_EPOCH,
+	}
+	lockInfoSignatureBytes, err := proto.Marshal(lockInfoSignature)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	lockInfo := &common.AssetLock{
+		LockMechanism: common.LockMechanism_SIGNATURE,
+		LockInfo:      lockInfoSignatureBytes,
+	}
+	lockInfoBytes, err := proto.Marshal(lockInfo)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	return base64.StdEncoding.EncodeToString(lockInfoBytes), nil
+}
+
+// Create an asset claim structure for a signature lock
+func createSignatureClaimInfoSerializedBase64(signatureBase64 string) (string, error) {
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	if err != nil {
+		return "", logThenErrorf("failed to decode signatureBase64: %s", err.Error())
+	}
+	claimInfoSignature := &common.AssetClaimSignature{
+		Signature: signature,
+	}
+	claimInfoSignatureBytes, err := proto.Marshal(claimInfoSignature)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	claimInfo := &common.AssetClaim{
+		LockMechanism: common.LockMechanism_SIGNATURE,
+		ClaimInfo:     claimInfoSignatureBytes,
+	}
+	claimInfoBytes, err := proto.Marshal(claimInfo)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	return base64.StdEncoding.EncodeToString(claimInfoBytes), nil
+}
+
+// Message signed by the arbiter to authorise the recipient to claim the assets locked under contractId
+func GenerateClaimAuthorizationMessage(contractId string, recipientECertBase64 string) []byte {
+	return []byte(contractId + ":" + recipientECertBase64)
+}
+
+/**
+ * GenerateClaimAuthorizationSignature is used by the arbiter to authorise the recipient to claim the assets locked under
+ * contractId. The arbiter key must be an ECDSA or Ed25519 key, and the signature is returned in base64 form.
+ **/
+func GenerateClaimAuthorizationSignature(arbiterKey crypto.Signer, contractId string, recipientECertBase64 string) (string, error) {
+	if arbiterKey == nil {
+		return "", logThenErrorf("arbiter key not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+	if recipientECertBase64 == "" {
+		return "", logThenErrorf("recipientECertBase64 id not supplied")
+	}
+
+	message := GenerateClaimAuthorizationMessage(contractId, recipientECertBase64)
+	var signature []byte
+	var err error
+	if _, ok := arbiterKey.Public().(ed25519.PublicKey); ok {
+		signature, err = arbiterKey.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		signature, err = arbiterKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return "", logThenErrorf("failed to sign the claim authorization: %s", err.Error())
+	}
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+func CreateSignatureLock(contract GatewayContract, assetType string, assetId string, recipientECertBase64 string,
+	arbiterPublicKeyPEM string, expiryTimeSecs uint64) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if assetType == "" {
+		return "", logThenErrorf("asset type not supplied")
+	}
+	if assetId == "" {
+		return "", logThenErrorf("asset id not supplied")
+	}
+	if recipientECertBase64 == "" {
+		return "", logThenErrorf("recipientECertBase64 id not supplied")
+	}
+	if arbiterPublicKeyPEM == "" {
+		return "", logThenErrorf("arbiterPublicKeyPEM is not supplied")
+	}
+	currentTimeSecs := uint64(time.Now().Unix())
+	if expiryTimeSecs <= currentTimeSecs {
+		return "", logThenErrorf("supplied expirty time in the past")
+	}
+
+	assetExchangeAgreementStr, err := createAssetExchangeAgreementSerializedBase64(assetType, assetId, recipientECertBase64, "")
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	lockInfoStr, err := createSignatureLockInfoSerializedBase64(arbiterPublicKeyPEM, expiryTimeSecs)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("LockAsset", assetExchangeAgreementStr, lockInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction LockAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+func CreateFungibleSignatureLock(contract GatewayContract, assetType string, numUnits uint64, recipientECertBase64 string,
+	arbiterPublicKeyPEM string, expiryTimeSecs uint64) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if assetType == "" {
+		return "", logThenErrorf("asset type not supplied")
+	}
+	if numUnits <= 0 {
+		return "", logThenErrorf("asset count must be a positive number")
+	}
+	if recipientECertBase64 == "" {
+		return "", logThenErrorf("recipientECertBase64 id not supplied")
+	}
+	if arbiterPublicKeyPEM == "" {
+		return "", logThenErrorf("arbiterPublicKeyPEM is not supplied")
+	}
+	currentTimeSecs := uint64(time.Now().Unix())
+	if expiryTimeSecs <= currentTimeSecs {
+		return "", logThenErrorf("supplied expirty time in the past")
+	}
+
+	assetExchangeAgreementStr, err := createFungibleAssetExchangeAgreementSerializedBase64(assetType, numUnits, recipientECertBase64, "")
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+	lockInfoStr, err := createSignatureLockInfoSerializedBase64(arbiterPublicKeyPEM, expiryTimeSecs)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("LockFungibleAsset", assetExchangeAgreementStr, lockInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction LockFungibleAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+func ClaimAssetInSignatureLock(contract GatewayContract, assetType string, assetId string, lockerECertBase64 string, signatureBase64 string) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if assetType == "" {
+		return "", logThenErrorf("asset type not supplied")
+	}
+	if assetId == "" {
+		return "", logThenErrorf("asset id not supplied")
+	}
+	if lockerECertBase64 == "" {
+		return "", logThenErrorf("lockerECertBase64 id not supplied")
+	}
+	if signatureBase64 == "" {
+		return "", logThenErrorf("signatureBase64 is not supplied")
+	}
+
+	claimInfoStr, err := createSignatureClaimInfoSerializedBase64(signatureBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	assetExchangeAgreementStr, err := createAssetExchangeAgreementSerializedBase64(assetType, assetId, "", lockerECertBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("ClaimAsset", assetExchangeAgreementStr, claimInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction ClaimAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+func ClaimFungibleAssetInSignatureLock(contract GatewayContract, contractId string, signatureBase64 string) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+	if signatureBase64 == "" {
+		return "", logThenErrorf("signatureBase64 is not supplied")
+	}
+
+	claimInfoStr, err := createSignatureClaimInfoSerializedBase64(signatureBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("ClaimFungibleAsset", contractId, claimInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction ClaimFungibleAsset: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+// Claim numUnits of the units locked in a fungible signature lock, leaving the rest locked; returns the number of units that remain locked
+func ClaimFungibleAssetUnitsInSignatureLock(contract GatewayContract, contractId string, numUnits uint64, signatureBase64 string) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+	if numUnits <= 0 {
+		return "", logThenErrorf("asset count must be a positive number")
+	}
+	if signatureBase64 == "" {
+		return "", logThenErrorf("signatureBase64 is not supplied")
+	}
+
+	claimInfoStr, err := createSignatureClaimInfoSerializedBase64(signatureBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("ClaimFungibleAssetUnits", contractId, strconv.FormatUint(numUnits, 10), claimInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction ClaimFungibleAssetUnits: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
+
+func ClaimAssetInSignatureLockUsingContractId(contract GatewayContract, contractId string, signatureBase64 string) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if contractId == "" {
+		return "", logThenErrorf("contractId not supplied")
+	}
+	if signatureBase64 == "" {
+		return "", logThenErrorf("signatureBase64 is not supplied")
+	}
+
+	claimInfoStr, err := createSignatureClaimInfoSerializedBase64(signatureBase64)
+	if err != nil {
+		return "", logThenErrorf(err.Error())
+	}
+
+	// Normal invoke function
+	result, err := contract.SubmitTransaction("ClaimAssetUsingContractId", contractId, claimInfoStr)
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction ClaimAssetUsingContractId: %+v", err.Error())
+	}
+
+	return string(result), nil
+}
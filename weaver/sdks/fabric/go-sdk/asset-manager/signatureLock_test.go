@@ -0,0 +1,119 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assetmanager
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateClaimAuthorizationSignature(t *testing.T) {
+	contractId := "contract-id"
+	recipientECertBase64 := "recipientECertBase64"
+	message := GenerateClaimAuthorizationMessage(contractId, recipientECertBase64)
+
+	_, err := GenerateClaimAuthorizationSignature(nil, contractId, recipientECertBase64)
+	require.EqualError(t, err, "arbiter key not supplied")
+
+	// Test signing with an ECDSA arbiter key
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = GenerateClaimAuthorizationSignature(ecdsaKey, "", recipientECertBase64)
+	require.EqualError(t, err, "contractId not supplied")
+	signatureBase64, err := GenerateClaimAuthorizationSignature(ecdsaKey, contractId, recipientECertBase64)
+	require.NoError(t, err)
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	require.NoError(t, err)
+	digest := sha256.Sum256(message)
+	require.True(t, ecdsa.VerifyASN1(&ecdsaKey.PublicKey, digest[:], signature))
+
+	// Test signing with an Ed25519 arbiter key
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signatureBase64, err = GenerateClaimAuthorizationSignature(edPrivateKey, contractId, recipientECertBase64)
+	require.NoError(t, err)
+	signature, err = base64.StdEncoding.DecodeString(signatureBase64)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(edPublicKey, message, signature))
+}
+
+func TestCreateFungibleSignatureLock(t *testing.T) {
+	contract := gatewayContractMock{}
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("contract-id"), nil
+	}
+
+	assetType := "asset-type"
+	numUnits := uint64(10)
+	recipientECertBase64 := "recipientECertBase64"
+	arbiterPublicKeyPEM := "arbiterPublicKeyPEM"
+	expiryTimeSecs := uint64(time.Now().Unix()) + 300
+
+	_, err := CreateFungibleSignatureLock(nil, assetType, numUnits, recipientECertBase64, arbiterPublicKeyPEM, expiryTimeSecs)
+	require.EqualError(t, err, "contract handle not supplied")
+
+	_, err = CreateFungibleSignatureLock(contract, assetType, numUnits, recipientECertBase64, "", expiryTimeSecs)
+	require.EqualError(t, err, "arbiterPublicKeyPEM is not supplied")
+
+	_, err = CreateFungibleSignatureLock(contract, assetType, numUnits, recipientECertBase64, arbiterPublicKeyPEM, uint64(time.Now().Unix())-10)
+	require.EqualError(t, err, "supplied expirty time in the past")
+
+	result, err := CreateFungibleSignatureLock(contract, assetType, numUnits, recipientECertBase64, arbiterPublicKeyPEM, expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", result)
+
+	result, err = CreateSignatureLock(contract, assetType, "asset-id", recipientECertBase64, arbiterPublicKeyPEM, expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "contract-id", result)
+}
+
+func TestClaimFungibleAssetInSignatureLock(t *testing.T) {
+	contract := gatewayContractMock{}
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("true"), nil
+	}
+	signatureBase64 := base64.StdEncoding.EncodeToString([]byte("signature"))
+
+	_, err := ClaimFungibleAssetInSignatureLock(contract, "", signatureBase64)
+	require.EqualError(t, err, "contractId not supplied")
+
+	_, err = ClaimFungibleAssetInSignatureLock(contract, "contract-id", "")
+	require.EqualError(t, err, "signatureBase64 is not supplied")
+
+	_, err = ClaimFungibleAssetInSignatureLock(contract, "contract-id", "invalid base64")
+	require.ErrorContains(t, err, "failed to decode signatureBase64")
+
+	result, err := ClaimFungibleAssetInSignatureLock(contract, "contract-id", signatureBase64)
+	require.NoError(t, err)
+	require.Equal(t, "true", result)
+
+	result, err = ClaimAssetInSignatureLock(contract, "asset-type", "asset-id", "lockerECertBase64", signatureBase64)
+	require.NoError(t, err)
+	require.Equal(t, "true", result)
+
+	result, err = ClaimAssetInSignatureLockUsingContractId(contract, "contract-id", signatureBase64)
+	require.NoError(t, err)
+	require.Equal(t, "true", result)
+
+	_, err = ClaimFungibleAssetUnitsInSignatureLock(contract, "contract-id", 0, signatureBase64)
+	require.EqualError(t, err, "asset count must be a positive number")
+
+	submitTransactionMock = func() ([]byte, error) {
+		return []byte("6"), nil
+	}
+	result, err = ClaimFungibleAssetUnitsInSignatureLock(contract, "contract-id", 4, signatureBase64)
+	require.NoError(t, err)
+	require.Equal(t, "6", result)
+}
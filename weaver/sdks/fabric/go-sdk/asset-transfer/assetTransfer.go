@@ -0,0 +1,327 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package assettransfer provides helper functions to transfer assets across networks by pledging them in the
+// source network, claiming them in the destination network with a proof of the pledge, and reclaiming them in the
+// source network with a proof that they were not claimed before the pledge expired.
+package assettransfer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/helpers"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/interoperablehelper"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
+	log "github.com/sirupsen/logrus"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+type GatewayContract interface {
+	SubmitTransaction(string, ...string) ([]byte, error)
+	EvaluateTransaction(string, ...string) ([]byte, error)
+}
+
+// helper functions to log and return errors
+func logThenErrorf(format string, args ...interface{}) error {
+	errorMsg := fmt.Sprintf(format, args...)
+	log.Error(errorMsg)
+	return errors.New(errorMsg)
+}
+
+// RemoteContract identifies the asset chaincode of a remote network, reachable through that network's relay
+type RemoteContract struct {
+	// Endpoint of the remote network's relay
+	RelayEndPoint string
+	NetworkId     string
+	ChannelId     string
+	ChaincodeId   string
+}
+
+// InteropConfig holds the parameters to fetch views from a remote network and submit them to the local network
+type InteropConfig struct {
+	// Interop chaincode of the local network, which verifies the views and invokes the asset chaincode
+	InteropContract GatewayContract
+	// ID of the local network
+	NetworkId string
+	// Channel and ID of the asset chaincode in the local network
+	ChannelId   string
+	ChaincodeId string
+	// MSP ID of the requestor's organization
+	Org                string
+	LocalRelayEndpoint string
+	Signer             interoperablehelper.Signer
+	// Certificate of the requestor (PEM)
+	CertUser string
+	Options  interoperablehelper.InteropFlowOptions
+}
+
+// PledgeNotExpiredError is returned when an asset is reclaimed before its pledge has expired
+type PledgeNotExpiredError struct {
+	PledgeId       string
+	ExpiryTimeSecs uint64
+}
+
+func (e *PledgeNotExpiredError) Error() string {
+	return fmt.Sprintf("pledge %s has not expired yet (expiry time %d)", e.PledgeId, e.ExpiryTimeSecs)
+}
+
+// Create the address of a view of a remote chaincode function, with all the function arguments. Arguments such as
+// base64 encoded ECerts may contain characters that are not allowed in a plain view address, so they are escaped.
+func createViewAddress(remoteContract RemoteContract, ccFunc string, ccArgs ...string) string {
+	return helpers.CreateFabricViewAddress(remoteContract.RelayEndPoint, remoteContract.NetworkId, remoteContract.ChannelId,
+		remoteContract.ChaincodeId, ccFunc, ccArgs)
+}
+
+func validateInteropConfig(config InteropConfig) error {
+	if config.InteropContract == nil {
+		return logThenErrorf("interop contract handle not supplied")
+	}
+	if config.NetworkId == "" {
+		return logThenErrorf("local network id not supplied")
+	}
+	if config.ChannelId == "" || config.ChaincodeId == "" {
+		return logThenErrorf("local asset chaincode not supplied")
+	}
+	if config.Signer == nil {
+		return logThenErrorf("signer not supplied")
+	}
+	return nil
+}
+
+// Fetch the view at viewAddress and submit it to the local asset chaincode function as the last of its arguments
+func invokeWithRemoteView(ctx context.Context, config InteropConfig, viewAddress string, ccFunc string, ccArgs []string) ([]byte, error) {
+	invokeObject := types.Query{
+		ContractName: config.ChaincodeId,
+		Channel:      config.ChannelId,
+		CcFunc:       ccFunc,
+		CcArgs:       append(ccArgs, ""),
+	}
+	_, result, err := interoperablehelper.InteropFlowContext(ctx, config.InteropContract, config.NetworkId, invokeObject, config.Org,
+		config.LocalRelayEndpoint, []int{len(ccArgs)}, []types.InteropJSON{{Address: viewAddress}}, config.Signer, config.CertUser, config.Options)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func pledgeAsset(contract GatewayContract, ccFunc, assetType, assetIdOrQuantity, remoteNetworkId, recipientECertBase64 string,
+	expiryTimeSecs uint64) (string, error) {
+	if remoteNetworkId == "" {
+		return "", logThenErrorf("remote network id not supplied")
+	}
+	if recipientECertBase64 == "" {
+		return "", logThenErrorf("recipientECertBase64 not supplied")
+	}
+	if expiryTimeSecs <= uint64(time.Now().Unix()) {
+		return "", logThenErrorf("supplied expiry time in the past")
+	}
+
+	result, err := contract.SubmitTransaction(ccFunc, assetType, assetIdOrQuantity, remoteNetworkId, recipientECertBase64,
+		strconv.FormatUint(expiryTimeSecs, 10))
+	if err != nil {
+		return "", logThenErrorf("error in contract.SubmitTransaction %s: %+v", ccFunc, err.Error())
+	}
+
+	return string(result), nil
+}
+
+/**
+ * PledgeAsset pledges an asset in the local (source) network for transfer to the recipient in the remote network,
+ * until expiryTimeSecs (epoch seconds). Returns the ID of the pledge.
+ **/
+func PledgeAsset(contract GatewayContract, assetType, assetId, remoteNetworkId, recipientECertBase64 string,
+	expiryTimeSecs uint64) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if assetType == "" {
+		return "", logThenErrorf("asset type not supplied")
+	}
+	if assetId == "" {
+		return "", logThenErrorf("asset id not supplied")
+	}
+	return pledgeAsset(contract, "PledgeAsset", assetType, assetId, remoteNetworkId, recipientECertBase64, expiryTimeSecs)
+}
+
+// PledgeFungibleAsset pledges numUnits units of a fungible asset, like PledgeAsset
+func PledgeFungibleAsset(contract GatewayContract, assetType string, numUnits uint64, remoteNetworkId, recipientECertBase64 string,
+	expiryTimeSecs uint64) (string, error) {
+	if contract == nil {
+		return "", logThenErrorf("contract handle not supplied")
+	}
+	if assetType == "" {
+		return "", logThenErrorf("asset type not supplied")
+	}
+	if numUnits <= 0 {
+		return "", logThenErrorf("asset count must be a positive number")
+	}
+	return pledgeAsset(contract, "PledgeTokenAsset", assetType, strconv.FormatUint(numUnits, 10), remoteNetworkId, recipientECertBase64, expiryTimeSecs)
+}
+
+func getAssetPledgeDetails(contract GatewayContract, ccFunc, pledgeId string) (*common.AssetPledge, error) {
+	if contract == nil {
+		return nil, logThenErrorf("contract handle not supplied")
+	}
+	if pledgeId == "" {
+		return nil, logThenErrorf("pledge id not supplied")
+	}
+
+	result, err := contract.EvaluateTransaction(ccFunc, pledgeId)
+	if err != nil {
+		return nil, logThenErrorf("error in contract.EvaluateTransaction %s: %+v", ccFunc, err.Error())
+	}
+	if len(result) == 0 {
+		return nil, logThenErrorf("pledge %s not found", pledgeId)
+	}
+	pledgeBytes, err := base64.StdEncoding.DecodeString(string(result))
+	if err != nil {
+		return nil, logThenErrorf("failed to decode pledge %s: %s", pledgeId, err.Error())
+	}
+	pledge := &common.AssetPledge{}
+	err = protoV2.Unmarshal(pledgeBytes, pledge)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal pledge %s: %s", pledgeId, err.Error())
+	}
+
+	return pledge, nil
+}
+
+// GetAssetPledgeDetails returns the details of a pledge made by the caller in the local network
+func GetAssetPledgeDetails(contract GatewayContract, pledgeId string) (*common.AssetPledge, error) {
+	return getAssetPledgeDetails(contract, "GetAssetPledgeDetails", pledgeId)
+}
+
+// GetFungibleAssetPledgeDetails returns the details of a fungible asset pledge made by the caller in the local network
+func GetFungibleAssetPledgeDetails(contract GatewayContract, pledgeId string) (*common.AssetPledge, error) {
+	return getAssetPledgeDetails(contract, "GetTokenAssetPledgeDetails", pledgeId)
+}
+
+func claimRemoteAsset(ctx context.Context, config InteropConfig, sourceContract RemoteContract, statusFunc, claimFunc,
+	pledgeId, assetType, assetIdOrQuantity, pledgerECertBase64, recipientECertBase64 string) ([]byte, error) {
+	if err := validateInteropConfig(config); err != nil {
+		return nil, err
+	}
+	if pledgeId == "" {
+		return nil, logThenErrorf("pledge id not supplied")
+	}
+	if pledgerECertBase64 == "" {
+		return nil, logThenErrorf("pledgerECertBase64 not supplied")
+	}
+	if recipientECertBase64 == "" {
+		return nil, logThenErrorf("recipientECertBase64 not supplied")
+	}
+
+	viewAddress := createViewAddress(sourceContract, statusFunc, pledgeId, pledgerECertBase64, config.NetworkId, recipientECertBase64)
+	result, err := invokeWithRemoteView(ctx, config, viewAddress, claimFunc,
+		[]string{pledgeId, assetType, assetIdOrQuantity, pledgerECertBase64, sourceContract.NetworkId})
+	if err != nil {
+		return nil, logThenErrorf("failed to claim pledge %s from network %s: %s", pledgeId, sourceContract.NetworkId, err.Error())
+	}
+	log.Infof("claimed pledge %s from network %s", pledgeId, sourceContract.NetworkId)
+
+	return result, nil
+}
+
+/**
+ * ClaimRemoteAsset claims an asset pledged in the source network for the recipient (the caller) in the local network.
+ * The pledge status is fetched as a view from the source network through the relays, and submitted to the local asset
+ * chaincode along with the claim, which succeeds if the view proves that the asset is pledged by the pledger to the recipient.
+ **/
+func ClaimRemoteAsset(ctx context.Context, config InteropConfig, sourceContract RemoteContract, pledgeId, assetType, assetId,
+	pledgerECertBase64, recipientECertBase64 string) ([]byte, error) {
+	if assetType == "" {
+		return nil, logThenErrorf("asset type not supplied")
+	}
+	if assetId == "" {
+		return nil, logThenErrorf("asset id not supplied")
+	}
+	return claimRemoteAsset(ctx, config, sourceContract, "GetAssetPledgeStatus", "ClaimRemoteAsset",
+		pledgeId, assetType, assetId, pledgerECertBase64, recipientECertBase64)
+}
+
+// ClaimRemoteFungibleAsset claims numUnits units of a fungible asset pledged in the source network, like ClaimRemoteAsset
+func ClaimRemoteFungibleAsset(ctx context.Context, config InteropConfig, sourceContract RemoteContract, pledgeId, assetType string,
+	numUnits uint64, pledgerECertBase64, recipientECertBase64 string) ([]byte, error) {
+	if assetType == "" {
+		return nil, logThenErrorf("asset type not supplied")
+	}
+	if numUnits <= 0 {
+		return nil, logThenErrorf("asset count must be a positive number")
+	}
+	return claimRemoteAsset(ctx, config, sourceContract, "GetTokenAssetPledgeStatus", "ClaimRemoteTokenAsset",
+		pledgeId, assetType, strconv.FormatUint(numUnits, 10), pledgerECertBase64, recipientECertBase64)
+}
+
+func reclaimAsset(ctx context.Context, config InteropConfig, assetContract GatewayContract, destinationContract RemoteContract,
+	detailsFunc, statusFunc, reclaimFunc, pledgeId, assetType, assetIdOrQuantity, pledgerECertBase64 string) ([]byte, error) {
+	if err := validateInteropConfig(config); err != nil {
+		return nil, err
+	}
+	if pledgerECertBase64 == "" {
+		return nil, logThenErrorf("pledgerECertBase64 not supplied")
+	}
+	pledge, err := getAssetPledgeDetails(assetContract, detailsFunc, pledgeId)
+	if err != nil {
+		return nil, err
+	}
+	if pledge.RemoteNetworkID != destinationContract.NetworkId {
+		return nil, logThenErrorf("pledge %s was made for network %s, not %s", pledgeId, pledge.RemoteNetworkID, destinationContract.NetworkId)
+	}
+	// The destination network only reports the asset as unclaimed after the pledge has expired
+	if uint64(time.Now().Unix()) < pledge.ExpiryTimeSecs {
+		err = &PledgeNotExpiredError{PledgeId: pledgeId, ExpiryTimeSecs: pledge.ExpiryTimeSecs}
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	viewAddress := createViewAddress(destinationContract, statusFunc, pledgeId, assetType, assetIdOrQuantity, pledge.Recipient,
+		pledgerECertBase64, pledge.LocalNetworkID, strconv.FormatUint(pledge.ExpiryTimeSecs, 10))
+	result, err := invokeWithRemoteView(ctx, config, viewAddress, reclaimFunc, []string{pledgeId, pledge.Recipient, pledge.RemoteNetworkID})
+	if err != nil {
+		return nil, logThenErrorf("failed to reclaim pledge %s: %s", pledgeId, err.Error())
+	}
+	log.Infof("reclaimed pledge %s", pledgeId)
+
+	return result, nil
+}
+
+/**
+ * ReclaimAsset reclaims an asset pledged by the caller in the local network after the pledge has expired, if it was not
+ * claimed in the destination network. The claim status is fetched as a view from the destination network through the
+ * relays, and submitted to the local asset chaincode along with the reclaim.
+ * assetContract is the local asset chaincode, used to look up the pledge. Returns a *PledgeNotExpiredError if the pledge
+ * has not expired yet.
+ **/
+func ReclaimAsset(ctx context.Context, config InteropConfig, assetContract GatewayContract, destinationContract RemoteContract,
+	pledgeId, assetType, assetId, pledgerECertBase64 string) ([]byte, error) {
+	if assetType == "" {
+		return nil, logThenErrorf("asset type not supplied")
+	}
+	if assetId == "" {
+		return nil, logThenErrorf("asset id not supplied")
+	}
+	return reclaimAsset(ctx, config, assetContract, destinationContract, "GetAssetPledgeDetails", "GetAssetClaimStatus", "ReclaimAsset",
+		pledgeId, assetType, assetId, pledgerECertBase64)
+}
+
+// ReclaimFungibleAsset reclaims numUnits units of a fungible asset pledged by the caller, like ReclaimAsset
+func ReclaimFungibleAsset(ctx context.Context, config InteropConfig, assetContract GatewayContract, destinationContract RemoteContract,
+	pledgeId, assetType string, numUnits uint64, pledgerECertBase64 string) ([]byte, error) {
+	if assetType == "" {
+		return nil, logThenErrorf("asset type not supplied")
+	}
+	if numUnits <= 0 {
+		return nil, logThenErrorf("asset count must be a positive number")
+	}
+	return reclaimAsset(ctx, config, assetContract, destinationContract, "GetTokenAssetPledgeDetails", "GetTokenAssetClaimStatus",
+		"ReclaimTokenAsset", pledgeId, assetType, strconv.FormatUint(numUnits, 10), pledgerECertBase64)
+}
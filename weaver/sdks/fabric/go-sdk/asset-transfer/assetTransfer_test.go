@@ -0,0 +1,267 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package assettransfer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/helpers"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/interoperablehelper"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// Fabric client certificate, whose base64 encodings are used as ECerts in view addresses
+const certPEM = `-----BEGIN CERTIFICATE-----
+MIICODCCAd+gAwIBAgIQRswrkNlTQ78hmQEkwg5oIzAKBggqhkjOPQQDAjB9MQsw
+CQYDVQQGEwJVUzETMBEGA1UECBMKQ2FsaWZvcm5pYTEWMBQGA1UEBxMNU2FuIEZy
+YW5jaXNjbzEeMBwGA1UEChMVZXhwb3J0ZXJvcmcudHJhZGUuY29tMSEwHwYDVQQD
+ExhjYS5leHBvcnRlcm9yZy50cmFkZS5jb20wHhcNMTkwNzEyMTkxNTAwWhcNMjkw
+NzA5MTkxNTAwWjBxMQswCQYDVQQGEwJVUzETMBEGA1UECBMKQ2FsaWZvcm5pYTEW
+MBQGA1UEBxMNU2FuIEZyYW5jaXNjbzEPMA0GA1UECxMGY2xpZW50MSQwIgYDVQQD
+DBtVc2VyMUBleHBvcnRlcm9yZy50cmFkZS5jb20wWTATBgcqhkjOPQIBBggqhkjO
+PQMBBwNCAARqfXkH3Wi+W0IGgjE58A3IMdlcIjYRU+mYaVkz6ARC6dKLeJ35s1/p
+MvVEJk6Dpfjco9Pj5mXZTt4UCy7ajLyFo00wSzAOBgNVHQ8BAf8EBAMCB4AwDAYD
+VR0TAQH/BAIwADArBgNVHSMEJDAigCDGyWha3vKRXBUjho86VA9XIMYZFG/t1Dl4
+74JtNpnFNDAKBggqhkjOPQQDAgNHADBEAiB4rx9c+FTbVNvzSpHMntTP0dBBkp0e
+m+dhMqISFQDjzAIgJ3M+hE7/0HFQM6C9dtz5gMseN3XuQJCBRJCD6W2/M/A=
+-----END CERTIFICATE-----`
+
+// Local relay that records the requested view addresses and returns their views
+type mockRelayServer struct {
+	networks.UnimplementedNetworkServer
+	mutex     sync.Mutex
+	addresses []string
+}
+
+func (s *mockRelayServer) RequestState(ctx context.Context, query *networks.NetworkQuery) (*common.Ack, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.addresses = append(s.addresses, query.Address)
+	return &common.Ack{Status: common.Ack_OK, RequestId: query.Address}, nil
+}
+
+func (s *mockRelayServer) GetState(ctx context.Context, getStateMessage *networks.GetStateMessage) (*common.RequestState, error) {
+	return &common.RequestState{
+		RequestId: getStateMessage.RequestId,
+		Status:    common.RequestState_COMPLETED,
+		State: &common.RequestState_View{
+			View: &common.View{Meta: &common.Meta{Protocol: common.Meta_FABRIC}, Data: []byte(getStateMessage.RequestId)},
+		},
+	}, nil
+}
+
+func startMockRelay(t *testing.T) (*mockRelayServer, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	relayServer := &mockRelayServer{}
+	networks.RegisterNetworkServer(server, relayServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return relayServer, listener.Addr().String()
+}
+
+// Contract that records submitted transactions, and serves interop and asset queries
+type mockContract struct {
+	submittedFunc string
+	submittedArgs []string
+	pledge        *common.AssetPledge
+}
+
+func (c *mockContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	switch name {
+	case "GetVerificationPolicyBySecurityDomain":
+		return json.Marshal(interoperablehelper.VerificationPolicy{
+			SecurityDomain: args[0],
+			Identifiers: []interoperablehelper.Identifier{{
+				Pattern: "mychannel:simpleassettransfer:*",
+				Policy:  interoperablehelper.IdentifierAccessPolicy{Type: "Signature", Criteria: []string{"Org1MSP"}},
+			}},
+		})
+	case "VerifyView":
+		return nil, nil
+	case "GetAssetPledgeDetails", "GetTokenAssetPledgeDetails":
+		pledgeBytes, err := protoV2.Marshal(c.pledge)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(base64.StdEncoding.EncodeToString(pledgeBytes)), nil
+	}
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+func (c *mockContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	c.submittedFunc = name
+	c.submittedArgs = args
+	return []byte("pledge-id"), nil
+}
+
+type mockSigner struct{}
+
+func (s mockSigner) Sign(msg []byte) ([]byte, error) {
+	return []byte("signature"), nil
+}
+
+func createInteropConfig(interopContract GatewayContract, relayEndpoint string) InteropConfig {
+	return InteropConfig{
+		InteropContract:    interopContract,
+		NetworkId:          "network2",
+		ChannelId:          "mychannel",
+		ChaincodeId:        "simpleassettransfer",
+		Org:                "Org1MSP",
+		LocalRelayEndpoint: relayEndpoint,
+		Signer:             mockSigner{},
+		CertUser:           "cert",
+	}
+}
+
+// Returns the local chaincode function and arguments submitted through WriteExternalState
+func getInvokedFunction(t *testing.T, interopContract *mockContract) (string, []string) {
+	require.Equal(t, "WriteExternalState", interopContract.submittedFunc)
+	var ccArgs []string
+	require.NoError(t, json.Unmarshal([]byte(interopContract.submittedArgs[3]), &ccArgs))
+	return interopContract.submittedArgs[2], ccArgs
+}
+
+func TestPledgeAsset(t *testing.T) {
+	contract := &mockContract{}
+	expiryTimeSecs := uint64(time.Now().Unix()) + 600
+
+	pledgeId, err := PledgeAsset(contract, "bond01", "a01", "network2", "recipient", expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "pledge-id", pledgeId)
+	require.Equal(t, "PledgeAsset", contract.submittedFunc)
+	require.Equal(t, []string{"bond01", "a01", "network2", "recipient", fmt.Sprint(expiryTimeSecs)}, contract.submittedArgs)
+
+	_, err = PledgeFungibleAsset(contract, "token1", 50, "network2", "recipient", expiryTimeSecs)
+	require.NoError(t, err)
+	require.Equal(t, "PledgeTokenAsset", contract.submittedFunc)
+	require.Equal(t, []string{"token1", "50", "network2", "recipient", fmt.Sprint(expiryTimeSecs)}, contract.submittedArgs)
+
+	_, err = PledgeAsset(nil, "bond01", "a01", "network2", "recipient", expiryTimeSecs)
+	require.EqualError(t, err, "contract handle not supplied")
+	_, err = PledgeAsset(contract, "bond01", "", "network2", "recipient", expiryTimeSecs)
+	require.EqualError(t, err, "asset id not supplied")
+	_, err = PledgeFungibleAsset(contract, "token1", 0, "network2", "recipient", expiryTimeSecs)
+	require.EqualError(t, err, "asset count must be a positive number")
+	_, err = PledgeAsset(contract, "bond01", "a01", "network2", "recipient", uint64(time.Now().Unix())-10)
+	require.EqualError(t, err, "supplied expiry time in the past")
+}
+
+func TestClaimRemoteAsset(t *testing.T) {
+	relayServer, relayEndpoint := startMockRelay(t)
+	interopContract := &mockContract{}
+	config := createInteropConfig(interopContract, relayEndpoint)
+	sourceContract := RemoteContract{RelayEndPoint: "localhost:9080", NetworkId: "network1", ChannelId: "mychannel", ChaincodeId: "simpleassettransfer"}
+
+	result, err := ClaimRemoteAsset(context.Background(), config, sourceContract, "pledge1", "bond01", "a01", "pledger", "recipient")
+	require.NoError(t, err)
+	require.Equal(t, []byte("pledge-id"), result)
+	require.Equal(t, []string{"localhost:9080/network1/mychannel:simpleassettransfer:GetAssetPledgeStatus:pledge1:pledger:network2:recipient"},
+		relayServer.addresses)
+	ccFunc, ccArgs := getInvokedFunction(t, interopContract)
+	require.Equal(t, "ClaimRemoteAsset", ccFunc)
+	require.Equal(t, []string{"pledge1", "bond01", "a01", "pledger", "network1", ""}, ccArgs)
+	require.Equal(t, "[5]", interopContract.submittedArgs[4])
+
+	relayServer.addresses = nil
+	_, err = ClaimRemoteFungibleAsset(context.Background(), config, sourceContract, "pledge1", "token1", 50, "pledger", "recipient")
+	require.NoError(t, err)
+	require.Equal(t, []string{"localhost:9080/network1/mychannel:simpleassettransfer:GetTokenAssetPledgeStatus:pledge1:pledger:network2:recipient"},
+		relayServer.addresses)
+	ccFunc, ccArgs = getInvokedFunction(t, interopContract)
+	require.Equal(t, "ClaimRemoteTokenAsset", ccFunc)
+	require.Equal(t, []string{"pledge1", "token1", "50", "pledger", "network1", ""}, ccArgs)
+
+	_, err = ClaimRemoteAsset(context.Background(), config, sourceContract, "", "bond01", "a01", "pledger", "recipient")
+	require.EqualError(t, err, "pledge id not supplied")
+	config.Signer = nil
+	_, err = ClaimRemoteAsset(context.Background(), config, sourceContract, "pledge1", "bond01", "a01", "pledger", "recipient")
+	require.EqualError(t, err, "signer not supplied")
+}
+
+func TestCreateViewAddressWithECerts(t *testing.T) {
+	remoteContract := RemoteContract{RelayEndPoint: "localhost:9080", NetworkId: "network1", ChannelId: "mychannel", ChaincodeId: "simpleassettransfer"}
+	// ECert encoded the way the asset chaincode returns it, and the DER certificate, whose encoding contains '/'
+	pemECert := base64.StdEncoding.EncodeToString([]byte(certPEM))
+	block, _ := pem.Decode([]byte(certPEM))
+	derECert := base64.StdEncoding.EncodeToString(block.Bytes)
+	require.Contains(t, derECert, "/")
+
+	ccArgs := []string{"pledge1", derECert, "network2", pemECert}
+	address := createViewAddress(remoteContract, "GetAssetPledgeStatus", ccArgs...)
+	parsedAddress, err := helpers.ParseAddress(address)
+	require.NoError(t, err)
+	require.Equal(t, "network1", parsedAddress.NetworkSegment)
+	sections, err := helpers.ParseViewSegment(parsedAddress.ViewSegment)
+	require.NoError(t, err)
+	require.Equal(t, append([]string{"mychannel", "simpleassettransfer", "GetAssetPledgeStatus"}, ccArgs...), sections)
+
+	// Addresses whose arguments need no escaping keep the plain format
+	address = createViewAddress(remoteContract, "GetAssetPledgeStatus", "pledge1", pemECert, "network2", pemECert)
+	require.Equal(t, "localhost:9080/network1/mychannel:simpleassettransfer:GetAssetPledgeStatus:pledge1:"+pemECert+":network2:"+pemECert, address)
+}
+
+func TestReclaimAsset(t *testing.T) {
+	relayServer, relayEndpoint := startMockRelay(t)
+	interopContract := &mockContract{}
+	config := createInteropConfig(interopContract, relayEndpoint)
+	config.NetworkId = "network1"
+	destinationContract := RemoteContract{RelayEndPoint: "localhost:9083", NetworkId: "network2", ChannelId: "mychannel", ChaincodeId: "simpleassettransfer"}
+	expiryTimeSecs := uint64(time.Now().Unix()) - 10
+	assetContract := &mockContract{pledge: &common.AssetPledge{
+		LocalNetworkID:  "network1",
+		RemoteNetworkID: "network2",
+		Recipient:       "recipient",
+		ExpiryTimeSecs:  expiryTimeSecs,
+	}}
+
+	// Test success after expiry
+	_, err := ReclaimAsset(context.Background(), config, assetContract, destinationContract, "pledge1", "bond01", "a01", "pledger")
+	require.NoError(t, err)
+	require.Equal(t, []string{fmt.Sprintf("localhost:9083/network2/mychannel:simpleassettransfer:GetAssetClaimStatus:pledge1:bond01:a01:recipient:pledger:network1:%d", expiryTimeSecs)},
+		relayServer.addresses)
+	ccFunc, ccArgs := getInvokedFunction(t, interopContract)
+	require.Equal(t, "ReclaimAsset", ccFunc)
+	require.Equal(t, []string{"pledge1", "recipient", "network2", ""}, ccArgs)
+	require.Equal(t, "[3]", interopContract.submittedArgs[4])
+
+	relayServer.addresses = nil
+	_, err = ReclaimFungibleAsset(context.Background(), config, assetContract, destinationContract, "pledge1", "token1", 50, "pledger")
+	require.NoError(t, err)
+	require.Equal(t, []string{fmt.Sprintf("localhost:9083/network2/mychannel:simpleassettransfer:GetTokenAssetClaimStatus:pledge1:token1:50:recipient:pledger:network1:%d", expiryTimeSecs)},
+		relayServer.addresses)
+	ccFunc, _ = getInvokedFunction(t, interopContract)
+	require.Equal(t, "ReclaimTokenAsset", ccFunc)
+
+	// Test failure for a pledge made for another network
+	destinationContract.NetworkId = "network3"
+	_, err = ReclaimAsset(context.Background(), config, assetContract, destinationContract, "pledge1", "bond01", "a01", "pledger")
+	require.EqualError(t, err, "pledge pledge1 was made for network network2, not network3")
+
+	// Test failure before expiry, without a remote request
+	destinationContract.NetworkId = "network2"
+	assetContract.pledge.ExpiryTimeSecs = uint64(time.Now().Unix()) + 600
+	relayServer.addresses = nil
+	_, err = ReclaimAsset(context.Background(), config, assetContract, destinationContract, "pledge1", "bond01", "a01", "pledger")
+	var notExpiredErr *PledgeNotExpiredError
+	require.ErrorAs(t, err, &notExpiredErr)
+	require.Equal(t, assetContract.pledge.ExpiryTimeSecs, notExpiredErr.ExpiryTimeSecs)
+	require.Empty(t, relayServer.addresses)
+}
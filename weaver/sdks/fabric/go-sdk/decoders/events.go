@@ -0,0 +1,60 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package decoders
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+const (
+	// Name of the chaincode event carrying the events emitted in a transaction, as set by the asset management interface
+	EventEnvelopeName = "ChaincodeEventEnvelope"
+	// Latest version of the event envelope format supported by DecodeEventEnvelope
+	EventEnvelopeVersion = 1
+)
+
+// DecodeEventEnvelope decodes the payload of an event envelope, and returns the events it carries in the order they were emitted
+func DecodeEventEnvelope(payload []byte) ([]*common.ChaincodeEventEntry, error) {
+	envelope := &common.ChaincodeEventEnvelope{}
+	err := proto.Unmarshal(payload, envelope)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal event envelope: %s", err.Error())
+	}
+	if envelope.GetVersion() > EventEnvelopeVersion {
+		return nil, logThenErrorf("unsupported event envelope version %d", envelope.GetVersion())
+	}
+
+	return envelope.GetEvents(), nil
+}
+
+/**
+ * ExpandChaincodeEvent returns the events carried by an event envelope as separate chaincode events, with the block
+ * number, transaction ID and chaincode name of the envelope. Any other event is returned as is.
+ **/
+func ExpandChaincodeEvent(event *client.ChaincodeEvent) ([]*client.ChaincodeEvent, error) {
+	if event.EventName != EventEnvelopeName {
+		return []*client.ChaincodeEvent{event}, nil
+	}
+	entries, err := DecodeEventEnvelope(event.Payload)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*client.ChaincodeEvent, len(entries))
+	for i, entry := range entries {
+		events[i] = &client.ChaincodeEvent{
+			BlockNumber:   event.BlockNumber,
+			TransactionID: event.TransactionID,
+			ChaincodeName: event.ChaincodeName,
+			EventName:     entry.GetEventName(),
+			Payload:       entry.GetPayload(),
+		}
+	}
+
+	return events, nil
+}
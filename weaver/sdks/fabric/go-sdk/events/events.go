@@ -0,0 +1,242 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package events provides helper functions to subscribe to events in remote networks through the local relay.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/interoperablehelper"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/relay"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// Default interval between two fetches of received events by StreamEvents
+const DefaultEventPollInterval = 2 * time.Second
+
+// helper functions to log and return errors
+func logThenErrorf(format string, args ...interface{}) error {
+	errorMsg := fmt.Sprintf(format, args...)
+	log.Error(errorMsg)
+	return errors.New(errorMsg)
+}
+
+// Subscription is an event subscription accepted by the relay
+type Subscription struct {
+	// ID of the subscription request in the local relay
+	RequestId string
+	// Latest known state of the subscription
+	State *common.EventSubscriptionState
+}
+
+// SubscriptionError is returned when the relay reports that a subscription or unsubscription request failed
+type SubscriptionError struct {
+	RequestId string
+	Message   string
+}
+
+func (e *SubscriptionError) Error() string {
+	return fmt.Sprintf("event subscription %s failed: %s", e.RequestId, e.Message)
+}
+
+// CreateEventMatcher creates a specification of the events to subscribe to in the remote network
+func CreateEventMatcher(eventType common.EventType, eventClassId, transactionLedgerId, transactionContractId, transactionFunc string) *common.EventMatcher {
+	return &common.EventMatcher{
+		EventType:             eventType,
+		EventClassId:          eventClassId,
+		TransactionLedgerId:   transactionLedgerId,
+		TransactionContractId: transactionContractId,
+		TransactionFunc:       transactionFunc,
+	}
+}
+
+// CreateAppEventPublicationSpec creates a specification to publish received events to an application at appUrl
+func CreateAppEventPublicationSpec(appUrl string) *common.EventPublication {
+	return &common.EventPublication{
+		PublicationTarget: &common.EventPublication_AppUrl{AppUrl: appUrl},
+	}
+}
+
+/**
+ * CreateContractEventPublicationSpec creates a specification to publish received events by invoking a chaincode in the
+ * local network through the driver. The event payload replaces the argument at replaceArgIndex in ccArgs.
+ **/
+func CreateContractEventPublicationSpec(driverId, channelId, chaincodeId, ccFunc string, ccArgs []string, replaceArgIndex uint64,
+	members []string) *common.EventPublication {
+	ccArgsBytes := make([][]byte, len(ccArgs))
+	for i, ccArg := range ccArgs {
+		ccArgsBytes[i] = []byte(ccArg)
+	}
+	return &common.EventPublication{
+		PublicationTarget: &common.EventPublication_Ctx{
+			Ctx: &common.ContractTransaction{
+				DriverId:        driverId,
+				LedgerId:        channelId,
+				ContractId:      chaincodeId,
+				Func:            ccFunc,
+				Args:            ccArgsBytes,
+				ReplaceArgIndex: replaceArgIndex,
+				Members:         members,
+			},
+		},
+	}
+}
+
+/**
+ * CreateEventSubscription creates an event subscription request with a query for the address in interopJSON signed by the
+ * requestor, which is authorized by the remote network like a view request. A new request must be created for each
+ * subscription or unsubscription, as every query carries a fresh nonce.
+ **/
+func CreateEventSubscription(interopContract interoperablehelper.GatewayContract, networkId, org string, interopJSON types.InteropJSON,
+	signer interoperablehelper.Signer, certUser string, confidential bool, eventMatcher *common.EventMatcher,
+	eventPublicationSpec *common.EventPublication) (*networks.NetworkEventSubscription, error) {
+	if eventMatcher == nil {
+		return nil, logThenErrorf("event matcher must be specified")
+	}
+	if eventPublicationSpec == nil {
+		return nil, logThenErrorf("event publication spec must be specified")
+	}
+	networkQuery, err := interoperablehelper.CreateNetworkQuery(interopContract, networkId, org, interopJSON, signer, certUser, confidential)
+	if err != nil {
+		return nil, err
+	}
+	return &networks.NetworkEventSubscription{
+		EventMatcher:         eventMatcher,
+		Query:                networkQuery,
+		EventPublicationSpec: eventPublicationSpec,
+	}, nil
+}
+
+/**
+ * SubscribeRemoteEvent sends an event subscription request to the local relay and waits until the remote network
+ * confirms or rejects the subscription. onTransition, if not nil, is called with every new state of the subscription.
+ * Returns a *SubscriptionError if the subscription is rejected.
+ **/
+func SubscribeRemoteEvent(ctx context.Context, relayClient *relay.Client, eventSubscription *networks.NetworkEventSubscription,
+	onTransition func(*common.EventSubscriptionState)) (*Subscription, error) {
+	requestId, err := relayClient.SendSubscribeEventRequest(ctx, eventSubscription)
+	if err != nil {
+		return nil, logThenErrorf("event subscription relay request error: %s", err.Error())
+	}
+	state, err := relayClient.PollEventSubscriptionState(ctx, requestId, onTransition)
+	if err != nil {
+		return nil, err
+	}
+	subscription := &Subscription{RequestId: requestId, State: state}
+	switch state.GetStatus() {
+	case common.EventSubscriptionState_SUBSCRIBED, common.EventSubscriptionState_DUPLICATE_QUERY_SUBSCRIBED:
+		log.Debugf("event subscription %s successful: %v", requestId, state)
+		return subscription, nil
+	case common.EventSubscriptionState_ERROR:
+		err = &SubscriptionError{RequestId: requestId, Message: state.GetMessage()}
+	default:
+		err = &SubscriptionError{RequestId: requestId, Message: fmt.Sprintf("unexpected subscription state %s", state.GetStatus())}
+	}
+	log.Error(err.Error())
+	return subscription, err
+}
+
+/**
+ * UnsubscribeRemoteEvent requests the local relay to cancel the subscription, and waits until the remote network
+ * confirms the cancellation. eventSubscription must match the original subscription request but be newly created
+ * (see CreateEventSubscription). onTransition, if not nil, is called with every new state of the subscription.
+ **/
+func UnsubscribeRemoteEvent(ctx context.Context, relayClient *relay.Client, subscription *Subscription,
+	eventSubscription *networks.NetworkEventSubscription, onTransition func(*common.EventSubscriptionState)) error {
+	eventUnsubscription := &networks.NetworkEventUnsubscription{
+		Request:   eventSubscription,
+		RequestId: subscription.RequestId,
+	}
+	requestId, err := relayClient.SendUnsubscribeEventRequest(ctx, eventUnsubscription)
+	if err != nil {
+		return logThenErrorf("event unsubscription relay request error: %s", err.Error())
+	}
+	state, err := relayClient.PollEventSubscriptionState(ctx, requestId, onTransition)
+	if err != nil {
+		return err
+	}
+	subscription.State = state
+	if state.GetStatus() != common.EventSubscriptionState_UNSUBSCRIBED {
+		message := state.GetMessage()
+		if state.GetStatus() != common.EventSubscriptionState_ERROR {
+			message = fmt.Sprintf("unexpected subscription state %s", state.GetStatus())
+		}
+		err = &SubscriptionError{RequestId: requestId, Message: message}
+		log.Error(err.Error())
+		return err
+	}
+	log.Debugf("event unsubscription %s successful", requestId)
+	return nil
+}
+
+// GetSubscriptionState returns the current state of an event subscription from the local relay
+func GetSubscriptionState(ctx context.Context, relayClient *relay.Client, requestId string) (*common.EventSubscriptionState, error) {
+	state, err := relayClient.GetEventSubscriptionState(ctx, requestId)
+	if err != nil {
+		return nil, logThenErrorf("get event subscription state relay response error: %s", err.Error())
+	}
+	return state, nil
+}
+
+// GetReceivedEvents returns the events received for a subscription since the last call (the relay deletes fetched events)
+func GetReceivedEvents(ctx context.Context, relayClient *relay.Client, requestId string) ([]*common.EventState, error) {
+	eventStates, err := relayClient.GetEventStates(ctx, requestId)
+	if err != nil {
+		return nil, logThenErrorf("get event states relay response error: %s", err.Error())
+	}
+	return eventStates.GetStates(), nil
+}
+
+/**
+ * StreamEvents fetches the events received for a subscription every pollInterval (DefaultEventPollInterval if 0), and
+ * sends them to the returned events channel in the order they were received. Both channels are closed when the context
+ * is done or fetching fails with an error that is not transient, which is sent to the errors channel first.
+ * As the relay deletes events once they are fetched, events fetched but not yet read when the context is done are lost.
+ **/
+func StreamEvents(ctx context.Context, relayClient *relay.Client, requestId string, pollInterval time.Duration) (<-chan *common.EventState, <-chan error) {
+	if pollInterval <= 0 {
+		pollInterval = DefaultEventPollInterval
+	}
+	eventsChan := make(chan *common.EventState)
+	errsChan := make(chan error, 1)
+	go func() {
+		defer close(eventsChan)
+		defer close(errsChan)
+		for {
+			eventStates, err := relayClient.GetEventStates(ctx, requestId)
+			if err != nil && ctx.Err() == nil {
+				if !relay.IsTransientError(err) {
+					errsChan <- logThenErrorf("get event states relay response error: %s", err.Error())
+					return
+				}
+				log.Warnf("failed to get events of subscription %s, retrying: %s", requestId, err.Error())
+			}
+			for _, eventState := range eventStates.GetStates() {
+				select {
+				case eventsChan <- eventState:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			timer := time.NewTimer(pollInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return eventsChan, errsChan
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/interoperablehelper"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/relay"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// Local relay that moves subscriptions through the configured states and returns queued events
+type mockRelayServer struct {
+	networks.UnimplementedNetworkServer
+	mutex              sync.Mutex
+	subscription       *networks.NetworkEventSubscription
+	unsubscription     *networks.NetworkEventUnsubscription
+	subscriptionStates []common.EventSubscriptionState_STATUS
+	stateCalls         int
+	eventStates        [][]*common.EventState
+}
+
+func (s *mockRelayServer) SubscribeEvent(ctx context.Context, eventSubscription *networks.NetworkEventSubscription) (*common.Ack, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.subscription = eventSubscription
+	return &common.Ack{Status: common.Ack_OK, RequestId: "request1"}, nil
+}
+
+func (s *mockRelayServer) UnsubscribeEvent(ctx context.Context, eventUnsubscription *networks.NetworkEventUnsubscription) (*common.Ack, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.unsubscription = eventUnsubscription
+	return &common.Ack{Status: common.Ack_OK, RequestId: eventUnsubscription.RequestId}, nil
+}
+
+func (s *mockRelayServer) GetEventSubscriptionState(ctx context.Context, getStateMessage *networks.GetStateMessage) (*common.EventSubscriptionState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := s.stateCalls
+	if index >= len(s.subscriptionStates) {
+		index = len(s.subscriptionStates) - 1
+	}
+	s.stateCalls++
+	status := s.subscriptionStates[index]
+	message := ""
+	if status == common.EventSubscriptionState_ERROR {
+		message = "access denied"
+	}
+	return &common.EventSubscriptionState{RequestId: getStateMessage.RequestId, Status: status, Message: message}, nil
+}
+
+func (s *mockRelayServer) GetEventStates(ctx context.Context, getStateMessage *networks.GetStateMessage) (*common.EventStates, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.eventStates) == 0 {
+		return &common.EventStates{}, nil
+	}
+	eventStates := s.eventStates[0]
+	s.eventStates = s.eventStates[1:]
+	return &common.EventStates{States: eventStates}, nil
+}
+
+func startMockRelay(t *testing.T, relayServer *mockRelayServer) *relay.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	networks.RegisterNetworkServer(server, relayServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	relayClient, err := relay.NewClient(listener.Addr().String(), relay.ClientOptions{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+	require.NoError(t, err)
+	t.Cleanup(func() { relayClient.Close() })
+	return relayClient
+}
+
+type mockInteropContract struct{}
+
+func (c mockInteropContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	if name != "GetVerificationPolicyBySecurityDomain" {
+		return nil, fmt.Errorf("unexpected transaction %s", name)
+	}
+	return json.Marshal(interoperablehelper.VerificationPolicy{
+		SecurityDomain: args[0],
+		Identifiers: []interoperablehelper.Identifier{{
+			Pattern: "mychannel:simpleasset:*",
+			Policy:  interoperablehelper.IdentifierAccessPolicy{Type: "Signature", Criteria: []string{"Org1MSP"}},
+		}},
+	})
+}
+
+func (c mockInteropContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+type mockSigner struct{}
+
+func (s mockSigner) Sign(msg []byte) ([]byte, error) {
+	return []byte("signature"), nil
+}
+
+func createTestEventSubscription(t *testing.T) *networks.NetworkEventSubscription {
+	eventMatcher := CreateEventMatcher(common.EventType_ASSET_LOCK, "lock", "mychannel", "simpleasset", "LockAsset")
+	eventPublicationSpec := CreateContractEventPublicationSpec("Fabric", "mychannel", "simplestate", "Create", []string{"key", ""}, 1, []string{"Org1MSP"})
+	interopJSON := types.InteropJSON{Address: "localhost:9080/network1/mychannel:simpleasset:LockAsset:a"}
+	eventSubscription, err := CreateEventSubscription(mockInteropContract{}, "network2", "Org1MSP", interopJSON, mockSigner{}, "cert", false, eventMatcher, eventPublicationSpec)
+	require.NoError(t, err)
+	return eventSubscription
+}
+
+func TestCreateEventSubscription(t *testing.T) {
+	eventSubscription := createTestEventSubscription(t)
+	require.Equal(t, common.EventType_ASSET_LOCK, eventSubscription.EventMatcher.EventType)
+	require.Equal(t, "LockAsset", eventSubscription.EventMatcher.TransactionFunc)
+	ctx := eventSubscription.EventPublicationSpec.GetCtx()
+	require.Equal(t, [][]byte{[]byte("key"), []byte("")}, ctx.Args)
+	require.Equal(t, uint64(1), ctx.ReplaceArgIndex)
+	query := eventSubscription.Query
+	require.Equal(t, "localhost:9080/network1/mychannel:simpleasset:LockAsset:a", query.Address)
+	require.Equal(t, []string{"Org1MSP"}, query.Policy)
+	require.Equal(t, "network2", query.RequestingNetwork)
+	require.NotEmpty(t, query.Nonce)
+	require.NotEmpty(t, query.RequestorSignature)
+
+	// Every request carries a fresh nonce
+	require.NotEqual(t, query.Nonce, createTestEventSubscription(t).Query.Nonce)
+
+	require.Equal(t, "http://localhost:8080/events", CreateAppEventPublicationSpec("http://localhost:8080/events").GetAppUrl())
+
+	_, err := CreateEventSubscription(mockInteropContract{}, "network2", "Org1MSP", types.InteropJSON{}, mockSigner{}, "cert", false, nil, nil)
+	require.EqualError(t, err, "event matcher must be specified")
+}
+
+func TestSubscribeRemoteEvent(t *testing.T) {
+	relayServer := &mockRelayServer{
+		subscriptionStates: []common.EventSubscriptionState_STATUS{
+			common.EventSubscriptionState_SUBSCRIBE_PENDING_ACK,
+			common.EventSubscriptionState_SUBSCRIBE_PENDING,
+			common.EventSubscriptionState_SUBSCRIBE_PENDING,
+			common.EventSubscriptionState_SUBSCRIBED,
+		},
+	}
+	relayClient := startMockRelay(t, relayServer)
+
+	// Test success, with every state transition reported once
+	var transitions []common.EventSubscriptionState_STATUS
+	subscription, err := SubscribeRemoteEvent(context.Background(), relayClient, createTestEventSubscription(t), func(state *common.EventSubscriptionState) {
+		transitions = append(transitions, state.Status)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "request1", subscription.RequestId)
+	require.Equal(t, common.EventSubscriptionState_SUBSCRIBED, subscription.State.Status)
+	require.Equal(t, []common.EventSubscriptionState_STATUS{
+		common.EventSubscriptionState_SUBSCRIBE_PENDING_ACK,
+		common.EventSubscriptionState_SUBSCRIBE_PENDING,
+		common.EventSubscriptionState_SUBSCRIBED,
+	}, transitions)
+	require.Equal(t, "LockAsset", relayServer.subscription.EventMatcher.TransactionFunc)
+
+	// Test unsubscription
+	relayServer.stateCalls = 0
+	relayServer.subscriptionStates = []common.EventSubscriptionState_STATUS{
+		common.EventSubscriptionState_UNSUBSCRIBE_PENDING,
+		common.EventSubscriptionState_UNSUBSCRIBED,
+	}
+	err = UnsubscribeRemoteEvent(context.Background(), relayClient, subscription, createTestEventSubscription(t), nil)
+	require.NoError(t, err)
+	require.Equal(t, "request1", relayServer.unsubscription.RequestId)
+	require.Equal(t, common.EventSubscriptionState_UNSUBSCRIBED, subscription.State.Status)
+
+	// Test subscription rejected by the remote network
+	relayServer.stateCalls = 0
+	relayServer.subscriptionStates = []common.EventSubscriptionState_STATUS{common.EventSubscriptionState_ERROR}
+	_, err = SubscribeRemoteEvent(context.Background(), relayClient, createTestEventSubscription(t), nil)
+	var subscriptionErr *SubscriptionError
+	require.ErrorAs(t, err, &subscriptionErr)
+	require.EqualError(t, err, "event subscription request1 failed: access denied")
+
+	// Test timeout while the subscription is pending
+	relayServer.stateCalls = 0
+	relayServer.subscriptionStates = []common.EventSubscriptionState_STATUS{common.EventSubscriptionState_SUBSCRIBE_PENDING}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = SubscribeRemoteEvent(ctx, relayClient, createTestEventSubscription(t), nil)
+	var timeoutErr *relay.EventSubscriptionTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, common.EventSubscriptionState_SUBSCRIBE_PENDING, timeoutErr.Status)
+}
+
+func TestStreamEvents(t *testing.T) {
+	newEventState := func(eventId string) *common.EventState {
+		return &common.EventState{EventId: eventId, State: &common.RequestState{RequestId: "request1", Status: common.RequestState_EVENT_RECEIVED}}
+	}
+	relayServer := &mockRelayServer{
+		eventStates: [][]*common.EventState{
+			{newEventState("event1"), newEventState("event2")},
+			{},
+			{newEventState("event3")},
+		},
+	}
+	relayClient := startMockRelay(t, relayServer)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	eventsChan, errsChan := StreamEvents(ctx, relayClient, "request1", 10*time.Millisecond)
+	var eventIds []string
+	for eventState := range eventsChan {
+		eventIds = append(eventIds, eventState.EventId)
+		if len(eventIds) == 3 {
+			cancel()
+		}
+	}
+	require.Equal(t, []string{"event1", "event2", "event3"}, eventIds)
+	require.NoError(t, <-errsChan)
+
+	events, err := GetReceivedEvents(context.Background(), relayClient, "request1")
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
@@ -3,21 +3,25 @@ module github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2
 go 1.20
 
 require (
+	github.com/ethereum/go-ethereum v1.11.5
 	github.com/golang/protobuf v1.5.3
 	github.com/google/uuid v1.3.0
 	github.com/hyperledger/cacti/weaver/common/protos-go/v2 v2.0.0-alpha.2
+	github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2 v2.0.0-alpha.2
 	github.com/hyperledger/fabric-admin-sdk v0.0.0
 	github.com/hyperledger/fabric-gateway v1.2.1
+	github.com/hyperledger/fabric-protos-go v0.3.0
 	github.com/hyperledger/fabric-protos-go-apiv2 v0.3.1
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.8.1
+	golang.org/x/crypto v0.21.0
 	google.golang.org/grpc v1.57.1
 	google.golang.org/protobuf v1.31.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/hyperledger/fabric-protos-go v0.3.0 // indirect
+	github.com/holiman/uint256 v1.2.0 // indirect
 	github.com/miekg/pkcs11 v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.23.0 // indirect
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/viewaddress"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -25,8 +26,18 @@ type ParsedAddress struct {
 	LocationSegment string
 	NetworkSegment  string
 	ViewSegment     string
+	Version         int
 }
 
+/**
+ * Encodings of the view segment of an address (see the viewaddress package).
+ **/
+const (
+	AddressVersionPlain         = viewaddress.VersionPlain
+	AddressVersionEscaped       = viewaddress.VersionEscaped
+	AddressVersionEscapedMarker = viewaddress.EscapedMarker
+)
+
 /**
  * Parses address string into location, network and view segments.
  * @param address
@@ -38,11 +49,58 @@ func ParseAddress(address string) (*ParsedAddress, error) {
 		return addressParts, logThenErrorf("invalid address string %s", address)
 	}
 
+	version, err := viewaddress.GetVersion(addressList[2])
+	if err != nil {
+		return addressParts, logThenErrorf("%s", err.Error())
+	}
+
 	addressParts = &ParsedAddress{
 		LocationSegment: addressList[0],
 		NetworkSegment:  addressList[1],
 		ViewSegment:     addressList[2],
+		Version:         version,
 	}
 
 	return addressParts, nil
 }
+
+/**
+ * Returns the canonical form of a view segment: an escaped view segment has every section escaped again and no encoding
+ * version marker, and a plain view segment is unchanged.
+ * Verification policy patterns are matched against it.
+ * @param viewSegment
+ **/
+func CanonicalViewSegment(viewSegment string) (string, error) {
+	canonicalViewSegment, err := viewaddress.Canonical(viewSegment)
+	if err != nil {
+		return "", logThenErrorf("%s", err.Error())
+	}
+	return canonicalViewSegment, nil
+}
+
+/**
+ * Splits a view segment into its sections, unescaping the sections of an escaped view segment.
+ * @param viewSegment
+ **/
+func ParseViewSegment(viewSegment string) ([]string, error) {
+	sections, err := viewaddress.Split(viewSegment)
+	if err != nil {
+		return nil, logThenErrorf("%s", err.Error())
+	}
+	return sections, nil
+}
+
+/**
+ * Creates the address of a view of a Fabric network, with all the chaincode arguments. The view segment is escaped only
+ * if a section contains a character that needs escaping, so that the addresses of other views remain in the plain format.
+ * @param remoteURL
+ * @param networkId
+ * @param channel
+ * @param contract
+ * @param ccFunc
+ * @param ccArgs
+ **/
+func CreateFabricViewAddress(remoteURL, networkId, channel, contract, ccFunc string, ccArgs []string) string {
+	sections := append([]string{channel, contract, ccFunc}, ccArgs...)
+	return remoteURL + "/" + networkId + "/" + viewaddress.Create(sections)
+}
@@ -33,3 +33,45 @@ func TestParseAddress(t *testing.T) {
 	require.EqualError(t, err, expectedErr)
 	fmt.Printf("Test failed as expected with error: %s\n", err)
 }
+
+func TestParseEscapedAddress(t *testing.T) {
+	// The view segment of an escaped address is kept escaped, with its version
+	address := "localhost:9080/network1/@v2:mychannel:simplestate:Read:a%3Ab%2Fc"
+	retValue, err := ParseAddress(address)
+	require.NoError(t, err)
+	require.Equal(t, "@v2:mychannel:simplestate:Read:a%3Ab%2Fc", retValue.ViewSegment)
+	require.Equal(t, AddressVersionEscaped, retValue.Version)
+	canonicalViewSegment, err := CanonicalViewSegment(retValue.ViewSegment)
+	require.NoError(t, err)
+	require.Equal(t, "mychannel:simplestate:Read:a%3Ab%2Fc", canonicalViewSegment)
+	sections, err := ParseViewSegment(retValue.ViewSegment)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mychannel", "simplestate", "Read", "a:b/c"}, sections)
+
+	retValue, err = ParseAddress("localhost:9080/network1/mychannel:simplestate:Read:a")
+	require.NoError(t, err)
+	require.Equal(t, AddressVersionPlain, retValue.Version)
+
+	_, err = ParseAddress("localhost:9080/network1/@v3:mychannel:simplestate:Read:a")
+	require.EqualError(t, err, "Unsupported address encoding version: @v3")
+	_, err = ParseViewSegment("@v2:mychannel:simplestate:Read:100%")
+	require.EqualError(t, err, "View segment section 3 is not escaped correctly @v2:mychannel:simplestate:Read:100%: invalid URL escape \"%\"")
+}
+
+func TestCreateFabricViewAddress(t *testing.T) {
+	// Addresses without characters to escape keep the plain format
+	require.Equal(t, "localhost:9080/network1/mychannel:simplestate:Read:a:b",
+		CreateFabricViewAddress("localhost:9080", "network1", "mychannel", "simplestate", "Read", []string{"a", "b"}))
+	require.Equal(t, "localhost:9080/network1/mychannel:simplestate:Read",
+		CreateFabricViewAddress("localhost:9080", "network1", "mychannel", "simplestate", "Read", nil))
+
+	// Otherwise all arguments are escaped, and parse back to their original values
+	args := []string{`{"id":"a/b"}`, "https://example.com/?q=100%", "line\nbreak", ""}
+	address := CreateFabricViewAddress("localhost:9080", "network1", "mychannel", "simplestate", "Read", args)
+	require.Equal(t, "localhost:9080/network1/@v2:mychannel:simplestate:Read:{\"id\"%3A\"a%2Fb\"}:https%3A%2F%2Fexample.com%2F?q=100%25:line%0Abreak:", address)
+	retValue, err := ParseAddress(address)
+	require.NoError(t, err)
+	sections, err := ParseViewSegment(retValue.ViewSegment)
+	require.NoError(t, err)
+	require.Equal(t, append([]string{"mychannel", "simplestate", "Read"}, args...), sections)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interoperablehelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/x25519"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// Latest version of the ConfidentialPayload envelope supported by the SDK
+const confidentialPayloadVersion = 1
+
+/**
+ * Decrypts a serialized ConfidentialPayload (from a confidential view) using the private key corresponding to the
+ * certificate sent in the query, and authenticates the decrypted contents against the hash in the payload.
+ * The private key must be an *ecdsa.PrivateKey for ECIES payloads and an ed25519.PrivateKey for X25519_CHACHA20_POLY1305 payloads.
+ **/
+func DecryptConfidentialPayload(confidentialPayloadBytes []byte, privateKey crypto.PrivateKey) (*common.ConfidentialPayloadContents, error) {
+	confidentialPayload := &common.ConfidentialPayload{}
+	err := protoV2.Unmarshal(confidentialPayloadBytes, confidentialPayload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal confidential payload: %s", err.Error())
+	}
+	if confidentialPayload.GetVersion() > confidentialPayloadVersion {
+		return nil, fmt.Errorf("unsupported confidential payload version: %d", confidentialPayload.GetVersion())
+	}
+
+	var decryptedPayload []byte
+	switch confidentialPayload.GetEncryptionScheme() {
+	case common.ConfidentialPayload_ECIES:
+		ecdsaPrivateKey, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("ECIES encrypted payloads require an ECDSA private key")
+		}
+		decryptedPayload, err = ecies.ImportECDSA(ecdsaPrivateKey).Decrypt(confidentialPayload.GetEncryptedPayload(), nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt payload: %s", err.Error())
+		}
+	case common.ConfidentialPayload_X25519_CHACHA20_POLY1305:
+		ed25519PrivateKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("X25519_CHACHA20_POLY1305 encrypted payloads require an Ed25519 private key")
+		}
+		decryptedPayload, err = x25519.DecryptWithEd25519PrivateKey(confidentialPayload.GetEncryptedPayload(), ed25519PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported confidential payload encryption scheme: %s", confidentialPayload.GetEncryptionScheme())
+	}
+
+	confidentialPayloadContents := &common.ConfidentialPayloadContents{}
+	err = protoV2.Unmarshal(decryptedPayload, confidentialPayloadContents)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unmarshal confidential payload contents: %s", err.Error())
+	}
+	if confidentialPayload.GetHashType() != common.ConfidentialPayload_HMAC {
+		return nil, fmt.Errorf("unsupported confidential payload hash type: %s", confidentialPayload.GetHashType())
+	}
+	payloadHMAC := hmac.New(sha256.New, confidentialPayloadContents.GetRandom())
+	payloadHMAC.Write(confidentialPayloadContents.GetPayload())
+	if !hmac.Equal(payloadHMAC.Sum(nil), confidentialPayload.GetHash()) {
+		return nil, fmt.Errorf("decrypted payload does not match the payload hash")
+	}
+	return confidentialPayloadContents, nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interoperablehelper
+
+import (
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/relay"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
+	log "github.com/sirupsen/logrus"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// Maximum number of remote views fetched in parallel by InteropFlowContext, unless overridden in InteropFlowOptions
+const DefaultMaxConcurrentRequests = 4
+
+// InteropFlowOptions holds the optional parameters of InteropFlowContext
+type InteropFlowOptions struct {
+	// Return the views and the arguments for WriteExternalState without invoking the local chaincode
+	ReturnWithoutLocalInvocation bool
+	// Request views encrypted with the public key in the requestor's certificate
+	Confidential bool
+	// Private key used to decrypt confidential views (an *ecdsa.PrivateKey or an ed25519.PrivateKey)
+	PrivateKey crypto.PrivateKey
+	// Maximum number of views fetched in parallel (DefaultMaxConcurrentRequests if 0)
+	MaxConcurrentRequests int
+	// Keep fetching the remaining views when a fetch fails, and return all views that were fetched successfully
+	AllowPartialResults bool
+	// Client for the local relay, used instead of connecting to localRelayEndpoint without TLS (e.g., to use TLS)
+	RelayClient *relay.Client
+}
+
+// Maximum time to wait for a remote view when the context has no deadline
+const defaultRelayTimeout = 600 * time.Second
+
+// RemoteViewError is the failure to fetch and validate the view at an address
+type RemoteViewError struct {
+	Index   int
+	Address string
+	Err     error
+}
+
+func (e *RemoteViewError) Error() string {
+	return fmt.Sprintf("view %d (%s): %s", e.Index, e.Address, e.Err.Error())
+}
+
+func (e *RemoteViewError) Unwrap() error {
+	return e.Err
+}
+
+// RemoteViewErrors aggregates the failures of the views requested in an InteropFlowContext call, ordered by index
+type RemoteViewErrors []*RemoteViewError
+
+func (e RemoteViewErrors) Error() string {
+	errorMsgs := make([]string, len(e))
+	for i, viewErr := range e {
+		errorMsgs[i] = viewErr.Error()
+	}
+	return fmt.Sprintf("failed to get %d remote view(s): %s", len(e), strings.Join(errorMsgs, "; "))
+}
+
+func (e RemoteViewErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, viewErr := range e {
+		errs[i] = viewErr
+	}
+	return errs
+}
+
+// Result of fetching a single remote view
+type remoteViewResult struct {
+	view               *common.View
+	address            string
+	nonce              string
+	viewContentsBase64 []string
+}
+
+/**
+ * Context-aware variant of InteropFlow that fetches the views from remote networks concurrently, with at most
+ * options.MaxConcurrentRequests requests in flight, before submitting them along with a local chaincode invocation.
+ * Pending relay requests are abandoned when the context is cancelled or its deadline expires.
+ * Failures are reported per view address as RemoteViewErrors. By default, the first failure cancels the remaining
+ * requests. If options.AllowPartialResults is set, all requests are completed and the returned views contain the
+ * successfully fetched views (and nil for the rest) along with the errors; the local chaincode is not invoked in that case.
+ **/
+func InteropFlowContext(ctx context.Context, interopContract GatewayContract, networkId string, invokeObject types.Query,
+	org, localRelayEndpoint string, interopArgIndices []int, interopJSONs []types.InteropJSON, signer Signer, certUser string,
+	options InteropFlowOptions) ([]*common.View, []byte, error) {
+	if len(interopArgIndices) != len(interopJSONs) {
+		return nil, nil, logThenErrorf("number of argument indices %d does not match number of view addresses %d", len(interopArgIndices), len(interopJSONs))
+	}
+	if options.Confidential && options.PrivateKey == nil {
+		return nil, nil, logThenErrorf("a private key is required to decrypt confidential views")
+	}
+
+	// Step 1: Send remote requests for each view address concurrently and get views in response
+	results, err := getRemoteViews(ctx, interopContract, networkId, org, localRelayEndpoint, interopJSONs, signer, certUser, options)
+	views := make([]*common.View, len(results))
+	for i, result := range results {
+		if result != nil {
+			views[i] = result.view
+		}
+	}
+	if err != nil {
+		if options.AllowPartialResults {
+			return views, nil, err
+		}
+		return nil, nil, err
+	}
+
+	var viewsSerializedBase64 []string
+	var computedAddresses []string
+	var viewContentsBase64 [][]string
+	var nonces []string
+	for _, result := range results {
+		viewBytes, err := protoV2.Marshal(result.view)
+		if err != nil {
+			return views, nil, logThenErrorf("failed to marshal view with error: %s", err.Error())
+		}
+		computedAddresses = append(computedAddresses, result.address)
+		nonces = append(nonces, result.nonce)
+		viewsSerializedBase64 = append(viewsSerializedBase64, base64.StdEncoding.EncodeToString(viewBytes))
+		viewContentsBase64 = append(viewContentsBase64, result.viewContentsBase64)
+	}
+
+	// Return here if caller just wants the views and doesn't want to invoke a local chaincode
+	if options.ReturnWithoutLocalInvocation {
+		ccArgs, err := getCCArgsForProofVerification(invokeObject, interopArgIndices, computedAddresses, viewsSerializedBase64, viewContentsBase64, nonces)
+		if err != nil {
+			return views, nil, logThenErrorf("InteropFlow getCCArgsForProofVerification error: %s", err.Error())
+		}
+		ccArgsBytes, err := json.Marshal(ccArgs)
+		if err != nil {
+			return views, nil, logThenErrorf("InteropFlow failed Marshal with error: %s", ccArgsBytes)
+		}
+		return views, ccArgsBytes, nil
+	}
+
+	// Step 2
+	result, err := submitTransactionWithRemoteViews(interopContract, invokeObject, interopArgIndices, computedAddresses, viewsSerializedBase64, viewContentsBase64, nonces)
+	if err != nil {
+		return views, nil, logThenErrorf("InteropFlow submit transaction with remote view error: %s", err.Error())
+	}
+
+	return views, result, nil
+}
+
+/**
+ * Fetch (and decrypt if confidential) the views for all interopJSONs, with bounded parallelism.
+ * Returns results in the order of interopJSONs, with nil for the views that could not be fetched.
+ **/
+func getRemoteViews(ctx context.Context, interopContract GatewayContract, networkId, org, localRelayEndpoint string,
+	interopJSONs []types.InteropJSON, signer Signer, certUser string, options InteropFlowOptions) ([]*remoteViewResult, error) {
+	maxConcurrentRequests := options.MaxConcurrentRequests
+	if maxConcurrentRequests <= 0 {
+		maxConcurrentRequests = DefaultMaxConcurrentRequests
+	}
+	relayClient := options.RelayClient
+	if relayClient == nil {
+		var err error
+		relayClient, err = relay.NewClient(localRelayEndpoint, relay.ClientOptions{Timeout: defaultRelayTimeout})
+		if err != nil {
+			return nil, logThenErrorf("failed to create relay client: %s", err.Error())
+		}
+		defer relayClient.Close()
+	}
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*remoteViewResult, len(interopJSONs))
+	var viewErrs RemoteViewErrors
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	failedFast := false
+	semaphore := make(chan struct{}, maxConcurrentRequests)
+
+	recordError := func(index int, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		// Skip the failures caused by cancelling the outstanding requests after the first failure
+		if failedFast && ctx.Err() == nil {
+			return
+		}
+		viewErrs = append(viewErrs, &RemoteViewError{Index: index, Address: getViewAddress(interopJSONs[index]), Err: err})
+		if !options.AllowPartialResults {
+			failedFast = true
+			cancel()
+		}
+	}
+
+	for i := range interopJSONs {
+		select {
+		case semaphore <- struct{}{}:
+		case <-fetchCtx.Done():
+			recordError(i, fetchCtx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			view, address, nonce, err := getRemoteView(fetchCtx, interopContract, networkId, org, relayClient, interopJSONs[index], signer, certUser, options.Confidential)
+			if err != nil {
+				recordError(index, err)
+				return
+			}
+			viewContentsBase64 := []string{}
+			if options.Confidential {
+				// the interop chaincode decides which payload to accept, so the payloads are not required to match
+				_, viewContentsBase64, err = getViewPayloads(view, options.PrivateKey)
+				if err != nil {
+					recordError(index, fmt.Errorf("failed to decrypt remote view: %s", err.Error()))
+					return
+				}
+			}
+			results[index] = &remoteViewResult{
+				view:               view,
+				address:            address,
+				nonce:              nonce,
+				viewContentsBase64: viewContentsBase64,
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(viewErrs) > 0 {
+		sort.Slice(viewErrs, func(i, j int) bool { return viewErrs[i].Index < viewErrs[j].Index })
+		log.Errorf("InteropFlow remote view request error: %s", viewErrs.Error())
+		return results, viewErrs
+	}
+	return results, nil
+}
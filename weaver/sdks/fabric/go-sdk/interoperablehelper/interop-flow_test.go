@@ -0,0 +1,204 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interoperablehelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const slowViewArg = "slow"
+const invalidViewArg = "invalid"
+
+// Local relay that returns a view with the requested address as payload, and blocks on "slow" addresses
+type mockRelayServer struct {
+	networks.UnimplementedNetworkServer
+	mutex       sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (s *mockRelayServer) RequestState(ctx context.Context, query *networks.NetworkQuery) (*common.Ack, error) {
+	return &common.Ack{Status: common.Ack_OK, RequestId: query.Address}, nil
+}
+
+func (s *mockRelayServer) GetState(ctx context.Context, getStateMessage *networks.GetStateMessage) (*common.RequestState, error) {
+	s.mutex.Lock()
+	s.inFlight++
+	if s.inFlight > s.maxInFlight {
+		s.maxInFlight = s.inFlight
+	}
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		s.inFlight--
+		s.mutex.Unlock()
+	}()
+
+	if strings.HasSuffix(getStateMessage.RequestId, slowViewArg) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(50 * time.Millisecond)
+	return &common.RequestState{
+		RequestId: getStateMessage.RequestId,
+		Status:    common.RequestState_COMPLETED,
+		State: &common.RequestState_View{
+			View: &common.View{
+				Meta: &common.Meta{Protocol: common.Meta_FABRIC},
+				Data: []byte(getStateMessage.RequestId),
+			},
+		},
+	}, nil
+}
+
+func startMockRelay(t *testing.T) (*mockRelayServer, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	relayServer := &mockRelayServer{}
+	networks.RegisterNetworkServer(server, relayServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return relayServer, listener.Addr().String()
+}
+
+// Interop contract that accepts all views except those with "invalid" addresses
+type mockInteropContract struct {
+	mutex           sync.Mutex
+	submittedArgs   []string
+	submittedCalled int
+}
+
+func (c *mockInteropContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	switch name {
+	case "GetVerificationPolicyBySecurityDomain":
+		return json.Marshal(VerificationPolicy{
+			SecurityDomain: args[0],
+			Identifiers: []Identifier{{
+				Pattern: "mychannel:simplestate:Read:*",
+				Policy:  IdentifierAccessPolicy{Type: "Signature", Criteria: []string{"Org1MSP"}},
+			}},
+		})
+	case "VerifyView":
+		if strings.HasSuffix(args[1], invalidViewArg) {
+			return nil, fmt.Errorf("invalid view")
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+func (c *mockInteropContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.submittedCalled++
+	c.submittedArgs = args
+	return []byte("result"), nil
+}
+
+type mockSigner struct{}
+
+func (s mockSigner) Sign(msg []byte) ([]byte, error) {
+	return []byte("signature"), nil
+}
+
+func createInteropJSONs(args ...string) []types.InteropJSON {
+	var interopJSONs []types.InteropJSON
+	for _, arg := range args {
+		interopJSONs = append(interopJSONs, types.InteropJSON{
+			Address: "localhost:9080/network1/mychannel:simplestate:Read:" + arg,
+		})
+	}
+	return interopJSONs
+}
+
+func TestInteropFlowContext(t *testing.T) {
+	relayServer, relayEndpoint := startMockRelay(t)
+	invokeObject := types.Query{ContractName: "simplestate", Channel: "mychannel", CcFunc: "Create", CcArgs: []string{"a", ""}}
+
+	// Test success with views fetched concurrently and submitted in order
+	contract := &mockInteropContract{}
+	interopJSONs := createInteropJSONs("a", "b", "c", "d")
+	views, result, err := InteropFlowContext(context.Background(), contract, "network2", invokeObject, "Org1MSP", relayEndpoint,
+		[]int{1, 1, 1, 1}, interopJSONs, mockSigner{}, "cert", InteropFlowOptions{MaxConcurrentRequests: 2})
+	require.NoError(t, err)
+	require.Equal(t, []byte("result"), result)
+	require.Len(t, views, 4)
+	for i, view := range views {
+		require.Equal(t, interopJSONs[i].Address, string(view.Data))
+	}
+	relayServer.mutex.Lock()
+	require.LessOrEqual(t, relayServer.maxInFlight, 2)
+	relayServer.mutex.Unlock()
+	require.Equal(t, 1, contract.submittedCalled)
+	var viewAddresses []string
+	require.NoError(t, json.Unmarshal([]byte(contract.submittedArgs[5]), &viewAddresses))
+	require.Equal(t, []string{interopJSONs[0].Address, interopJSONs[1].Address, interopJSONs[2].Address, interopJSONs[3].Address}, viewAddresses)
+	var viewContents [][]string
+	require.NoError(t, json.Unmarshal([]byte(contract.submittedArgs[7]), &viewContents))
+	require.Equal(t, [][]string{{}, {}, {}, {}}, viewContents)
+
+	// Test failure with mismatching argument indices
+	_, _, err = InteropFlowContext(context.Background(), contract, "network2", invokeObject, "Org1MSP", relayEndpoint,
+		[]int{1}, interopJSONs, mockSigner{}, "cert", InteropFlowOptions{})
+	require.EqualError(t, err, "number of argument indices 1 does not match number of view addresses 4")
+
+	// Test partial results, with the invalid view reported by address and the local chaincode not invoked
+	contract = &mockInteropContract{}
+	interopJSONs = createInteropJSONs("a", invalidViewArg, "c")
+	views, _, err = InteropFlowContext(context.Background(), contract, "network2", invokeObject, "Org1MSP", relayEndpoint,
+		[]int{1, 1, 1}, interopJSONs, mockSigner{}, "cert", InteropFlowOptions{AllowPartialResults: true})
+	var viewErrs RemoteViewErrors
+	require.ErrorAs(t, err, &viewErrs)
+	require.Len(t, viewErrs, 1)
+	require.Equal(t, 1, viewErrs[0].Index)
+	require.Equal(t, interopJSONs[1].Address, viewErrs[0].Address)
+	require.Contains(t, viewErrs[0].Error(), "invalid view")
+	require.Len(t, views, 3)
+	require.NotNil(t, views[0])
+	require.Nil(t, views[1])
+	require.NotNil(t, views[2])
+	require.Equal(t, 0, contract.submittedCalled)
+
+	// Test failure without partial results, which returns no views
+	views, _, err = InteropFlowContext(context.Background(), contract, "network2", invokeObject, "Org1MSP", relayEndpoint,
+		[]int{1, 1, 1}, interopJSONs, mockSigner{}, "cert", InteropFlowOptions{})
+	require.ErrorAs(t, err, &viewErrs)
+	require.Len(t, viewErrs, 1)
+	require.Equal(t, 1, viewErrs[0].Index)
+	require.Nil(t, views)
+	require.Equal(t, 0, contract.submittedCalled)
+
+	// Test that the context deadline abandons pending requests
+	interopJSONs = createInteropJSONs("a", slowViewArg)
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	views, _, err = InteropFlowContext(ctx, contract, "network2", invokeObject, "Org1MSP", relayEndpoint,
+		[]int{1, 1}, interopJSONs, mockSigner{}, "cert", InteropFlowOptions{AllowPartialResults: true})
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.ErrorAs(t, err, &viewErrs)
+	require.Len(t, viewErrs, 1)
+	require.Equal(t, interopJSONs[1].Address, viewErrs[0].Address)
+	require.NotNil(t, views[0])
+	require.Nil(t, views[1])
+	require.True(t, errors.Is(ctx.Err(), context.DeadlineExceeded))
+}
@@ -7,12 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package interoperablehelper
 
 import (
+	"context"
+	"crypto"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/golang/protobuf/proto"
@@ -20,6 +23,8 @@ import (
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/corda"
 	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/fabric"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/patternmatcher"
 	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/helpers"
 	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/relay"
 	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
@@ -43,62 +48,22 @@ func logThenErrorf(format string, args ...interface{}) error {
 	return errors.New(errorMsg)
 }
 
+/**
+ * Fetches views from remote networks and submits them along with a local chaincode invocation to the interop chaincode.
+ * If confidential is set, the views are encrypted by the remote networks using the public key in certUser and decrypted
+ * using privateKey, which must be the corresponding private key (an *ecdsa.PrivateKey or an ed25519.PrivateKey).
+ * Views are fetched one at a time; use InteropFlowContext to fetch them concurrently.
+ **/
 func InteropFlow(interopContract GatewayContract, networkId string, invokeObject types.Query, org, localRelayEndpoint string,
-	interopArgIndices []int, interopJSONs []types.InteropJSON, signer Signer, certUser string, returnWithoutLocalInvocation bool, confidential bool) ([]*common.View, []byte, error) {
-	if len(interopArgIndices) != len(interopJSONs) {
-		logThenErrorf("number of argument indices %d does not match number of view addresses %d", len(interopArgIndices), len(interopJSONs))
-	}
-
-	// Step 1: Iterate through the view addresses, and send remote requests and get views in response for each
-	var views []*common.View
-	var viewsSerializedBase64 []string
-	var computedAddresses []string
-	var viewContentsBase64 []string
-
-	for i := 0; i < len(interopJSONs); i++ {
-		requestResponseView, requestResponseAddress, err := getRemoteView(interopContract, networkId, org, localRelayEndpoint, interopJSONs[i], signer, certUser)
-		if err != nil {
-			return views, nil, logThenErrorf("InteropFlow remote view request error: %s", err.Error())
-		}
-
-		viewBytes, err := protoV2.Marshal(requestResponseView)
-		if err != nil {
-			return views, nil, logThenErrorf("failed to marshal view with error: %s", err.Error())
-		}
-
-		views = append(views, requestResponseView)
-		computedAddresses = append(computedAddresses, requestResponseAddress)
-		viewsSerializedBase64 = append(viewsSerializedBase64, base64.StdEncoding.EncodeToString(viewBytes))
-
-		if confidential {
-			// TODO
-			respDataContents, _ := GetResponseDataFromView(requestResponseView)
-			viewContentsBase64 = append(viewContentsBase64, base64.StdEncoding.EncodeToString(respDataContents))
-		} else {
-			viewContentsBase64 = append(viewContentsBase64, "")
-		}
-	}
-
-	// Return here if caller just wants the views and doesn't want to invoke a local chaincode
-	if returnWithoutLocalInvocation {
-		ccArgs, err := getCCArgsForProofVerification(invokeObject, interopArgIndices, computedAddresses, viewsSerializedBase64, viewContentsBase64)
-		if err != nil {
-			return views, nil, logThenErrorf("InteropFlow getCCArgsForProofVerification error: %s", err.Error())
-		}
-		ccArgsBytes, err := json.Marshal(ccArgs)
-		if err != nil {
-			return views, nil, logThenErrorf("InteropFlow failed Marshal with error: %s", ccArgsBytes)
-		}
-		return views, ccArgsBytes, nil
-	}
-
-	// Step 2
-	result, err := submitTransactionWithRemoteViews(interopContract, invokeObject, interopArgIndices, computedAddresses, viewsSerializedBase64, viewContentsBase64)
-	if err != nil {
-		return views, nil, logThenErrorf("InteropFlow submit transaction with remote view error: %s", err.Error())
-	}
-
-	return views, result, nil
+	interopArgIndices []int, interopJSONs []types.InteropJSON, signer Signer, certUser string, returnWithoutLocalInvocation bool, confidential bool,
+	privateKey crypto.PrivateKey) ([]*common.View, []byte, error) {
+	return InteropFlowContext(context.Background(), interopContract, networkId, invokeObject, org, localRelayEndpoint,
+		interopArgIndices, interopJSONs, signer, certUser, InteropFlowOptions{
+			ReturnWithoutLocalInvocation: returnWithoutLocalInvocation,
+			Confidential:                 confidential,
+			PrivateKey:                   privateKey,
+			MaxConcurrentRequests:        1,
+		})
 }
 
 /**
@@ -106,8 +71,8 @@ func InteropFlow(interopContract GatewayContract, networkId string, invokeObject
  * - Prepare arguments and call WriteExternalState.
  **/
 func submitTransactionWithRemoteViews(interopContract GatewayContract, invokeObject types.Query,
-	interopArgIndices []int, viewAddresses []string, viewsSerializedBase64 []string, viewContentsBase64 []string) ([]byte, error) {
-	ccArgs, err := getCCArgsForProofVerification(invokeObject, interopArgIndices, viewAddresses, viewsSerializedBase64, viewContentsBase64)
+	interopArgIndices []int, viewAddresses []string, viewsSerializedBase64 []string, viewContentsBase64 [][]string, nonces []string) ([]byte, error) {
+	ccArgs, err := getCCArgsForProofVerification(invokeObject, interopArgIndices, viewAddresses, viewsSerializedBase64, viewContentsBase64, nonces)
 	if err != nil {
 		return nil, logThenErrorf("failed calling getCCArgsForProofVerification with error: %s", err.Error())
 	}
@@ -159,72 +124,37 @@ func getPolicyCriteriaForAddress(contract GatewayContract, address string) ([]st
 		return emptyCriteria, logThenErrorf("failed to unmarshal verification policy with error: %s", err.Error())
 	}
 
-	// Get policy criteria matching the requested information in the address
-	matchingIdentifier := Identifier{
-		Pattern: "",
-		Policy: IdentifierAccessPolicy{
-			Type:     "",
-			Criteria: []string{},
-		},
+	// Get policy criteria of the most specific pattern matching the requested information in the address
+	patterns := make([]string, len(verificationPolicy.Identifiers))
+	for i, identifier := range verificationPolicy.Identifiers {
+		patterns[i] = identifier.Pattern
 	}
-	for i := 0; i < len(verificationPolicy.Identifiers); i++ {
-		item := verificationPolicy.Identifiers[i]
-		if item.Pattern == parsedAddress.ViewSegment {
-			matchingIdentifier = item
-			break
-		}
-		if validPatternString(item.Pattern) && isPatternAndAddressMatch(item.Pattern, parsedAddress.ViewSegment) &&
-			(matchingIdentifier.Pattern == "") || (len(item.Pattern) > len(matchingIdentifier.Pattern)) {
-			matchingIdentifier = item
-			break
-		}
-	}
-
-	return matchingIdentifier.Policy.Criteria, nil
-}
-
-func validPatternString(pattern string) bool {
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// check if 0 or 1 stars
-	if numStars <= 1 {
-		// if 0 stars, return true, if 1 star, make sure its at the end
-		return (numStars == 0) || (strings.Index(pattern, "*")+1 == len(pattern))
+	viewSegment, err := helpers.CanonicalViewSegment(parsedAddress.ViewSegment)
+	if err != nil {
+		return emptyCriteria, err
 	}
-
-	return false
-}
-
-func isPatternAndAddressMatch(pattern string, address string) bool {
-	// make sure the pattern is valid
-	if !validPatternString(pattern) {
-		return false
+	log.Debugf("resolving verification policy: %s", patternmatcher.Explain(patterns, viewSegment))
+	bestMatch := patternmatcher.BestMatch(patterns, viewSegment)
+	if bestMatch < 0 {
+		return emptyCriteria, nil
 	}
+	matchingIdentifier := verificationPolicy.Identifiers[bestMatch]
 
-	// count number of stars in pattern
-	numStars := strings.Count(pattern, "*")
-
-	// if 0 stars, and exact match, return true
-	if numStars == 0 && pattern == address {
-		return true
+	// The organizations to request the view from are the principals of a Fabric signature policy
+	if strings.EqualFold(matchingIdentifier.Policy.Type, FabricSignaturePolicyType) && len(matchingIdentifier.Policy.Criteria) == 1 {
+		return getFabricSignaturePolicyMspIds(matchingIdentifier.Policy.Criteria[0])
 	}
-
-	// if 1 star and pattern is a substring of address, return true
-	if numStars == 1 && strings.Contains(address, pattern) {
-		return true
+	// The criteria of an expression policy are not signer IDs, but the expressions referencing them
+	if strings.EqualFold(matchingIdentifier.Policy.Type, ExpressionPolicyType) {
+		return getExpressionPolicyIds(matchingIdentifier.Policy.Criteria), nil
 	}
 
-	return false
+	return matchingIdentifier.Policy.Criteria, nil
 }
 
-/**
- * Extracts actual remote query response embedded in view structure.
- * Argument is a View protobuf ('statePb.View')
- **/
-func GetResponseDataFromView(view *common.View) ([]byte, error) {
-	var viewAddress string
-	var viewPayload []byte
+// Extract the interop payloads generated by each endorser or notary from a view
+func getInteropPayloadsFromView(view *common.View) ([]*common.InteropPayload, error) {
+	var interopPayloads []*common.InteropPayload
 	if view.Meta.Protocol == common.Meta_FABRIC {
 		var fabricViewData fabric.FabricView
 		err := protoV2.Unmarshal(view.Data, &fabricViewData)
@@ -242,54 +172,143 @@ func GetResponseDataFromView(view *common.View) ([]byte, error) {
 			if err != nil {
 				return nil, logThenErrorf("unable to unmarshal interopPayload: %s", err.Error())
 			}
-			if interopPayload.GetConfidential() {
-				// TODO Add support for confidential (encrypted) view payloads
-				return nil, logThenErrorf("Encrypted view payloads not currently supported in Weaver Go SDK")
-			}
-			if i == 0 {
-				viewAddress = interopPayload.GetAddress()
-				viewPayload = interopPayload.GetPayload()
-			} else {
-				if viewAddress != interopPayload.GetAddress() {
-					return nil, logThenErrorf("Proposal response view addresses mismatch: 0 - %s, %d - %s", viewAddress, i, interopPayload.GetAddress())
-				}
-				if bytes.Compare(viewPayload, interopPayload.GetPayload()) != 0 {
-					return nil, logThenErrorf("Proposal response payloads mismatch: 0 - %s, %d - %s", string(viewPayload), i, string(interopPayload.GetPayload()))
-				}
-			}
+			interopPayloads = append(interopPayloads, &interopPayload)
 		}
 	} else if view.Meta.Protocol == common.Meta_CORDA {
 		var cordaViewData corda.ViewData
 		err := protoV2.Unmarshal(view.Data, &cordaViewData)
 		if err != nil {
-			return nil, fmt.Errorf("cordaView unmarshal error: %s", err.Error())
+			return nil, logThenErrorf("cordaView unmarshal error: %s", err.Error())
 		}
 		for i := 0; i < len(cordaViewData.NotarizedPayloads); i++ {
 			var interopPayload common.InteropPayload
 			err = protoV2.Unmarshal(cordaViewData.NotarizedPayloads[i].Payload, &interopPayload)
 			if err != nil {
-				return nil, fmt.Errorf("unable to unmarshal interopPayload: %s", err.Error())
-			}
-			if interopPayload.GetConfidential() {
-				// TODO Add support for confidential (encrypted) view payloads
-				return nil, logThenErrorf("Encrypted view payloads not currently supported in Weaver Go SDK")
-			}
-			if i == 0 {
-				viewAddress = interopPayload.GetAddress()
-				viewPayload = interopPayload.GetPayload()
-			} else {
-				if viewAddress != interopPayload.GetAddress() {
-					return nil, logThenErrorf("Proposal response view addresses mismatch: 0 - %s, %d - %s", viewAddress, i, interopPayload.GetAddress())
-				}
-				if bytes.Compare(viewPayload, interopPayload.GetPayload()) != 0 {
-					return nil, logThenErrorf("Proposal response payloads mismatch: 0 - %s, %d - %s", string(viewPayload), i, string(interopPayload.GetPayload()))
-				}
+				return nil, logThenErrorf("unable to unmarshal interopPayload: %s", err.Error())
 			}
+			interopPayloads = append(interopPayloads, &interopPayload)
 		}
 	} else {
 		return nil, logThenErrorf("cannot extract data from view; unsupported DLT type: %+v", view.Meta.Protocol)
 	}
-	return viewPayload, nil
+	return interopPayloads, nil
+}
+
+/**
+ * Extracts actual remote query response embedded in view structure.
+ * Argument is a View protobuf ('statePb.View')
+ * Confidential (encrypted) views must be read using GetConfidentialResponseDataFromView.
+ * If the endorsers or notaries returned different payloads, the payload returned by the most of them is returned. The
+ * interop chaincode decides which payload to accept, using the verification policy, when the view is written to the ledger.
+ **/
+func GetResponseDataFromView(view *common.View) ([]byte, error) {
+	viewPayload, _, err := getResponseDataFromView(view, nil)
+	return viewPayload, err
+}
+
+/**
+ * Extracts actual remote query response embedded in view structure, decrypting it if the view is confidential.
+ * Arguments are a View protobuf ('statePb.View') and the private key corresponding to the certificate sent in the query.
+ * Also returns the decrypted contents (serialized 'ConfidentialPayloadContents' in base64 form) of each proposal response
+ * or notarization, which must be supplied along with the view to 'WriteExternalState'. These are empty if the view is not confidential.
+ * Payloads that differ across endorsers or notaries are handled as in GetResponseDataFromView.
+ **/
+func GetConfidentialResponseDataFromView(view *common.View, privateKey crypto.PrivateKey) ([]byte, []string, error) {
+	return getResponseDataFromView(view, privateKey)
+}
+
+func getResponseDataFromView(view *common.View, privateKey crypto.PrivateKey) ([]byte, []string, error) {
+	viewPayloads, viewContentsBase64, err := getViewPayloads(view, privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	viewPayload, err := selectViewPayload(viewPayloads)
+	if err != nil {
+		return nil, nil, err
+	}
+	return viewPayload, viewContentsBase64, nil
+}
+
+// getViewPayloads returns the payload of each proposal response or notarization in a view, decrypting them if the view
+// is confidential, along with the decrypted contents. The payloads are not required to match, as the interop chaincode
+// accepts the payload whose signers satisfy the verification policy.
+func getViewPayloads(view *common.View, privateKey crypto.PrivateKey) ([][]byte, []string, error) {
+	interopPayloads, err := getInteropPayloadsFromView(view)
+	if err != nil {
+		return nil, nil, err
+	}
+	viewPayloads := [][]byte{}
+	viewContentsBase64 := []string{}
+	for i, interopPayload := range interopPayloads {
+		payload := interopPayload.GetPayload()
+		if interopPayload.GetConfidential() {
+			if privateKey == nil {
+				return nil, nil, logThenErrorf("view payload is confidential; a private key is required to decrypt it")
+			}
+			confidentialPayloadContents, err := DecryptConfidentialPayload(payload, privateKey)
+			if err != nil {
+				return nil, nil, logThenErrorf("unable to decrypt view payload %d: %s", i, err.Error())
+			}
+			confidentialPayloadContentsBytes, err := protoV2.Marshal(confidentialPayloadContents)
+			if err != nil {
+				return nil, nil, logThenErrorf("failed to marshal confidential payload contents: %s", err.Error())
+			}
+			viewContentsBase64 = append(viewContentsBase64, base64.StdEncoding.EncodeToString(confidentialPayloadContentsBytes))
+			payload = confidentialPayloadContents.GetPayload()
+		}
+		if i > 0 {
+			if interopPayloads[0].GetConfidential() != interopPayload.GetConfidential() {
+				return nil, nil, logThenErrorf("Mismatching payload confidentiality flags across proposal responses")
+			}
+			if interopPayloads[0].GetAddress() != interopPayload.GetAddress() {
+				return nil, nil, logThenErrorf("Proposal response view addresses mismatch: 0 - %s, %d - %s", interopPayloads[0].GetAddress(), i, interopPayload.GetAddress())
+			}
+		}
+		viewPayloads = append(viewPayloads, payload)
+	}
+	return viewPayloads, viewContentsBase64, nil
+}
+
+// selectViewPayload returns the payload returned by the most endorsers or notaries, failing if several payloads are
+// returned by equally many of them
+func selectViewPayload(viewPayloads [][]byte) ([]byte, error) {
+	if len(viewPayloads) == 0 {
+		return nil, logThenErrorf("view contains no payloads")
+	}
+	// Group the payloads by content, in the order in which they first appear in the view
+	var payloadGroups [][]byte
+	var payloadGroupIndices [][]int
+	for i, viewPayload := range viewPayloads {
+		j := 0
+		for j < len(payloadGroups) && !bytes.Equal(payloadGroups[j], viewPayload) {
+			j++
+		}
+		if j == len(payloadGroups) {
+			payloadGroups = append(payloadGroups, viewPayload)
+			payloadGroupIndices = append(payloadGroupIndices, []int{})
+		}
+		payloadGroupIndices[j] = append(payloadGroupIndices[j], i)
+	}
+	if len(payloadGroups) == 1 {
+		return payloadGroups[0], nil
+	}
+
+	selectedGroup := 0
+	tied := false
+	for j := 1; j < len(payloadGroups); j++ {
+		if len(payloadGroupIndices[j]) > len(payloadGroupIndices[selectedGroup]) {
+			selectedGroup = j
+			tied = false
+		} else if len(payloadGroupIndices[j]) == len(payloadGroupIndices[selectedGroup]) {
+			tied = true
+		}
+	}
+	if tied {
+		return nil, logThenErrorf("Proposal response payloads mismatch: no payload is returned by more proposal responses than the others: %v", payloadGroupIndices)
+	}
+	log.Warnf("Proposal response payloads mismatch: returning the payload of proposal responses %v, grouped by payload as %v",
+		payloadGroupIndices[selectedGroup], payloadGroupIndices)
+	return payloadGroups[selectedGroup], nil
 }
 
 func verifyView(contract GatewayContract, b64ViewProto string, address string) error {
@@ -304,7 +323,7 @@ func verifyView(contract GatewayContract, b64ViewProto string, address string) e
  * Prepare arguments for WriteExternalState chaincode transaction to verify a view and write data to ledger.
  **/
 func getCCArgsForProofVerification(invokeObject types.Query, interopArgIndices []int, viewAddresses []string,
-	viewsSerializedBase64 []string, viewContentsBase64 []string) ([]string, error) {
+	viewsSerializedBase64 []string, viewContentsBase64 [][]string, nonces []string) ([]string, error) {
 
 	invokeObjectCcArgsBytes, err := json.Marshal(invokeObject.CcArgs)
 	if err != nil {
@@ -331,6 +350,11 @@ func getCCArgsForProofVerification(invokeObject types.Query, interopArgIndices [
 		return nil, logThenErrorf("failed to Marshal viewContentsBase64: %s", viewContentsBase64)
 	}
 
+	noncesBytes, err := json.Marshal(nonces)
+	if err != nil {
+		return nil, logThenErrorf("failed to Marshal nonces: %s", nonces)
+	}
+
 	ccArgs := []string{
 		invokeObject.ContractName,
 		invokeObject.Channel,
@@ -339,17 +363,17 @@ func getCCArgsForProofVerification(invokeObject types.Query, interopArgIndices [
 		string(interopArgIndicesBytes),
 		string(viewAddressesBytes),
 		string(viewsSerializedBase64Bytes),
-		string(viewContentsBase64Bytes)}
+		string(viewContentsBase64Bytes),
+		string(noncesBytes)}
 
 	return ccArgs, nil
 }
 
 /**
- * Creates an address string based on a query object, networkid and remote url.
+ * Creates an address string based on a query object, networkid and remote url, with all the chaincode arguments.
  **/
 func createAddress(query types.Query, networkId, remoteURL string) string {
-	addressString := remoteURL + "/" + networkId + "/" + query.Channel + ":" + query.ContractName + ":" + query.CcFunc + ":" + query.CcArgs[0]
-	return addressString
+	return helpers.CreateFabricViewAddress(remoteURL, networkId, query.Channel, query.ContractName, query.CcFunc, query.CcArgs)
 }
 
 /**
@@ -361,6 +385,13 @@ func createFlowAddress(flow types.Flow, networkId string, remoteURL string) stri
 	return addressString
 }
 
+// generateNonce creates a query nonce prefixed with its issue time (in unix seconds), which lets the
+// interop chaincode reject stale queries
+func generateNonce() string {
+	uuidValue := uuid.New()
+	return fmt.Sprintf("%d:%s", time.Now().Unix(), base64.StdEncoding.EncodeToString([]byte(uuidValue.String())))
+}
+
 func signMessage(computedAddress string, uuidStr string, signer Signer) (string, error) {
 	message := computedAddress + uuidStr
 	signature, err := signer.Sign([]byte(message))
@@ -372,66 +403,87 @@ func signMessage(computedAddress string, uuidStr string, signer Signer) (string,
 }
 
 /**
- * Send a relay request with a view address and get a view in response
- * 1. Will get address from input, if address not there it will create the address from interopJSON
- * 2. Get policy from chaincode for supplied address.
- * 3. Call the relay Process request which will send a request to the remote network via local relay and poll for an update in the request status.
- * 4. Call the local chaincode to verify the view before trying to submit to chaincode.
+ * Returns the view address in interopJSON, or creates it from the remote query if not supplied.
  **/
-func getRemoteView(interopContract GatewayContract, networkId, org, localRelayEndPoint string, interopJSON types.InteropJSON,
-	signer Signer, certUser string) (*common.View, string, error) {
-
-	// Step 1
+func getViewAddress(interopJSON types.InteropJSON) string {
+	if interopJSON.Address != "" {
+		return interopJSON.Address
+	}
 	query := types.Query{
 		ContractName: interopJSON.ChaincodeId,
 		Channel:      interopJSON.ChannelId,
 		CcFunc:       interopJSON.ChaincodeFunc,
 		CcArgs:       interopJSON.CcArgs,
 	}
-	var computedAddress string
-	if interopJSON.Address == "" {
-		computedAddress = createAddress(query, interopJSON.NetworkId, interopJSON.RemoteEndPoint)
-	} else {
-		computedAddress = interopJSON.Address
-	}
+	return createAddress(query, interopJSON.NetworkId, interopJSON.RemoteEndPoint)
+}
+
+/**
+ * Creates a query for the remote view at the address in interopJSON (or created from it), signed by the requestor.
+ * 1. Will get address from input, if address not there it will create the address from interopJSON
+ * 2. Get policy from chaincode for supplied address.
+ * 3. Generate a nonce and sign the address and nonce.
+ * The query can be sent to the local relay in a view request or an event subscription.
+ **/
+func CreateNetworkQuery(interopContract GatewayContract, networkId, org string, interopJSON types.InteropJSON,
+	signer Signer, certUser string, confidential bool) (*networks.NetworkQuery, error) {
+
+	// Step 1
+	computedAddress := getViewAddress(interopJSON)
 
 	// Step 2
 	policyCriteria, err := getPolicyCriteriaForAddress(interopContract, computedAddress)
 	if err != nil {
-		return nil, "", logThenErrorf("InteropFlow failed to get policy criteria for address %s with error: %s", computedAddress, err.Error())
+		return nil, logThenErrorf("InteropFlow failed to get policy criteria for address %s with error: %s", computedAddress, err.Error())
 	}
 
-	//relay = new Relay(localRelayEndpoint);
-	uuidValue := uuid.New()
-	uuidStr := base64.StdEncoding.EncodeToString([]byte(uuidValue.String()))
-
 	// Step 3
-	// TODO fix types here so can return proper view
+	uuidStr := generateNonce()
 
-	log.Infof("localRelayEndPoint: %s, computedAddress: %s, policyCriteria: %s, networkId: %s, certUser: %s, uuidStr: %s, org: %s",
-		localRelayEndPoint, computedAddress, policyCriteria, networkId, certUser, uuidStr, org)
+	log.Infof("computedAddress: %s, policyCriteria: %s, networkId: %s, certUser: %s, uuidStr: %s, org: %s",
+		computedAddress, policyCriteria, networkId, certUser, uuidStr, org)
 
 	signatureBase64, err := signMessage(computedAddress, uuidStr, signer)
 	if err != nil {
-		return nil, "", logThenErrorf("failed signMessage with error: %s", err.Error())
+		return nil, logThenErrorf("failed signMessage with error: %s", err.Error())
+	}
+
+	return relay.NewNetworkQuery(computedAddress, policyCriteria, networkId, certUser, signatureBase64, uuidStr, org, confidential), nil
+}
+
+/**
+ * Send a relay request with a view address and get a view in response
+ * 1. Create a signed query for the view address using CreateNetworkQuery.
+ * 2. Call the relay Process request which will send a request to the remote network via local relay and poll for an update in the request status.
+ * 3. Call the local chaincode to verify the view before trying to submit to chaincode.
+ * Returns the view along with the address and nonce of the request, which the local chaincode checks the view against.
+ **/
+func getRemoteView(ctx context.Context, interopContract GatewayContract, networkId, org string, relayClient *relay.Client, interopJSON types.InteropJSON,
+	signer Signer, certUser string, confidential bool) (*common.View, string, string, error) {
+
+	// Step 1
+	networkQuery, err := CreateNetworkQuery(interopContract, networkId, org, interopJSON, signer, certUser, confidential)
+	if err != nil {
+		return nil, "", "", err
 	}
+	computedAddress := networkQuery.GetAddress()
 
-	relayObj := relay.NewRelay(localRelayEndPoint, 600)
-	relayResponse, err := relayObj.ProcessRequest(computedAddress, policyCriteria, networkId, certUser, signatureBase64, uuidStr, org)
+	// Step 2
+	relayResponse, err := relayClient.ProcessRequest(ctx, networkQuery)
 	if err != nil {
-		return nil, "", logThenErrorf("InteropFlow relay response error: %s", err.Error())
+		return nil, "", "", logThenErrorf("InteropFlow relay response error: %s", err.Error())
 	}
 
-	// Step 4
+	// Step 3
 	// Verify view to ensure it is valid before starting expensive WriteExternalState flow.
 
 	viewBytes, err := protoV2.Marshal(relayResponse.GetView())
 	if err != nil {
-		return nil, "", logThenErrorf("failed to marshal view with error: %s", err.Error())
+		return nil, "", "", logThenErrorf("failed to marshal view with error: %s", err.Error())
 	}
 	err = verifyView(interopContract, base64.StdEncoding.EncodeToString(viewBytes), computedAddress)
 	if err != nil {
-		return nil, "", logThenErrorf("view verification failed with error: %s", err.Error())
+		return nil, "", "", logThenErrorf("view verification failed with error: %s", err.Error())
 	}
-	return relayResponse.GetView(), computedAddress, nil
+	return relayResponse.GetView(), computedAddress, networkQuery.GetNonce(), nil
 }
@@ -7,35 +7,275 @@ SPDX-License-Identifier: Apache-2.0
 package interoperablehelper
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/fabric"
+	"github.com/hyperledger/cacti/weaver/core/network/fabric-interop-cc/libs/utils/v2/x25519"
+	"github.com/hyperledger/cacti/weaver/sdks/fabric/go-sdk/v2/types"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	fabricpeer "github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
 )
 
-func TestValidPatternString(t *testing.T) {
-
-	// Test success with the pattern passed in the correct format with just one "*" at the end
-	pattern := "abcd*"
-	retValue := validPatternString(pattern)
-	require.Equal(t, retValue, true)
-	fmt.Printf("Test success as the pattern passed correctly with just one star at the end\n")
-
-	// Test success with the pattern passed in the correct format with no starts
-	pattern = "abcd"
-	retValue = validPatternString(pattern)
-	require.Equal(t, retValue, true)
-	fmt.Printf("Test success as with the pattern passed in the correct format with no stars\n")
-
-	// Test failure with the pattern containing more then one "*" character
-	pattern = "ab*cd*"
-	retValue = validPatternString(pattern)
-	require.Equal(t, retValue, false)
-	fmt.Printf("Test failed as expected with pattern containing more than one star\n")
-
-	// Test failure with the pattern containing one "*" character NOT at the end
-	pattern = "ab*cd"
-	retValue = validPatternString(pattern)
-	require.Equal(t, retValue, false)
-	fmt.Printf("Test failed as expected with pattern containing one star but NOT at the end\n")
+func TestGenerateNonce(t *testing.T) {
+	// Test that the nonce carries its issue time as a unix seconds prefix
+	before := time.Now().Unix()
+	nonce := generateNonce()
+	parts := strings.SplitN(nonce, ":", 2)
+	require.Len(t, parts, 2)
+	issuedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, issuedAt, before)
+	require.LessOrEqual(t, issuedAt, time.Now().Unix())
+	require.NotEmpty(t, parts[1])
+
+	// Test that nonces are not repeated
+	require.NotEqual(t, nonce, generateNonce())
+	fmt.Printf("Test success as nonce %s carries its issue time\n", nonce)
+}
+
+// Encrypts the payload the way the interop chaincode does for ECIES
+func encryptConfidentialPayloadECIES(t *testing.T, payload []byte, publicKey *ecdsa.PublicKey) []byte {
+	contents := &common.ConfidentialPayloadContents{Payload: payload, Random: []byte("random")}
+	contentsBytes, err := protoV2.Marshal(contents)
+	require.NoError(t, err)
+	encryptedContents, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(publicKey), contentsBytes, nil, nil)
+	require.NoError(t, err)
+	payloadHMAC := hmac.New(sha256.New, contents.Random)
+	payloadHMAC.Write(payload)
+	confidentialPayload := &common.ConfidentialPayload{
+		EncryptedPayload: encryptedContents,
+		HashType:         common.ConfidentialPayload_HMAC,
+		Hash:             payloadHMAC.Sum(nil),
+		Version:          confidentialPayloadVersion,
+		EncryptionScheme: common.ConfidentialPayload_ECIES,
+	}
+	confidentialPayloadBytes, err := protoV2.Marshal(confidentialPayload)
+	require.NoError(t, err)
+	return confidentialPayloadBytes
+}
+
+// Builds a Fabric view with one proposal response per interop payload
+func createFabricView(t *testing.T, interopPayloads []*common.InteropPayload) *common.View {
+	fabricView := &fabric.FabricView{}
+	for _, interopPayload := range interopPayloads {
+		interopPayloadBytes, err := protoV2.Marshal(interopPayload)
+		require.NoError(t, err)
+		ccActionBytes, err := protoV2.Marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200, Payload: interopPayloadBytes}})
+		require.NoError(t, err)
+		fabricView.EndorsedProposalResponses = append(fabricView.EndorsedProposalResponses, &fabric.FabricView_EndorsedProposalResponse{
+			Payload: &fabricpeer.ProposalResponsePayload{Extension: ccActionBytes},
+		})
+	}
+	fabricViewBytes, err := protoV2.Marshal(fabricView)
+	require.NoError(t, err)
+	return &common.View{
+		Meta: &common.Meta{Protocol: common.Meta_FABRIC},
+		Data: fabricViewBytes,
+	}
+}
+
+func TestGetConfidentialResponseDataFromView(t *testing.T) {
+	address := "localhost:9080/network1/mychannel:simplestate:Read:a"
+	payload := []byte("value")
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// Test success with a view that is not confidential
+	view := createFabricView(t, []*common.InteropPayload{
+		{Address: address, Payload: payload},
+		{Address: address, Payload: payload},
+	})
+	viewPayload, err := GetResponseDataFromView(view)
+	require.NoError(t, err)
+	require.Equal(t, payload, viewPayload)
+	viewPayload, viewContents, err := GetConfidentialResponseDataFromView(view, nil)
+	require.NoError(t, err)
+	require.Equal(t, payload, viewPayload)
+	require.Empty(t, viewContents)
+
+	// Test success with a confidential view, each endorsement being encrypted separately
+	view = createFabricView(t, []*common.InteropPayload{
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey), Confidential: true},
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey), Confidential: true},
+	})
+	viewPayload, viewContents, err = GetConfidentialResponseDataFromView(view, privateKey)
+	require.NoError(t, err)
+	require.Equal(t, payload, viewPayload)
+	require.Len(t, viewContents, 2)
+	for _, viewContentsBase64 := range viewContents {
+		contentsBytes, err := base64.StdEncoding.DecodeString(viewContentsBase64)
+		require.NoError(t, err)
+		contents := &common.ConfidentialPayloadContents{}
+		require.NoError(t, protoV2.Unmarshal(contentsBytes, contents))
+		require.Equal(t, payload, contents.Payload)
+		require.Equal(t, []byte("random"), contents.Random)
+	}
+
+	// Test failure with a confidential view and no private key
+	_, err = GetResponseDataFromView(view)
+	require.EqualError(t, err, "view payload is confidential; a private key is required to decrypt it")
+
+	// Test failure with the wrong private key
+	otherPrivateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, _, err = GetConfidentialResponseDataFromView(view, otherPrivateKey)
+	require.ErrorContains(t, err, "unable to decrypt view payload 0")
+
+	// Test success with an endorsement returning a different payload, the payload of the most endorsements being returned
+	view = createFabricView(t, []*common.InteropPayload{
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, []byte("stale"), &privateKey.PublicKey), Confidential: true},
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey), Confidential: true},
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey), Confidential: true},
+	})
+	viewPayload, viewContents, err = GetConfidentialResponseDataFromView(view, privateKey)
+	require.NoError(t, err)
+	require.Equal(t, payload, viewPayload)
+	require.Len(t, viewContents, 3)
+
+	// Test failure with mismatching payloads returned by equally many endorsements
+	view = createFabricView(t, []*common.InteropPayload{
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey), Confidential: true},
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, []byte("other"), &privateKey.PublicKey), Confidential: true},
+	})
+	_, _, err = GetConfidentialResponseDataFromView(view, privateKey)
+	require.EqualError(t, err, "Proposal response payloads mismatch: no payload is returned by more proposal responses than the others: [[0] [1]]")
+
+	// The contents of all endorsements are still decrypted for the interop chaincode, which decides which payload to accept
+	viewPayloads, viewContents, err := getViewPayloads(view, privateKey)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{payload, []byte("other")}, viewPayloads)
+	require.Len(t, viewContents, 2)
+
+	// Test failure with endorsements that disagree on confidentiality
+	view = createFabricView(t, []*common.InteropPayload{
+		{Address: address, Payload: encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey), Confidential: true},
+		{Address: address, Payload: payload},
+	})
+	_, _, err = GetConfidentialResponseDataFromView(view, privateKey)
+	require.EqualError(t, err, "Mismatching payload confidentiality flags across proposal responses")
+}
+
+func TestDecryptConfidentialPayload(t *testing.T) {
+	payload := []byte("value")
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// Test success with an ECIES encrypted payload
+	confidentialPayloadBytes := encryptConfidentialPayloadECIES(t, payload, &privateKey.PublicKey)
+	contents, err := DecryptConfidentialPayload(confidentialPayloadBytes, privateKey)
+	require.NoError(t, err)
+	require.Equal(t, payload, contents.Payload)
+
+	// Test failure with a key of the wrong type
+	_, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = DecryptConfidentialPayload(confidentialPayloadBytes, edPrivateKey)
+	require.EqualError(t, err, "ECIES encrypted payloads require an ECDSA private key")
+
+	// Test failure with a tampered hash
+	confidentialPayload := &common.ConfidentialPayload{}
+	require.NoError(t, protoV2.Unmarshal(confidentialPayloadBytes, confidentialPayload))
+	confidentialPayload.Hash[0] ^= 0xff
+	tamperedPayloadBytes, err := protoV2.Marshal(confidentialPayload)
+	require.NoError(t, err)
+	_, err = DecryptConfidentialPayload(tamperedPayloadBytes, privateKey)
+	require.EqualError(t, err, "decrypted payload does not match the payload hash")
+
+	// Test failure with an unsupported version
+	confidentialPayload.Version = confidentialPayloadVersion + 1
+	unsupportedPayloadBytes, err := protoV2.Marshal(confidentialPayload)
+	require.NoError(t, err)
+	_, err = DecryptConfidentialPayload(unsupportedPayloadBytes, privateKey)
+	require.EqualError(t, err, fmt.Sprintf("unsupported confidential payload version: %d", confidentialPayloadVersion+1))
+
+	// Test success with an X25519_CHACHA20_POLY1305 encrypted payload, encrypted the way the interop chaincode does
+	contentsBytes, err := protoV2.Marshal(&common.ConfidentialPayloadContents{Payload: payload, Random: []byte("random")})
+	require.NoError(t, err)
+	encryptedPayload, err := x25519.EncryptWithEd25519PublicKey(contentsBytes, edPrivateKey.Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+	payloadHMAC := hmac.New(sha256.New, []byte("random"))
+	payloadHMAC.Write(payload)
+	confidentialPayloadBytes, err = protoV2.Marshal(&common.ConfidentialPayload{
+		EncryptedPayload: encryptedPayload,
+		HashType:         common.ConfidentialPayload_HMAC,
+		Hash:             payloadHMAC.Sum(nil),
+		Version:          confidentialPayloadVersion,
+		EncryptionScheme: common.ConfidentialPayload_X25519_CHACHA20_POLY1305,
+	})
+	require.NoError(t, err)
+	contents, err = DecryptConfidentialPayload(confidentialPayloadBytes, edPrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, payload, contents.Payload)
+	_, err = DecryptConfidentialPayload(confidentialPayloadBytes, privateKey)
+	require.EqualError(t, err, "X25519_CHACHA20_POLY1305 encrypted payloads require an Ed25519 private key")
+}
+
+// Interop contract that only returns a fixed verification policy
+type verificationPolicyContract struct {
+	verificationPolicy VerificationPolicy
+}
+
+func (c verificationPolicyContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return json.Marshal(c.verificationPolicy)
+}
+
+func (c verificationPolicyContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return nil, fmt.Errorf("unexpected transaction %s", name)
+}
+
+func TestGetPolicyCriteriaForAddress(t *testing.T) {
+	contract := verificationPolicyContract{verificationPolicy: VerificationPolicy{
+		SecurityDomain: "network1",
+		Identifiers: []Identifier{
+			{Pattern: "mychannel:*", Policy: IdentifierAccessPolicy{Type: "Signature", Criteria: []string{"Org1MSP"}}},
+			{Pattern: "mychannel:simplestate:Read:**", Policy: IdentifierAccessPolicy{Type: "Signature", Criteria: []string{"Org2MSP"}}},
+			{Pattern: "mychannel:simplestate:Read:key[0-9]", Policy: IdentifierAccessPolicy{Type: "Signature", Criteria: []string{"Org3MSP"}}},
+			{Pattern: "mychannel:simplestate:Query:*", Policy: IdentifierAccessPolicy{Type: FabricSignaturePolicyType, Criteria: []string{"AND('Org1MSP.peer', 'Org4MSP.peer')"}}},
+			{Pattern: "mychannel:simplestate:Count:*", Policy: IdentifierAccessPolicy{Type: "expression", Criteria: []string{"count >= 6"}}},
+			{Pattern: "mychannel:simplestate:Either:*", Policy: IdentifierAccessPolicy{Type: ExpressionPolicyType, Criteria: []string{"Org1MSP || (Org5MSP && Org1MSP)"}}},
+			{Pattern: "mychannel:simplestate:Both:*", Policy: IdentifierAccessPolicy{Type: ExpressionPolicyType, Criteria: []string{"Org1MSP && count >= 2"}}},
+		},
+	}}
+
+	// The criteria of the most specific pattern matching the address are returned
+	for address, criteria := range map[string][]string{
+		"localhost:9080/network1/mychannel:simplestate:Read:key1":  {"Org3MSP"},
+		"localhost:9080/network1/mychannel:simplestate:Read:a":     {"Org2MSP"},
+		"localhost:9080/network1/mychannel:simplestate:Write:a":    {"Org1MSP"},
+		"localhost:9080/network1/mychannel:simplestate:Query:a":    {"Org1MSP", "Org4MSP"},
+		"localhost:9080/network1/otherchannel:simplestate:Write:a": {},
+		// Expression policies yield the signers they reference, or none (i.e., all endorsers) with a count threshold
+		"localhost:9080/network1/mychannel:simplestate:Count:a":  {},
+		"localhost:9080/network1/mychannel:simplestate:Either:a": {"Org1MSP", "Org5MSP"},
+		"localhost:9080/network1/mychannel:simplestate:Both:a":   {},
+		// Patterns are matched against escaped view segments without their version marker
+		"localhost:9080/network1/@v2:mychannel:simplestate:Read:a%3Ab": {"Org2MSP"},
+	} {
+		policyCriteria, err := getPolicyCriteriaForAddress(contract, address)
+		require.NoError(t, err)
+		require.Equal(t, criteria, policyCriteria, address)
+	}
+}
+
+func TestCreateAddress(t *testing.T) {
+	query := types.Query{ContractName: "simplestate", Channel: "mychannel", CcFunc: "Read", CcArgs: []string{"a", "b"}}
+	require.Equal(t, "localhost:9080/network1/mychannel:simplestate:Read:a:b", createAddress(query, "network1", "localhost:9080"))
+	query.CcArgs = []string{`{"id":"a/b"}`}
+	require.Equal(t, "localhost:9080/network1/@v2:mychannel:simplestate:Read:{\"id\"%3A\"a%2Fb\"}", createAddress(query, "network1", "localhost:9080"))
 }
@@ -0,0 +1,323 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interoperablehelper
+
+import (
+	"encoding/base64"
+	"regexp"
+	"sort"
+	"strings"
+
+	fabriccommon "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// Verification policy type whose single criterion is a Fabric signature policy, either as a base64-encoded serialized
+// SignaturePolicyEnvelope or in the Fabric policy DSL (e.g., "OutOf(2, 'Org1MSP.peer', 'Org2MSP.peer')")
+const FabricSignaturePolicyType = "FabricSignaturePolicy"
+
+// Verification policy type whose criteria are policy DSL expressions (e.g., "Org1MSP && count >= 2")
+const ExpressionPolicyType = "Expression"
+
+// matches the 'MSPID.role' principals of a Fabric policy DSL expression
+var fabricPolicyDSLPrincipal = regexp.MustCompile(`['"]([^'"]+)\.[A-Za-z]+['"]`)
+
+// matches the signer identifiers and the 'count' keyword of a verification policy expression
+var policyExpressionIdentifier = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.\-]*`)
+
+/**
+ * Build a verification policy for the views of a remote Fabric network matching the given patterns, whose criteria is the
+ * endorsement policy of a chaincode definition of that network (e.g., as returned by the lifecycle QueryChaincodeDefinition
+ * function, or by chaincode.QueryCommittedWithName in the Fabric admin SDK).
+ * The endorsement policy must be an explicit signature policy. A chaincode definition whose endorsement policy refers to a
+ * channel config policy, which is the default ('/Channel/Application/Endorsement'), is rejected; use
+ * GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock to resolve the reference instead.
+ **/
+func GetVerificationPolicyFromChaincodeDefinition(securityDomain string, chaincodeDefinition *lifecycle.QueryChaincodeDefinitionResult,
+	patterns []string) (*VerificationPolicy, error) {
+	return getVerificationPolicyFromChaincodeDefinition(securityDomain, chaincodeDefinition, nil, patterns)
+}
+
+/**
+ * Build a verification policy like GetVerificationPolicyFromChaincodeDefinition, resolving an endorsement policy that refers
+ * to a channel config policy (e.g., the default '/Channel/Application/Endorsement') from a config block of the channel
+ * (e.g., as returned by membershipmanager.GetConfigBlockFromChannel).
+ * Implicit meta policies are resolved as Fabric evaluates them, into a signature policy requiring ANY, ALL or a MAJORITY of
+ * the policies of the same name in the sub-groups (i.e., organizations). The resolved policy must be updated whenever the
+ * channel's organizations or their policies change.
+ **/
+func GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock(securityDomain string, chaincodeDefinition *lifecycle.QueryChaincodeDefinitionResult,
+	configBlock *fabriccommon.Block, patterns []string) (*VerificationPolicy, error) {
+	if configBlock == nil {
+		return nil, logThenErrorf("channel config block is missing")
+	}
+	channelConfig, err := getChannelConfigFromBlock(configBlock)
+	if err != nil {
+		return nil, err
+	}
+	return getVerificationPolicyFromChaincodeDefinition(securityDomain, chaincodeDefinition, channelConfig, patterns)
+}
+
+func getVerificationPolicyFromChaincodeDefinition(securityDomain string, chaincodeDefinition *lifecycle.QueryChaincodeDefinitionResult,
+	channelConfig *fabriccommon.Config, patterns []string) (*VerificationPolicy, error) {
+	if chaincodeDefinition == nil {
+		return nil, logThenErrorf("chaincode definition is missing")
+	}
+	if len(patterns) == 0 {
+		return nil, logThenErrorf("no view patterns supplied for the verification policy")
+	}
+	var applicationPolicy peer.ApplicationPolicy
+	err := protoV2.Unmarshal(chaincodeDefinition.ValidationParameter, &applicationPolicy)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal endorsement policy of chaincode definition with error: %s", err.Error())
+	}
+	signaturePolicy := applicationPolicy.GetSignaturePolicy()
+	if signaturePolicy == nil {
+		policyReference := applicationPolicy.GetChannelConfigPolicyReference()
+		if policyReference == "" {
+			return nil, logThenErrorf("chaincode definition has no endorsement policy")
+		}
+		if channelConfig == nil {
+			return nil, logThenErrorf("endorsement policy of chaincode definition refers to channel config policy %s; "+
+				"use GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock to resolve it", policyReference)
+		}
+		signaturePolicy, err = resolveChannelConfigPolicyReference(channelConfig, policyReference)
+		if err != nil {
+			return nil, err
+		}
+	}
+	signaturePolicyBytes, err := protoV2.Marshal(signaturePolicy)
+	if err != nil {
+		return nil, logThenErrorf("failed to marshal endorsement policy of chaincode definition with error: %s", err.Error())
+	}
+	criterion := base64.StdEncoding.EncodeToString(signaturePolicyBytes)
+
+	verificationPolicy := &VerificationPolicy{
+		SecurityDomain: securityDomain,
+		Identifiers:    []Identifier{},
+	}
+	for _, pattern := range patterns {
+		verificationPolicy.Identifiers = append(verificationPolicy.Identifiers, Identifier{
+			Pattern: pattern,
+			Policy: IdentifierAccessPolicy{
+				Type:     FabricSignaturePolicyType,
+				Criteria: []string{criterion},
+			},
+		})
+	}
+	return verificationPolicy, nil
+}
+
+// getChannelConfigFromBlock extracts the channel configuration from a config block
+func getChannelConfigFromBlock(configBlock *fabriccommon.Block) (*fabriccommon.Config, error) {
+	if configBlock.Data == nil || len(configBlock.Data.Data) == 0 {
+		return nil, logThenErrorf("channel config block has no data")
+	}
+	var envelope fabriccommon.Envelope
+	err := protoV2.Unmarshal(configBlock.Data.Data[0], &envelope)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal envelope of channel config block with error: %s", err.Error())
+	}
+	var payload fabriccommon.Payload
+	err = protoV2.Unmarshal(envelope.Payload, &payload)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal payload of channel config block with error: %s", err.Error())
+	}
+	if payload.Header == nil {
+		return nil, logThenErrorf("payload of channel config block has no header")
+	}
+	var channelHeader fabriccommon.ChannelHeader
+	err = protoV2.Unmarshal(payload.Header.ChannelHeader, &channelHeader)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal channel header of channel config block with error: %s", err.Error())
+	}
+	if fabriccommon.HeaderType(channelHeader.Type) != fabriccommon.HeaderType_CONFIG {
+		return nil, logThenErrorf("block is not a channel config block: header type %s", fabriccommon.HeaderType(channelHeader.Type))
+	}
+	var configEnvelope fabriccommon.ConfigEnvelope
+	err = protoV2.Unmarshal(payload.Data, &configEnvelope)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal config envelope of channel config block with error: %s", err.Error())
+	}
+	if configEnvelope.Config == nil || configEnvelope.Config.ChannelGroup == nil {
+		return nil, logThenErrorf("channel config block has no channel group")
+	}
+	return configEnvelope.Config, nil
+}
+
+// resolveChannelConfigPolicyReference resolves an absolute channel config policy path (e.g., '/Channel/Application/Endorsement')
+// into a signature policy
+func resolveChannelConfigPolicyReference(channelConfig *fabriccommon.Config, policyReference string) (*fabriccommon.SignaturePolicyEnvelope, error) {
+	pathElements := strings.Split(strings.TrimPrefix(policyReference, "/"), "/")
+	if !strings.HasPrefix(policyReference, "/") || len(pathElements) < 2 || pathElements[0] != "Channel" {
+		return nil, logThenErrorf("channel config policy reference %s is not an absolute path in the channel group", policyReference)
+	}
+	configGroup := channelConfig.ChannelGroup
+	for _, groupName := range pathElements[1 : len(pathElements)-1] {
+		configGroup = configGroup.Groups[groupName]
+		if configGroup == nil {
+			return nil, logThenErrorf("channel config has no group %s for policy reference %s", groupName, policyReference)
+		}
+	}
+	policyName := pathElements[len(pathElements)-1]
+	if configGroup.Policies[policyName] == nil {
+		return nil, logThenErrorf("channel config has no policy %s", policyReference)
+	}
+	return resolveConfigGroupPolicy(configGroup, policyName)
+}
+
+// resolveConfigGroupPolicy resolves a policy of a channel config group into a signature policy, following Fabric's
+// evaluation of implicit meta policies, where a sub-group lacking the sub-policy counts as a policy that is never satisfied
+func resolveConfigGroupPolicy(configGroup *fabriccommon.ConfigGroup, policyName string) (*fabriccommon.SignaturePolicyEnvelope, error) {
+	configPolicy := configGroup.Policies[policyName]
+	if configPolicy == nil || configPolicy.Policy == nil {
+		return &fabriccommon.SignaturePolicyEnvelope{
+			Rule: &fabriccommon.SignaturePolicy{Type: &fabriccommon.SignaturePolicy_NOutOf_{NOutOf: &fabriccommon.SignaturePolicy_NOutOf{N: 1}}},
+		}, nil
+	}
+	switch fabriccommon.Policy_PolicyType(configPolicy.Policy.Type) {
+	case fabriccommon.Policy_SIGNATURE:
+		var envelope fabriccommon.SignaturePolicyEnvelope
+		err := protoV2.Unmarshal(configPolicy.Policy.Value, &envelope)
+		if err != nil {
+			return nil, logThenErrorf("failed to unmarshal channel config signature policy %s with error: %s", policyName, err.Error())
+		}
+		return &envelope, nil
+	case fabriccommon.Policy_IMPLICIT_META:
+		var implicitMetaPolicy fabriccommon.ImplicitMetaPolicy
+		err := protoV2.Unmarshal(configPolicy.Policy.Value, &implicitMetaPolicy)
+		if err != nil {
+			return nil, logThenErrorf("failed to unmarshal channel config implicit meta policy %s with error: %s", policyName, err.Error())
+		}
+		groupNames := make([]string, 0, len(configGroup.Groups))
+		for groupName := range configGroup.Groups {
+			groupNames = append(groupNames, groupName)
+		}
+		sort.Strings(groupNames)
+		envelope := &fabriccommon.SignaturePolicyEnvelope{}
+		rules := []*fabriccommon.SignaturePolicy{}
+		for _, groupName := range groupNames {
+			subEnvelope, err := resolveConfigGroupPolicy(configGroup.Groups[groupName], implicitMetaPolicy.SubPolicy)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, offsetSignedByRules(subEnvelope.Rule, int32(len(envelope.Identities))))
+			envelope.Identities = append(envelope.Identities, subEnvelope.Identities...)
+		}
+		var threshold int32
+		switch implicitMetaPolicy.Rule {
+		case fabriccommon.ImplicitMetaPolicy_ANY:
+			threshold = 1
+		case fabriccommon.ImplicitMetaPolicy_ALL:
+			threshold = int32(len(rules))
+		case fabriccommon.ImplicitMetaPolicy_MAJORITY:
+			threshold = int32(len(rules)/2 + 1)
+		default:
+			return nil, logThenErrorf("unknown rule %s of channel config implicit meta policy %s", implicitMetaPolicy.Rule, policyName)
+		}
+		// As in Fabric, a policy without sub-policies is always satisfied
+		if len(rules) == 0 {
+			threshold = 0
+		}
+		envelope.Rule = &fabriccommon.SignaturePolicy{
+			Type: &fabriccommon.SignaturePolicy_NOutOf_{NOutOf: &fabriccommon.SignaturePolicy_NOutOf{N: threshold, Rules: rules}},
+		}
+		return envelope, nil
+	default:
+		return nil, logThenErrorf("channel config policy %s has unsupported type %s", policyName,
+			fabriccommon.Policy_PolicyType(configPolicy.Policy.Type))
+	}
+}
+
+// offsetSignedByRules copies a signature policy rule, shifting its identity indices by the given offset
+func offsetSignedByRules(rule *fabriccommon.SignaturePolicy, offset int32) *fabriccommon.SignaturePolicy {
+	switch ruleType := rule.GetType().(type) {
+	case *fabriccommon.SignaturePolicy_SignedBy:
+		return &fabriccommon.SignaturePolicy{Type: &fabriccommon.SignaturePolicy_SignedBy{SignedBy: ruleType.SignedBy + offset}}
+	case *fabriccommon.SignaturePolicy_NOutOf_:
+		rules := []*fabriccommon.SignaturePolicy{}
+		for _, subRule := range ruleType.NOutOf.GetRules() {
+			rules = append(rules, offsetSignedByRules(subRule, offset))
+		}
+		return &fabriccommon.SignaturePolicy{
+			Type: &fabriccommon.SignaturePolicy_NOutOf_{NOutOf: &fabriccommon.SignaturePolicy_NOutOf{N: ruleType.NOutOf.GetN(), Rules: rules}},
+		}
+	default:
+		return &fabriccommon.SignaturePolicy{}
+	}
+}
+
+/**
+ * Get the distinct MSP IDs of the principals of a Fabric signature policy, given in the Fabric policy DSL or as a
+ * base64-encoded serialized SignaturePolicyEnvelope, which are the organizations whose peers should endorse a view.
+ **/
+func getFabricSignaturePolicyMspIds(criterion string) ([]string, error) {
+	mspIds := []string{}
+	addMspId := func(mspId string) {
+		for _, knownMspId := range mspIds {
+			if knownMspId == mspId {
+				return
+			}
+		}
+		mspIds = append(mspIds, mspId)
+	}
+	trimmedCriterion := strings.TrimSpace(criterion)
+	if strings.HasSuffix(trimmedCriterion, ")") {
+		for _, match := range fabricPolicyDSLPrincipal.FindAllStringSubmatch(trimmedCriterion, -1) {
+			addMspId(match[1])
+		}
+		return mspIds, nil
+	}
+	envelopeBytes, err := base64.StdEncoding.DecodeString(trimmedCriterion)
+	if err != nil {
+		return nil, logThenErrorf("failed to decode Fabric signature policy with error: %s", err.Error())
+	}
+	var envelope fabriccommon.SignaturePolicyEnvelope
+	err = protoV2.Unmarshal(envelopeBytes, &envelope)
+	if err != nil {
+		return nil, logThenErrorf("failed to unmarshal Fabric signature policy with error: %s", err.Error())
+	}
+	for _, identity := range envelope.Identities {
+		if identity.PrincipalClassification != msp.MSPPrincipal_ROLE {
+			return nil, logThenErrorf("principal of Fabric signature policy is not an MSP role: %s", identity.PrincipalClassification)
+		}
+		var mspRole msp.MSPRole
+		err = protoV2.Unmarshal(identity.Principal, &mspRole)
+		if err != nil {
+			return nil, logThenErrorf("failed to unmarshal principal of Fabric signature policy with error: %s", err.Error())
+		}
+		addMspId(mspRole.MspIdentifier)
+	}
+	return mspIds, nil
+}
+
+/**
+ * Get the distinct signer IDs (e.g., MSP IDs) referenced by the expression criteria of a verification policy, which are the
+ * organizations whose peers should endorse a view. An expression with a 'count' threshold can be satisfied by signers it
+ * does not reference, so no signer IDs are returned then, and the relay driver requests the view from all endorsers.
+ **/
+func getExpressionPolicyIds(criteria []string) []string {
+	ids := []string{}
+	for _, criterion := range criteria {
+		for _, id := range policyExpressionIdentifier.FindAllString(criterion, -1) {
+			if id == "count" {
+				return []string{}
+			}
+			known := false
+			for _, knownId := range ids {
+				known = known || knownId == id
+			}
+			if !known {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package interoperablehelper
+
+import (
+	"encoding/base64"
+	"testing"
+
+	fabriccommon "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer/lifecycle"
+	"github.com/stretchr/testify/require"
+	protoV2 "google.golang.org/protobuf/proto"
+)
+
+// signaturePolicyEnvelope builds the envelope of OutOf(n, 'mspId.peer', ...)
+func signaturePolicyEnvelope(t *testing.T, n int32, mspIds []string) *fabriccommon.SignaturePolicyEnvelope {
+	envelope := &fabriccommon.SignaturePolicyEnvelope{}
+	var rules []*fabriccommon.SignaturePolicy
+	for i, mspId := range mspIds {
+		principalBytes, err := protoV2.Marshal(&msp.MSPRole{MspIdentifier: mspId, Role: msp.MSPRole_PEER})
+		require.NoError(t, err)
+		envelope.Identities = append(envelope.Identities, &msp.MSPPrincipal{PrincipalClassification: msp.MSPPrincipal_ROLE, Principal: principalBytes})
+		rules = append(rules, &fabriccommon.SignaturePolicy{Type: &fabriccommon.SignaturePolicy_SignedBy{SignedBy: int32(i)}})
+	}
+	envelope.Rule = &fabriccommon.SignaturePolicy{
+		Type: &fabriccommon.SignaturePolicy_NOutOf_{NOutOf: &fabriccommon.SignaturePolicy_NOutOf{N: n, Rules: rules}},
+	}
+	return envelope
+}
+
+func TestGetVerificationPolicyFromChaincodeDefinition(t *testing.T) {
+	envelope := signaturePolicyEnvelope(t, 2, []string{"Org1MSP", "Org2MSP", "Org1MSP"})
+	validationParameter, err := protoV2.Marshal(&peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_SignaturePolicy{SignaturePolicy: envelope}})
+	require.NoError(t, err)
+	chaincodeDefinition := &lifecycle.QueryChaincodeDefinitionResult{Sequence: 1, Version: "1.0", ValidationParameter: validationParameter}
+
+	// Test success with a signature endorsement policy
+	verificationPolicy, err := GetVerificationPolicyFromChaincodeDefinition("network1", chaincodeDefinition, []string{"mychannel:simplestate:Read:*", "mychannel:simplestate:Query:*"})
+	require.NoError(t, err)
+	require.Equal(t, "network1", verificationPolicy.SecurityDomain)
+	require.Len(t, verificationPolicy.Identifiers, 2)
+	require.Equal(t, "mychannel:simplestate:Query:*", verificationPolicy.Identifiers[1].Pattern)
+	require.Equal(t, FabricSignaturePolicyType, verificationPolicy.Identifiers[0].Policy.Type)
+	require.Len(t, verificationPolicy.Identifiers[0].Policy.Criteria, 1)
+	mspIds, err := getFabricSignaturePolicyMspIds(verificationPolicy.Identifiers[0].Policy.Criteria[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org1MSP", "Org2MSP"}, mspIds)
+
+	// Test failure with an endorsement policy referring to the channel config
+	validationParameter, err = protoV2.Marshal(&peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"}})
+	require.NoError(t, err)
+	chaincodeDefinition.ValidationParameter = validationParameter
+	_, err = GetVerificationPolicyFromChaincodeDefinition("network1", chaincodeDefinition, []string{"mychannel:simplestate:Read:*"})
+	require.ErrorContains(t, err, "refers to channel config policy /Channel/Application/Endorsement; use GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock")
+
+	// Test failure without patterns or chaincode definition
+	_, err = GetVerificationPolicyFromChaincodeDefinition("network1", chaincodeDefinition, []string{})
+	require.ErrorContains(t, err, "no view patterns")
+	_, err = GetVerificationPolicyFromChaincodeDefinition("network1", nil, []string{"mychannel:simplestate:Read:*"})
+	require.ErrorContains(t, err, "chaincode definition is missing")
+}
+
+// channelConfigBlock builds a config block whose application group has an Endorsement policy with the given rule
+// over the Endorsement policies of its organizations
+func channelConfigBlock(t *testing.T, rule fabriccommon.ImplicitMetaPolicy_Rule, orgEndorsementPolicies map[string]*fabriccommon.SignaturePolicyEnvelope) *fabriccommon.Block {
+	implicitMetaPolicyBytes, err := protoV2.Marshal(&fabriccommon.ImplicitMetaPolicy{SubPolicy: "Endorsement", Rule: rule})
+	require.NoError(t, err)
+	applicationGroup := &fabriccommon.ConfigGroup{
+		Groups: map[string]*fabriccommon.ConfigGroup{},
+		Policies: map[string]*fabriccommon.ConfigPolicy{
+			"Endorsement": {Policy: &fabriccommon.Policy{Type: int32(fabriccommon.Policy_IMPLICIT_META), Value: implicitMetaPolicyBytes}},
+		},
+	}
+	for orgName, envelope := range orgEndorsementPolicies {
+		orgGroup := &fabriccommon.ConfigGroup{Policies: map[string]*fabriccommon.ConfigPolicy{}}
+		if envelope != nil {
+			envelopeBytes, err := protoV2.Marshal(envelope)
+			require.NoError(t, err)
+			orgGroup.Policies["Endorsement"] = &fabriccommon.ConfigPolicy{Policy: &fabriccommon.Policy{Type: int32(fabriccommon.Policy_SIGNATURE), Value: envelopeBytes}}
+		}
+		applicationGroup.Groups[orgName] = orgGroup
+	}
+	configEnvelopeBytes, err := protoV2.Marshal(&fabriccommon.ConfigEnvelope{Config: &fabriccommon.Config{
+		ChannelGroup: &fabriccommon.ConfigGroup{Groups: map[string]*fabriccommon.ConfigGroup{"Application": applicationGroup}},
+	}})
+	require.NoError(t, err)
+	channelHeaderBytes, err := protoV2.Marshal(&fabriccommon.ChannelHeader{Type: int32(fabriccommon.HeaderType_CONFIG), ChannelId: "mychannel"})
+	require.NoError(t, err)
+	payloadBytes, err := protoV2.Marshal(&fabriccommon.Payload{Header: &fabriccommon.Header{ChannelHeader: channelHeaderBytes}, Data: configEnvelopeBytes})
+	require.NoError(t, err)
+	envelopeBytes, err := protoV2.Marshal(&fabriccommon.Envelope{Payload: payloadBytes})
+	require.NoError(t, err)
+	return &fabriccommon.Block{Data: &fabriccommon.BlockData{Data: [][]byte{envelopeBytes}}}
+}
+
+// decodeFabricSignaturePolicy decodes a base64-encoded SignaturePolicyEnvelope criterion
+func decodeFabricSignaturePolicy(t *testing.T, criterion string) *fabriccommon.SignaturePolicyEnvelope {
+	envelopeBytes, err := base64.StdEncoding.DecodeString(criterion)
+	require.NoError(t, err)
+	var envelope fabriccommon.SignaturePolicyEnvelope
+	require.NoError(t, protoV2.Unmarshal(envelopeBytes, &envelope))
+	return &envelope
+}
+
+func TestGetVerificationPolicyFromChaincodeDefinitionAndConfigBlock(t *testing.T) {
+	validationParameter, err := protoV2.Marshal(&peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: "/Channel/Application/Endorsement"}})
+	require.NoError(t, err)
+	chaincodeDefinition := &lifecycle.QueryChaincodeDefinitionResult{Sequence: 1, Version: "1.0", ValidationParameter: validationParameter}
+	patterns := []string{"mychannel:simplestate:Read:*"}
+
+	// Test success with the default MAJORITY Endorsement policy of three organizations
+	configBlock := channelConfigBlock(t, fabriccommon.ImplicitMetaPolicy_MAJORITY, map[string]*fabriccommon.SignaturePolicyEnvelope{
+		"Org1": signaturePolicyEnvelope(t, 1, []string{"Org1MSP"}),
+		"Org2": signaturePolicyEnvelope(t, 1, []string{"Org2MSP"}),
+		"Org3": signaturePolicyEnvelope(t, 1, []string{"Org3MSP"}),
+	})
+	verificationPolicy, err := GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock("network1", chaincodeDefinition, configBlock, patterns)
+	require.NoError(t, err)
+	require.Len(t, verificationPolicy.Identifiers, 1)
+	require.Equal(t, FabricSignaturePolicyType, verificationPolicy.Identifiers[0].Policy.Type)
+	mspIds, err := getFabricSignaturePolicyMspIds(verificationPolicy.Identifiers[0].Policy.Criteria[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org1MSP", "Org2MSP", "Org3MSP"}, mspIds)
+	envelope := decodeFabricSignaturePolicy(t, verificationPolicy.Identifiers[0].Policy.Criteria[0])
+	require.Equal(t, int32(2), envelope.Rule.GetNOutOf().GetN())
+	require.Len(t, envelope.Rule.GetNOutOf().GetRules(), 3)
+	// The identity indices of each organization's rule are shifted into the merged identities
+	for i, orgRule := range envelope.Rule.GetNOutOf().GetRules() {
+		require.Equal(t, int32(1), orgRule.GetNOutOf().GetN())
+		require.Equal(t, int32(i), orgRule.GetNOutOf().GetRules()[0].GetSignedBy())
+	}
+
+	// Test success with an ALL policy, where an organization without an Endorsement policy can never be satisfied
+	configBlock = channelConfigBlock(t, fabriccommon.ImplicitMetaPolicy_ALL, map[string]*fabriccommon.SignaturePolicyEnvelope{
+		"Org1": signaturePolicyEnvelope(t, 1, []string{"Org1MSP"}),
+		"Org2": nil,
+	})
+	verificationPolicy, err = GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock("network1", chaincodeDefinition, configBlock, patterns)
+	require.NoError(t, err)
+	envelope = decodeFabricSignaturePolicy(t, verificationPolicy.Identifiers[0].Policy.Criteria[0])
+	require.Equal(t, int32(2), envelope.Rule.GetNOutOf().GetN())
+	require.Len(t, envelope.Identities, 1)
+	require.Equal(t, int32(1), envelope.Rule.GetNOutOf().GetRules()[1].GetNOutOf().GetN())
+	require.Empty(t, envelope.Rule.GetNOutOf().GetRules()[1].GetNOutOf().GetRules())
+
+	// Test success with a signature endorsement policy, which does not need the config block
+	validationParameter, err = protoV2.Marshal(&peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_SignaturePolicy{SignaturePolicy: signaturePolicyEnvelope(t, 1, []string{"Org4MSP"})}})
+	require.NoError(t, err)
+	verificationPolicy, err = GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock("network1",
+		&lifecycle.QueryChaincodeDefinitionResult{ValidationParameter: validationParameter}, configBlock, patterns)
+	require.NoError(t, err)
+	mspIds, err = getFabricSignaturePolicyMspIds(verificationPolicy.Identifiers[0].Policy.Criteria[0])
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org4MSP"}, mspIds)
+
+	// Test failure with references to missing or non-absolute policies
+	for reference, expectedError := range map[string]string{
+		"/Channel/Application/Writers":   "channel config has no policy /Channel/Application/Writers",
+		"/Channel/Orderer/Endorsement":   "channel config has no group Orderer",
+		"Application/Endorsement":        "is not an absolute path",
+		"/Other/Application/Endorsement": "is not an absolute path",
+	} {
+		validationParameter, err = protoV2.Marshal(&peer.ApplicationPolicy{Type: &peer.ApplicationPolicy_ChannelConfigPolicyReference{ChannelConfigPolicyReference: reference}})
+		require.NoError(t, err)
+		_, err = GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock("network1",
+			&lifecycle.QueryChaincodeDefinitionResult{ValidationParameter: validationParameter}, configBlock, patterns)
+		require.ErrorContains(t, err, expectedError)
+	}
+
+	// Test failure without a config block, or with a block that is not a config block
+	_, err = GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock("network1", chaincodeDefinition, nil, patterns)
+	require.ErrorContains(t, err, "channel config block is missing")
+	channelHeaderBytes, err := protoV2.Marshal(&fabriccommon.ChannelHeader{Type: int32(fabriccommon.HeaderType_ENDORSER_TRANSACTION)})
+	require.NoError(t, err)
+	payloadBytes, err := protoV2.Marshal(&fabriccommon.Payload{Header: &fabriccommon.Header{ChannelHeader: channelHeaderBytes}})
+	require.NoError(t, err)
+	envelopeBytes, err := protoV2.Marshal(&fabriccommon.Envelope{Payload: payloadBytes})
+	require.NoError(t, err)
+	_, err = GetVerificationPolicyFromChaincodeDefinitionAndConfigBlock("network1", chaincodeDefinition,
+		&fabriccommon.Block{Data: &fabriccommon.BlockData{Data: [][]byte{envelopeBytes}}}, patterns)
+	require.ErrorContains(t, err, "block is not a channel config block")
+}
+
+func TestGetFabricSignaturePolicyMspIds(t *testing.T) {
+	mspIds, err := getFabricSignaturePolicyMspIds("AND('Org1MSP.member', OR('Org2MSP.peer', 'Org1MSP.admin'))")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Org1MSP", "Org2MSP"}, mspIds)
+
+	_, err = getFabricSignaturePolicyMspIds("not a policy")
+	require.ErrorContains(t, err, "failed to decode Fabric signature policy")
+}
@@ -218,6 +218,9 @@ func GetMembershipForMspIdFromBlock(block *common.Block, mspId string) (*cactipr
 					for _, certBytes := range fabricMspConfig.GetIntermediateCerts() {
 						memberUnit.Chain = append(memberUnit.Chain, string(certBytes))
 					}
+					for _, crlBytes := range fabricMspConfig.GetRevocationList() {
+						memberUnit.Crls = append(memberUnit.Crls, string(crlBytes))
+					}
 					return memberUnit, nil
 				}
 			}
@@ -297,6 +300,9 @@ func GetMembershipForMspIdsFromBlock(block *common.Block, mspIds []string) (*cac
 					for _, certBytes := range fabricMspConfig.GetIntermediateCerts() {
 						memberUnit.Chain = append(memberUnit.Chain, string(certBytes))
 					}
+					for _, crlBytes := range fabricMspConfig.GetRevocationList() {
+						memberUnit.Crls = append(memberUnit.Crls, string(crlBytes))
+					}
 					membership.Members[fabricMspConfig.GetName()] = memberUnit
 				}
 			}
@@ -376,6 +382,9 @@ func GetMembershipForAllMspIdsFromBlock(block *common.Block, ordererMspIds []str
 					for _, certBytes := range fabricMspConfig.GetIntermediateCerts() {
 						memberUnit.Chain = append(memberUnit.Chain, string(certBytes))
 					}
+					for _, crlBytes := range fabricMspConfig.GetRevocationList() {
+						memberUnit.Crls = append(memberUnit.Crls, string(crlBytes))
+					}
 					membership.Members[fabricMspConfig.GetName()] = memberUnit
 				}
 			}
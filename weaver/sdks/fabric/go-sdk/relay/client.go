@@ -0,0 +1,279 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package relay
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// Default initial interval between two polls for the state of a request (same as the Node SDK)
+	DefaultInitialBackoff = 500 * time.Millisecond
+	// Default maximum interval between two polls for the state of a request
+	DefaultMaxBackoff = 10 * time.Second
+	// Default timeout of a single gRPC call to the relay
+	DefaultCallTimeout = 5 * time.Second
+)
+
+// ClientOptions configures the connection to the relay and the polling of request states
+type ClientOptions struct {
+	// Connect to the relay over TLS
+	UseTLS bool
+	// PEM files with the root CA certificates used to verify the relay's TLS certificate (system roots if empty)
+	TLSRootCACertPaths []string
+	// PEM files with the client certificate and key presented to the relay for mutual TLS (optional)
+	TLSClientCertPath string
+	TLSClientKeyPath  string
+	// Server name used to verify the relay's TLS certificate, if different from the host in the endpoint
+	TLSServerName string
+	// Interval before the first poll for a request state, doubled after every poll up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout of each gRPC call to the relay
+	CallTimeout time.Duration
+	// Maximum time ProcessRequest waits for a response if the context has no deadline (no limit if 0)
+	Timeout time.Duration
+	// Additional options for the gRPC connection
+	DialOptions []grpc.DialOption
+}
+
+// Client is a relay client that reuses a single gRPC connection for all requests, and is safe for concurrent use
+type Client struct {
+	endPoint      string
+	options       ClientOptions
+	conn          *grpc.ClientConn
+	networkClient networks.NetworkClient
+}
+
+// RequestStateError is returned when the relay reports that a request failed (RequestState_ERROR)
+type RequestStateError struct {
+	RequestId string
+	Message   string
+}
+
+func (e *RequestStateError) Error() string {
+	return fmt.Sprintf("request %s failed: %s", e.RequestId, e.Message)
+}
+
+// TimeoutError is returned when a request is still pending when the deadline for its response expires
+type TimeoutError struct {
+	RequestId string
+	Status    common.RequestState_STATUS
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timeout: request %s is still in state %s", e.RequestId, e.Status)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// NewClient creates a client for the relay at endPoint. The connection is established lazily and must be released using Close.
+func NewClient(endPoint string, options ClientOptions) (*Client, error) {
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = DefaultInitialBackoff
+	}
+	if options.MaxBackoff < options.InitialBackoff {
+		options.MaxBackoff = DefaultMaxBackoff
+		if options.MaxBackoff < options.InitialBackoff {
+			options.MaxBackoff = options.InitialBackoff
+		}
+	}
+	if options.CallTimeout <= 0 {
+		options.CallTimeout = DefaultCallTimeout
+	}
+
+	transportCredentials := insecure.NewCredentials()
+	if options.UseTLS {
+		tlsConfig, err := getTLSConfig(options)
+		if err != nil {
+			return nil, err
+		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
+	}
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}, options.DialOptions...)
+	conn, err := grpc.Dial(endPoint, dialOptions...)
+	if err != nil {
+		return nil, logThenErrorf("grpc Dial() failed to connect to relay %s: %v", endPoint, err)
+	}
+	return &Client{
+		endPoint:      endPoint,
+		options:       options,
+		conn:          conn,
+		networkClient: networks.NewNetworkClient(conn),
+	}, nil
+}
+
+func getTLSConfig(options ClientOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: options.TLSServerName,
+	}
+	if len(options.TLSRootCACertPaths) > 0 {
+		rootCAs := x509.NewCertPool()
+		for _, certPath := range options.TLSRootCACertPaths {
+			certPEM, err := os.ReadFile(certPath)
+			if err != nil {
+				return nil, logThenErrorf("invalid TLS root CA file path %s: %s", certPath, err.Error())
+			}
+			if !rootCAs.AppendCertsFromPEM(certPEM) {
+				return nil, logThenErrorf("no valid certificates in TLS root CA file %s", certPath)
+			}
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	if options.TLSClientCertPath != "" || options.TLSClientKeyPath != "" {
+		clientCert, err := tls.LoadX509KeyPair(options.TLSClientCertPath, options.TLSClientKeyPath)
+		if err != nil {
+			return nil, logThenErrorf("failed to load TLS client certificate and key: %s", err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+	return tlsConfig, nil
+}
+
+// Close releases the connection to the relay
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// SendRequest sends a query for a remote view to the relay, and returns the ID of the request
+func (c *Client) SendRequest(ctx context.Context, networkQuery *networks.NetworkQuery) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	resp, err := c.networkClient.RequestState(callCtx, networkQuery)
+	if err != nil {
+		return "", logThenErrorf("error in grpc RequestState(): %v", err)
+	}
+	if resp.GetStatus() == common.Ack_ERROR {
+		return "", logThenErrorf("relay rejected request: %s", resp.GetMessage())
+	}
+	return resp.GetRequestId(), nil
+}
+
+// GetState returns the current state of a request from the relay
+func (c *Client) GetState(ctx context.Context, requestId string) (*common.RequestState, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	requestState, err := c.networkClient.GetState(callCtx, &networks.GetStateMessage{RequestId: requestId})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("requestState: %v", requestState)
+	return requestState, nil
+}
+
+/**
+ * ProcessRequest sends a query to the relay and polls for the response with exponential backoff until the request
+ * completes or fails, or the context is cancelled or its deadline (or the client's timeout) expires.
+ * Returns a *RequestStateError if the request failed and a *TimeoutError if it is still pending at the deadline.
+ */
+func (c *Client) ProcessRequest(ctx context.Context, networkQuery *networks.NetworkQuery) (*common.RequestState, error) {
+	if _, ok := ctx.Deadline(); !ok && c.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+	}
+
+	requestId, err := c.SendRequest(ctx, networkQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.PollState(ctx, requestId)
+}
+
+/**
+ * PollState polls for the state of a request with exponential backoff until it is no longer pending.
+ * Transient failures to reach the relay are retried.
+ */
+func (c *Client) PollState(ctx context.Context, requestId string) (*common.RequestState, error) {
+	var state *common.RequestState
+	lastStatus := common.RequestState_PENDING_ACK
+	err := c.pollWithBackoff(ctx, "request "+requestId, func() (bool, bool, error) {
+		var err error
+		state, err = c.GetState(ctx, requestId)
+		if err != nil {
+			return false, false, err
+		}
+		lastStatus = state.GetStatus()
+		return lastStatus != common.RequestState_PENDING && lastStatus != common.RequestState_PENDING_ACK, false, nil
+	})
+	if err == context.DeadlineExceeded {
+		err = &TimeoutError{RequestId: requestId, Status: lastStatus}
+		log.Error(err.Error())
+		return nil, err
+	} else if err == context.Canceled {
+		return nil, logThenErrorf("request %s cancelled: %s", requestId, err.Error())
+	} else if err != nil {
+		return nil, logThenErrorf("error in grpc GetState(): %s", err.Error())
+	}
+	if lastStatus == common.RequestState_ERROR {
+		err = &RequestStateError{RequestId: requestId, Message: state.GetError()}
+		log.Error(err.Error())
+		return nil, err
+	}
+	return state, nil
+}
+
+// pollWithBackoff calls poll until it reports being done, waiting between calls with exponential backoff, which poll can
+// also ask to reset. Transient errors returned by poll are retried; other errors, or the error of ctx if it is done first,
+// are returned.
+func (c *Client) pollWithBackoff(ctx context.Context, description string, poll func() (done bool, resetBackoff bool, err error)) error {
+	backoff := c.options.InitialBackoff
+	for {
+		done, resetBackoff, err := poll()
+		if err == nil {
+			if done {
+				return nil
+			}
+			if resetBackoff {
+				backoff = c.options.InitialBackoff
+			}
+		} else if ctx.Err() == nil {
+			if !IsTransientError(err) {
+				return err
+			}
+			log.Warnf("failed to get state of %s, retrying: %s", description, err.Error())
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+		if backoff > c.options.MaxBackoff {
+			backoff = c.options.MaxBackoff
+		}
+	}
+}
+
+// IsTransientError returns whether a failed gRPC call to the relay may succeed if retried
+func IsTransientError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package relay
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// Local relay that returns the configured states for successive GetState calls, and the last one thereafter
+type mockRelayServer struct {
+	networks.UnimplementedNetworkServer
+	mutex         sync.Mutex
+	states        []*common.RequestState
+	errs          []error
+	getStateCalls int
+	// Event subscription states returned in the same way by successive GetEventSubscriptionState calls
+	subscriptionStates        []*common.EventSubscriptionState
+	getSubscriptionStateCalls int
+}
+
+func (s *mockRelayServer) RequestState(ctx context.Context, query *networks.NetworkQuery) (*common.Ack, error) {
+	return &common.Ack{Status: common.Ack_OK, RequestId: "request1"}, nil
+}
+
+func (s *mockRelayServer) GetState(ctx context.Context, getStateMessage *networks.GetStateMessage) (*common.RequestState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := s.getStateCalls
+	s.getStateCalls++
+	if index < len(s.errs) && s.errs[index] != nil {
+		return nil, s.errs[index]
+	}
+	if index >= len(s.states) {
+		index = len(s.states) - 1
+	}
+	return s.states[index], nil
+}
+
+func (s *mockRelayServer) GetEventSubscriptionState(ctx context.Context, getStateMessage *networks.GetStateMessage) (*common.EventSubscriptionState, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	index := s.getSubscriptionStateCalls
+	s.getSubscriptionStateCalls++
+	if index < len(s.errs) && s.errs[index] != nil {
+		return nil, s.errs[index]
+	}
+	if index >= len(s.subscriptionStates) {
+		index = len(s.subscriptionStates) - 1
+	}
+	return s.subscriptionStates[index], nil
+}
+
+func startMockRelay(t *testing.T, relayServer *mockRelayServer, serverOptions ...grpc.ServerOption) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(serverOptions...)
+	networks.RegisterNetworkServer(server, relayServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func pendingState() *common.RequestState {
+	return &common.RequestState{RequestId: "request1", Status: common.RequestState_PENDING}
+}
+
+func completedState() *common.RequestState {
+	return &common.RequestState{
+		RequestId: "request1",
+		Status:    common.RequestState_COMPLETED,
+		State:     &common.RequestState_View{View: &common.View{Data: []byte("view")}},
+	}
+}
+
+func TestClientProcessRequest(t *testing.T) {
+	relayServer := &mockRelayServer{
+		states: []*common.RequestState{pendingState(), pendingState(), completedState()},
+		errs:   []error{nil, status.Error(codes.Unavailable, "relay unavailable")},
+	}
+	endPoint := startMockRelay(t, relayServer)
+	client, err := NewClient(endPoint, ClientOptions{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Test success after polling through pending states and a transient failure, over one connection
+	state, err := client.ProcessRequest(context.Background(), NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	require.NoError(t, err)
+	require.Equal(t, []byte("view"), state.GetView().GetData())
+	require.Equal(t, 3, relayServer.getStateCalls)
+
+	// Test failure reported by the relay
+	relayServer.getStateCalls = 0
+	relayServer.errs = nil
+	relayServer.states = []*common.RequestState{{RequestId: "request1", Status: common.RequestState_ERROR, State: &common.RequestState_Error{Error: "access denied"}}}
+	_, err = client.ProcessRequest(context.Background(), NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	var requestStateErr *RequestStateError
+	require.ErrorAs(t, err, &requestStateErr)
+	require.Equal(t, "request1", requestStateErr.RequestId)
+	require.EqualError(t, err, "request request1 failed: access denied")
+
+	// Test timeout when the request stays pending
+	relayServer.states = []*common.RequestState{pendingState()}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = client.ProcessRequest(ctx, NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, common.RequestState_PENDING, timeoutErr.Status)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Test that the client timeout applies when the context has no deadline
+	timeoutClient, err := NewClient(endPoint, ClientOptions{InitialBackoff: 10 * time.Millisecond, Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+	defer timeoutClient.Close()
+	_, err = timeoutClient.ProcessRequest(context.Background(), NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	require.ErrorAs(t, err, &timeoutErr)
+
+	// Test cancellation, which is not reported as a timeout
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = client.ProcessRequest(ctx, NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	require.Error(t, err)
+	require.False(t, errors.As(err, &timeoutErr))
+
+	// Test failure that is not transient
+	relayServer.getStateCalls = 0
+	relayServer.errs = []error{status.Error(codes.NotFound, "no such request")}
+	_, err = client.ProcessRequest(context.Background(), NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	require.ErrorContains(t, err, "no such request")
+	require.Equal(t, 1, relayServer.getStateCalls)
+}
+
+func TestClientPollEventSubscriptionState(t *testing.T) {
+	relayServer := &mockRelayServer{
+		subscriptionStates: []*common.EventSubscriptionState{
+			{RequestId: "request1", Status: common.EventSubscriptionState_SUBSCRIBE_PENDING_ACK},
+			{RequestId: "request1", Status: common.EventSubscriptionState_SUBSCRIBE_PENDING},
+			{RequestId: "request1", Status: common.EventSubscriptionState_SUBSCRIBE_PENDING},
+			{RequestId: "request1", Status: common.EventSubscriptionState_SUBSCRIBED},
+		},
+		errs: []error{nil, status.Error(codes.Unavailable, "relay unavailable")},
+	}
+	endPoint := startMockRelay(t, relayServer)
+	client, err := NewClient(endPoint, ClientOptions{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Test success after polling through pending states and a transient failure, reporting each transition once
+	transitions := []common.EventSubscriptionState_STATUS{}
+	state, err := client.PollEventSubscriptionState(context.Background(), "request1", func(state *common.EventSubscriptionState) {
+		transitions = append(transitions, state.GetStatus())
+	})
+	require.NoError(t, err)
+	require.Equal(t, common.EventSubscriptionState_SUBSCRIBED, state.GetStatus())
+	require.Equal(t, []common.EventSubscriptionState_STATUS{common.EventSubscriptionState_SUBSCRIBE_PENDING_ACK, common.EventSubscriptionState_SUBSCRIBE_PENDING, common.EventSubscriptionState_SUBSCRIBED}, transitions)
+
+	// Test timeout when the subscription stays pending
+	relayServer.getSubscriptionStateCalls = 0
+	relayServer.errs = nil
+	relayServer.subscriptionStates = relayServer.subscriptionStates[1:2]
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = client.PollEventSubscriptionState(ctx, "request1", nil)
+	var timeoutErr *EventSubscriptionTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	require.Equal(t, common.EventSubscriptionState_SUBSCRIBE_PENDING, timeoutErr.Status)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Test failure that is not transient
+	relayServer.getSubscriptionStateCalls = 0
+	relayServer.errs = []error{status.Error(codes.NotFound, "no such subscription")}
+	_, err = client.PollEventSubscriptionState(context.Background(), "request1", nil)
+	require.ErrorContains(t, err, "no such subscription")
+	require.Equal(t, 1, relayServer.getSubscriptionStateCalls)
+}
+
+// Creates a certificate signed by parent (self-signed if nil) and writes the certificate and key PEM files to dir
+func createTestCertificate(t *testing.T, dir, name string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:              []string{"relay.example.com"},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certPath := filepath.Join(dir, name+"-cert.pem")
+	keyPath := filepath.Join(dir, name+"-key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, key, certPath, keyPath
+}
+
+func TestClientMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey, caCertPath, _ := createTestCertificate(t, dir, "ca", true, nil, nil)
+	_, _, serverCertPath, serverKeyPath := createTestCertificate(t, dir, "server", false, caCert, caKey)
+	_, _, clientCertPath, clientKeyPath := createTestCertificate(t, dir, "client", false, caCert, caKey)
+
+	serverCert, err := tls.LoadX509KeyPair(serverCertPath, serverKeyPath)
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caCert)
+	serverCredentials := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	relayServer := &mockRelayServer{states: []*common.RequestState{completedState()}}
+	endPoint := startMockRelay(t, relayServer, grpc.Creds(serverCredentials))
+
+	// Test success with mutual TLS
+	client, err := NewClient(endPoint, ClientOptions{
+		UseTLS:             true,
+		TLSRootCACertPaths: []string{caCertPath},
+		TLSClientCertPath:  clientCertPath,
+		TLSClientKeyPath:   clientKeyPath,
+		TLSServerName:      "relay.example.com",
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	state, err := client.ProcessRequest(context.Background(), NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	require.NoError(t, err)
+	require.Equal(t, common.RequestState_COMPLETED, state.GetStatus())
+
+	// Test failure without a client certificate
+	client, err = NewClient(endPoint, ClientOptions{
+		UseTLS:             true,
+		TLSRootCACertPaths: []string{caCertPath},
+		TLSServerName:      "relay.example.com",
+	})
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.ProcessRequest(context.Background(), NewNetworkQuery("address", nil, "network1", "cert", "sig", "nonce", "Org1MSP", false))
+	require.Error(t, err)
+
+	// Test failure with invalid TLS files
+	_, err = NewClient(endPoint, ClientOptions{UseTLS: true, TLSRootCACertPaths: []string{filepath.Join(dir, "missing.pem")}})
+	require.ErrorContains(t, err, "invalid TLS root CA file path")
+	_, err = NewClient(endPoint, ClientOptions{UseTLS: true, TLSClientCertPath: clientCertPath, TLSClientKeyPath: serverKeyPath})
+	require.ErrorContains(t, err, "failed to load TLS client certificate and key")
+}
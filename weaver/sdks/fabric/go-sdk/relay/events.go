@@ -0,0 +1,127 @@
+/*
+Copyright 2020 IBM All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package relay
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/common"
+	"github.com/hyperledger/cacti/weaver/common/protos-go/v2/networks"
+	log "github.com/sirupsen/logrus"
+)
+
+// SendSubscribeEventRequest sends an event subscription request to the relay, and returns the ID of the subscription
+func (c *Client) SendSubscribeEventRequest(ctx context.Context, eventSubscription *networks.NetworkEventSubscription) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	resp, err := c.networkClient.SubscribeEvent(callCtx, eventSubscription)
+	if err != nil {
+		return "", logThenErrorf("error in grpc SubscribeEvent(): %v", err)
+	}
+	if resp.GetStatus() == common.Ack_ERROR {
+		return "", logThenErrorf("event subscription request received negative Ack error: %s", resp.GetMessage())
+	}
+	return resp.GetRequestId(), nil
+}
+
+// SendUnsubscribeEventRequest sends a request to the relay to cancel an event subscription, and returns the ID of the subscription
+func (c *Client) SendUnsubscribeEventRequest(ctx context.Context, eventUnsubscription *networks.NetworkEventUnsubscription) (string, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	resp, err := c.networkClient.UnsubscribeEvent(callCtx, eventUnsubscription)
+	if err != nil {
+		return "", logThenErrorf("error in grpc UnsubscribeEvent(): %v", err)
+	}
+	if resp.GetStatus() == common.Ack_ERROR {
+		return "", logThenErrorf("event unsubscription request received negative Ack error: %s", resp.GetMessage())
+	}
+	return resp.GetRequestId(), nil
+}
+
+// GetEventSubscriptionState returns the current state of an event subscription from the relay
+func (c *Client) GetEventSubscriptionState(ctx context.Context, requestId string) (*common.EventSubscriptionState, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	subscriptionState, err := c.networkClient.GetEventSubscriptionState(callCtx, &networks.GetStateMessage{RequestId: requestId})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("eventSubscriptionState: %v", subscriptionState)
+	return subscriptionState, nil
+}
+
+// GetEventStates returns the events received by the relay for a subscription since the last call.
+// The relay deletes the events once they are fetched.
+func (c *Client) GetEventStates(ctx context.Context, requestId string) (*common.EventStates, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.options.CallTimeout)
+	defer cancel()
+	eventStates, err := c.networkClient.GetEventStates(callCtx, &networks.GetStateMessage{RequestId: requestId})
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("eventStates: %v", eventStates)
+	return eventStates, nil
+}
+
+func isEventSubscriptionPending(status common.EventSubscriptionState_STATUS) bool {
+	return status == common.EventSubscriptionState_SUBSCRIBE_PENDING_ACK || status == common.EventSubscriptionState_SUBSCRIBE_PENDING ||
+		status == common.EventSubscriptionState_UNSUBSCRIBE_PENDING_ACK || status == common.EventSubscriptionState_UNSUBSCRIBE_PENDING
+}
+
+/**
+ * PollEventSubscriptionState polls for the state of an event subscription with exponential backoff until it is no longer
+ * pending, and returns the final state. If onTransition is not nil, it is called with every state whose status differs
+ * from the previous one. Returns an *EventSubscriptionTimeoutError if the subscription is still pending when the context
+ * deadline (or the client's timeout) expires.
+ */
+func (c *Client) PollEventSubscriptionState(ctx context.Context, requestId string,
+	onTransition func(*common.EventSubscriptionState)) (*common.EventSubscriptionState, error) {
+	if _, ok := ctx.Deadline(); !ok && c.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.options.Timeout)
+		defer cancel()
+	}
+	var lastState *common.EventSubscriptionState
+	err := c.pollWithBackoff(ctx, "event subscription "+requestId, func() (bool, bool, error) {
+		state, err := c.GetEventSubscriptionState(ctx, requestId)
+		if err != nil {
+			return false, false, err
+		}
+		transition := lastState == nil || state.GetStatus() != lastState.GetStatus()
+		if transition && onTransition != nil {
+			onTransition(state)
+		}
+		lastState = state
+		// Poll quickly again after a transition
+		return !isEventSubscriptionPending(state.GetStatus()), transition, nil
+	})
+	if err == context.DeadlineExceeded {
+		err = &EventSubscriptionTimeoutError{RequestId: requestId, Status: lastState.GetStatus()}
+		log.Error(err.Error())
+		return nil, err
+	} else if err == context.Canceled {
+		return nil, logThenErrorf("event subscription %s cancelled: %s", requestId, err.Error())
+	} else if err != nil {
+		return nil, logThenErrorf("error in grpc GetEventSubscriptionState(): %s", err.Error())
+	}
+	return lastState, nil
+}
+
+// EventSubscriptionTimeoutError is returned when an event subscription is still pending when the deadline for its confirmation expires
+type EventSubscriptionTimeoutError struct {
+	RequestId string
+	Status    common.EventSubscriptionState_STATUS
+}
+
+func (e *EventSubscriptionTimeoutError) Error() string {
+	return fmt.Sprintf("timeout: event subscription %s is still in state %s", e.RequestId, e.Status)
+}
+
+func (e *EventSubscriptionTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
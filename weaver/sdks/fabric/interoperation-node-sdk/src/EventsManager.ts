@@ -10,7 +10,6 @@
 /** End file docs */
 
 import log4js from "log4js";
-import { v4 as uuidv4 } from "uuid";
 import { ICryptoKey } from "fabric-common";
 import { Contract } from "fabric-network";
 import eventsPb from "@hyperledger/cacti-weaver-protos-js/common/events_pb";
@@ -141,7 +140,7 @@ const subscribeRemoteEvent = async (
   const relay = useTls
     ? new Relay(localRelayEndpoint, true, tlsRootCACertPaths)
     : new Relay(localRelayEndpoint);
-  const uuidValue = uuidv4();
+  const uuidValue = helpers.generateNonce();
 
   logger.debug(
     "Making event subscription call to relay for \
@@ -229,7 +228,7 @@ const unsubscribeRemoteEvent = async (
   const relay = useTls
     ? new Relay(localRelayEndpoint, true, tlsRootCACertPaths)
     : new Relay(localRelayEndpoint);
-  const uuidValue = uuidv4();
+  const uuidValue = helpers.generateNonce();
 
   logger.debug(
     "Making event unsubscription call to relay for \
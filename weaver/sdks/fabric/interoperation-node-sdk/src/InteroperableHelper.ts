@@ -19,6 +19,7 @@ import fabproto6 from "fabric-protos";
 import crypto from "crypto";
 import eciesCrypto from "./eciesCrypto.js";
 import * as helpers from "./helpers";
+import { getBestMatch } from "./PatternMatcher";
 import {
   deserializeRemoteProposalResponseBase64,
   serializeRemoteProposalResponse,
@@ -32,7 +33,6 @@ import proposalResponsePb from "@hyperledger/cacti-weaver-protos-js/peer/proposa
 import identitiesPb from "@hyperledger/cacti-weaver-protos-js/msp/identities_pb";
 import { Relay } from "./Relay";
 import { Gateway, Contract } from "fabric-network";
-import { v4 as uuidv4 } from "uuid";
 import { ICryptoKey } from "fabric-common";
 import { InteropJSON, InvocationSpec, Flow, RemoteJSON } from "./types";
 const logger = log4js.getLogger("InteroperableHelper");
@@ -224,6 +224,7 @@ const getResponseDataFromView = (view, privKeyPEM = null) => {
     const fabricViewProposalResponses =
       fabricView.getEndorsedProposalResponsesList();
     let viewAddress = "";
+    let viewNonce = "";
     let responsePayload = "";
     const responsePayloadContents = [];
     let payloadConfidential = false;
@@ -251,6 +252,7 @@ const getResponseDataFromView = (view, privKeyPEM = null) => {
           );
         if (i === 0) {
           viewAddress = interopPayload.getAddress();
+          viewNonce = interopPayload.getNonce();
           responsePayload = Buffer.from(
             decryptedPayloadContents.getPayload(),
           ).toString();
@@ -280,6 +282,7 @@ const getResponseDataFromView = (view, privKeyPEM = null) => {
       } else {
         if (i === 0) {
           viewAddress = interopPayload.getAddress();
+          viewNonce = interopPayload.getNonce();
           responsePayload = Buffer.from(interopPayload.getPayload()).toString();
           payloadConfidential = false;
         } else if (payloadConfidential) {
@@ -306,16 +309,18 @@ const getResponseDataFromView = (view, privKeyPEM = null) => {
     if (payloadConfidential) {
       return {
         viewAddress: viewAddress,
+        nonce: viewNonce,
         data: responsePayload,
         contents: responsePayloadContents,
       };
     } else {
-      return { viewAddress: viewAddress, data: responsePayload };
+      return { viewAddress: viewAddress, nonce: viewNonce, data: responsePayload };
     }
   } else if (view.getMeta().getProtocol() == statePb.Meta.Protocol.CORDA) {
     const cordaView = cordaViewPb.ViewData.deserializeBinary(view.getData());
     const cordaNotarizedPayloads = cordaView.getNotarizedPayloadsList();
     let viewAddress = "";
+    let viewNonce = "";
     let responsePayload = "";
     const responsePayloadContents = [];
     let payloadConfidential = false;
@@ -332,6 +337,7 @@ const getResponseDataFromView = (view, privKeyPEM = null) => {
       } else {
         if (i === 0) {
           viewAddress = interopPayload.getAddress();
+          viewNonce = interopPayload.getNonce();
           responsePayload = Buffer.from(interopPayload.getPayload()).toString();
           payloadConfidential = false;
         } else if (payloadConfidential) {
@@ -358,11 +364,12 @@ const getResponseDataFromView = (view, privKeyPEM = null) => {
     if (payloadConfidential) {
       return {
         viewAddress: viewAddress,
+        nonce: viewNonce,
         data: responsePayload,
         contents: responsePayloadContents,
       };
     } else {
-      return { viewAddress: viewAddress, data: responsePayload };
+      return { viewAddress: viewAddress, nonce: viewNonce, data: responsePayload };
     }
   } else {
     const protocolType = view.getMeta().getProtocol();
@@ -479,40 +486,24 @@ function verifySignature(
   return crypto.verify(algorithm, messageBuffer, publicKey, signBuffer);
 }
 
-const validPatternString = (pattern: string): boolean => {
-  // count number of stars in pattern
-  const numStars = (pattern.match(/\*/g) || []).length;
-
-  // check if 0 or 1 stars
-  if (numStars <= 1) {
-    // if 0 stars, return true, if 1 star, make sure its at the end
-    return pattern.endsWith("*") || numStars == 0;
-  }
-
-  return false;
-};
-
-const isPatternAndAddressMatch = (
-  pattern: string,
-  address: string,
-): boolean => {
-  // make sure the pattern is valid
-  if (!validPatternString(pattern)) {
-    return false;
-  }
-
-  // count number of stars in pattern
-  const numStars = (pattern.match(/\*/g) || []).length;
-
-  // if 0 stars, and exact match, return true
-  if (numStars == 0 && pattern == address) {
-    return true;
-  }
-
-  // if 1 star and pattern is a substring of address, return true
-  if (numStars == 1 && address.includes(pattern.slice(0, -1))) {
-    return true;
+/**
+ * Get the distinct signer IDs (e.g., MSP IDs) referenced by the expression criteria of a verification policy, which are the
+ * organizations whose peers should endorse a view. An expression with a 'count' threshold can be satisfied by signers it
+ * does not reference, so no signer IDs are returned then, and the relay driver requests the view from all endorsers.
+ **/
+const getExpressionPolicyIds = (criteria: string[]): string[] => {
+  const ids: string[] = [];
+  for (const criterion of criteria) {
+    for (const id of criterion.match(/[A-Za-z_][A-Za-z0-9_.-]*/g) || []) {
+      if (id === "count") {
+        return [];
+      }
+      if (!ids.includes(id)) {
+        ids.push(id);
+      }
+    }
   }
+  return ids;
 };
 
 /**
@@ -539,28 +530,18 @@ const getPolicyCriteriaForAddress = async (
       throw new Error(`No verification policy for address ${address}`);
     }
     const verificationPolicy = JSON.parse(queryResponse.toString());
-    // Get policy criteria matching the requested information in the address
-    let matchingIdentifier = null;
-    verificationPolicy.identifiers.forEach((item) => {
-      if (item.pattern === parsedAddress.viewSegment) {
-        return true;
-      }
-      return false;
-    });
-    for (const item of verificationPolicy.identifiers) {
-      // short circuit if there is an exact match
-      if (item.pattern === parsedAddress.viewSegment) {
-        matchingIdentifier = item;
-        break;
-      }
-      if (
-        validPatternString(item.pattern) &&
-        isPatternAndAddressMatch(item.pattern, parsedAddress.viewSegment) &&
-        (!matchingIdentifier ||
-          item.pattern.length > matchingIdentifier.pattern.length)
-      ) {
-        matchingIdentifier = item;
-      }
+    // Get policy criteria of the most specific pattern matching the requested information in the address
+    const bestMatch = getBestMatch(
+      verificationPolicy.identifiers.map((item) => item.pattern),
+      parsedAddress.viewSegment,
+    );
+    const matchingIdentifier =
+      bestMatch < 0 ? null : verificationPolicy.identifiers[bestMatch];
+    if (
+      matchingIdentifier?.policy?.criteria &&
+      matchingIdentifier.policy.type?.toLowerCase() === "expression"
+    ) {
+      return getExpressionPolicyIds(matchingIdentifier.policy.criteria);
     }
     if (matchingIdentifier?.policy?.criteria) {
       return matchingIdentifier.policy.criteria;
@@ -589,12 +570,14 @@ const verifyView = async (
 
 /**
  * Verifies a view's contents and extracts confidential payload by using chaincode function in interop chaincode. Verification is based on verification policy of the network, proof type and protocol type.
+ * The view must have been obtained by the caller with a query carrying the given nonce.
  **/
 const parseAndValidateView = async (
   contract: Contract,
   address: string,
   base64ViewProto: string,
   b64ViewContents: Array<string>,
+  nonce: string,
 ): Promise<Buffer> => {
   try {
     const viewPayload = await contract.evaluateTransaction(
@@ -602,6 +585,7 @@ const parseAndValidateView = async (
       address,
       base64ViewProto,
       JSON.stringify(b64ViewContents),
+      nonce,
     );
     return viewPayload;
   } catch (e) {
@@ -662,7 +646,8 @@ const interopFlow = async (
   const views = [],
     viewsSerializedBase64 = [],
     computedAddresses = [],
-    viewContentsBase64 = [];
+    viewContentsBase64 = [],
+    nonces = [];
   for (let i = 0; i < interopJSONs.length; i++) {
     const [requestResponse, requestResponseError] = await helpers.handlePromise(
       getRemoteView(
@@ -687,6 +672,7 @@ const interopFlow = async (
       Buffer.from(requestResponse.view.serializeBinary()).toString("base64"),
     );
     computedAddresses.push(requestResponse.address);
+    nonces.push(requestResponse.nonce);
     if (confidential) {
       const respData = getResponseDataFromView(
         requestResponse.view,
@@ -705,6 +691,7 @@ const interopFlow = async (
       computedAddresses,
       viewsSerializedBase64,
       viewContentsBase64,
+      nonces,
     );
     return { views, result: ccArgs };
   }
@@ -716,6 +703,7 @@ const interopFlow = async (
     computedAddresses,
     viewsSerializedBase64,
     viewContentsBase64,
+    nonces,
     endorsingOrgs,
     gateway,
   );
@@ -731,6 +719,7 @@ const getCCArgsForProofVerification = (
   viewAddresses: Array<string>,
   viewsSerializedBase64: Array<string>,
   viewContentsBase64: Array<Array<string>>,
+  nonces: Array<string>,
 ): Array<any> => {
   const {
     ccArgs: localCCArgs,
@@ -747,6 +736,7 @@ const getCCArgsForProofVerification = (
     JSON.stringify(viewAddresses),
     JSON.stringify(viewsSerializedBase64),
     JSON.stringify(viewContentsBase64),
+    JSON.stringify(nonces),
   ];
   return ccArgs;
 };
@@ -788,6 +778,7 @@ const submitTransactionWithRemoteViews = async (
   viewAddresses: Array<string>,
   viewsSerializedBase64: Array<string>,
   viewContentsBase64: Array<Array<string>>,
+  nonces: Array<string>,
   endorsingOrgs: Array<string>,
   gateway: Gateway = null,
 ): Promise<any> => {
@@ -797,6 +788,7 @@ const submitTransactionWithRemoteViews = async (
     viewAddresses,
     viewsSerializedBase64,
     viewContentsBase64,
+    nonces,
   );
 
   interopContract = await addAppCCEndorsementPolicy(
@@ -823,6 +815,7 @@ const submitTransactionWithRemoteViews = async (
  * 2. Get policy from chaincode for supplied address.
  * 3. Call the relay Process request which will send a request to the remote network via local relay and poll for an update in the request status.
  * 4. Call the local chaincode to verify the view before trying to submit to chaincode.
+ * Returns the view along with the address and nonce of the request, which the local chaincode checks the view against.
  **/
 const getRemoteView = async (
   interopContract: Contract,
@@ -834,7 +827,7 @@ const getRemoteView = async (
   useTls: boolean = false,
   tlsRootCACertPaths?: Array<string>,
   confidential: boolean = false,
-): Promise<{ view: any; address: any }> => {
+): Promise<{ view: any; address: any; nonce: string }> => {
   const {
     address,
     ChaincodeFunc,
@@ -870,7 +863,7 @@ const getRemoteView = async (
   const relay = useTls
     ? new Relay(localRelayEndpoint, true, tlsRootCACertPaths)
     : new Relay(localRelayEndpoint);
-  const uuidValue = uuidv4();
+  const uuidValue = helpers.generateNonce();
   // Step 3
   // TODO fix types here so can return proper view
   const [relayResponse, relayResponseError] = await helpers.handlePromise(
@@ -903,7 +896,11 @@ const getRemoteView = async (
   if (verifyError) {
     throw new Error(`View verification failed ${verifyError}`);
   }
-  return { view: relayResponse.getView(), address: computedAddress };
+  return {
+    view: relayResponse.getView(),
+    address: computedAddress,
+    nonce: uuidValue,
+  };
 };
 
 /**
@@ -82,6 +82,8 @@ async function readMembership(
   );
 }
 
+// The MSP information exposed by channel discovery does not include revocation lists,
+// so members built here carry no CRLs; record them explicitly if revocation is needed.
 function getMembershipUnit(
   channel: Channel,
   mspId: string,
@@ -0,0 +1,411 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+/**
+ * Matches view addresses against the patterns in verification policies, as the interop chaincode does (see the
+ * patternmatcher package of the chaincode's utilities), so that the SDK resolves the same policy for an address.
+ *
+ * A pattern is a sequence of segments separated by ':', matched against the segments of the address:
+ * - a literal character matches itself; '\' escapes the character that follows it (e.g. '\*' or '\[')
+ * - '?' matches any single character within a segment
+ * - '[abc]', '[a-z]' match any single character in the class within a segment; '[!a-z]' or '[^a-z]' any character not in it
+ * - '*' matches any sequence of characters within a segment, so a segment '*' matches exactly one segment
+ * - a segment '**' matches any number of segments, including none
+ * - a '*' at the end of the pattern matches the remainder of the address, as in `mychannel:simplestate:*`
+ *
+ * When several patterns match an address, the most specific one takes precedence:
+ * 1. a pattern without wildcards, or identical to the address
+ * 2. the pattern with more literal characters
+ * 3. the pattern with fewer wildcards matching any number of segments ('**' or a trailing '*')
+ * 4. the pattern with fewer '*' wildcards
+ * 5. the pattern with more character classes
+ * 6. the pattern with fewer '?' wildcards
+ * 7. the pattern appearing first
+ **/
+/** End file docs */
+
+const segmentSeparator = ":";
+
+enum TokenKind {
+  Literal,
+  AnyChar,
+  Class,
+  Star,
+}
+
+interface ClassRange {
+  low: string;
+  high: string;
+}
+
+interface Token {
+  kind: TokenKind;
+  char?: string;
+  negated?: boolean;
+  ranges?: ClassRange[];
+  // a trailing '*' matching the remainder of the address, across segments
+  anyTail?: boolean;
+}
+
+interface Specificity {
+  exact: boolean;
+  literals: number;
+  multiSegmentStars: number;
+  stars: number;
+  anyChars: number;
+  classes: number;
+}
+
+interface Pattern {
+  segments: Token[][];
+  // segments that are '**'
+  multiStar: boolean[];
+  specificity: Specificity;
+}
+
+// Split a string into its code points, so that characters outside the BMP count as one character
+const toCodePoints = (text: string): string[] => {
+  const codePoints: string[] = [];
+  for (let i = 0; i < text.length; i++) {
+    const code = text.charCodeAt(i);
+    if (code >= 0xd800 && code <= 0xdbff && i + 1 < text.length) {
+      codePoints.push(text.substr(i, 2));
+      i++;
+    } else {
+      codePoints.push(text.charAt(i));
+    }
+  }
+  return codePoints;
+};
+
+const compareCodePoints = (a: string, b: string): number =>
+  a.codePointAt(0) - b.codePointAt(0);
+
+/**
+ * Compare two specificities: returns a positive number if s takes precedence over other, a negative number if other
+ * takes precedence over s, and 0 if neither does
+ **/
+const compareSpecificity = (s: Specificity, other: Specificity): number => {
+  if (s.exact !== other.exact) {
+    return s.exact ? 1 : -1;
+  }
+  if (s.literals !== other.literals) {
+    return s.literals - other.literals;
+  }
+  if (s.multiSegmentStars !== other.multiSegmentStars) {
+    return other.multiSegmentStars - s.multiSegmentStars;
+  }
+  if (s.stars !== other.stars) {
+    return other.stars - s.stars;
+  }
+  if (s.classes !== other.classes) {
+    return s.classes - other.classes;
+  }
+  return other.anyChars - s.anyChars;
+};
+
+// Parse the character class starting at chars[start], returning its token and the position of its closing ']'
+const parseClass = (chars: string[], start: number): [Token, number] => {
+  const classToken: Token = {
+    kind: TokenKind.Class,
+    negated: false,
+    ranges: [],
+  };
+  let i = start + 1;
+  if (i < chars.length && (chars[i] === "!" || chars[i] === "^")) {
+    classToken.negated = true;
+    i++;
+  }
+  for (
+    ;
+    i < chars.length && (chars[i] !== "]" || classToken.ranges.length === 0);
+    i++
+  ) {
+    let low = chars[i];
+    if (low === "\\") {
+      if (i + 1 >= chars.length) {
+        break;
+      }
+      i++;
+      low = chars[i];
+    }
+    let high = low;
+    if (i + 2 < chars.length && chars[i + 1] === "-" && chars[i + 2] !== "]") {
+      high = chars[i + 2];
+      i += 2;
+      if (compareCodePoints(high, low) < 0) {
+        throw new Error(
+          `invalid range '${low}-${high}' in character class at position ${start}`,
+        );
+      }
+    }
+    classToken.ranges.push({ low, high });
+  }
+  if (i >= chars.length) {
+    throw new Error(`unterminated character class at position ${start}`);
+  }
+  return [classToken, i];
+};
+
+/**
+ * Parse a pattern, throwing an error if it is not well-formed
+ **/
+const compilePattern = (pattern: string): Pattern => {
+  if (pattern === "") {
+    throw new Error("pattern is empty");
+  }
+  const p: Pattern = {
+    segments: [],
+    multiStar: [],
+    specificity: {
+      exact: false,
+      literals: 0,
+      multiSegmentStars: 0,
+      stars: 0,
+      anyChars: 0,
+      classes: 0,
+    },
+  };
+  const chars = toCodePoints(pattern);
+  let segment: Token[] = [];
+  const endSegment = () => {
+    if (
+      segment.length === 2 &&
+      segment[0].kind === TokenKind.Star &&
+      segment[1].kind === TokenKind.Star
+    ) {
+      p.segments.push([]);
+      p.multiStar.push(true);
+      p.specificity.multiSegmentStars++;
+    } else {
+      for (let i = 1; i < segment.length; i++) {
+        if (
+          segment[i - 1].kind === TokenKind.Star &&
+          segment[i].kind === TokenKind.Star
+        ) {
+          throw new Error("'**' must be a whole segment");
+        }
+      }
+      p.segments.push(segment);
+      p.multiStar.push(false);
+    }
+    segment = [];
+  };
+  for (let i = 0; i < chars.length; i++) {
+    switch (chars[i]) {
+      case "\\":
+        if (i + 1 >= chars.length) {
+          throw new Error("pattern ends with an escape character");
+        }
+        i++;
+        if (chars[i] === segmentSeparator) {
+          throw new Error(
+            `segment separator '${segmentSeparator}' cannot be escaped`,
+          );
+        }
+        segment.push({ kind: TokenKind.Literal, char: chars[i] });
+        p.specificity.literals++;
+        break;
+      case segmentSeparator:
+        endSegment();
+        p.specificity.literals++;
+        break;
+      case "?":
+        segment.push({ kind: TokenKind.AnyChar });
+        p.specificity.anyChars++;
+        break;
+      case "*":
+        segment.push({ kind: TokenKind.Star });
+        break;
+      case "[": {
+        const [classToken, end] = parseClass(chars, i);
+        segment.push(classToken);
+        p.specificity.classes++;
+        i = end;
+        break;
+      }
+      default:
+        segment.push({ kind: TokenKind.Literal, char: chars[i] });
+        p.specificity.literals++;
+    }
+  }
+  endSegment();
+  p.segments.forEach((tokens, i) => {
+    tokens.forEach((t, j) => {
+      if (t.kind !== TokenKind.Star) {
+        return;
+      }
+      if (i === p.segments.length - 1 && j === tokens.length - 1) {
+        t.anyTail = true;
+        p.specificity.multiSegmentStars++;
+      } else {
+        p.specificity.stars++;
+      }
+    });
+  });
+  p.specificity.exact =
+    p.specificity.multiSegmentStars === 0 &&
+    p.specificity.stars === 0 &&
+    p.specificity.anyChars === 0 &&
+    p.specificity.classes === 0;
+  return p;
+};
+
+/**
+ * Check that a pattern is well-formed
+ **/
+const validPattern = (pattern: string): boolean => {
+  try {
+    compilePattern(pattern);
+    return true;
+  } catch (e) {
+    return false;
+  }
+};
+
+const matchChar = (t: Token, c: string): boolean => {
+  switch (t.kind) {
+    case TokenKind.Literal:
+      return t.char === c;
+    case TokenKind.AnyChar:
+      return true;
+    case TokenKind.Class:
+      for (const r of t.ranges) {
+        if (
+          compareCodePoints(c, r.low) >= 0 &&
+          compareCodePoints(c, r.high) <= 0
+        ) {
+          return !t.negated;
+        }
+      }
+      return t.negated;
+  }
+  return false;
+};
+
+// Match the tokens of a segment against text, where matched[i][j] records whether tokens[i:] match text[j:]
+const matchTokens = (tokens: Token[], text: string[]): boolean => {
+  const matched: boolean[][] = [];
+  for (let i = 0; i <= tokens.length; i++) {
+    matched.push(new Array(text.length + 1).fill(false));
+  }
+  matched[tokens.length][text.length] = true;
+  for (let i = tokens.length - 1; i >= 0; i--) {
+    for (let j = text.length; j >= 0; j--) {
+      const t = tokens[i];
+      if (t.kind === TokenKind.Star) {
+        matched[i][j] =
+          matched[i + 1][j] ||
+          (j < text.length &&
+            (t.anyTail || text[j] !== segmentSeparator) &&
+            matched[i][j + 1]);
+      } else {
+        matched[i][j] =
+          j < text.length &&
+          text[j] !== segmentSeparator &&
+          matchChar(t, text[j]) &&
+          matched[i + 1][j + 1];
+      }
+    }
+  }
+  return matched[0][0];
+};
+
+const matchSegments = (
+  p: Pattern,
+  patternIndex: number,
+  addressSegments: string[],
+): boolean => {
+  if (patternIndex === p.segments.length) {
+    return addressSegments.length === 0;
+  }
+  if (p.multiStar[patternIndex]) {
+    for (let consumed = 0; consumed <= addressSegments.length; consumed++) {
+      if (matchSegments(p, patternIndex + 1, addressSegments.slice(consumed))) {
+        return true;
+      }
+    }
+    return false;
+  }
+  if (addressSegments.length === 0) {
+    return false;
+  }
+  const segment = p.segments[patternIndex];
+  if (patternIndex === p.segments.length - 1) {
+    // the last segment can match the remainder of the address when it ends
+    // with a '*'
+    if (segment.length > 0 && segment[segment.length - 1].anyTail) {
+      return matchTokens(
+        segment,
+        toCodePoints(addressSegments.join(segmentSeparator)),
+      );
+    }
+    return (
+      addressSegments.length === 1 &&
+      matchTokens(segment, toCodePoints(addressSegments[0]))
+    );
+  }
+  return (
+    matchTokens(segment, toCodePoints(addressSegments[0])) &&
+    matchSegments(p, patternIndex + 1, addressSegments.slice(1))
+  );
+};
+
+/**
+ * Check whether the address matches the pattern; an invalid pattern matches nothing
+ **/
+const isPatternAndAddressMatch = (
+  pattern: string,
+  address: string,
+): boolean => {
+  let p: Pattern;
+  try {
+    p = compilePattern(pattern);
+  } catch (e) {
+    return false;
+  }
+  return matchSegments(p, 0, address.split(segmentSeparator));
+};
+
+/**
+ * Get the index of the pattern that takes precedence among those matching the address, or -1 if none matches.
+ * Invalid patterns are ignored.
+ **/
+const getBestMatch = (patterns: string[], address: string): number => {
+  let bestIndex = -1;
+  let bestSpecificity: Specificity = null;
+  patterns.forEach((pattern, i) => {
+    let specificity: Specificity;
+    if (pattern === address) {
+      specificity = {
+        exact: true,
+        literals: toCodePoints(pattern).length,
+        multiSegmentStars: 0,
+        stars: 0,
+        anyChars: 0,
+        classes: 0,
+      };
+    } else {
+      let p: Pattern;
+      try {
+        p = compilePattern(pattern);
+      } catch (e) {
+        return;
+      }
+      if (!matchSegments(p, 0, address.split(segmentSeparator))) {
+        return;
+      }
+      specificity = p.specificity;
+    }
+    if (bestIndex < 0 || compareSpecificity(specificity, bestSpecificity) > 0) {
+      bestIndex = i;
+      bestSpecificity = specificity;
+    }
+  });
+  return bestIndex;
+};
+
+export { validPattern, isPatternAndAddressMatch, getBestMatch };
@@ -9,6 +9,7 @@
  **/
 /** End file docs */
 import { promisify } from "util";
+import { v4 as uuidv4 } from "uuid";
 // A better way to handle errors for promises
 function handlePromise<T>(promise: Promise<T>): Promise<[T?, Error?]> {
   const result: Promise<[T?, Error?]> = promise
@@ -51,8 +52,16 @@ function parseAddress(address: string): {
   };
 }
 
+/**
+ * Creates a query nonce prefixed with its issue time (in unix seconds), which lets the
+ * source network's interop chaincode reject stale queries.
+ **/
+function generateNonce(): string {
+  return `${Math.floor(Date.now() / 1000)}:${uuidv4()}`;
+}
+
 async function delay(ms: number) {
   await new Promise((f) => setTimeout(f, ms));
 }
 
-export { handlePromise, promisifyAll, parseAddress, delay };
+export { handlePromise, promisifyAll, parseAddress, generateNonce, delay };
@@ -349,6 +349,17 @@ describe("InteroperableHelper", () => {
             pattern: "notmatching",
             policy: { type: "Signature", criteria: ["NotMatching"] },
           },
+          {
+            pattern: "mychannel:simplestate:Count:*",
+            policy: { type: "Expression", criteria: ["count >= 6"] },
+          },
+          {
+            pattern: "mychannel:simplestate:Either:*",
+            policy: {
+              type: "Expression",
+              criteria: ["Org1MSP || (Org2MSP && Org1MSP)"],
+            },
+          },
         ],
         viewPatterns: [],
       };
@@ -373,6 +384,19 @@ describe("InteroperableHelper", () => {
       expect(policyJSON.length).to.equal(1);
       expect(policyJSON[0]).to.be.equal("Org1MSP");
     });
+    it("get the signers of expression policies", async () => {
+      // An expression with a count threshold yields no signers, so the driver uses all endorsers
+      let policyJSON = await getPolicyCriteriaForAddress(
+        interopcc,
+        "localhost:9080/network1/mychannel:simplestate:Count:a",
+      );
+      expect(policyJSON).to.deep.equal([]);
+      policyJSON = await getPolicyCriteriaForAddress(
+        interopcc,
+        "localhost:9080/network1/mychannel:simplestate:Either:a",
+      );
+      expect(policyJSON).to.deep.equal(["Org1MSP", "Org2MSP"]);
+    });
     it("fail to match verificationPolicy", async () => {
       // no match found
       let policyJSON = await getPolicyCriteriaForAddress(
@@ -420,6 +444,17 @@ describe("InteroperableHelper", () => {
             pattern: "notmatching",
             policy: { type: "Signature", criteria: ["NotMatching"] },
           },
+          {
+            pattern: "mychannel:simplestate:Count:*",
+            policy: { type: "Expression", criteria: ["count >= 6"] },
+          },
+          {
+            pattern: "mychannel:simplestate:Either:*",
+            policy: {
+              type: "Expression",
+              criteria: ["Org1MSP || (Org2MSP && Org1MSP)"],
+            },
+          },
         ],
         viewPatterns: [],
       };
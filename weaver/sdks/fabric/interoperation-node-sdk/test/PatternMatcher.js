@@ -0,0 +1,157 @@
+/*
+ * Copyright IBM Corp. All Rights Reserved.
+ *
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+/* eslint-disable no-unused-expressions */
+
+const chai = require("chai");
+
+const { expect } = chai;
+
+const {
+  validPattern,
+  isPatternAndAddressMatch,
+  getBestMatch,
+} = require("../src/PatternMatcher");
+
+describe("PatternMatcher", () => {
+  const address = "mychannel:simplestate:Read:a";
+
+  it("validate patterns", () => {
+    [
+      "valid:no:star",
+      "valid:star:*",
+      "*",
+      "abcd*",
+      "abcd",
+      "ab*cd*",
+      "test:*:star",
+      "mychannel:cc:Read:**",
+      "mychannel:cc:[RW]*:?",
+      "mychannel:cc:[!a-z]:x",
+      "literal\\*star",
+      "[]]",
+    ].forEach((pattern) => expect(validPattern(pattern), pattern).to.be.true);
+    [
+      "",
+      "mychannel:cc:[Read",
+      "mychannel:cc:[z-a]",
+      "mychannel:cc**",
+      "mychannel\\",
+      "mychannel\\:cc",
+    ].forEach((pattern) => expect(validPattern(pattern), pattern).to.be.false);
+  });
+
+  it("match addresses against patterns", () => {
+    // A trailing star matches the remainder of the address
+    expect(isPatternAndAddressMatch("test:*", "test:star")).to.be.true;
+    expect(isPatternAndAddressMatch("mychannel:*", address)).to.be.true;
+    expect(isPatternAndAddressMatch("*", "test:exact")).to.be.true;
+    expect(isPatternAndAddressMatch("test:exact", "test:exact")).to.be.true;
+    expect(isPatternAndAddressMatch("notMatch:*", "test:exact")).to.be.false;
+    expect(isPatternAndAddressMatch("exact*", "test:exact")).to.be.false;
+    expect(isPatternAndAddressMatch("test:*", "test")).to.be.false;
+
+    // A star elsewhere matches within a single segment
+    expect(isPatternAndAddressMatch("mychannel:*:Read:a", address)).to.be.true;
+    expect(
+      isPatternAndAddressMatch(
+        "mychannel:*:Read:a",
+        "mychannel:simple:state:Read:a",
+      ),
+    ).to.be.false;
+
+    // Argument wildcards
+    expect(
+      isPatternAndAddressMatch(
+        "mychannel:simplestate:Read:**",
+        "mychannel:simplestate:Read",
+      ),
+    ).to.be.true;
+    expect(isPatternAndAddressMatch("mychannel:simplestate:**:a", address)).to
+      .be.true;
+    expect(
+      isPatternAndAddressMatch(
+        "mychannel:simplestate:**:a",
+        "mychannel:simplestate:Read:b",
+      ),
+    ).to.be.false;
+
+    // Single characters and character classes
+    expect(
+      isPatternAndAddressMatch(
+        "mychannel:simplestate:Read:key[0-9]",
+        "mychannel:simplestate:Read:key7",
+      ),
+    ).to.be.true;
+    expect(
+      isPatternAndAddressMatch(
+        "mychannel:simplestate:Read:key[!0-9]",
+        "mychannel:simplestate:Read:key7",
+      ),
+    ).to.be.false;
+    expect(isPatternAndAddressMatch("mychannel:simplestate:Read:?", address)).to
+      .be.true;
+    expect(
+      isPatternAndAddressMatch(
+        "mychannel:simplestate:Read:\\*",
+        "mychannel:simplestate:Read:*",
+      ),
+    ).to.be.true;
+
+    // Invalid patterns match nothing
+    expect(isPatternAndAddressMatch("mychannel:[", "mychannel:[")).to.be.false;
+  });
+
+  it("pick the most specific matching pattern", () => {
+    expect(
+      getBestMatch(["otherchannel:*", "mychannel:simplestate:Write:*"], address),
+    ).to.equal(-1);
+    // Exact matches take precedence
+    expect(
+      getBestMatch(["mychannel:simplestate:Read:*", address], address),
+    ).to.equal(1);
+    // Then patterns with more literal characters
+    expect(
+      getBestMatch(["mychannel:*", "mychannel:simplestate:*", "*"], address),
+    ).to.equal(1);
+    // Then patterns with fewer multi-segment wildcards
+    expect(
+      getBestMatch(
+        ["mychannel:simplestate:*:a", "mychannel:simplestate:**:a"],
+        address,
+      ),
+    ).to.equal(0);
+    // Then patterns with fewer stars
+    expect(
+      getBestMatch(
+        ["mychannel:simplestate:*:a", "mychannel:simplestate:????:a"],
+        address,
+      ),
+    ).to.equal(1);
+    // Then patterns with more character classes, or fewer single character wildcards
+    expect(
+      getBestMatch(
+        ["mychannel:simplestate:Read:*", "mychannel:simplestate:Read:[a-c]*"],
+        address,
+      ),
+    ).to.equal(1);
+    expect(
+      getBestMatch(
+        ["mychannel:simplestate:Read:?", "mychannel:simplestate:Read:[a-c]"],
+        address,
+      ),
+    ).to.equal(1);
+    // Then the first pattern
+    expect(
+      getBestMatch(
+        ["mychannel:simplestate:R*:a", "mychannel:simplestate:*d:a"],
+        address,
+      ),
+    ).to.equal(0);
+    // Invalid patterns are ignored
+    expect(getBestMatch(["mychannel:[", "mychannel:*"], address)).to.equal(1);
+  });
+});